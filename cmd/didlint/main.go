@@ -0,0 +1,132 @@
+// Command didlint checks DID documents and reports their findings, see did.Document.Lint.
+//
+// Usage:
+//
+//	didlint [-json] [-strict] [file ...]
+//
+// The documents are read from the files, or from the standard input if none is given. The exit status is 1 if a
+// document has findings of error severity, or warnings in strict mode, and 2 if a document cannot be parsed.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/zRich/zFusion/did"
+)
+
+var (
+	jsonOutput = flag.Bool("json", false, "print the findings as JSON")
+	strict     = flag.Bool("strict", false, "fail on warnings")
+)
+
+// report holds the findings of a document.
+type report struct {
+	File     string        `json:"file"`
+	Error    string        `json:"error,omitempty"`
+	Findings []did.Finding `json:"findings"`
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: didlint [-json] [-strict] [file ...]\n")
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	files := flag.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	status := 0
+	reports := make([]report, 0, len(files))
+
+	for _, file := range files {
+		r := lint(file)
+		reports = append(reports, r)
+
+		switch {
+		case r.Error != "":
+			status = 2
+		case status == 0 && failed(r.Findings):
+			status = 1
+		}
+
+		if !*jsonOutput {
+			printReport(r)
+		}
+	}
+
+	if *jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+
+		if err := enc.Encode(reports); err != nil {
+			fmt.Fprintf(os.Stderr, "didlint: %v\n", err)
+			os.Exit(2)
+		}
+	}
+
+	os.Exit(status)
+}
+
+func lint(file string) report {
+	r := report{File: file, Findings: []did.Finding{}}
+
+	var (
+		data []byte
+		err  error
+	)
+
+	if file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+
+	if err != nil {
+		r.Error = err.Error()
+
+		return r
+	}
+
+	doc, err := did.ParseDocument(data)
+	if err != nil {
+		r.Error = err.Error()
+
+		return r
+	}
+
+	if findings := doc.Lint(); findings != nil {
+		r.Findings = findings
+	}
+
+	return r
+}
+
+func failed(findings []did.Finding) bool {
+	for _, f := range findings {
+		if f.Severity == did.SeverityError || *strict {
+			return true
+		}
+	}
+
+	return false
+}
+
+func printReport(r report) {
+	if r.Error != "" {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", r.File, r.Error)
+
+		return
+	}
+
+	for _, f := range r.Findings {
+		fmt.Printf("%s: %s\n", r.File, f)
+	}
+}
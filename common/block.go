@@ -0,0 +1,82 @@
+package common
+
+import (
+	"bytes"
+	"errors"
+	"hash"
+
+	"github.com/zRich/zFusion/common/crypto"
+)
+
+// ErrDataHashMismatch is returned when the data hash of a block header does not match the block data.
+var ErrDataHashMismatch = errors.New("block data hash mismatch")
+
+// BlockDataHash returns the data hash of a block header: the root of the Merkle tree of the data entries, hashed
+// with alg. The tree is built as in RFC 6962, with leaves H(0x00 || entry) and nodes H(0x01 || left || right),
+// and the hash of no data is H("").
+func BlockDataHash(data *BlockData, alg crypto.HashAlgorithm) ([]byte, error) {
+	h, err := crypto.NewHash(alg)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := data.GetData()
+	if len(entries) == 0 {
+		return h.Sum(nil), nil
+	}
+
+	return merkleRoot(h, entries), nil
+}
+
+// NewBlockHeader returns the header of block number with the given data, chained to the hash of the previous
+// header. The data hash is computed with alg.
+func NewBlockHeader(number uint64, previousHash []byte, data *BlockData, alg crypto.HashAlgorithm) (*BlockHeader,
+	error) {
+	dataHash, err := BlockDataHash(data, alg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BlockHeader{Number: number, PreviousHash: previousHash, DataHash: dataHash}, nil
+}
+
+// VerifyDataHash checks that the data hash of the block header, computed with alg, matches the block data.
+func (x *Block) VerifyDataHash(alg crypto.HashAlgorithm) error {
+	dataHash, err := BlockDataHash(x.GetData(), alg)
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(dataHash, x.GetHeader().GetDataHash()) {
+		return ErrDataHashMismatch
+	}
+
+	return nil
+}
+
+func merkleRoot(h hash.Hash, entries [][]byte) []byte {
+	h.Reset()
+
+	if len(entries) == 1 {
+		h.Write([]byte{0x00}) //nolint:errcheck
+		h.Write(entries[0])   //nolint:errcheck
+
+		return h.Sum(nil)
+	}
+
+	// split at the largest power of two smaller than the number of entries
+	k := 1
+	for k<<1 < len(entries) {
+		k <<= 1
+	}
+
+	left := merkleRoot(h, entries[:k])
+	right := merkleRoot(h, entries[k:])
+
+	h.Reset()
+	h.Write([]byte{0x01}) //nolint:errcheck
+	h.Write(left)         //nolint:errcheck
+	h.Write(right)        //nolint:errcheck
+
+	return h.Sum(nil)
+}
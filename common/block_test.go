@@ -0,0 +1,63 @@
+package common
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zRich/zFusion/common/crypto"
+	"github.com/zRich/zFusion/common/crypto/sm3"
+)
+
+func TestBlockDataHash(t *testing.T) {
+	// empty tree and single leaf of RFC 6962 section 2.1.
+	empty, err := BlockDataHash(&BlockData{}, crypto.SHA256)
+	require.NoError(t, err)
+	require.Equal(t, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", hex.EncodeToString(empty))
+
+	single, err := BlockDataHash(&BlockData{Data: [][]byte{[]byte("tx")}}, crypto.SHA256)
+	require.NoError(t, err)
+
+	leaf := sha256.Sum256([]byte("\x00tx"))
+	require.Equal(t, leaf[:], single)
+
+	entries := [][]byte{[]byte("tx1"), []byte("tx2"), []byte("tx3")}
+
+	// H(0x01 || H(0x01 || H(0x00 || tx1) || H(0x00 || tx2)) || H(0x00 || tx3)) for three entries
+	leaves := make([][]byte, len(entries))
+
+	for i, entry := range entries {
+		sum := sm3.Sum(append([]byte{0x00}, entry...))
+		leaves[i] = sum[:]
+	}
+
+	node := sm3.Sum(append(append([]byte{0x01}, leaves[0]...), leaves[1]...))
+	root := sm3.Sum(append(append([]byte{0x01}, node[:]...), leaves[2]...))
+
+	dataHash, err := BlockDataHash(&BlockData{Data: entries}, crypto.SM3)
+	require.NoError(t, err)
+	require.Equal(t, root[:], dataHash)
+
+	sha256Hash, err := BlockDataHash(&BlockData{Data: entries}, crypto.SHA256)
+	require.NoError(t, err)
+	require.NotEqual(t, dataHash, sha256Hash)
+
+	_, err = BlockDataHash(&BlockData{Data: entries}, "MD5")
+	require.Error(t, err)
+}
+
+func TestBlock_VerifyDataHash(t *testing.T) {
+	data := &BlockData{Data: [][]byte{[]byte("tx1"), []byte("tx2")}}
+
+	header, err := NewBlockHeader(1, []byte("previous"), data, crypto.SM3)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), header.GetNumber())
+
+	block := &Block{Header: header, Data: data}
+	require.NoError(t, block.VerifyDataHash(crypto.SM3))
+	require.ErrorIs(t, block.VerifyDataHash(crypto.SHA256), ErrDataHashMismatch)
+
+	block.Data = &BlockData{Data: [][]byte{[]byte("tx1")}}
+	require.ErrorIs(t, block.VerifyDataHash(crypto.SM3), ErrDataHashMismatch)
+}
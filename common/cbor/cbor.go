@@ -0,0 +1,552 @@
+// Package cbor encodes the JSON data model in CBOR (RFC 8949) and decodes it back.
+//
+// Marshal produces the deterministic encoding of RFC 8949 section 4.2.1: definite lengths, integers and floats
+// in their shortest form, and map keys sorted by the bytewise order of their encoding. Unmarshal accepts any
+// well-formed CBOR data item which maps to the JSON data model, that is without byte strings, tags, undefined,
+// or non-finite floats.
+package cbor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+)
+
+// ErrInvalid is returned when decoding data which is not a well-formed CBOR data item of the JSON data model.
+var ErrInvalid = errors.New("invalid CBOR")
+
+// maxDepth limits the nesting of arrays and maps.
+const maxDepth = 256
+
+// Major types.
+const (
+	majorUnsigned = 0
+	majorNegative = 1
+	majorBytes    = 2
+	majorText     = 3
+	majorArray    = 4
+	majorMap      = 5
+	majorTag      = 6
+	majorSimple   = 7
+)
+
+// Additional information of major type 7.
+const (
+	simpleFalse   = 20
+	simpleTrue    = 21
+	simpleNull    = 22
+	simpleFloat16 = 25
+	simpleFloat32 = 26
+	simpleFloat64 = 27
+)
+
+const indefinite = 31
+
+// FromJSON converts JSON to CBOR.
+func FromJSON(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v interface{}
+
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	if dec.More() {
+		return nil, errors.New("invalid JSON: data after the top-level value")
+	}
+
+	return Marshal(v)
+}
+
+// ToJSON converts CBOR to JSON.
+func ToJSON(data []byte) ([]byte, error) {
+	v, err := Unmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(v)
+}
+
+// Marshal encodes a value of the JSON data model, as decoded by encoding/json: nil, bool, float64, json.Number,
+// string, []interface{} or map[string]interface{}.
+func Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+
+	if err := encode(&buf, v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func encode(buf *bytes.Buffer, v interface{}) error {
+	switch value := v.(type) {
+	case nil:
+		buf.WriteByte(majorSimple<<5 | simpleNull)
+	case bool:
+		if value {
+			buf.WriteByte(majorSimple<<5 | simpleTrue)
+		} else {
+			buf.WriteByte(majorSimple<<5 | simpleFalse)
+		}
+	case json.Number:
+		return encodeNumber(buf, value)
+	case float64:
+		return encodeFloat(buf, value)
+	case string:
+		writeHead(buf, majorText, uint64(len(value)))
+		buf.WriteString(value)
+	case []interface{}:
+		writeHead(buf, majorArray, uint64(len(value)))
+
+		for _, item := range value {
+			if err := encode(buf, item); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		return encodeMap(buf, value)
+	default:
+		return fmt.Errorf("cbor: unsupported type %T", v)
+	}
+
+	return nil
+}
+
+func encodeNumber(buf *bytes.Buffer, n json.Number) error {
+	if u, err := strconv.ParseUint(string(n), 10, 64); err == nil {
+		writeHead(buf, majorUnsigned, u)
+
+		return nil
+	}
+
+	if i, err := strconv.ParseInt(string(n), 10, 64); err == nil {
+		if i == 0 { // -0
+			writeHead(buf, majorUnsigned, 0)
+		} else {
+			writeHead(buf, majorNegative, uint64(-(i + 1)))
+		}
+
+		return nil
+	}
+
+	f, err := n.Float64()
+	if err != nil {
+		return fmt.Errorf("cbor: invalid number %s: %w", n, err)
+	}
+
+	return encodeFloat(buf, f)
+}
+
+// encodeFloat encodes f in the shortest floating-point form which preserves its value.
+func encodeFloat(buf *bytes.Buffer, f float64) error {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return fmt.Errorf("cbor: unsupported float %v", f)
+	}
+
+	if h, ok := toFloat16(f); ok {
+		buf.WriteByte(majorSimple<<5 | simpleFloat16)
+		buf.Write([]byte{byte(h >> 8), byte(h)})
+
+		return nil
+	}
+
+	if f32 := float32(f); float64(f32) == f {
+		var b [4]byte
+
+		binary.BigEndian.PutUint32(b[:], math.Float32bits(f32))
+		buf.WriteByte(majorSimple<<5 | simpleFloat32)
+		buf.Write(b[:])
+
+		return nil
+	}
+
+	var b [8]byte
+
+	binary.BigEndian.PutUint64(b[:], math.Float64bits(f))
+	buf.WriteByte(majorSimple<<5 | simpleFloat64)
+	buf.Write(b[:])
+
+	return nil
+}
+
+func encodeMap(buf *bytes.Buffer, m map[string]interface{}) error {
+	type entry struct {
+		key   []byte
+		value interface{}
+	}
+
+	entries := make([]entry, 0, len(m))
+
+	for k, v := range m {
+		var key bytes.Buffer
+
+		writeHead(&key, majorText, uint64(len(k)))
+		key.WriteString(k)
+
+		entries = append(entries, entry{key: key.Bytes(), value: v})
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return bytes.Compare(entries[i].key, entries[j].key) < 0 })
+
+	writeHead(buf, majorMap, uint64(len(m)))
+
+	for _, e := range entries {
+		buf.Write(e.key)
+
+		if err := encode(buf, e.value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeHead writes the initial byte and argument of a data item, in the shortest form.
+func writeHead(buf *bytes.Buffer, major byte, arg uint64) {
+	switch {
+	case arg < 24:
+		buf.WriteByte(major<<5 | byte(arg))
+	case arg <= math.MaxUint8:
+		buf.Write([]byte{major<<5 | 24, byte(arg)})
+	case arg <= math.MaxUint16:
+		var b [2]byte
+
+		binary.BigEndian.PutUint16(b[:], uint16(arg))
+		buf.WriteByte(major<<5 | 25)
+		buf.Write(b[:])
+	case arg <= math.MaxUint32:
+		var b [4]byte
+
+		binary.BigEndian.PutUint32(b[:], uint32(arg))
+		buf.WriteByte(major<<5 | 26)
+		buf.Write(b[:])
+	default:
+		var b [8]byte
+
+		binary.BigEndian.PutUint64(b[:], arg)
+		buf.WriteByte(major<<5 | 27)
+		buf.Write(b[:])
+	}
+}
+
+// toFloat16 returns the half-precision encoding of f, if it represents f exactly.
+func toFloat16(f float64) (uint16, bool) {
+	f32 := float32(f)
+	if float64(f32) != f {
+		return 0, false
+	}
+
+	bits := math.Float32bits(f32)
+	sign := uint16(bits>>16) & 0x8000
+	exp := int(bits>>23&0xff) - 127
+	mant := bits & 0x7fffff
+
+	switch {
+	case f == 0:
+		return sign, true
+	case exp >= -14 && exp <= 15:
+		// normal half: 10 bits of mantissa.
+		if mant&0x1fff != 0 {
+			return 0, false
+		}
+
+		return sign | uint16(exp+15)<<10 | uint16(mant>>13), true
+	case exp >= -24 && exp < -14:
+		// subnormal half: the implicit leading bit becomes explicit.
+		shift := uint(-14-exp) + 13
+		full := mant | 0x800000
+
+		if full&(1<<shift-1) != 0 {
+			return 0, false
+		}
+
+		return sign | uint16(full>>shift), true
+	default:
+		return 0, false
+	}
+}
+
+func fromFloat16(h uint16) float64 {
+	sign := 1.0
+	if h&0x8000 != 0 {
+		sign = -1
+	}
+
+	exp := int(h >> 10 & 0x1f)
+	mant := float64(h & 0x3ff)
+
+	switch exp {
+	case 0:
+		return sign * math.Ldexp(mant, -24)
+	case 0x1f:
+		if mant == 0 {
+			return sign * math.Inf(1)
+		}
+
+		return math.NaN()
+	default:
+		return sign * math.Ldexp(mant+1024, exp-25)
+	}
+}
+
+// Unmarshal decodes a CBOR data item to a value of the JSON data model: nil, bool, json.Number, string,
+// []interface{} or map[string]interface{}.
+func Unmarshal(data []byte) (interface{}, error) {
+	d := &decoder{data: data}
+
+	v, err := d.decode(0)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.off != len(d.data) {
+		return nil, d.errorf("data after the top-level data item")
+	}
+
+	return v, nil
+}
+
+type decoder struct {
+	data []byte
+	off  int
+}
+
+func (d *decoder) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s at offset %d", ErrInvalid, fmt.Sprintf(format, args...), d.off)
+}
+
+// head reads the initial byte and argument of a data item.
+func (d *decoder) head() (byte, byte, uint64, error) {
+	if d.off >= len(d.data) {
+		return 0, 0, 0, d.errorf("unexpected end of data")
+	}
+
+	major, info := d.data[d.off]>>5, d.data[d.off]&0x1f
+	d.off++
+
+	var size int
+
+	switch {
+	case info < 24:
+		return major, info, uint64(info), nil
+	case info == indefinite:
+		return major, info, 0, nil
+	case info > simpleFloat64:
+		return 0, 0, 0, d.errorf("reserved additional information %d", info)
+	default:
+		size = 1 << (info - 24)
+	}
+
+	if len(d.data)-d.off < size {
+		return 0, 0, 0, d.errorf("unexpected end of data")
+	}
+
+	var arg uint64
+	for _, b := range d.data[d.off : d.off+size] {
+		arg = arg<<8 | uint64(b)
+	}
+
+	d.off += size
+
+	return major, info, arg, nil
+}
+
+//nolint:gocyclo
+func (d *decoder) decode(depth int) (interface{}, error) {
+	if depth > maxDepth {
+		return nil, d.errorf("nesting deeper than %d", maxDepth)
+	}
+
+	start := d.off
+
+	major, info, arg, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+
+	if info == indefinite {
+		switch major {
+		case majorUnsigned, majorNegative, majorTag:
+			d.off = start
+
+			return nil, d.errorf("invalid indefinite length")
+		case majorSimple:
+			d.off = start
+
+			return nil, d.errorf("unexpected break")
+		}
+	}
+
+	switch major {
+	case majorUnsigned:
+		return json.Number(strconv.FormatUint(arg, 10)), nil
+	case majorNegative:
+		n := new(big.Int).SetUint64(arg)
+
+		return json.Number(n.Neg(n.Add(n, big.NewInt(1))).String()), nil
+	case majorBytes:
+		return nil, d.errorf("byte strings are not supported")
+	case majorText:
+		return d.decodeText(info, arg)
+	case majorArray:
+		return d.decodeArray(depth, info, arg)
+	case majorMap:
+		return d.decodeMap(depth, info, arg)
+	case majorTag:
+		d.off = start
+
+		return nil, d.errorf("tags are not supported")
+	default:
+		return d.decodeSimple(start, info, arg)
+	}
+}
+
+func (d *decoder) decodeText(info byte, arg uint64) (interface{}, error) {
+	if info != indefinite {
+		if arg > uint64(len(d.data)-d.off) {
+			return nil, d.errorf("unexpected end of data")
+		}
+
+		s := d.data[d.off : d.off+int(arg)]
+		if !utf8.Valid(s) {
+			return nil, d.errorf("invalid UTF-8 text string")
+		}
+
+		d.off += int(arg)
+
+		return string(s), nil
+	}
+
+	var sb bytes.Buffer
+
+	for !d.isBreak() {
+		major, chunkInfo, chunkArg, err := d.head()
+		if err != nil {
+			return nil, err
+		}
+
+		if major != majorText || chunkInfo == indefinite {
+			return nil, d.errorf("invalid chunk of indefinite-length text string")
+		}
+
+		chunk, err := d.decodeText(chunkInfo, chunkArg)
+		if err != nil {
+			return nil, err
+		}
+
+		sb.WriteString(chunk.(string))
+	}
+
+	return sb.String(), nil
+}
+
+func (d *decoder) decodeArray(depth int, info byte, arg uint64) (interface{}, error) {
+	// each item takes at least one byte.
+	if info != indefinite && arg > uint64(len(d.data)-d.off) {
+		return nil, d.errorf("unexpected end of data")
+	}
+
+	items := make([]interface{}, 0, int(arg))
+
+	for i := uint64(0); info == indefinite && !d.isBreak() || info != indefinite && i < arg; i++ {
+		item, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+func (d *decoder) decodeMap(depth int, info byte, arg uint64) (interface{}, error) {
+	// each entry takes at least two bytes.
+	if info != indefinite && arg > uint64(len(d.data)-d.off)/2 {
+		return nil, d.errorf("unexpected end of data")
+	}
+
+	m := make(map[string]interface{}, int(arg))
+
+	for i := uint64(0); info == indefinite && !d.isBreak() || info != indefinite && i < arg; i++ {
+		keyOff := d.off
+
+		key, err := d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+
+		k, ok := key.(string)
+		if !ok {
+			d.off = keyOff
+
+			return nil, d.errorf("map key is not a text string")
+		}
+
+		if _, ok := m[k]; ok {
+			d.off = keyOff
+
+			return nil, d.errorf("duplicate map key %q", k)
+		}
+
+		m[k], err = d.decode(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+func (d *decoder) decodeSimple(start int, info byte, arg uint64) (interface{}, error) {
+	var f float64
+
+	switch info {
+	case simpleFalse:
+		return false, nil
+	case simpleTrue:
+		return true, nil
+	case simpleNull:
+		return nil, nil
+	case simpleFloat16:
+		f = fromFloat16(uint16(arg))
+	case simpleFloat32:
+		f = float64(math.Float32frombits(uint32(arg)))
+	case simpleFloat64:
+		f = math.Float64frombits(arg)
+	default:
+		d.off = start
+
+		return nil, d.errorf("unsupported simple value %d", arg)
+	}
+
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		d.off = start
+
+		return nil, d.errorf("unsupported float %v", f)
+	}
+
+	return json.Number(strconv.FormatFloat(f, 'g', -1, 64)), nil
+}
+
+// isBreak consumes the break stop code of an indefinite-length item, if it is next.
+func (d *decoder) isBreak() bool {
+	if d.off < len(d.data) && d.data[d.off] == majorSimple<<5|indefinite {
+		d.off++
+
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,137 @@
+package cbor
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromJSON(t *testing.T) {
+	// examples of RFC 8949 appendix A, with map keys in deterministic order.
+	for _, tc := range []struct {
+		json string
+		cbor string
+	}{
+		{`0`, "00"},
+		{`23`, "17"},
+		{`24`, "1818"},
+		{`1000`, "1903e8"},
+		{`1000000`, "1a000f4240"},
+		{`1000000000000`, "1b000000e8d4a51000"},
+		{`18446744073709551615`, "1bffffffffffffffff"},
+		{`-1`, "20"},
+		{`-1000`, "3903e7"},
+		{`0.0`, "f90000"},
+		{`1.5`, "f93e00"},
+		{`65504.0`, "f97bff"},
+		{`100000.0`, "fa47c35000"},
+		{`5.960464477539063e-8`, "f90001"},
+		{`0.00006103515625`, "f90400"},
+		{`-4.0`, "f9c400"},
+		{`-4.1`, "fbc010666666666666"},
+		{`1.0e+300`, "fb7e37e43c8800759c"},
+		{`false`, "f4"},
+		{`true`, "f5"},
+		{`null`, "f6"},
+		{`""`, "60"},
+		{`"IETF"`, "6449455446"},
+		{`"ü"`, "62c3bc"},
+		{`"水"`, "63e6b0b4"},
+		{`[]`, "80"},
+		{`[1, [2, 3], [4, 5]]`, "8301820203820405"},
+		{`{}`, "a0"},
+		{`{"a": 1, "b": [2, 3]}`, "a26161016162820203"},
+		{`{"b": 1, "a": 2, "aa": 3}`, "a3616102616201626161" + "03"},
+	} {
+		data, err := FromJSON([]byte(tc.json))
+		require.NoError(t, err, tc.json)
+		require.Equal(t, tc.cbor, hex.EncodeToString(data), tc.json)
+
+		decoded, err := ToJSON(data)
+		require.NoError(t, err, tc.json)
+
+		var expected, actual interface{}
+
+		require.NoError(t, json.Unmarshal([]byte(tc.json), &expected))
+		require.NoError(t, json.Unmarshal(decoded, &actual))
+		require.Equal(t, expected, actual, tc.json)
+	}
+
+	for _, invalid := range []string{``, `{`, `1 2`} {
+		_, err := FromJSON([]byte(invalid))
+		require.Error(t, err, invalid)
+	}
+
+	_, err := Marshal(map[string]interface{}{"a": []byte{}})
+	require.EqualError(t, err, "cbor: unsupported type []uint8")
+}
+
+func TestToJSON(t *testing.T) {
+	// non-deterministic encodings of RFC 8949 appendix A.
+	for cbor, expected := range map[string]string{
+		"3bffffffffffffffff":                 `-18446744073709551616`,
+		"fa3fc00000":                         `1.5`,
+		"fb3ff8000000000000":                 `1.5`,
+		"7f657374726561646d696e67ff":         `"streaming"`,
+		"9fff":                               `[]`,
+		"9f018202039f0405ffff":               `[1,[2,3],[4,5]]`,
+		"bf61610161629f0203ffff":             `{"a":1,"b":[2,3]}`,
+		"826161bf61626163ff":                 `["a",{"b":"c"}]`,
+		"a201020304":                         ``,
+		"1900ff":                             `255`,
+		"a2616101616102":                     ``,
+		"c074323031332d30332d32315432303a30": ``,
+	} {
+		data, err := hex.DecodeString(cbor)
+		require.NoError(t, err)
+
+		actual, err := ToJSON(data)
+		if expected == "" {
+			require.ErrorIs(t, err, ErrInvalid, cbor)
+
+			continue
+		}
+
+		require.NoError(t, err, cbor)
+		require.Equal(t, expected, string(actual), cbor)
+	}
+
+	for name, cbor := range map[string]string{
+		"empty":               "",
+		"truncated argument":  "19",
+		"truncated string":    "6449",
+		"truncated array":     "8301",
+		"byte string":         "4449455446",
+		"undefined":           "f7",
+		"simple value":        "f820",
+		"infinity":            "f97c00",
+		"NaN":                 "f97e00",
+		"invalid UTF-8":       "61ff",
+		"reserved":            "1c",
+		"break":               "ff",
+		"indefinite integer":  "1f",
+		"indefinite chunk":    "7f4161ff",
+		"trailing data":       "0000",
+		"huge array":          "9bffffffffffffffff",
+		"huge map":            "bb7fffffffffffffff00",
+		"unterminated stream": "9f01",
+	} {
+		data, err := hex.DecodeString(cbor)
+		require.NoError(t, err)
+
+		_, err = Unmarshal(data)
+		require.ErrorIs(t, err, ErrInvalid, name)
+	}
+
+	deep := make([]byte, maxDepth+2)
+	for i := range deep {
+		deep[i] = 0x81
+	}
+
+	deep[len(deep)-1] = 0x80
+
+	_, err := Unmarshal(deep)
+	require.ErrorIs(t, err, ErrInvalid)
+}
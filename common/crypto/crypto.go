@@ -0,0 +1,316 @@
+// Package crypto provides the signature primitives used to sign and verify DID operations, documents
+// and credentials, independently of how the keys are encoded in DID documents.
+package crypto
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/zRich/zFusion/common/crypto/secp256k1"
+	"github.com/zRich/zFusion/common/crypto/sm2"
+)
+
+// KeyType is the type of a public/private key pair.
+type KeyType string
+
+const (
+	// Ed25519 EdDSA key over Curve25519.
+	Ed25519 KeyType = "Ed25519"
+	// X25519 Diffie-Hellman key over Curve25519.
+	X25519 KeyType = "X25519"
+	// ECDSAP256 ECDSA key over NIST P-256.
+	ECDSAP256 KeyType = "P-256"
+	// ECDSAP384 ECDSA key over NIST P-384.
+	ECDSAP384 KeyType = "P-384"
+	// ECDSASecp256k1 ECDSA key over secp256k1.
+	ECDSASecp256k1 KeyType = "secp256k1"
+	// RSA RSA key, signatures use RSASSA-PSS with SHA-256.
+	RSA KeyType = "RSA"
+	// SM2 key over the sm2p256v1 curve of GB/T 32918, signatures use the default user ID sm2.DefaultID.
+	SM2 KeyType = "SM2"
+)
+
+// ErrInvalidSignature is returned when a signature does not verify.
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// Signer signs messages with a private key.
+type Signer interface {
+	// Sign signs msg. ECDSA signatures are returned in their fixed size r||s form.
+	Sign(msg []byte) ([]byte, error)
+	// KeyType returns the type of the signing key.
+	KeyType() KeyType
+	// PublicKeyBytes returns the public key, see Verify for the encodings.
+	PublicKeyBytes() []byte
+}
+
+// NewSigner creates a Signer from an ed25519.PrivateKey, *ecdsa.PrivateKey or *rsa.PrivateKey. ECDSA keys on the
+// SM2 curve sign SM2 signatures.
+func NewSigner(privateKey crypto.PrivateKey) (Signer, error) {
+	switch key := privateKey.(type) {
+	case ed25519.PrivateKey:
+		return &ed25519Signer{key: key}, nil
+	case *ecdsa.PrivateKey:
+		keyType, err := curveKeyType(key.Curve)
+		if err != nil {
+			return nil, err
+		}
+
+		if keyType == SM2 {
+			return &sm2Signer{key: key}, nil
+		}
+
+		return &ecdsaSigner{key: key, keyType: keyType}, nil
+	case *rsa.PrivateKey:
+		return &rsaSigner{key: key}, nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", privateKey)
+	}
+}
+
+// Verify verifies sig over msg with the public key of the given type. Public keys are encoded as:
+//   - Ed25519: the raw 32 bytes key
+//   - ECDSA and SM2: the compressed or uncompressed SEC 1 point
+//   - RSA: a PKIX or PKCS #1 DER structure
+func Verify(keyType KeyType, pubKey, msg, sig []byte) error {
+	switch keyType {
+	case Ed25519:
+		if len(pubKey) != ed25519.PublicKeySize {
+			return fmt.Errorf("invalid Ed25519 public key size %d", len(pubKey))
+		}
+
+		if !ed25519.Verify(pubKey, msg, sig) {
+			return ErrInvalidSignature
+		}
+
+		return nil
+	case ECDSAP256, ECDSAP384, ECDSASecp256k1:
+		key, err := ParseECDSAPublicKey(keyType, pubKey)
+		if err != nil {
+			return err
+		}
+
+		return verifyECDSA(key, msg, sig)
+	case SM2:
+		key, err := ParseECDSAPublicKey(keyType, pubKey)
+		if err != nil {
+			return err
+		}
+
+		if err = sm2.Verify(key, []byte(sm2.DefaultID), msg, sig); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+		}
+
+		return nil
+	case RSA:
+		key, err := ParseRSAPublicKey(pubKey)
+		if err != nil {
+			return err
+		}
+
+		digest := sha256.Sum256(msg)
+
+		if err = rsa.VerifyPSS(key, crypto.SHA256, digest[:], sig, nil); err != nil {
+			return ErrInvalidSignature
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("unsupported key type for signature verification: %s", keyType)
+	}
+}
+
+// Curve returns the elliptic curve of an ECDSA or SM2 key type.
+func Curve(keyType KeyType) (elliptic.Curve, error) {
+	switch keyType {
+	case SM2:
+		return sm2.P256(), nil
+	case ECDSAP256:
+		return elliptic.P256(), nil
+	case ECDSAP384:
+		return elliptic.P384(), nil
+	case ECDSASecp256k1:
+		return secp256k1.S256(), nil
+	default:
+		return nil, fmt.Errorf("%s is not an ECDSA key type", keyType)
+	}
+}
+
+// ParseECDSAPublicKey parses a compressed or uncompressed SEC 1 encoded ECDSA or SM2 public key.
+func ParseECDSAPublicKey(keyType KeyType, pubKey []byte) (*ecdsa.PublicKey, error) {
+	curve, err := Curve(keyType)
+	if err != nil {
+		return nil, err
+	}
+
+	var x, y *big.Int
+
+	if keyType == ECDSASecp256k1 {
+		x, y, err = secp256k1.Unmarshal(pubKey)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		if len(pubKey) > 0 && pubKey[0] == 4 {
+			x, y = elliptic.Unmarshal(curve, pubKey) //nolint:staticcheck
+		} else {
+			x, y = elliptic.UnmarshalCompressed(curve, pubKey)
+		}
+
+		if x == nil {
+			return nil, fmt.Errorf("invalid %s public key", keyType)
+		}
+	}
+
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+func curveKeyType(curve elliptic.Curve) (KeyType, error) {
+	switch curve.Params().Name {
+	case elliptic.P256().Params().Name:
+		return ECDSAP256, nil
+	case elliptic.P384().Params().Name:
+		return ECDSAP384, nil
+	case secp256k1.S256().Params().Name:
+		return ECDSASecp256k1, nil
+	case sm2.P256().Params().Name:
+		return SM2, nil
+	default:
+		return "", fmt.Errorf("unsupported curve %s", curve.Params().Name)
+	}
+}
+
+// hashECDSA hashes msg with the hash function matching the curve size (SHA-256 or SHA-384).
+func hashECDSA(curve elliptic.Curve, msg []byte) []byte {
+	if curve.Params().BitSize > 256 {
+		digest := sha512.Sum384(msg)
+
+		return digest[:]
+	}
+
+	digest := sha256.Sum256(msg)
+
+	return digest[:]
+}
+
+func verifyECDSA(key *ecdsa.PublicKey, msg, sig []byte) error {
+	size := (key.Curve.Params().BitSize + 7) / 8
+
+	if len(sig) != 2*size {
+		return fmt.Errorf("%w: expected %d bytes, got %d", ErrInvalidSignature, 2*size, len(sig))
+	}
+
+	r := new(big.Int).SetBytes(sig[:size])
+	s := new(big.Int).SetBytes(sig[size:])
+
+	if !ecdsa.Verify(key, hashECDSA(key.Curve, msg), r, s) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// ParseRSAPublicKey parses a PKIX or PKCS #1 DER encoded RSA public key.
+func ParseRSAPublicKey(pubKey []byte) (*rsa.PublicKey, error) {
+	if key, err := x509.ParsePKCS1PublicKey(pubKey); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKIXPublicKey(pubKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RSA public key: %w", err)
+	}
+
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("public key is not an RSA key")
+	}
+
+	return rsaKey, nil
+}
+
+type ed25519Signer struct {
+	key ed25519.PrivateKey
+}
+
+func (s *ed25519Signer) Sign(msg []byte) ([]byte, error) {
+	return ed25519.Sign(s.key, msg), nil
+}
+
+func (s *ed25519Signer) KeyType() KeyType {
+	return Ed25519
+}
+
+func (s *ed25519Signer) PublicKeyBytes() []byte {
+	return []byte(s.key.Public().(ed25519.PublicKey))
+}
+
+type ecdsaSigner struct {
+	key     *ecdsa.PrivateKey
+	keyType KeyType
+}
+
+func (s *ecdsaSigner) Sign(msg []byte) ([]byte, error) {
+	r, sig, err := ecdsa.Sign(rand.Reader, s.key, hashECDSA(s.key.Curve, msg))
+	if err != nil {
+		return nil, fmt.Errorf("ecdsa sign: %w", err)
+	}
+
+	size := (s.key.Curve.Params().BitSize + 7) / 8
+	out := make([]byte, 2*size)
+	r.FillBytes(out[:size])
+	sig.FillBytes(out[size:])
+
+	return out, nil
+}
+
+func (s *ecdsaSigner) KeyType() KeyType {
+	return s.keyType
+}
+
+func (s *ecdsaSigner) PublicKeyBytes() []byte {
+	return elliptic.MarshalCompressed(s.key.Curve, s.key.X, s.key.Y)
+}
+
+type rsaSigner struct {
+	key *rsa.PrivateKey
+}
+
+func (s *rsaSigner) Sign(msg []byte) ([]byte, error) {
+	digest := sha256.Sum256(msg)
+
+	return rsa.SignPSS(rand.Reader, s.key, crypto.SHA256, digest[:], nil)
+}
+
+func (s *rsaSigner) KeyType() KeyType {
+	return RSA
+}
+
+func (s *rsaSigner) PublicKeyBytes() []byte {
+	return x509.MarshalPKCS1PublicKey(&s.key.PublicKey)
+}
+
+type sm2Signer struct {
+	key *ecdsa.PrivateKey
+}
+
+func (s *sm2Signer) Sign(msg []byte) ([]byte, error) {
+	return sm2.Sign(rand.Reader, s.key, []byte(sm2.DefaultID), msg)
+}
+
+func (s *sm2Signer) KeyType() KeyType {
+	return SM2
+}
+
+func (s *sm2Signer) PublicKeyBytes() []byte {
+	return elliptic.MarshalCompressed(s.key.Curve, s.key.X, s.key.Y)
+}
@@ -0,0 +1,148 @@
+package crypto
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zRich/zFusion/common/crypto/secp256k1"
+	"github.com/zRich/zFusion/common/crypto/sm2"
+)
+
+func TestSignVerify(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	k1Key, err := ecdsa.GenerateKey(secp256k1.S256(), rand.Reader)
+	require.NoError(t, err)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	sm2Key, err := sm2.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		key     interface{}
+		keyType KeyType
+	}{
+		{"Ed25519", edKey, Ed25519},
+		{"P-256", p256Key, ECDSAP256},
+		{"P-384", p384Key, ECDSAP384},
+		{"secp256k1", k1Key, ECDSASecp256k1},
+		{"RSA", rsaKey, RSA},
+		{"SM2", sm2Key, SM2},
+	}
+
+	msg := []byte("test message")
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			signer, err := NewSigner(tc.key)
+			require.NoError(t, err)
+			require.Equal(t, tc.keyType, signer.KeyType())
+
+			sig, err := signer.Sign(msg)
+			require.NoError(t, err)
+
+			require.NoError(t, Verify(tc.keyType, signer.PublicKeyBytes(), msg, sig))
+			require.ErrorIs(t, Verify(tc.keyType, signer.PublicKeyBytes(), []byte("other"), sig), ErrInvalidSignature)
+		})
+	}
+
+	t.Run("uncompressed and PKIX public keys", func(t *testing.T) {
+		signer, err := NewSigner(p256Key)
+		require.NoError(t, err)
+
+		sig, err := signer.Sign(msg)
+		require.NoError(t, err)
+
+		uncompressed := elliptic.Marshal(elliptic.P256(), p256Key.X, p256Key.Y) //nolint:staticcheck
+		require.NoError(t, Verify(ECDSAP256, uncompressed, msg, sig))
+
+		signer, err = NewSigner(sm2Key)
+		require.NoError(t, err)
+
+		sig, err = signer.Sign(msg)
+		require.NoError(t, err)
+
+		uncompressed = elliptic.Marshal(sm2.P256(), sm2Key.X, sm2Key.Y) //nolint:staticcheck
+		require.NoError(t, Verify(SM2, uncompressed, msg, sig))
+
+		signer, err = NewSigner(rsaKey)
+		require.NoError(t, err)
+
+		sig, err = signer.Sign(msg)
+		require.NoError(t, err)
+
+		pkix, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+		require.NoError(t, err)
+		require.NoError(t, Verify(RSA, pkix, msg, sig))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := NewSigner("key")
+		require.EqualError(t, err, "unsupported private key type string")
+
+		require.Error(t, Verify(Ed25519, []byte{1}, msg, nil))
+		require.Error(t, Verify(ECDSAP256, []byte{1}, msg, nil))
+		require.Error(t, Verify(X25519, make([]byte, 32), msg, nil))
+		require.ErrorIs(t, Verify(ECDSAP256, elliptic.MarshalCompressed(elliptic.P256(), p256Key.X, p256Key.Y),
+			msg, []byte{1, 2}), ErrInvalidSignature)
+	})
+}
+
+func TestProvider(t *testing.T) {
+	for name, expected := range map[string]struct {
+		hashAlgorithm HashAlgorithm
+		keyType       KeyType
+		keySize       int
+	}{
+		ProviderStandard: {SHA256, ECDSAP256, 32},
+		ProviderGM:       {SM3, SM2, 16},
+	} {
+		provider, err := NewProvider(name)
+		require.NoError(t, err)
+		require.Equal(t, name, provider.Name())
+		require.Equal(t, expected.hashAlgorithm, provider.HashAlgorithm())
+		require.Equal(t, expected.keyType, provider.KeyType())
+
+		h, err := NewHash(provider.HashAlgorithm())
+		require.NoError(t, err)
+		require.Equal(t, h.Sum([]byte("abc")), provider.NewHash().Sum([]byte("abc")))
+
+		signer, err := provider.GenerateSigner()
+		require.NoError(t, err)
+		require.Equal(t, expected.keyType, signer.KeyType())
+
+		sig, err := signer.Sign([]byte("message"))
+		require.NoError(t, err)
+		require.NoError(t, Verify(signer.KeyType(), signer.PublicKeyBytes(), []byte("message"), sig))
+
+		aead, err := provider.NewAEAD(make([]byte, expected.keySize))
+		require.NoError(t, err)
+
+		nonce := make([]byte, aead.NonceSize())
+		opened, err := aead.Open(nil, nonce, aead.Seal(nil, nonce, []byte("message"), nil), nil)
+		require.NoError(t, err)
+		require.Equal(t, []byte("message"), opened)
+	}
+
+	_, err := NewProvider("fips")
+	require.Error(t, err)
+
+	_, err = NewHash("MD5")
+	require.Error(t, err)
+}
@@ -0,0 +1,369 @@
+// Package jwk implements public JSON Web Keys (RFC 7517) of the key types supported by common/crypto,
+// together with their RFC 7638 thumbprints.
+package jwk
+
+import (
+	stdcrypto "crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/zRich/zFusion/common/crypto"
+	"github.com/zRich/zFusion/common/crypto/secp256k1"
+)
+
+// Key types (kty).
+const (
+	OKP = "OKP"
+	EC  = "EC"
+	RSA = "RSA"
+)
+
+// Curves (crv) of OKP keys. EC curves share their names with crypto.ECDSAP256, crypto.ECDSAP384 and
+// crypto.ECDSASecp256k1.
+const (
+	Ed25519 = "Ed25519"
+	X25519  = "X25519"
+)
+
+const curve25519KeySize = 32
+
+// signatureAlgorithms maps key types to the JWS algorithms (RFC 7518, RFC 8037 and RFC 8812) of their
+// signatures, as produced by crypto.Signer.
+var signatureAlgorithms = map[crypto.KeyType]string{ //nolint:gochecknoglobals
+	crypto.Ed25519:        "EdDSA",
+	crypto.ECDSAP256:      "ES256",
+	crypto.ECDSAP384:      "ES384",
+	crypto.ECDSASecp256k1: "ES256K",
+	crypto.RSA:            "PS256",
+}
+
+// ErrInvalidKey is returned when a JWK is malformed or its key material is invalid.
+var ErrInvalidKey = errors.New("invalid JWK")
+
+// JWK is a public JSON Web Key. Key holds one of ed25519.PublicKey, *ecdsa.PublicKey, *rsa.PublicKey, or the
+// raw 32 bytes of an X25519 key.
+type JWK struct {
+	Key       interface{}
+	KeyID     string
+	Algorithm string
+	Use       string
+	Kty       string
+	Crv       string
+}
+
+// rawJWK is the JSON representation of a JWK.
+type rawJWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Use string `json:"use,omitempty"`
+	Kid string `json:"kid,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	D   string `json:"d,omitempty"`
+}
+
+// New creates a JWK from an ed25519.PublicKey, *ecdsa.PublicKey or *rsa.PublicKey.
+func New(key interface{}) (*JWK, error) {
+	switch k := key.(type) {
+	case ed25519.PublicKey:
+		return NewFromBytes(crypto.Ed25519, k)
+	case *ecdsa.PublicKey:
+		if _, err := ecKeyType(k.Curve); err != nil {
+			return nil, err
+		}
+
+		return &JWK{Key: k, Kty: EC, Crv: k.Curve.Params().Name}, nil
+	case *rsa.PublicKey:
+		return &JWK{Key: k, Kty: RSA}, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported key %T", ErrInvalidKey, key)
+	}
+}
+
+// NewFromBytes creates a JWK from a public key in the encoding accepted by crypto.Verify. X25519 keys are
+// the raw 32 bytes key.
+func NewFromBytes(keyType crypto.KeyType, pubKey []byte) (*JWK, error) {
+	switch keyType {
+	case crypto.Ed25519, crypto.X25519:
+		if len(pubKey) != curve25519KeySize {
+			return nil, fmt.Errorf("%w: invalid %s key size %d", ErrInvalidKey, keyType, len(pubKey))
+		}
+
+		key := append([]byte(nil), pubKey...)
+		if keyType == crypto.Ed25519 {
+			return &JWK{Key: ed25519.PublicKey(key), Kty: OKP, Crv: Ed25519}, nil
+		}
+
+		return &JWK{Key: key, Kty: OKP, Crv: X25519}, nil
+	case crypto.ECDSAP256, crypto.ECDSAP384, crypto.ECDSASecp256k1:
+		key, err := crypto.ParseECDSAPublicKey(keyType, pubKey)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+		}
+
+		return &JWK{Key: key, Kty: EC, Crv: string(keyType)}, nil
+	case crypto.RSA:
+		key, err := crypto.ParseRSAPublicKey(pubKey)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+		}
+
+		return &JWK{Key: key, Kty: RSA}, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported key type %s", ErrInvalidKey, keyType)
+	}
+}
+
+// KeyType returns the type of the key.
+func (j *JWK) KeyType() (crypto.KeyType, error) {
+	switch j.Kty {
+	case OKP:
+		switch j.Crv {
+		case Ed25519:
+			return crypto.Ed25519, nil
+		case X25519:
+			return crypto.X25519, nil
+		}
+	case EC:
+		switch keyType := crypto.KeyType(j.Crv); keyType {
+		case crypto.ECDSAP256, crypto.ECDSAP384, crypto.ECDSASecp256k1:
+			return keyType, nil
+		}
+	case RSA:
+		return crypto.RSA, nil
+	}
+
+	return "", fmt.Errorf("%w: unsupported kty %q and crv %q", ErrInvalidKey, j.Kty, j.Crv)
+}
+
+// PublicKeyBytes returns the public key in the encoding accepted by crypto.Verify: the raw key of OKP keys,
+// the uncompressed SEC 1 point of EC keys and the PKCS #1 DER structure of RSA keys.
+func (j *JWK) PublicKeyBytes() ([]byte, error) {
+	switch key := j.Key.(type) {
+	case ed25519.PublicKey:
+		return append([]byte(nil), key...), nil
+	case []byte:
+		return append([]byte(nil), key...), nil
+	case *ecdsa.PublicKey:
+		if j.Crv == string(crypto.ECDSASecp256k1) {
+			return secp256k1.Marshal(key.X, key.Y), nil
+		}
+
+		return elliptic.Marshal(key.Curve, key.X, key.Y), nil //nolint:staticcheck
+	case *rsa.PublicKey:
+		return x509.MarshalPKCS1PublicKey(key), nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported key %T", ErrInvalidKey, j.Key)
+	}
+}
+
+// Thumbprint computes the RFC 7638 thumbprint of the key with the given hash function.
+func (j *JWK) Thumbprint(hash stdcrypto.Hash) ([]byte, error) {
+	raw, err := j.raw()
+	if err != nil {
+		return nil, err
+	}
+
+	// the required members in lexicographic order, see https://www.rfc-editor.org/rfc/rfc7638#section-3.2.
+	var input string
+
+	switch raw.Kty {
+	case OKP:
+		input = fmt.Sprintf(`{"crv":%q,"kty":%q,"x":%q}`, raw.Crv, raw.Kty, raw.X)
+	case EC:
+		input = fmt.Sprintf(`{"crv":%q,"kty":%q,"x":%q,"y":%q}`, raw.Crv, raw.Kty, raw.X, raw.Y)
+	default: // RSA
+		input = fmt.Sprintf(`{"e":%q,"kty":%q,"n":%q}`, raw.E, raw.Kty, raw.N)
+	}
+
+	if !hash.Available() {
+		return nil, fmt.Errorf("hash function %v is not available", hash)
+	}
+
+	h := hash.New()
+	h.Write([]byte(input))
+
+	return h.Sum(nil), nil
+}
+
+// MarshalJSON marshals the JWK.
+func (j JWK) MarshalJSON() ([]byte, error) {
+	raw, err := j.raw()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(raw)
+}
+
+// UnmarshalJSON unmarshals and validates a public JWK.
+func (j *JWK) UnmarshalJSON(data []byte) error {
+	var raw rawJWK
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+
+	if raw.D != "" {
+		return fmt.Errorf("%w: private keys are not supported", ErrInvalidKey)
+	}
+
+	parsed := JWK{KeyID: raw.Kid, Algorithm: raw.Alg, Use: raw.Use, Kty: raw.Kty, Crv: raw.Crv}
+
+	keyType, err := parsed.KeyType()
+	if err != nil {
+		return err
+	}
+
+	switch raw.Kty {
+	case OKP:
+		x, err := decodeMember("x", raw.X, curve25519KeySize)
+		if err != nil {
+			return err
+		}
+
+		parsed.Key = x
+		if keyType == crypto.Ed25519 {
+			parsed.Key = ed25519.PublicKey(x)
+		}
+	case EC:
+		parsed.Key, err = decodeECKey(keyType, &raw)
+		if err != nil {
+			return err
+		}
+	default: // RSA
+		parsed.Key, err = decodeRSAKey(&raw)
+		if err != nil {
+			return err
+		}
+	}
+
+	*j = parsed
+
+	return nil
+}
+
+func (j *JWK) raw() (*rawJWK, error) {
+	raw := &rawJWK{Kid: j.KeyID, Alg: j.Algorithm, Use: j.Use}
+
+	switch key := j.Key.(type) {
+	case ed25519.PublicKey:
+		raw.Kty, raw.Crv, raw.X = OKP, Ed25519, encode(key)
+	case []byte:
+		if len(key) != curve25519KeySize {
+			return nil, fmt.Errorf("%w: invalid X25519 key size %d", ErrInvalidKey, len(key))
+		}
+
+		raw.Kty, raw.Crv, raw.X = OKP, X25519, encode(key)
+	case *ecdsa.PublicKey:
+		keyType, err := ecKeyType(key.Curve)
+		if err != nil {
+			return nil, err
+		}
+
+		size := (key.Curve.Params().BitSize + 7) / 8 //nolint:gomnd
+		raw.Kty, raw.Crv = EC, string(keyType)
+		raw.X, raw.Y = encode(key.X.FillBytes(make([]byte, size))), encode(key.Y.FillBytes(make([]byte, size)))
+	case *rsa.PublicKey:
+		raw.Kty = RSA
+		raw.N, raw.E = encode(key.N.Bytes()), encode(big.NewInt(int64(key.E)).Bytes())
+	default:
+		return nil, fmt.Errorf("%w: unsupported key %T", ErrInvalidKey, j.Key)
+	}
+
+	return raw, nil
+}
+
+func decodeECKey(keyType crypto.KeyType, raw *rawJWK) (*ecdsa.PublicKey, error) {
+	curve, err := crypto.Curve(keyType)
+	if err != nil {
+		return nil, err
+	}
+
+	size := (curve.Params().BitSize + 7) / 8 //nolint:gomnd
+
+	x, err := decodeMember("x", raw.X, size)
+	if err != nil {
+		return nil, err
+	}
+
+	y, err := decodeMember("y", raw.Y, size)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+
+	if !curve.IsOnCurve(key.X, key.Y) {
+		return nil, fmt.Errorf("%w: point is not on curve %s", ErrInvalidKey, keyType)
+	}
+
+	return key, nil
+}
+
+func decodeRSAKey(raw *rawJWK) (*rsa.PublicKey, error) {
+	n, err := decodeMember("n", raw.N, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := decodeMember("e", raw.E, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	exponent := new(big.Int).SetBytes(e)
+	if len(e) > 4 || exponent.Int64() < 3 || exponent.Bit(0) == 0 { //nolint:gomnd
+		return nil, fmt.Errorf("%w: invalid RSA exponent", ErrInvalidKey)
+	}
+
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+}
+
+// decodeMember decodes a base64url encoded member. A size of 0 accepts any non-empty value.
+func decodeMember(name, value string, size int) ([]byte, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: decode %q: %v", ErrInvalidKey, name, err)
+	}
+
+	if len(decoded) == 0 || (size > 0 && len(decoded) != size) {
+		return nil, fmt.Errorf("%w: invalid %q", ErrInvalidKey, name)
+	}
+
+	return decoded, nil
+}
+
+// SignatureAlgorithm returns the JWS algorithm of signatures created by keys of the given type.
+func SignatureAlgorithm(keyType crypto.KeyType) (string, error) {
+	alg, ok := signatureAlgorithms[keyType]
+	if !ok {
+		return "", fmt.Errorf("no JWS algorithm for %s keys", keyType)
+	}
+
+	return alg, nil
+}
+
+func ecKeyType(curve elliptic.Curve) (crypto.KeyType, error) {
+	switch keyType := crypto.KeyType(curve.Params().Name); keyType {
+	case crypto.ECDSAP256, crypto.ECDSAP384, crypto.ECDSASecp256k1:
+		return keyType, nil
+	default:
+		return "", fmt.Errorf("%w: unsupported curve %s", ErrInvalidKey, keyType)
+	}
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
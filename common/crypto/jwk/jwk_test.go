@@ -0,0 +1,171 @@
+package jwk
+
+import (
+	stdcrypto "crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zRich/zFusion/common/crypto"
+)
+
+const (
+	// from https://www.rfc-editor.org/rfc/rfc7638#section-3.1.
+	rsaJWK = `{
+		"kty": "RSA",
+		"n": "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+		"e": "AQAB",
+		"alg": "RS256",
+		"kid": "2011-04-29"
+	}`
+	rsaThumbprint = "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"
+
+	// from https://www.rfc-editor.org/rfc/rfc8037#appendix-A.3.
+	ed25519JWK        = `{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`
+	ed25519Thumbprint = "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k"
+
+	secp256k1JWK = `{
+		"kty": "EC",
+		"crv": "secp256k1",
+		"x": "DSE4CfCVKNgxNMDV6dK_DbcwshievbxwHJwOsGoSpaw",
+		"y": "xzrnm-VHA22nfGrNGGaLL9aPHRN26qyJNli3jByQSfQ",
+		"kid": "5hgq2bNVTqyns_Nvcc_ybVHnFMx33_dAsfrfpZMTqTA"
+	}`
+)
+
+func TestThumbprint(t *testing.T) {
+	for jwkJSON, thumbprint := range map[string]string{rsaJWK: rsaThumbprint, ed25519JWK: ed25519Thumbprint} {
+		var j JWK
+
+		require.NoError(t, json.Unmarshal([]byte(jwkJSON), &j))
+
+		tp, err := j.Thumbprint(stdcrypto.SHA256)
+		require.NoError(t, err)
+		require.Equal(t, thumbprint, base64.RawURLEncoding.EncodeToString(tp))
+	}
+
+	_, err := (&JWK{Key: "invalid"}).Thumbprint(stdcrypto.SHA256)
+	require.ErrorIs(t, err, ErrInvalidKey)
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	ecJWK, err := New(&ecKey.PublicKey)
+	require.NoError(t, err)
+
+	ecJSON, err := json.Marshal(ecJWK)
+	require.NoError(t, err)
+
+	for _, jwkJSON := range []string{rsaJWK, ed25519JWK, secp256k1JWK, string(ecJSON),
+		`{"kty":"OKP","crv":"X25519","x":"hSDwCYkwp1R0i33ctD73Wg2_Og0mOBr066SpjqqbTmo"}`} {
+		var j JWK
+
+		require.NoError(t, json.Unmarshal([]byte(jwkJSON), &j))
+
+		jwkBytes, err := json.Marshal(&j)
+		require.NoError(t, err)
+		require.JSONEq(t, jwkJSON, string(jwkBytes))
+
+		keyType, err := j.KeyType()
+		require.NoError(t, err)
+
+		pubKey, err := j.PublicKeyBytes()
+		require.NoError(t, err)
+
+		fromBytes, err := NewFromBytes(keyType, pubKey)
+		require.NoError(t, err)
+
+		fromBytes.KeyID, fromBytes.Algorithm = j.KeyID, j.Algorithm
+		require.Equal(t, &j, fromBytes)
+	}
+}
+
+func TestNew(t *testing.T) {
+	edKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	j, err := New(edKey)
+	require.NoError(t, err)
+	require.Equal(t, OKP, j.Kty)
+	require.Equal(t, Ed25519, j.Crv)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	j, err = New(&ecKey.PublicKey)
+	require.NoError(t, err)
+
+	keyType, err := j.KeyType()
+	require.NoError(t, err)
+	require.Equal(t, crypto.ECDSAP256, keyType)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	j, err = New(&rsaKey.PublicKey)
+	require.NoError(t, err)
+
+	pubKey, err := j.PublicKeyBytes()
+	require.NoError(t, err)
+
+	signer, err := crypto.NewSigner(rsaKey)
+	require.NoError(t, err)
+
+	sig, err := signer.Sign([]byte("message"))
+	require.NoError(t, err)
+	require.NoError(t, crypto.Verify(crypto.RSA, pubKey, []byte("message"), sig))
+
+	p521Key, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	require.NoError(t, err)
+
+	_, err = New(&p521Key.PublicKey)
+	require.ErrorIs(t, err, ErrInvalidKey)
+
+	_, err = New("invalid")
+	require.ErrorIs(t, err, ErrInvalidKey)
+
+	_, err = NewFromBytes(crypto.X25519, []byte("short"))
+	require.ErrorIs(t, err, ErrInvalidKey)
+
+	_, err = NewFromBytes(crypto.ECDSAP256, []byte("invalid"))
+	require.ErrorIs(t, err, ErrInvalidKey)
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	for name, jwkJSON := range map[string]string{
+		"not JSON":        `[]`,
+		"unsupported kty": `{"kty":"oct","k":"AAAA"}`,
+		"unsupported crv": `{"kty":"EC","crv":"P-521","x":"AAAA","y":"AAAA"}`,
+		"private key":     `{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo","d":"AAAA"}`,
+		"invalid base64":  `{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo="}`,
+		"invalid size":    `{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcH"}`,
+		"missing y": `{"kty":"EC","crv":"secp256k1",
+			"x":"DSE4CfCVKNgxNMDV6dK_DbcwshievbxwHJwOsGoSpaw"}`,
+		"not on curve": `{"kty":"EC","crv":"secp256k1","x":"DSE4CfCVKNgxNMDV6dK_DbcwshievbxwHJwOsGoSpaw",
+			"y":"xzrnm-VHA22nfGrNGGaLL9aPHRN26qyJNli3jByQSfA"}`,
+		"invalid exponent": `{"kty":"RSA","n":"0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7",
+			"e":"AQ"}`,
+	} {
+		var j JWK
+
+		require.ErrorIs(t, json.Unmarshal([]byte(jwkJSON), &j), ErrInvalidKey, name)
+	}
+}
+
+func TestSignatureAlgorithm(t *testing.T) {
+	alg, err := SignatureAlgorithm(crypto.ECDSASecp256k1)
+	require.NoError(t, err)
+	require.Equal(t, "ES256K", alg)
+
+	_, err = SignatureAlgorithm(crypto.X25519)
+	require.EqualError(t, err, "no JWS algorithm for X25519 keys")
+}
@@ -0,0 +1,147 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+
+	"github.com/zRich/zFusion/common/crypto/sm2"
+	"github.com/zRich/zFusion/common/crypto/sm3"
+	"github.com/zRich/zFusion/common/crypto/sm4"
+)
+
+// HashAlgorithm is a hash function.
+type HashAlgorithm string
+
+const (
+	// SHA256 is SHA-256 of FIPS 180-4.
+	SHA256 HashAlgorithm = "SHA-256"
+	// SM3 is SM3 of GB/T 32905.
+	SM3 HashAlgorithm = "SM3"
+)
+
+// NewHash returns a new hash computing the digests of alg.
+func NewHash(alg HashAlgorithm) (hash.Hash, error) {
+	switch alg {
+	case SHA256:
+		return sha256.New(), nil
+	case SM3:
+		return sm3.New(), nil
+	default:
+		return nil, fmt.Errorf("unsupported hash algorithm: %s", alg)
+	}
+}
+
+// Names of the providers returned by NewProvider.
+const (
+	// ProviderStandard uses SHA-256, ECDSA P-256 signatures and AES-GCM.
+	ProviderStandard = "standard"
+	// ProviderGM uses the Chinese national algorithms: SM3, SM2 signatures and SM4-GCM.
+	ProviderGM = "gm"
+)
+
+// Provider is a suite of cryptographic algorithms, so that deployments select the algorithms mandated by their
+// jurisdiction instead of hard coding them.
+type Provider interface {
+	// Name returns the name of the suite.
+	Name() string
+	// HashAlgorithm returns the hash algorithm of the suite.
+	HashAlgorithm() HashAlgorithm
+	// NewHash returns a new hash of the suite.
+	NewHash() hash.Hash
+	// KeyType returns the type of the signature keys of the suite.
+	KeyType() KeyType
+	// GenerateSigner generates a signing key of the suite.
+	GenerateSigner() (Signer, error)
+	// NewAEAD returns the authenticated cipher of the suite with key.
+	NewAEAD(key []byte) (cipher.AEAD, error)
+}
+
+// NewProvider returns the provider with the given name.
+func NewProvider(name string) (Provider, error) {
+	switch name {
+	case ProviderStandard:
+		return &standardProvider{}, nil
+	case ProviderGM:
+		return &gmProvider{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported crypto provider: %s", name)
+	}
+}
+
+type standardProvider struct{}
+
+func (p *standardProvider) Name() string {
+	return ProviderStandard
+}
+
+func (p *standardProvider) HashAlgorithm() HashAlgorithm {
+	return SHA256
+}
+
+func (p *standardProvider) NewHash() hash.Hash {
+	return sha256.New()
+}
+
+func (p *standardProvider) KeyType() KeyType {
+	return ECDSAP256
+}
+
+func (p *standardProvider) GenerateSigner() (Signer, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSigner(key)
+}
+
+func (p *standardProvider) NewAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+type gmProvider struct{}
+
+func (p *gmProvider) Name() string {
+	return ProviderGM
+}
+
+func (p *gmProvider) HashAlgorithm() HashAlgorithm {
+	return SM3
+}
+
+func (p *gmProvider) NewHash() hash.Hash {
+	return sm3.New()
+}
+
+func (p *gmProvider) KeyType() KeyType {
+	return SM2
+}
+
+func (p *gmProvider) GenerateSigner() (Signer, error) {
+	key, err := sm2.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSigner(key)
+}
+
+func (p *gmProvider) NewAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := sm4.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
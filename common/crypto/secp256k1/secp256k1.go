@@ -0,0 +1,329 @@
+// Package secp256k1 implements the secp256k1 elliptic curve (SEC 2, section 2.4.1) on top of
+// crypto/elliptic so that it can be used with crypto/ecdsa. The implementation relies on
+// math/big and is not constant time.
+package secp256k1
+
+import (
+	"crypto/elliptic"
+	"errors"
+	"math/big"
+	"sync"
+)
+
+// PublicKeySizeCompressed is the size of a compressed SEC 1 public key.
+const PublicKeySizeCompressed = 33
+
+// PublicKeySizeUncompressed is the size of an uncompressed SEC 1 public key.
+const PublicKeySizeUncompressed = 65
+
+var (
+	initOnce sync.Once     //nolint:gochecknoglobals
+	curve    *koblitzCurve //nolint:gochecknoglobals
+)
+
+// koblitzCurve is a short Weierstrass curve y² = x³ + b (a = 0), which elliptic.CurveParams does
+// not support since it assumes a = -3.
+type koblitzCurve struct {
+	params *elliptic.CurveParams
+}
+
+func initS256() {
+	p, _ := new(big.Int).SetString("FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEFFFFFC2F", 16)
+	n, _ := new(big.Int).SetString("FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEBAAEDCE6AF48A03BBFD25E8CD0364141", 16)
+	gx, _ := new(big.Int).SetString("79BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798", 16)
+	gy, _ := new(big.Int).SetString("483ADA7726A3C4655DA4FBFC0E1108A8FD17B448A68554199C47D08FFB10D4B8", 16)
+
+	curve = &koblitzCurve{params: &elliptic.CurveParams{
+		Name:    "secp256k1",
+		P:       p,
+		N:       n,
+		B:       big.NewInt(7),
+		Gx:      gx,
+		Gy:      gy,
+		BitSize: 256,
+	}}
+}
+
+// S256 returns the secp256k1 curve.
+func S256() elliptic.Curve {
+	initOnce.Do(initS256)
+
+	return curve
+}
+
+// Params returns the curve parameters. Note that the parameters' own methods must not be used for
+// arithmetic since they assume a = -3.
+func (c *koblitzCurve) Params() *elliptic.CurveParams {
+	return c.params
+}
+
+// IsOnCurve reports whether (x, y) satisfies y² = x³ + 7 mod p.
+func (c *koblitzCurve) IsOnCurve(x, y *big.Int) bool {
+	p := c.params.P
+
+	if x.Sign() < 0 || x.Cmp(p) >= 0 || y.Sign() < 0 || y.Cmp(p) >= 0 {
+		return false
+	}
+
+	y2 := new(big.Int).Mul(y, y)
+	y2.Mod(y2, p)
+
+	return c.polynomial(x).Cmp(y2) == 0
+}
+
+// polynomial returns x³ + 7 mod p.
+func (c *koblitzCurve) polynomial(x *big.Int) *big.Int {
+	x3 := new(big.Int).Mul(x, x)
+	x3.Mul(x3, x)
+	x3.Add(x3, c.params.B)
+
+	return x3.Mod(x3, c.params.P)
+}
+
+// Add returns the sum of (x1, y1) and (x2, y2).
+func (c *koblitzCurve) Add(x1, y1, x2, y2 *big.Int) (*big.Int, *big.Int) {
+	z1 := zForAffine(x1, y1)
+	z2 := zForAffine(x2, y2)
+
+	return c.affineFromJacobian(c.addJacobian(x1, y1, z1, x2, y2, z2))
+}
+
+// Double returns 2*(x, y).
+func (c *koblitzCurve) Double(x1, y1 *big.Int) (*big.Int, *big.Int) {
+	z1 := zForAffine(x1, y1)
+
+	return c.affineFromJacobian(c.doubleJacobian(x1, y1, z1))
+}
+
+// ScalarMult returns k*(x, y) where k is a big-endian integer.
+func (c *koblitzCurve) ScalarMult(bx, by *big.Int, k []byte) (*big.Int, *big.Int) {
+	bz := zForAffine(bx, by)
+	x, y, z := new(big.Int), new(big.Int), new(big.Int)
+
+	for _, b := range k {
+		for bitNum := 0; bitNum < 8; bitNum++ {
+			x, y, z = c.doubleJacobian(x, y, z)
+
+			if b&0x80 == 0x80 {
+				x, y, z = c.addJacobian(bx, by, bz, x, y, z)
+			}
+
+			b <<= 1
+		}
+	}
+
+	return c.affineFromJacobian(x, y, z)
+}
+
+// ScalarBaseMult returns k*G where G is the base point of the group.
+func (c *koblitzCurve) ScalarBaseMult(k []byte) (*big.Int, *big.Int) {
+	return c.ScalarMult(c.params.Gx, c.params.Gy, k)
+}
+
+func zForAffine(x, y *big.Int) *big.Int {
+	z := new(big.Int)
+	if x.Sign() != 0 || y.Sign() != 0 {
+		z.SetInt64(1)
+	}
+
+	return z
+}
+
+func (c *koblitzCurve) affineFromJacobian(x, y, z *big.Int) (*big.Int, *big.Int) {
+	if z.Sign() == 0 {
+		return new(big.Int), new(big.Int)
+	}
+
+	p := c.params.P
+
+	zinv := new(big.Int).ModInverse(z, p)
+	zinvsq := new(big.Int).Mul(zinv, zinv)
+
+	xOut := new(big.Int).Mul(x, zinvsq)
+	xOut.Mod(xOut, p)
+	zinvsq.Mul(zinvsq, zinv)
+	yOut := new(big.Int).Mul(y, zinvsq)
+	yOut.Mod(yOut, p)
+
+	return xOut, yOut
+}
+
+// addJacobian uses the "add-2007-bl" formulas, which do not depend on a.
+func (c *koblitzCurve) addJacobian(x1, y1, z1, x2, y2, z2 *big.Int) (*big.Int, *big.Int, *big.Int) {
+	p := c.params.P
+	x3, y3, z3 := new(big.Int), new(big.Int), new(big.Int)
+
+	if z1.Sign() == 0 {
+		return x3.Set(x2), y3.Set(y2), z3.Set(z2)
+	}
+
+	if z2.Sign() == 0 {
+		return x3.Set(x1), y3.Set(y1), z3.Set(z1)
+	}
+
+	z1z1 := new(big.Int).Mul(z1, z1)
+	z1z1.Mod(z1z1, p)
+	z2z2 := new(big.Int).Mul(z2, z2)
+	z2z2.Mod(z2z2, p)
+
+	u1 := new(big.Int).Mul(x1, z2z2)
+	u1.Mod(u1, p)
+	u2 := new(big.Int).Mul(x2, z1z1)
+	u2.Mod(u2, p)
+	h := new(big.Int).Sub(u2, u1)
+	xEqual := h.Sign() == 0
+
+	if h.Sign() == -1 {
+		h.Add(h, p)
+	}
+
+	i := new(big.Int).Lsh(h, 1)
+	i.Mul(i, i)
+	j := new(big.Int).Mul(h, i)
+
+	s1 := new(big.Int).Mul(y1, z2)
+	s1.Mul(s1, z2z2)
+	s1.Mod(s1, p)
+	s2 := new(big.Int).Mul(y2, z1)
+	s2.Mul(s2, z1z1)
+	s2.Mod(s2, p)
+	r := new(big.Int).Sub(s2, s1)
+
+	if r.Sign() == -1 {
+		r.Add(r, p)
+	}
+
+	yEqual := r.Sign() == 0
+
+	if xEqual && yEqual {
+		return c.doubleJacobian(x1, y1, z1)
+	}
+
+	r.Lsh(r, 1)
+	v := new(big.Int).Mul(u1, i)
+
+	x3.Set(r)
+	x3.Mul(x3, x3)
+	x3.Sub(x3, j)
+	x3.Sub(x3, v)
+	x3.Sub(x3, v)
+	x3.Mod(x3, p)
+
+	y3.Set(r)
+	v.Sub(v, x3)
+	y3.Mul(y3, v)
+	s1.Mul(s1, j)
+	s1.Lsh(s1, 1)
+	y3.Sub(y3, s1)
+	y3.Mod(y3, p)
+
+	z3.Add(z1, z2)
+	z3.Mul(z3, z3)
+	z3.Sub(z3, z1z1)
+	z3.Sub(z3, z2z2)
+	z3.Mul(z3, h)
+	z3.Mod(z3, p)
+
+	return x3, y3, z3
+}
+
+// doubleJacobian uses the "dbl-2009-l" formulas for a = 0 curves.
+func (c *koblitzCurve) doubleJacobian(x, y, z *big.Int) (*big.Int, *big.Int, *big.Int) {
+	p := c.params.P
+
+	if z.Sign() == 0 || y.Sign() == 0 {
+		return new(big.Int), new(big.Int), new(big.Int)
+	}
+
+	a := new(big.Int).Mul(x, x)
+	a.Mod(a, p)
+	b := new(big.Int).Mul(y, y)
+	b.Mod(b, p)
+	cc := new(big.Int).Mul(b, b)
+	cc.Mod(cc, p)
+
+	d := new(big.Int).Add(x, b)
+	d.Mul(d, d)
+	d.Sub(d, a)
+	d.Sub(d, cc)
+	d.Lsh(d, 1)
+	d.Mod(d, p)
+
+	e := new(big.Int).Mul(big.NewInt(3), a)
+	f := new(big.Int).Mul(e, e)
+
+	x3 := new(big.Int).Lsh(d, 1)
+	x3.Sub(f, x3)
+	x3.Mod(x3, p)
+
+	y3 := new(big.Int).Sub(d, x3)
+	y3.Mul(e, y3)
+	y3.Sub(y3, new(big.Int).Lsh(cc, 3))
+	y3.Mod(y3, p)
+
+	z3 := new(big.Int).Mul(y, z)
+	z3.Lsh(z3, 1)
+	z3.Mod(z3, p)
+
+	return x3, y3, z3
+}
+
+// MarshalCompressed converts a point into the 33-byte compressed form of SEC 1, section 2.3.3.
+func MarshalCompressed(x, y *big.Int) []byte {
+	return elliptic.MarshalCompressed(S256(), x, y)
+}
+
+// Marshal converts a point into the 65-byte uncompressed form of SEC 1, section 2.3.3.
+func Marshal(x, y *big.Int) []byte {
+	byteLen := (S256().Params().BitSize + 7) / 8
+
+	ret := make([]byte, 1+2*byteLen)
+	ret[0] = 4 // uncompressed point
+
+	x.FillBytes(ret[1 : 1+byteLen])
+	y.FillBytes(ret[1+byteLen : 1+2*byteLen])
+
+	return ret
+}
+
+// Unmarshal parses a compressed or uncompressed SEC 1 encoded point. An error is returned when the
+// encoding is invalid or the point is not on the curve.
+func Unmarshal(data []byte) (*big.Int, *big.Int, error) {
+	c := S256().(*koblitzCurve)
+	p := c.params.P
+
+	switch {
+	case len(data) == PublicKeySizeUncompressed && data[0] == 4:
+		x := new(big.Int).SetBytes(data[1:33])
+		y := new(big.Int).SetBytes(data[33:])
+
+		if !c.IsOnCurve(x, y) {
+			return nil, nil, errors.New("secp256k1: point is not on curve")
+		}
+
+		return x, y, nil
+	case len(data) == PublicKeySizeCompressed && (data[0] == 2 || data[0] == 3):
+		x := new(big.Int).SetBytes(data[1:])
+		if x.Cmp(p) >= 0 {
+			return nil, nil, errors.New("secp256k1: invalid x coordinate")
+		}
+
+		// p = 3 mod 4, so a square root of y² is (y²)^((p+1)/4).
+		y2 := c.polynomial(x)
+		exp := new(big.Int).Add(p, big.NewInt(1))
+		exp.Rsh(exp, 2)
+		y := new(big.Int).Exp(y2, exp, p)
+
+		if new(big.Int).Exp(y, big.NewInt(2), p).Cmp(y2) != 0 {
+			return nil, nil, errors.New("secp256k1: point is not on curve")
+		}
+
+		if byte(y.Bit(0)) != data[0]&1 {
+			y.Sub(p, y)
+		}
+
+		return x, y, nil
+	default:
+		return nil, nil, errors.New("secp256k1: invalid public key encoding")
+	}
+}
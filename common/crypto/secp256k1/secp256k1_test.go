@@ -0,0 +1,82 @@
+package secp256k1
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScalarBaseMult(t *testing.T) {
+	c := S256()
+
+	// 2G and 3G from the SEC 2 test vectors.
+	x, y := c.ScalarBaseMult([]byte{2})
+	require.Equal(t, "c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee5", hex.EncodeToString(x.Bytes()))
+	require.Equal(t, "1ae168fea63dc339a3c58419466ceaeef7f632653266d0e1236431a950cfe52a", hex.EncodeToString(y.Bytes()))
+
+	x3, y3 := c.ScalarBaseMult([]byte{3})
+	require.Equal(t, "f9308a019258c31049344f85f89d5229b531c845836f99b08601f113bce036f9", hex.EncodeToString(x3.Bytes()))
+
+	ax, ay := c.Add(x, y, c.Params().Gx, c.Params().Gy)
+	require.Equal(t, x3, ax)
+	require.Equal(t, y3, ay)
+
+	dx, dy := c.Double(c.Params().Gx, c.Params().Gy)
+	require.Equal(t, x, dx)
+	require.Equal(t, y, dy)
+
+	require.True(t, c.IsOnCurve(x3, y3))
+	require.False(t, c.IsOnCurve(x3, new(big.Int).Add(y3, big.NewInt(1))))
+
+	// n*G is the point at infinity.
+	ix, iy := c.ScalarBaseMult(c.Params().N.Bytes())
+	require.Zero(t, ix.Sign())
+	require.Zero(t, iy.Sign())
+}
+
+func TestECDSA(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(S256(), rand.Reader)
+	require.NoError(t, err)
+
+	digest := sha256.Sum256([]byte("test message"))
+
+	sig, err := ecdsa.SignASN1(rand.Reader, priv, digest[:])
+	require.NoError(t, err)
+	require.True(t, ecdsa.VerifyASN1(&priv.PublicKey, digest[:], sig))
+
+	other := sha256.Sum256([]byte("other message"))
+	require.False(t, ecdsa.VerifyASN1(&priv.PublicKey, other[:], sig))
+}
+
+func TestMarshalUnmarshal(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(S256(), rand.Reader)
+	require.NoError(t, err)
+
+	compressed := MarshalCompressed(priv.X, priv.Y)
+	require.Len(t, compressed, PublicKeySizeCompressed)
+
+	x, y, err := Unmarshal(compressed)
+	require.NoError(t, err)
+	require.Equal(t, priv.X, x)
+	require.Equal(t, priv.Y, y)
+
+	uncompressed := Marshal(priv.X, priv.Y)
+	require.Len(t, uncompressed, PublicKeySizeUncompressed)
+
+	x, y, err = Unmarshal(uncompressed)
+	require.NoError(t, err)
+	require.Equal(t, priv.X, x)
+	require.Equal(t, priv.Y, y)
+
+	uncompressed[64] ^= 1
+	_, _, err = Unmarshal(uncompressed)
+	require.EqualError(t, err, "secp256k1: point is not on curve")
+
+	_, _, err = Unmarshal([]byte{5, 1, 2})
+	require.EqualError(t, err, "secp256k1: invalid public key encoding")
+}
@@ -0,0 +1,364 @@
+// Package sm2 implements the SM2 public key algorithms of GB/T 32918-2016: digital signatures (part 2) and key
+// exchange (part 3), over the recommended sm2p256v1 curve. Keys are crypto/ecdsa keys on the P256 curve of this
+// package, signatures are encoded as the fixed size r||s. The implementation relies on math/big and is not
+// constant time.
+package sm2
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sync"
+
+	"github.com/zRich/zFusion/common/crypto/sm3"
+)
+
+const (
+	// DefaultID is the default user ID of GB/T 35276-2017, used when parties did not agree on IDs.
+	DefaultID = "1234567812345678"
+	// SignatureSize is the size of r||s signatures.
+	SignatureSize = 64
+
+	coordinateSize = 32
+	// maxIDBits is the maximum bit length of user IDs, whose length is encoded on 2 bytes.
+	maxIDBits = 0xffff
+)
+
+var (
+	// ErrInvalidSignature is returned when a signature does not verify.
+	ErrInvalidSignature = errors.New("invalid SM2 signature")
+	// ErrInvalidKey is returned for keys which are not valid SM2 keys.
+	ErrInvalidKey = errors.New("invalid SM2 key")
+	// ErrConfirmation is returned when the key confirmation of a key exchange fails.
+	ErrConfirmation = errors.New("SM2 key confirmation failed")
+)
+
+var (
+	initOnce sync.Once             //nolint:gochecknoglobals
+	curve    *elliptic.CurveParams //nolint:gochecknoglobals
+)
+
+func initP256() {
+	p, _ := new(big.Int).SetString("FFFFFFFEFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF00000000FFFFFFFFFFFFFFFF", 16)
+	n, _ := new(big.Int).SetString("FFFFFFFEFFFFFFFFFFFFFFFFFFFFFFFF7203DF6B21C6052B53BBF40939D54123", 16)
+	b, _ := new(big.Int).SetString("28E9FA9E9D9F5E344D5A9E4BCF6509A7F39789F515AB8F92DDBCBD414D940E93", 16)
+	gx, _ := new(big.Int).SetString("32C4AE2C1F1981195F9904466A39C9948FE30BBFF2660BE1715A4589334C74C7", 16)
+	gy, _ := new(big.Int).SetString("BC3736A2F4F6779C59BDCEE36B692153D0A9877CC62A474002DF32E52139F0A0", 16)
+
+	curve = &elliptic.CurveParams{Name: "SM2-P-256", P: p, N: n, B: b, Gx: gx, Gy: gy, BitSize: 256}
+}
+
+// P256 returns the sm2p256v1 curve. Its a coefficient is -3, so that the generic elliptic.CurveParams arithmetic
+// applies.
+func P256() elliptic.Curve {
+	initOnce.Do(initP256)
+
+	return curve
+}
+
+// GenerateKey generates an SM2 key pair. Private keys are in [1, n-2], as 1+d must be invertible.
+func GenerateKey(random io.Reader) (*ecdsa.PrivateKey, error) {
+	c := P256()
+
+	d, err := randScalar(random, new(big.Int).Sub(c.Params().N, big.NewInt(2)))
+	if err != nil {
+		return nil, err
+	}
+
+	key := &ecdsa.PrivateKey{PublicKey: ecdsa.PublicKey{Curve: c}, D: d}
+	key.X, key.Y = c.ScalarBaseMult(d.FillBytes(make([]byte, coordinateSize))) //nolint:staticcheck
+
+	return key, nil
+}
+
+// Z returns the hash of the user ID, the curve parameters and the public key, which prefixes signed messages and
+// binds exchanged keys to the parties.
+func Z(pub *ecdsa.PublicKey, id []byte) ([]byte, error) {
+	if len(id)*8 > maxIDBits {
+		return nil, fmt.Errorf("SM2 user ID too long: %d bytes", len(id))
+	}
+
+	if err := checkPublicKey(pub); err != nil {
+		return nil, err
+	}
+
+	params := P256().Params()
+	a := new(big.Int).Sub(params.P, big.NewInt(3))
+
+	h := sm3.New()
+
+	var entl [2]byte
+
+	binary.BigEndian.PutUint16(entl[:], uint16(len(id)*8))
+	h.Write(entl[:]) //nolint:errcheck
+	h.Write(id)      //nolint:errcheck
+
+	for _, v := range []*big.Int{a, params.B, params.Gx, params.Gy, pub.X, pub.Y} {
+		h.Write(v.FillBytes(make([]byte, coordinateSize))) //nolint:errcheck
+	}
+
+	return h.Sum(nil), nil
+}
+
+// Sign signs msg, prefixed with the Z value of the key and id, and returns the r||s signature.
+func Sign(random io.Reader, priv *ecdsa.PrivateKey, id, msg []byte) ([]byte, error) {
+	e, err := digest(&priv.PublicKey, id, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	c := P256()
+	n := c.Params().N
+
+	dPlus1Inv := new(big.Int).ModInverse(new(big.Int).Add(priv.D, big.NewInt(1)), n)
+	if dPlus1Inv == nil || priv.D.Sign() <= 0 {
+		return nil, ErrInvalidKey
+	}
+
+	for {
+		k, err := randScalar(random, new(big.Int).Sub(n, big.NewInt(1)))
+		if err != nil {
+			return nil, err
+		}
+
+		x1, _ := c.ScalarBaseMult(k.FillBytes(make([]byte, coordinateSize))) //nolint:staticcheck
+
+		r := new(big.Int).Add(e, x1)
+		r.Mod(r, n)
+
+		if r.Sign() == 0 || new(big.Int).Add(r, k).Cmp(n) == 0 {
+			continue
+		}
+
+		s := new(big.Int).Mul(r, priv.D)
+		s.Sub(k, s)
+		s.Mul(s, dPlus1Inv)
+		s.Mod(s, n)
+
+		if s.Sign() == 0 {
+			continue
+		}
+
+		sig := make([]byte, SignatureSize)
+		r.FillBytes(sig[:coordinateSize])
+		s.FillBytes(sig[coordinateSize:])
+
+		return sig, nil
+	}
+}
+
+// Verify verifies the r||s signature sig over msg, prefixed with the Z value of pub and id.
+func Verify(pub *ecdsa.PublicKey, id, msg, sig []byte) error {
+	if len(sig) != SignatureSize {
+		return fmt.Errorf("%w: expected %d bytes, got %d", ErrInvalidSignature, SignatureSize, len(sig))
+	}
+
+	e, err := digest(pub, id, msg)
+	if err != nil {
+		return err
+	}
+
+	c := P256()
+	n := c.Params().N
+
+	r := new(big.Int).SetBytes(sig[:coordinateSize])
+	s := new(big.Int).SetBytes(sig[coordinateSize:])
+
+	if r.Sign() == 0 || r.Cmp(n) >= 0 || s.Sign() == 0 || s.Cmp(n) >= 0 {
+		return ErrInvalidSignature
+	}
+
+	t := new(big.Int).Add(r, s)
+	t.Mod(t, n)
+
+	if t.Sign() == 0 {
+		return ErrInvalidSignature
+	}
+
+	x1, y1 := c.ScalarBaseMult(s.Bytes())           //nolint:staticcheck
+	x2, y2 := c.ScalarMult(pub.X, pub.Y, t.Bytes()) //nolint:staticcheck
+	x, _ := c.Add(x1, y1, x2, y2)                   //nolint:staticcheck
+
+	v := new(big.Int).Add(e, x)
+	if v.Mod(v, n).Cmp(r) != 0 {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// Party is the peer of a key exchange.
+type Party struct {
+	// ID is the user ID of the peer.
+	ID []byte
+	// PublicKey is the static public key of the peer.
+	PublicKey *ecdsa.PublicKey
+	// Ephemeral is the ephemeral public key R sent by the peer.
+	Ephemeral *ecdsa.PublicKey
+}
+
+// KeyExchangeResult is the outcome of a key exchange.
+type KeyExchangeResult struct {
+	// Key is the shared key.
+	Key []byte
+	// Confirmation is the optional confirmation hash to send to the peer: SB for the responder, SA for the
+	// initiator.
+	Confirmation []byte
+	// PeerConfirmation is the confirmation hash expected from the peer, see CheckConfirmation.
+	PeerConfirmation []byte
+}
+
+// CheckConfirmation checks the confirmation hash received from the peer.
+func (r *KeyExchangeResult) CheckConfirmation(confirmation []byte) error {
+	if !bytes.Equal(r.PeerConfirmation, confirmation) {
+		return ErrConfirmation
+	}
+
+	return nil
+}
+
+// KeyExchange derives a shared key of keyLen bytes with peer, from the static key and ID and the ephemeral key of
+// this party. The initiator (user A of the standard) and the responder (user B) exchange their ephemeral public
+// keys beforehand, and may then exchange the confirmation hashes.
+func KeyExchange(keyLen int, initiator bool, key *ecdsa.PrivateKey, id []byte, ephemeral *ecdsa.PrivateKey,
+	peer *Party) (*KeyExchangeResult, error) {
+	if keyLen <= 0 {
+		return nil, fmt.Errorf("invalid SM2 key exchange length %d", keyLen)
+	}
+
+	if err := checkPublicKey(peer.Ephemeral); err != nil {
+		return nil, fmt.Errorf("peer ephemeral key: %w", err)
+	}
+
+	z, err := Z(&key.PublicKey, id)
+	if err != nil {
+		return nil, err
+	}
+
+	peerZ, err := Z(peer.PublicKey, peer.ID)
+	if err != nil {
+		return nil, fmt.Errorf("peer key: %w", err)
+	}
+
+	c := P256()
+	n := c.Params().N
+
+	// t = (d + x̄ * r) mod n
+	t := new(big.Int).Mul(reduceX(ephemeral.X), ephemeral.D)
+	t.Add(t, key.D)
+	t.Mod(t, n)
+
+	// V = [t](P + [x̄peer]Rpeer)
+	x, y := c.ScalarMult(peer.Ephemeral.X, peer.Ephemeral.Y, reduceX(peer.Ephemeral.X).Bytes()) //nolint:staticcheck
+	x, y = c.Add(peer.PublicKey.X, peer.PublicKey.Y, x, y)                                      //nolint:staticcheck
+	vx, vy := c.ScalarMult(x, y, t.Bytes())                                                     //nolint:staticcheck
+
+	if vx.Sign() == 0 && vy.Sign() == 0 {
+		return nil, errors.New("SM2 key exchange: shared point at infinity")
+	}
+
+	zA, zB, rA, rB := z, peerZ, &ephemeral.PublicKey, peer.Ephemeral
+	if !initiator {
+		zA, zB, rA, rB = peerZ, z, peer.Ephemeral, &ephemeral.PublicKey
+	}
+
+	xV, yV := vx.FillBytes(make([]byte, coordinateSize)), vy.FillBytes(make([]byte, coordinateSize))
+
+	result := &KeyExchangeResult{Key: KDF(concat(xV, yV, zA, zB), keyLen)}
+
+	inner := sm3.Sum(concat(xV, zA, zB, coordinates(rA), coordinates(rB)))
+	s02 := sm3.Sum(concat([]byte{0x02}, yV, inner[:]))
+	s03 := sm3.Sum(concat([]byte{0x03}, yV, inner[:]))
+
+	if initiator {
+		result.Confirmation, result.PeerConfirmation = s03[:], s02[:]
+	} else {
+		result.Confirmation, result.PeerConfirmation = s02[:], s03[:]
+	}
+
+	return result, nil
+}
+
+// KDF is the key derivation function of GB/T 32918.4, based on SM3.
+func KDF(z []byte, keyLen int) []byte {
+	key := make([]byte, 0, keyLen+sm3.Size)
+
+	var counter [4]byte
+
+	for ct := uint32(1); len(key) < keyLen; ct++ {
+		binary.BigEndian.PutUint32(counter[:], ct)
+
+		h := sm3.New()
+		h.Write(z)          //nolint:errcheck
+		h.Write(counter[:]) //nolint:errcheck
+		key = h.Sum(key)
+	}
+
+	return key[:keyLen]
+}
+
+func digest(pub *ecdsa.PublicKey, id, msg []byte) (*big.Int, error) {
+	z, err := Z(pub, id)
+	if err != nil {
+		return nil, err
+	}
+
+	e := sm3.Sum(concat(z, msg))
+
+	return new(big.Int).SetBytes(e[:]), nil
+}
+
+func checkPublicKey(pub *ecdsa.PublicKey) error {
+	if pub == nil || pub.X == nil || pub.Y == nil {
+		return fmt.Errorf("%w: missing public key", ErrInvalidKey)
+	}
+
+	if pub.Curve.Params().Name != P256().Params().Name || !P256().IsOnCurve(pub.X, pub.Y) { //nolint:staticcheck
+		return fmt.Errorf("%w: public key is not on the SM2 curve", ErrInvalidKey)
+	}
+
+	return nil
+}
+
+// reduceX returns x̄ = 2^w + (x & (2^w - 1)), with w = 127 for the 256 bits curve order.
+func reduceX(x *big.Int) *big.Int {
+	const w = 127
+
+	twoW := new(big.Int).Lsh(big.NewInt(1), w)
+	reduced := new(big.Int).And(x, new(big.Int).Sub(twoW, big.NewInt(1)))
+
+	return reduced.Add(reduced, twoW)
+}
+
+// randScalar returns a random integer in [1, max].
+func randScalar(random io.Reader, max *big.Int) (*big.Int, error) {
+	b := make([]byte, coordinateSize+8)
+
+	if _, err := io.ReadFull(random, b); err != nil {
+		return nil, fmt.Errorf("SM2 random scalar: %w", err)
+	}
+
+	k := new(big.Int).SetBytes(b)
+	k.Mod(k, max)
+
+	return k.Add(k, big.NewInt(1)), nil
+}
+
+func coordinates(pub *ecdsa.PublicKey) []byte {
+	return concat(pub.X.FillBytes(make([]byte, coordinateSize)), pub.Y.FillBytes(make([]byte, coordinateSize)))
+}
+
+func concat(parts ...[]byte) []byte {
+	var out []byte
+
+	for _, part := range parts {
+		out = append(out, part...)
+	}
+
+	return out
+}
@@ -0,0 +1,124 @@
+package sm2
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func fromHex(t *testing.T, s string) []byte {
+	t.Helper()
+
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+
+	return b
+}
+
+func TestCurve(t *testing.T) {
+	params := P256().Params()
+	require.True(t, P256().IsOnCurve(params.Gx, params.Gy))
+
+	x, y := P256().ScalarBaseMult(params.N.Bytes())
+	require.Zero(t, x.Sign())
+	require.Zero(t, y.Sign())
+}
+
+func TestSignVerify(t *testing.T) {
+	// key and signature generated with OpenSSL 3:
+	//   openssl genpkey -algorithm SM2 -out sm2.pem
+	//   openssl pkeyutl -sign -inkey sm2.pem -rawin -in msg -digest sm3 -pkeyopt distid:1234567812345678
+	pub := fromHex(t, "04b5f32199eab83376a01b8a8b66e8e3ba6a1b2f9efac7dd41f865d511e5f8beb5"+
+		"b3b1bef2a25f0c6a6d7e22cbc2d2ba1cdf9bd41e8cb17d26346b945e93c3f15c")
+	key := &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: P256(), X: new(big.Int).SetBytes(pub[1:33]), Y: new(big.Int).SetBytes(pub[33:]),
+		},
+		D: new(big.Int).SetBytes(fromHex(t, "d195868266e7b099f6cb4d0f6074353a642f4600a375bdb5eef7e269c83c9c93")),
+	}
+	msg := []byte("message digest")
+	sig := fromHex(t, "150745488feed2291936647a5f24530137ce44da3b1dd2e1f6e00b2ca97b61f0"+
+		"3034afeb153c1ff06cdef4694c34bab8d9aa693eb249466a7bd2c69ff84d933f")
+
+	require.NoError(t, Verify(&key.PublicKey, []byte(DefaultID), msg, sig))
+	require.ErrorIs(t, Verify(&key.PublicKey, []byte("ALICE123@YAHOO.COM"), msg, sig), ErrInvalidSignature)
+	require.ErrorIs(t, Verify(&key.PublicKey, []byte(DefaultID), []byte("message"), sig), ErrInvalidSignature)
+	require.ErrorIs(t, Verify(&key.PublicKey, []byte(DefaultID), msg, sig[1:]), ErrInvalidSignature)
+
+	sig, err := Sign(rand.Reader, key, []byte(DefaultID), msg)
+	require.NoError(t, err)
+	require.Len(t, sig, SignatureSize)
+	require.NoError(t, Verify(&key.PublicKey, []byte(DefaultID), msg, sig))
+
+	generated, err := GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	sig, err = Sign(rand.Reader, generated, []byte("ALICE123@YAHOO.COM"), msg)
+	require.NoError(t, err)
+	require.NoError(t, Verify(&generated.PublicKey, []byte("ALICE123@YAHOO.COM"), msg, sig))
+	require.ErrorIs(t, Verify(&key.PublicKey, []byte("ALICE123@YAHOO.COM"), msg, sig), ErrInvalidSignature)
+
+	offCurve := &ecdsa.PublicKey{Curve: P256(), X: big.NewInt(1), Y: big.NewInt(1)}
+	require.ErrorIs(t, Verify(offCurve, []byte(DefaultID), msg, sig), ErrInvalidKey)
+}
+
+func TestKeyExchange(t *testing.T) {
+	alice, err := GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	bob, err := GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	aliceEphemeral, err := GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	bobEphemeral, err := GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	aliceID, bobID := []byte("ALICE123@YAHOO.COM"), []byte("BILL456@YAHOO.COM")
+
+	responder, err := KeyExchange(16, false, bob, bobID, bobEphemeral, &Party{
+		ID: aliceID, PublicKey: &alice.PublicKey, Ephemeral: &aliceEphemeral.PublicKey,
+	})
+	require.NoError(t, err)
+
+	initiator, err := KeyExchange(16, true, alice, aliceID, aliceEphemeral, &Party{
+		ID: bobID, PublicKey: &bob.PublicKey, Ephemeral: &bobEphemeral.PublicKey,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, initiator.Key, 16)
+	require.Equal(t, initiator.Key, responder.Key)
+	require.NoError(t, initiator.CheckConfirmation(responder.Confirmation))
+	require.NoError(t, responder.CheckConfirmation(initiator.Confirmation))
+	require.ErrorIs(t, initiator.CheckConfirmation(initiator.Confirmation), ErrConfirmation)
+
+	// a party using another ID derives another key
+	mallory, err := KeyExchange(16, true, alice, []byte("MALLORY"), aliceEphemeral, &Party{
+		ID: bobID, PublicKey: &bob.PublicKey, Ephemeral: &bobEphemeral.PublicKey,
+	})
+	require.NoError(t, err)
+	require.NotEqual(t, responder.Key, mallory.Key)
+	require.ErrorIs(t, responder.CheckConfirmation(mallory.Confirmation), ErrConfirmation)
+
+	_, err = KeyExchange(16, true, alice, aliceID, aliceEphemeral, &Party{
+		ID: bobID, PublicKey: &bob.PublicKey,
+		Ephemeral: &ecdsa.PublicKey{Curve: P256(), X: big.NewInt(1), Y: big.NewInt(2)},
+	})
+	require.ErrorIs(t, err, ErrInvalidKey)
+
+	_, err = KeyExchange(0, true, alice, aliceID, aliceEphemeral, &Party{
+		ID: bobID, PublicKey: &bob.PublicKey, Ephemeral: &bobEphemeral.PublicKey,
+	})
+	require.Error(t, err)
+}
+
+func TestKDF(t *testing.T) {
+	require.Len(t, KDF([]byte("z"), 100), 100)
+	require.Equal(t, KDF([]byte("z"), 100)[:32], KDF([]byte("z"), 32))
+	require.NotEqual(t, KDF([]byte("z"), 32), KDF([]byte("y"), 32))
+}
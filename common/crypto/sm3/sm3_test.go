@@ -0,0 +1,49 @@
+package sm3
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSum(t *testing.T) {
+	// examples of GB/T 32905-2016 appendix A.
+	sum := Sum([]byte("abc"))
+	require.Equal(t, "66c7f0f462eeedd9d1f2d46bdc10e4e24167c4875cf2f7a2297da02b8f4ba8e0", hex.EncodeToString(sum[:]))
+
+	sum = Sum([]byte(strings.Repeat("abcd", 16)))
+	require.Equal(t, "debe9ff92275b8a138604889c18e5a4d6fdb70e5387e5765293dcba39c0c5732", hex.EncodeToString(sum[:]))
+
+	sum = Sum(nil)
+	require.Equal(t, "1ab21d8355cfa17f8e61194831e81a8f22bec8c728fefb747ed035eb5082aa2b", hex.EncodeToString(sum[:]))
+}
+
+func TestHash(t *testing.T) {
+	data := []byte(strings.Repeat("The quick brown fox jumps over the lazy dog. ", 10))
+	expected := Sum(data)
+
+	h := New()
+	require.Equal(t, Size, h.Size())
+	require.Equal(t, BlockSize, h.BlockSize())
+
+	// writes of any size, and sums in the middle, do not change the digest
+	for _, chunk := range []int{1, 7, 63, 64, 65} {
+		h.Reset()
+
+		for i := 0; i < len(data); i += chunk {
+			end := i + chunk
+			if end > len(data) {
+				end = len(data)
+			}
+
+			_, err := h.Write(data[i:end])
+			require.NoError(t, err)
+
+			h.Sum(nil)
+		}
+
+		require.Equal(t, expected[:], h.Sum(nil), chunk)
+	}
+}
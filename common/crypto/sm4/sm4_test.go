@@ -0,0 +1,70 @@
+package sm4
+
+import (
+	"crypto/cipher"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCipher(t *testing.T) {
+	// examples of GB/T 32907-2016 appendix A.
+	key, err := hex.DecodeString("0123456789abcdeffedcba9876543210")
+	require.NoError(t, err)
+
+	block, err := NewCipher(key)
+	require.NoError(t, err)
+	require.Equal(t, BlockSize, block.BlockSize())
+
+	out := make([]byte, BlockSize)
+	block.Encrypt(out, key)
+	require.Equal(t, "681edf34d206965e86b3e94f536e4246", hex.EncodeToString(out))
+
+	block.Decrypt(out, out)
+	require.Equal(t, key, out)
+
+	copy(out, key)
+
+	for i := 0; i < 1000000; i++ {
+		block.Encrypt(out, out)
+	}
+
+	require.Equal(t, "595298c7c6fd271f0402f804c33d3f66", hex.EncodeToString(out))
+
+	_, err = NewCipher(key[:15])
+	require.Error(t, err)
+}
+
+func TestGCM(t *testing.T) {
+	// RFC 8998 appendix A.1.
+	fromHex := func(s string) []byte {
+		b, err := hex.DecodeString(s)
+		require.NoError(t, err)
+
+		return b
+	}
+
+	block, err := NewCipher(fromHex("0123456789abcdeffedcba9876543210"))
+	require.NoError(t, err)
+
+	aead, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+
+	nonce := fromHex("00001234567800000000abcd")
+	aad := fromHex("feedfacedeadbeeffeedfacedeadbeefabaddad2")
+	plaintext := fromHex("aaaaaaaaaaaaaaaabbbbbbbbbbbbbbbbccccccccccccccccdddddddddddddddd" +
+		"eeeeeeeeeeeeeeeeffffffffffffffffeeeeeeeeeeeeeeeeaaaaaaaaaaaaaaaa")
+
+	sealed := aead.Seal(nil, nonce, plaintext, aad)
+	require.Equal(t, "17f399f08c67d5ee19d0dc9969c4bb7d5fd46fd3756489069157b282bb200735"+
+		"d82710ca5c22f0ccfa7cbf93d496ac15a56834cbcf98c397b4024a2691233b8d"+
+		"83de3541e4c2b58177e065a9bf7b62ec", hex.EncodeToString(sealed))
+
+	opened, err := aead.Open(nil, nonce, sealed, aad)
+	require.NoError(t, err)
+	require.Equal(t, plaintext, opened)
+
+	_, err = aead.Open(nil, nonce, sealed, []byte("other data"))
+	require.Error(t, err)
+}
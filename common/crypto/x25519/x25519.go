@@ -0,0 +1,145 @@
+// Package x25519 implements the X25519 Diffie-Hellman function of RFC 7748, for the key agreement keys of
+// DID documents. The arithmetic uses math/big and is not constant time.
+package x25519
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+const (
+	// ScalarSize is the size of private keys.
+	ScalarSize = 32
+	// PointSize is the size of public keys and shared secrets.
+	PointSize = 32
+)
+
+// ErrLowOrderPoint is returned when the shared secret is all zeros, because the peer key is a low order point.
+var ErrLowOrderPoint = errors.New("low order point")
+
+//nolint:gochecknoglobals
+var (
+	basepoint = []byte{9, 31: 0}
+	prime     = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+	a24       = big.NewInt(121665)
+)
+
+// GenerateKey returns a random private key and its public key.
+func GenerateKey() ([]byte, []byte, error) {
+	privateKey := make([]byte, ScalarSize)
+	if _, err := rand.Read(privateKey); err != nil {
+		return nil, nil, fmt.Errorf("generate X25519 key: %w", err)
+	}
+
+	publicKey, err := PublicKey(privateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return privateKey, publicKey, nil
+}
+
+// PublicKey returns the public key of privateKey.
+func PublicKey(privateKey []byte) ([]byte, error) {
+	return X25519(privateKey, basepoint)
+}
+
+// X25519 multiplies the point by the scalar, both little-endian encoded as specified by RFC 7748.
+func X25519(scalar, point []byte) ([]byte, error) {
+	if len(scalar) != ScalarSize {
+		return nil, fmt.Errorf("invalid X25519 scalar size %d", len(scalar))
+	}
+
+	if len(point) != PointSize {
+		return nil, fmt.Errorf("invalid X25519 point size %d", len(point))
+	}
+
+	k := make([]byte, ScalarSize)
+	copy(k, scalar)
+	k[0] &= 248
+	k[31] &= 127
+	k[31] |= 64
+
+	u := make([]byte, PointSize)
+	copy(u, point)
+	u[31] &= 127
+
+	out := encode(ladder(decode(k), new(big.Int).Mod(decode(u), prime)))
+
+	for _, b := range out {
+		if b != 0 {
+			return out, nil
+		}
+	}
+
+	return nil, ErrLowOrderPoint
+}
+
+// ladder is the Montgomery ladder of RFC 7748 section 5.
+func ladder(k, u *big.Int) *big.Int {
+	x1 := u
+	x2, z2 := big.NewInt(1), big.NewInt(0)
+	x3, z3 := new(big.Int).Set(u), big.NewInt(1)
+	swap := uint(0)
+
+	for t := 254; t >= 0; t-- {
+		bit := k.Bit(t)
+		swap ^= bit
+
+		if swap == 1 {
+			x2, x3 = x3, x2
+			z2, z3 = z3, z2
+		}
+
+		swap = bit
+
+		a := mod(new(big.Int).Add(x2, z2))
+		aa := mod(new(big.Int).Mul(a, a))
+		b := mod(new(big.Int).Sub(x2, z2))
+		bb := mod(new(big.Int).Mul(b, b))
+		e := mod(new(big.Int).Sub(aa, bb))
+		c := mod(new(big.Int).Add(x3, z3))
+		d := mod(new(big.Int).Sub(x3, z3))
+		da := mod(new(big.Int).Mul(d, a))
+		cb := mod(new(big.Int).Mul(c, b))
+
+		sum := mod(new(big.Int).Add(da, cb))
+		x3 = mod(new(big.Int).Mul(sum, sum))
+		diff := mod(new(big.Int).Sub(da, cb))
+		z3 = mod(new(big.Int).Mul(x1, mod(new(big.Int).Mul(diff, diff))))
+		x2 = mod(new(big.Int).Mul(aa, bb))
+		z2 = mod(new(big.Int).Mul(e, mod(new(big.Int).Add(aa, mod(new(big.Int).Mul(a24, e))))))
+	}
+
+	if swap == 1 {
+		x2, z2 = x3, z3
+	}
+
+	return mod(new(big.Int).Mul(x2, new(big.Int).Exp(z2, new(big.Int).Sub(prime, big.NewInt(2)), prime)))
+}
+
+func mod(x *big.Int) *big.Int {
+	return x.Mod(x, prime)
+}
+
+func decode(b []byte) *big.Int {
+	be := make([]byte, len(b))
+	for i := range b {
+		be[len(b)-1-i] = b[i]
+	}
+
+	return new(big.Int).SetBytes(be)
+}
+
+func encode(x *big.Int) []byte {
+	out := make([]byte, PointSize)
+	x.FillBytes(out)
+
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+
+	return out
+}
@@ -0,0 +1,63 @@
+package x25519
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func fromHex(t *testing.T, s string) []byte {
+	t.Helper()
+
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+
+	return b
+}
+
+func TestX25519(t *testing.T) {
+	// RFC 7748 section 5.2.
+	out, err := X25519(
+		fromHex(t, "a546e36bf0527c9d3b16154b82465edd62144c0ac1fc5a18506a2244ba449ac4"),
+		fromHex(t, "e6db6867583030db3594c1a424b15f7c726624ec26b3353b10a903a6d0ab1c4c"),
+	)
+	require.NoError(t, err)
+	require.Equal(t, "c3da55379de9c6908e94ea4df28d084f32eccf03491c71f754b4075577a28552", hex.EncodeToString(out))
+
+	// RFC 7748 section 6.1.
+	alice := fromHex(t, "77076d0a7318a57d3c16c17251b26645df4c2f87ebc0992ab177fba51db92c2a")
+	bob := fromHex(t, "5dab087e624a8a4b79e17f8b83800ee66f3bb1292618b6fd1c2f8b27ff88e0eb")
+
+	alicePub, err := PublicKey(alice)
+	require.NoError(t, err)
+	require.Equal(t, "8520f0098930a754748b7ddcb43ef75a0dbf3a0d26381af4eba4a98eaa9b4e6a", hex.EncodeToString(alicePub))
+
+	bobPub, err := PublicKey(bob)
+	require.NoError(t, err)
+	require.Equal(t, "de9edb7d7b7dc1b4d35b61c2ece435373f8343c85b78674dadfc7e146f882b4f", hex.EncodeToString(bobPub))
+
+	shared, err := X25519(alice, bobPub)
+	require.NoError(t, err)
+	require.Equal(t, "4a5d9d5ba4ce2de1728e3bf480350f25e07e21c947d19e3376f09b3c1e161742", hex.EncodeToString(shared))
+
+	shared, err = X25519(bob, alicePub)
+	require.NoError(t, err)
+	require.Equal(t, "4a5d9d5ba4ce2de1728e3bf480350f25e07e21c947d19e3376f09b3c1e161742", hex.EncodeToString(shared))
+
+	privateKey, publicKey, err := GenerateKey()
+	require.NoError(t, err)
+
+	derived, err := PublicKey(privateKey)
+	require.NoError(t, err)
+	require.Equal(t, publicKey, derived)
+
+	_, err = X25519(alice, make([]byte, PointSize))
+	require.ErrorIs(t, err, ErrLowOrderPoint)
+
+	_, err = X25519(alice[:31], bobPub)
+	require.Error(t, err)
+
+	_, err = X25519(alice, bobPub[:31])
+	require.Error(t, err)
+}
@@ -0,0 +1,717 @@
+package jsonld
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// maxRemoteContexts bounds the number of remote contexts loaded while processing a context, which also
+// stops context inclusion cycles.
+const maxRemoteContexts = 32
+
+var (
+	keywordLike = regexp.MustCompile(`^@[a-zA-Z]+$`)               //nolint:gochecknoglobals
+	iriScheme   = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+\-.]*:`) //nolint:gochecknoglobals
+)
+
+// keywords are the JSON-LD 1.1 keywords.
+var keywords = map[string]bool{ //nolint:gochecknoglobals
+	"@base": true, "@container": true, "@context": true, "@default": true, "@direction": true,
+	"@embed": true, "@explicit": true, "@graph": true, "@id": true, "@import": true, "@included": true,
+	"@index": true, "@json": true, "@language": true, "@list": true, "@nest": true, "@none": true,
+	"@omitDefault": true, "@prefix": true, "@preserve": true, "@propagate": true, "@protected": true,
+	"@requireAll": true, "@reverse": true, "@set": true, "@type": true, "@value": true, "@version": true,
+	"@vocab": true,
+}
+
+// termDefinition is the definition of a term in an active context. An empty id is a null mapping.
+type termDefinition struct {
+	id         string
+	reverse    bool
+	typ        string
+	language   *string
+	container  []string
+	context    interface{}
+	hasContext bool
+	baseURL    string
+	protected  bool
+	prefix     bool
+	index      string
+	nest       string
+}
+
+func (d *termDefinition) hasContainer(container string) bool {
+	for _, c := range d.container {
+		if c == container {
+			return true
+		}
+	}
+
+	return false
+}
+
+// equal compares definitions ignoring the protected flag.
+func (d *termDefinition) equal(other *termDefinition) bool {
+	a, b := *d, *other
+	a.protected, b.protected = false, false
+
+	return reflect.DeepEqual(a, b)
+}
+
+type activeContext struct {
+	base     string
+	hasBase  bool
+	vocab    string
+	language string
+	terms    map[string]*termDefinition
+	previous *activeContext
+}
+
+func newActiveContext(base string) *activeContext {
+	return &activeContext{base: base, hasBase: base != "", terms: map[string]*termDefinition{}}
+}
+
+func (c *activeContext) clone() *activeContext {
+	clone := *c
+	clone.terms = make(map[string]*termDefinition, len(c.terms))
+
+	for term, def := range c.terms {
+		clone.terms[term] = def
+	}
+
+	return &clone
+}
+
+func (c *activeContext) term(name string) *termDefinition {
+	if def, ok := c.terms[name]; ok {
+		return def
+	}
+
+	return &termDefinition{}
+}
+
+func (c *activeContext) hasProtectedTerms() bool {
+	for _, def := range c.terms {
+		if def.protected {
+			return true
+		}
+	}
+
+	return false
+}
+
+// termDefiner holds the state of the term definitions created from a local context.
+type termDefiner struct {
+	local     map[string]interface{}
+	defined   map[string]bool
+	baseURL   string
+	protected bool
+	opts      contextOpts
+	err       error
+}
+
+type contextOpts struct {
+	overrideProtected bool
+	propagate         bool
+	remoteContexts    map[string]bool
+}
+
+// processContext implements the context processing algorithm, returning the active context resulting from
+// applying localContext to active.
+func (p *processor) processContext(active *activeContext, localContext interface{}, baseURL string,
+	o contextOpts) (*activeContext, error) {
+	result := active.clone()
+
+	if m, ok := localContext.(map[string]interface{}); ok {
+		if propagate, ok := m["@propagate"]; ok {
+			b, ok := propagate.(bool)
+			if !ok {
+				return nil, invalidf("invalid @propagate value")
+			}
+
+			o.propagate = b
+		}
+	}
+
+	if !o.propagate && result.previous == nil {
+		result.previous = active
+	}
+
+	if o.remoteContexts == nil {
+		o.remoteContexts = map[string]bool{}
+	}
+
+	contexts := asArray(localContext)
+	if localContext == nil {
+		contexts = []interface{}{nil}
+	}
+
+	for _, context := range contexts {
+		switch ctx := context.(type) {
+		case nil:
+			if !o.overrideProtected && result.hasProtectedTerms() {
+				return nil, invalidf("invalid context nullification of protected terms")
+			}
+
+			previous := result
+			result = newActiveContext(p.opts.base)
+
+			if !o.propagate {
+				result.previous = previous
+			}
+		case string:
+			contextURL := resolveIRI(baseURL, ctx)
+
+			if o.remoteContexts[contextURL] {
+				continue
+			}
+
+			if len(o.remoteContexts) >= maxRemoteContexts {
+				return nil, invalidf("context overflow loading %s", contextURL)
+			}
+
+			remote, err := p.loadContext(contextURL)
+			if err != nil {
+				return nil, err
+			}
+
+			remoteContexts := copySet(o.remoteContexts)
+			remoteContexts[contextURL] = true
+
+			result, err = p.processContext(result, remote, contextURL, contextOpts{
+				overrideProtected: o.overrideProtected,
+				propagate:         true,
+				remoteContexts:    remoteContexts,
+			})
+			if err != nil {
+				return nil, err
+			}
+		case map[string]interface{}:
+			var err error
+
+			result, err = p.processLocalContext(result, ctx, baseURL, o)
+			if err != nil {
+				return nil, err
+			}
+		default:
+			return nil, invalidf("invalid local context")
+		}
+	}
+
+	return result, nil
+}
+
+func (p *processor) processLocalContext(result *activeContext, ctx map[string]interface{}, baseURL string,
+	o contextOpts) (*activeContext, error) {
+	if version, ok := ctx["@version"]; ok {
+		if n, ok := version.(json.Number); !ok || n.String() != "1.1" {
+			if f, ok := version.(float64); !ok || f != 1.1 { //nolint:gomnd
+				return nil, invalidf("invalid @version value")
+			}
+		}
+	}
+
+	if imp, ok := ctx["@import"]; ok {
+		importURL, ok := imp.(string)
+		if !ok {
+			return nil, invalidf("invalid @import value")
+		}
+
+		imported, err := p.loadContext(resolveIRI(baseURL, importURL))
+		if err != nil {
+			return nil, err
+		}
+
+		importedMap, ok := imported.(map[string]interface{})
+		if !ok {
+			return nil, invalidf("invalid remote context %s", importURL)
+		}
+
+		if _, ok := importedMap["@import"]; ok {
+			return nil, invalidf("invalid context entry @import in imported context")
+		}
+
+		merged := make(map[string]interface{}, len(ctx)+len(importedMap))
+
+		for k, v := range importedMap {
+			merged[k] = v
+		}
+
+		for k, v := range ctx {
+			if k != "@import" {
+				merged[k] = v
+			}
+		}
+
+		ctx = merged
+	}
+
+	if base, ok := ctx["@base"]; ok && len(o.remoteContexts) == 0 {
+		switch b := base.(type) {
+		case nil:
+			result.base, result.hasBase = "", false
+		case string:
+			result.base, result.hasBase = resolveIRI(result.base, b), true
+		default:
+			return nil, invalidf("invalid base IRI")
+		}
+	}
+
+	if vocab, ok := ctx["@vocab"]; ok {
+		switch v := vocab.(type) {
+		case nil:
+			result.vocab = ""
+		case string:
+			expanded, ok := p.expandIRI(result, v, true, true, nil)
+			if !ok || (!isAbsoluteIRI(expanded) && !isBlankNode(expanded)) {
+				return nil, invalidf("invalid vocab mapping %q", v)
+			}
+
+			result.vocab = expanded
+		default:
+			return nil, invalidf("invalid vocab mapping")
+		}
+	}
+
+	if language, ok := ctx["@language"]; ok {
+		switch l := language.(type) {
+		case nil:
+			result.language = ""
+		case string:
+			result.language = strings.ToLower(l)
+		default:
+			return nil, invalidf("invalid default language")
+		}
+	}
+
+	protected := false
+
+	if value, ok := ctx["@protected"]; ok {
+		b, ok := value.(bool)
+		if !ok {
+			return nil, invalidf("invalid @protected value")
+		}
+
+		protected = b
+	}
+
+	d := &termDefiner{local: ctx, defined: map[string]bool{}, baseURL: baseURL, protected: protected, opts: o}
+
+	for _, term := range sortedKeys(ctx) {
+		switch term {
+		case "@base", "@direction", "@import", "@language", "@propagate", "@protected", "@version", "@vocab":
+			continue
+		}
+
+		if err := p.createTermDefinition(result, d, term); err != nil {
+			return nil, err
+		}
+
+		if d.err != nil {
+			return nil, d.err
+		}
+	}
+
+	return result, nil
+}
+
+// createTermDefinition implements the create term definition algorithm.
+//
+//nolint:gocyclo,funlen
+func (p *processor) createTermDefinition(active *activeContext, d *termDefiner, term string) error {
+	local, defined := d.local, d.defined
+
+	if done, ok := defined[term]; ok {
+		if done {
+			return nil
+		}
+
+		return invalidf("cyclic IRI mapping of term %q", term)
+	}
+
+	if term == "" {
+		return invalidf("invalid term definition of the empty term")
+	}
+
+	defined[term] = false
+	value := local[term]
+
+	if term == "@type" {
+		m, ok := value.(map[string]interface{})
+		if !ok || len(m) == 0 {
+			return invalidf("keyword redefinition of @type")
+		}
+
+		for k, v := range m {
+			if !(k == "@container" && v == "@set") && k != "@protected" {
+				return invalidf("keyword redefinition of @type")
+			}
+		}
+	} else if keywords[term] {
+		return invalidf("keyword redefinition of %s", term)
+	} else if keywordLike.MatchString(term) {
+		defined[term] = true
+
+		return nil
+	}
+
+	previous := active.terms[term]
+	delete(active.terms, term)
+
+	simpleTerm := false
+
+	var entries map[string]interface{}
+
+	switch v := value.(type) {
+	case nil:
+		entries = map[string]interface{}{"@id": nil}
+	case string:
+		entries, simpleTerm = map[string]interface{}{"@id": v}, true
+	case map[string]interface{}:
+		entries = v
+	default:
+		return invalidf("invalid term definition of %q", term)
+	}
+
+	def := &termDefinition{protected: d.protected}
+
+	if value, ok := entries["@protected"]; ok {
+		b, ok := value.(bool)
+		if !ok {
+			return invalidf("invalid @protected value of term %q", term)
+		}
+
+		def.protected = b
+	}
+
+	if value, ok := entries["@type"]; ok {
+		typ, ok := value.(string)
+		if !ok {
+			return invalidf("invalid type mapping of term %q", term)
+		}
+
+		expanded, ok := p.expandIRI(active, typ, false, true, d)
+		if !ok || (expanded != "@id" && expanded != "@json" && expanded != "@none" && expanded != "@vocab" &&
+			!isAbsoluteIRI(expanded)) {
+			return invalidf("invalid type mapping %q of term %q", typ, term)
+		}
+
+		def.typ = expanded
+	}
+
+	if value, ok := entries["@reverse"]; ok {
+		if _, ok := entries["@id"]; ok {
+			return invalidf("invalid reverse property %q", term)
+		}
+
+		reverse, ok := value.(string)
+		if !ok {
+			return invalidf("invalid IRI mapping of term %q", term)
+		}
+
+		if keywordLike.MatchString(reverse) {
+			defined[term] = true
+
+			return nil
+		}
+
+		expanded, ok := p.expandIRI(active, reverse, false, true, d)
+		if !ok || (!isAbsoluteIRI(expanded) && !isBlankNode(expanded)) {
+			return invalidf("invalid IRI mapping of term %q", term)
+		}
+
+		def.id, def.reverse = expanded, true
+	} else if value, ok := entries["@id"]; ok && value != term {
+		switch id := value.(type) {
+		case nil:
+		case string:
+			if !keywords[id] && keywordLike.MatchString(id) {
+				defined[term] = true
+
+				return nil
+			}
+
+			expanded, ok := p.expandIRI(active, id, false, true, d)
+			if !ok || (!keywords[expanded] && !isAbsoluteIRI(expanded) && !isBlankNode(expanded)) {
+				return invalidf("invalid IRI mapping of term %q", term)
+			}
+
+			if expanded == "@context" {
+				return invalidf("invalid keyword alias @context")
+			}
+
+			if strings.Contains(strings.TrimSuffix(term[1:], ":"), ":") || strings.Contains(term, "/") {
+				defined[term] = true
+
+				termIRI, ok := p.expandIRI(active, term, false, true, d)
+				if !ok || termIRI != expanded {
+					return invalidf("invalid IRI mapping of term %q", term)
+				}
+			}
+
+			def.id = expanded
+
+			if !strings.Contains(term, ":") && !strings.Contains(term, "/") && simpleTerm &&
+				(isBlankNode(expanded) || strings.ContainsAny(expanded[len(expanded)-1:], ":/?#[]@")) {
+				def.prefix = true
+			}
+		default:
+			return invalidf("invalid IRI mapping of term %q", term)
+		}
+	} else if i := strings.Index(term[1:], ":"); i >= 0 {
+		prefix, suffix := term[:i+1], term[i+2:]
+
+		if _, ok := local[prefix]; ok {
+			if err := p.createTermDefinition(active, d, prefix); err != nil {
+				return err
+			}
+		}
+
+		if prefixDef, ok := active.terms[prefix]; ok && prefixDef.id != "" {
+			def.id = prefixDef.id + suffix
+		} else {
+			def.id = term
+		}
+	} else if strings.Contains(term, "/") {
+		expanded, ok := p.expandIRI(active, term, false, true, nil)
+		if !ok || !isAbsoluteIRI(expanded) {
+			return invalidf("invalid IRI mapping of term %q", term)
+		}
+
+		def.id = expanded
+	} else if term == "@type" {
+		def.id = "@type"
+	} else if active.vocab != "" {
+		def.id = active.vocab + term
+	} else {
+		return invalidf("invalid IRI mapping of term %q", term)
+	}
+
+	if value, ok := entries["@container"]; ok {
+		for _, c := range asArray(value) {
+			container, ok := c.(string)
+			if !ok {
+				return invalidf("invalid container mapping of term %q", term)
+			}
+
+			switch container {
+			case "@graph", "@id", "@index", "@language", "@list", "@set", "@type":
+			default:
+				return invalidf("invalid container mapping %q of term %q", container, term)
+			}
+
+			def.container = append(def.container, container)
+		}
+
+		sort.Strings(def.container)
+
+		if def.reverse {
+			for _, c := range def.container {
+				if c != "@set" && c != "@index" {
+					return invalidf("invalid reverse property %q", term)
+				}
+			}
+		}
+	}
+
+	if value, ok := entries["@index"]; ok {
+		index, ok := value.(string)
+		if !ok || !def.hasContainer("@index") {
+			return invalidf("invalid term definition of %q", term)
+		}
+
+		def.index = index
+	}
+
+	if value, ok := entries["@context"]; ok {
+		if _, err := p.processContext(active, value, d.baseURL, contextOpts{
+			overrideProtected: true,
+			propagate:         true,
+			remoteContexts:    copySet(d.opts.remoteContexts),
+		}); err != nil {
+			return invalidf("invalid scoped context of term %q: %v", term, err)
+		}
+
+		def.context, def.hasContext, def.baseURL = value, true, d.baseURL
+	}
+
+	if value, ok := entries["@language"]; ok {
+		switch l := value.(type) {
+		case nil:
+			empty := ""
+			def.language = &empty
+		case string:
+			language := strings.ToLower(l)
+			def.language = &language
+		default:
+			return invalidf("invalid language mapping of term %q", term)
+		}
+	}
+
+	if value, ok := entries["@nest"]; ok {
+		nest, ok := value.(string)
+		if !ok || (keywords[nest] && nest != "@nest") {
+			return invalidf("invalid @nest value of term %q", term)
+		}
+
+		def.nest = nest
+	}
+
+	if value, ok := entries["@prefix"]; ok {
+		prefix, ok := value.(bool)
+		if !ok || strings.Contains(term, ":") || strings.Contains(term, "/") {
+			return invalidf("invalid @prefix value of term %q", term)
+		}
+
+		def.prefix = prefix
+	}
+
+	for key := range entries {
+		switch key {
+		case "@id", "@reverse", "@container", "@context", "@direction", "@index", "@language", "@nest",
+			"@prefix", "@protected", "@type":
+		default:
+			return invalidf("invalid term definition of %q: unexpected %s", term, key)
+		}
+	}
+
+	if !d.opts.overrideProtected && previous != nil && previous.protected {
+		if !def.equal(previous) {
+			return invalidf("protected term redefinition of %q", term)
+		}
+
+		def = previous
+	}
+
+	active.terms[term] = def
+	defined[term] = true
+
+	return nil
+}
+
+// expandIRI implements the IRI expansion algorithm. It returns false when value expands to null. d holds the
+// local context being processed, if any.
+func (p *processor) expandIRI(active *activeContext, value string, documentRelative, vocab bool,
+	d *termDefiner) (string, bool) {
+	if keywords[value] {
+		return value, true
+	}
+
+	if keywordLike.MatchString(value) {
+		return "", false
+	}
+
+	p.defineLocalTerm(active, d, value)
+
+	if def, ok := active.terms[value]; ok {
+		if keywords[def.id] {
+			return def.id, true
+		}
+
+		if vocab {
+			return def.id, def.id != ""
+		}
+	}
+
+	if i := strings.Index(value, ":"); i > 0 {
+		prefix, suffix := value[:i], value[i+1:]
+
+		if prefix == "_" || strings.HasPrefix(suffix, "//") {
+			return value, true
+		}
+
+		p.defineLocalTerm(active, d, prefix)
+
+		if def, ok := active.terms[prefix]; ok && def.id != "" && def.prefix {
+			return def.id + suffix, true
+		}
+
+		if isAbsoluteIRI(value) {
+			return value, true
+		}
+	}
+
+	if vocab && active.vocab != "" {
+		return active.vocab + value, true
+	}
+
+	if documentRelative && active.hasBase {
+		return resolveIRI(active.base, value), true
+	}
+
+	return value, true
+}
+
+// defineLocalTerm creates the definition of term if it is defined by the local context being processed.
+func (p *processor) defineLocalTerm(active *activeContext, d *termDefiner, term string) {
+	if d == nil {
+		return
+	}
+
+	if _, ok := d.local[term]; !ok || d.defined[term] {
+		return
+	}
+
+	if err := p.createTermDefinition(active, d, term); err != nil && d.err == nil {
+		d.err = err
+	}
+}
+
+func (p *processor) loadContext(contextURL string) (interface{}, error) {
+	remote, err := p.opts.loader.LoadDocument(contextURL)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, ok := remote.Document.(map[string]interface{})
+	if !ok {
+		return nil, invalidf("invalid remote context %s", contextURL)
+	}
+
+	context, ok := doc["@context"]
+	if !ok {
+		return nil, invalidf("invalid remote context %s: missing @context", contextURL)
+	}
+
+	return context, nil
+}
+
+func resolveIRI(base, ref string) string {
+	if base == "" || isAbsoluteIRI(ref) {
+		return ref
+	}
+
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+
+	return baseURL.ResolveReference(refURL).String()
+}
+
+func isAbsoluteIRI(value string) bool {
+	return iriScheme.MatchString(value)
+}
+
+func isBlankNode(value string) bool {
+	return strings.HasPrefix(value, "_:")
+}
+
+func copySet(set map[string]bool) map[string]bool {
+	c := make(map[string]bool, len(set))
+
+	for k, v := range set {
+		c[k] = v
+	}
+
+	return c
+}
@@ -0,0 +1,738 @@
+package jsonld
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+type processor struct {
+	opts *options
+}
+
+// expandDocument expands a document and normalizes the result to an array of node objects.
+func (p *processor) expandDocument(input interface{}) ([]interface{}, error) {
+	active := newActiveContext(p.opts.base)
+
+	expanded, err := p.expand(active, "", input, p.opts.base, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if m, ok := expanded.(map[string]interface{}); ok && len(m) == 1 {
+		if graph, ok := m["@graph"]; ok {
+			expanded = graph
+		}
+	}
+
+	if expanded == nil {
+		return []interface{}{}, nil
+	}
+
+	return asArray(expanded), nil
+}
+
+// expand implements the expansion algorithm. An empty activeProperty is the null active property.
+//
+//nolint:gocyclo,funlen
+func (p *processor) expand(active *activeContext, activeProperty string, element interface{}, baseURL string,
+	fromMap bool) (interface{}, error) {
+	if element == nil {
+		return nil, nil
+	}
+
+	propertyDef := active.term(activeProperty)
+
+	switch e := element.(type) {
+	case []interface{}:
+		result := []interface{}{}
+
+		for _, item := range e {
+			expanded, err := p.expand(active, activeProperty, item, baseURL, fromMap)
+			if err != nil {
+				return nil, err
+			}
+
+			if items, ok := expanded.([]interface{}); ok && propertyDef.hasContainer("@list") {
+				expanded = map[string]interface{}{"@list": items}
+			}
+
+			switch v := expanded.(type) {
+			case nil:
+			case []interface{}:
+				result = append(result, v...)
+			default:
+				result = append(result, v)
+			}
+		}
+
+		return result, nil
+	case map[string]interface{}:
+		return p.expandMap(active, activeProperty, e, baseURL, fromMap)
+	default:
+		if activeProperty == "" || activeProperty == "@graph" {
+			return nil, p.dropped("free-floating scalar %v", element)
+		}
+
+		if propertyDef.hasContext {
+			var err error
+
+			active, err = p.processContext(active, propertyDef.context, propertyDef.baseURL,
+				contextOpts{overrideProtected: true, propagate: true})
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return p.expandValue(active, activeProperty, element)
+	}
+}
+
+//nolint:gocyclo,funlen
+func (p *processor) expandMap(active *activeContext, activeProperty string, element map[string]interface{},
+	baseURL string, fromMap bool) (interface{}, error) {
+	propertyDef := active.term(activeProperty)
+
+	if active.previous != nil && !fromMap && !p.keepsContext(active, element) {
+		active = active.previous
+	}
+
+	var err error
+
+	if propertyDef.hasContext {
+		active, err = p.processContext(active, propertyDef.context, propertyDef.baseURL,
+			contextOpts{overrideProtected: true, propagate: true})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if context, ok := element["@context"]; ok {
+		active, err = p.processContext(active, context, baseURL, contextOpts{propagate: true})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	typeScoped := active
+
+	for _, key := range sortedKeys(element) {
+		if expanded, _ := p.expandIRI(active, key, false, true, nil); expanded != "@type" {
+			continue
+		}
+
+		types := []string{}
+
+		for _, t := range asArray(element[key]) {
+			if s, ok := t.(string); ok {
+				types = append(types, s)
+			}
+		}
+
+		sort.Strings(types)
+
+		for _, t := range types {
+			if def := typeScoped.term(t); def.hasContext {
+				active, err = p.processContext(active, def.context, def.baseURL, contextOpts{propagate: false})
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	result := map[string]interface{}{}
+
+	if err := p.expandObject(active, typeScoped, activeProperty, element, baseURL, result); err != nil {
+		return nil, err
+	}
+
+	return p.finishObject(activeProperty, result)
+}
+
+// keepsContext reports whether a non-propagated context applies to element, which is the case of value
+// objects and node references.
+func (p *processor) keepsContext(active *activeContext, element map[string]interface{}) bool {
+	for key := range element {
+		if expanded, _ := p.expandIRI(active, key, false, true, nil); expanded == "@value" {
+			return true
+		}
+	}
+
+	if len(element) == 1 {
+		for key := range element {
+			if expanded, _ := p.expandIRI(active, key, false, true, nil); expanded == "@id" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+//nolint:gocyclo,funlen
+func (p *processor) expandObject(active, typeScoped *activeContext, activeProperty string,
+	element map[string]interface{}, baseURL string, result map[string]interface{}) error {
+	var nests []string
+
+	for _, key := range sortedKeys(element) {
+		value := element[key]
+
+		if key == "@context" {
+			continue
+		}
+
+		expandedProperty, ok := p.expandIRI(active, key, false, true, nil)
+		if !ok || (!strings.Contains(expandedProperty, ":") && !keywords[expandedProperty]) {
+			if err := p.dropped("property %q", key); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if keywords[expandedProperty] {
+			if activeProperty == "@reverse" {
+				return invalidf("invalid reverse property map")
+			}
+
+			if _, ok := result[expandedProperty]; ok && expandedProperty != "@included" &&
+				expandedProperty != "@type" {
+				return invalidf("colliding keywords %s", expandedProperty)
+			}
+
+			if expandedProperty == "@nest" {
+				nests = append(nests, key)
+
+				continue
+			}
+
+			expandedValue, err := p.expandKeyword(active, typeScoped, activeProperty, expandedProperty, value,
+				baseURL, result)
+			if err != nil {
+				return err
+			}
+
+			if expandedValue != nil || expandedProperty == "@value" {
+				result[expandedProperty] = expandedValue
+			}
+
+			continue
+		}
+
+		def := active.term(key)
+
+		var (
+			expandedValue interface{}
+			err           error
+		)
+
+		switch {
+		case def.typ == "@json":
+			expandedValue = map[string]interface{}{"@value": value, "@type": "@json"}
+		case def.hasContainer("@language") && isMap(value):
+			expandedValue, err = p.expandLanguageMap(active, def, value.(map[string]interface{}))
+		case (def.hasContainer("@index") || def.hasContainer("@id") || def.hasContainer("@type")) && isMap(value):
+			expandedValue, err = p.expandIndexMap(active, key, def, value.(map[string]interface{}), baseURL)
+		default:
+			expandedValue, err = p.expand(active, key, value, baseURL, false)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if expandedValue == nil {
+			continue
+		}
+
+		if def.hasContainer("@list") && !isListObject(expandedValue) {
+			expandedValue = map[string]interface{}{"@list": asArray(expandedValue)}
+		}
+
+		if def.hasContainer("@graph") && !def.hasContainer("@id") && !def.hasContainer("@index") {
+			graphs := []interface{}{}
+
+			for _, v := range asArray(expandedValue) {
+				graphs = append(graphs, map[string]interface{}{"@graph": asArray(v)})
+			}
+
+			expandedValue = graphs
+		}
+
+		if def.reverse {
+			reverse, _ := result["@reverse"].(map[string]interface{})
+			if reverse == nil {
+				reverse = map[string]interface{}{}
+				result["@reverse"] = reverse
+			}
+
+			for _, item := range asArray(expandedValue) {
+				if isValueObject(item) || isListObject(item) {
+					return invalidf("invalid reverse property value of %q", key)
+				}
+
+				addValue(reverse, expandedProperty, item)
+			}
+
+			continue
+		}
+
+		addValue(result, expandedProperty, expandedValue)
+	}
+
+	for _, nestKey := range nests {
+		for _, nested := range asArray(element[nestKey]) {
+			nestedMap, ok := nested.(map[string]interface{})
+			if !ok || isValueObject(nestedMap) {
+				return invalidf("invalid @nest value")
+			}
+
+			if err := p.expandObject(active, typeScoped, activeProperty, nestedMap, baseURL, result); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+//nolint:gocyclo,funlen
+func (p *processor) expandKeyword(active, typeScoped *activeContext, activeProperty, keyword string,
+	value interface{}, baseURL string, result map[string]interface{}) (interface{}, error) {
+	switch keyword {
+	case "@id":
+		id, ok := value.(string)
+		if !ok {
+			return nil, invalidf("invalid @id value")
+		}
+
+		expanded, _ := p.expandIRI(active, id, true, false, nil)
+
+		return expanded, nil
+	case "@type":
+		var types []interface{}
+
+		for _, t := range asArray(value) {
+			s, ok := t.(string)
+			if !ok {
+				return nil, invalidf("invalid type value")
+			}
+
+			expanded, ok := p.expandIRI(typeScoped, s, true, true, nil)
+			if !ok {
+				continue
+			}
+
+			if !isAbsoluteIRI(expanded) && !isBlankNode(expanded) && expanded != "@json" {
+				if err := p.dropped("type %q", s); err != nil {
+					return nil, err
+				}
+			}
+
+			types = append(types, expanded)
+		}
+
+		if existing, ok := result["@type"]; ok {
+			types = append(asArray(existing), types...)
+		}
+
+		if _, ok := value.(string); ok && len(types) == 1 {
+			return types[0], nil
+		}
+
+		return types, nil
+	case "@graph":
+		expanded, err := p.expand(active, "@graph", value, baseURL, false)
+		if err != nil {
+			return nil, err
+		}
+
+		return asArray(expanded), nil
+	case "@included":
+		expanded, err := p.expand(active, "", value, baseURL, false)
+		if err != nil {
+			return nil, err
+		}
+
+		included := asArray(expanded)
+
+		for _, item := range included {
+			if !isMap(item) || isValueObject(item) || isListObject(item) {
+				return nil, invalidf("invalid @included value")
+			}
+		}
+
+		if existing, ok := result["@included"]; ok {
+			included = append(asArray(existing), included...)
+		}
+
+		return included, nil
+	case "@value":
+		switch value.(type) {
+		case nil, string, bool, json.Number, float64:
+			return value, nil
+		default:
+			if typ, _ := p.expandIRI(active, stringValue(result["@type"]), false, true, nil); typ == "@json" {
+				return value, nil
+			}
+
+			return nil, invalidf("invalid value object value")
+		}
+	case "@language":
+		language, ok := value.(string)
+		if !ok {
+			return nil, invalidf("invalid language-tagged string")
+		}
+
+		return strings.ToLower(language), nil
+	case "@direction":
+		direction, ok := value.(string)
+		if !ok || (direction != "ltr" && direction != "rtl") {
+			return nil, invalidf("invalid base direction")
+		}
+
+		return direction, nil
+	case "@index":
+		index, ok := value.(string)
+		if !ok {
+			return nil, invalidf("invalid @index value")
+		}
+
+		return index, nil
+	case "@list":
+		if activeProperty == "" || activeProperty == "@graph" {
+			return nil, p.dropped("free-floating list")
+		}
+
+		expanded, err := p.expand(active, activeProperty, value, baseURL, false)
+		if err != nil {
+			return nil, err
+		}
+
+		return asArray(expanded), nil
+	case "@set":
+		return p.expand(active, activeProperty, value, baseURL, false)
+	case "@reverse":
+		if !isMap(value) {
+			return nil, invalidf("invalid @reverse value")
+		}
+
+		expanded, err := p.expand(active, "@reverse", value, baseURL, false)
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, mergeReverse(result, expanded)
+	default:
+		return nil, nil
+	}
+}
+
+// mergeReverse adds the properties of an expanded @reverse map to result.
+func mergeReverse(result map[string]interface{}, expanded interface{}) error {
+	reverseMap, _ := expanded.(map[string]interface{})
+
+	if doubleReverse, ok := reverseMap["@reverse"].(map[string]interface{}); ok {
+		for property, items := range doubleReverse {
+			addValue(result, property, items)
+		}
+	}
+
+	for property, items := range reverseMap {
+		if property == "@reverse" {
+			continue
+		}
+
+		reverse, _ := result["@reverse"].(map[string]interface{})
+		if reverse == nil {
+			reverse = map[string]interface{}{}
+			result["@reverse"] = reverse
+		}
+
+		for _, item := range asArray(items) {
+			if isValueObject(item) || isListObject(item) {
+				return invalidf("invalid reverse property value")
+			}
+
+			addValue(reverse, property, item)
+		}
+	}
+
+	return nil
+}
+
+func (p *processor) expandLanguageMap(active *activeContext, def *termDefinition,
+	value map[string]interface{}) (interface{}, error) {
+	result := []interface{}{}
+
+	for _, language := range sortedKeys(value) {
+		expandedLanguage, _ := p.expandIRI(active, language, false, true, nil)
+
+		for _, item := range asArray(value[language]) {
+			if item == nil {
+				continue
+			}
+
+			s, ok := item.(string)
+			if !ok {
+				return nil, invalidf("invalid language map value")
+			}
+
+			v := map[string]interface{}{"@value": s}
+			if expandedLanguage != "@none" {
+				v["@language"] = strings.ToLower(language)
+			}
+
+			result = append(result, v)
+		}
+	}
+
+	return result, nil
+}
+
+//nolint:gocyclo
+func (p *processor) expandIndexMap(active *activeContext, key string, def *termDefinition,
+	value map[string]interface{}, baseURL string) (interface{}, error) {
+	result := []interface{}{}
+
+	for _, index := range sortedKeys(value) {
+		mapContext := active
+
+		if active.previous != nil {
+			mapContext = active.previous
+		}
+
+		if def.hasContainer("@type") {
+			if indexDef := mapContext.term(index); indexDef.hasContext {
+				var err error
+
+				mapContext, err = p.processContext(mapContext, indexDef.context, indexDef.baseURL,
+					contextOpts{propagate: true})
+				if err != nil {
+					return nil, err
+				}
+			}
+		} else {
+			mapContext = active
+		}
+
+		expandedIndex, _ := p.expandIRI(active, index, false, true, nil)
+
+		expanded, err := p.expand(mapContext, key, asArray(value[index]), baseURL, true)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range asArray(expanded) {
+			m, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, invalidf("invalid index map value of %q", key)
+			}
+
+			switch {
+			case def.hasContainer("@index"):
+				if _, ok := m["@index"]; !ok && expandedIndex != "@none" {
+					m["@index"] = index
+				}
+			case def.hasContainer("@id"):
+				if _, ok := m["@id"]; !ok && expandedIndex != "@none" {
+					m["@id"], _ = p.expandIRI(active, index, true, false, nil)
+				}
+			case def.hasContainer("@type"):
+				if expandedIndex != "@none" {
+					m["@type"] = append([]interface{}{expandedIndex}, asArray(m["@type"])...)
+				}
+			}
+
+			result = append(result, m)
+		}
+	}
+
+	return result, nil
+}
+
+// finishObject validates and simplifies an expanded object.
+//
+//nolint:gocyclo
+func (p *processor) finishObject(activeProperty string, result map[string]interface{}) (interface{}, error) {
+	if value, ok := result["@value"]; ok {
+		for key := range result {
+			switch key {
+			case "@value", "@language", "@type", "@index", "@direction":
+			default:
+				return nil, invalidf("invalid value object with %s", key)
+			}
+		}
+
+		if _, ok := result["@language"]; ok {
+			if _, ok := result["@type"]; ok {
+				return nil, invalidf("invalid value object with @language and @type")
+			}
+		}
+
+		if result["@type"] == "@json" {
+			return result, nil
+		}
+
+		if value == nil {
+			return nil, nil
+		}
+
+		if _, ok := result["@language"]; ok {
+			if _, ok := value.(string); !ok {
+				return nil, invalidf("invalid language-tagged value")
+			}
+		}
+
+		if typ, ok := result["@type"]; ok {
+			if s, ok := typ.(string); !ok || !isAbsoluteIRI(s) {
+				return nil, invalidf("invalid typed value")
+			}
+		}
+	} else if typ, ok := result["@type"]; ok {
+		result["@type"] = asArray(typ)
+	} else if set, ok := result["@set"]; ok {
+		if len(result) > 2 || (len(result) == 2 && result["@index"] == nil) {
+			return nil, invalidf("invalid set object")
+		}
+
+		return set, nil
+	} else if _, ok := result["@list"]; ok {
+		if len(result) > 2 || (len(result) == 2 && result["@index"] == nil) {
+			return nil, invalidf("invalid list object")
+		}
+	}
+
+	if _, ok := result["@language"]; ok && len(result) == 1 {
+		return nil, nil
+	}
+
+	if activeProperty == "" || activeProperty == "@graph" {
+		_, hasValue := result["@value"]
+		_, hasList := result["@list"]
+		_, hasID := result["@id"]
+
+		if len(result) == 0 || hasValue || hasList {
+			return nil, p.dropped("free-floating value")
+		}
+
+		if len(result) == 1 && hasID {
+			return nil, nil
+		}
+	}
+
+	return result, nil
+}
+
+// expandValue implements the value expansion algorithm.
+func (p *processor) expandValue(active *activeContext, activeProperty string, value interface{}) (interface{}, error) {
+	def := active.term(activeProperty)
+
+	if s, ok := value.(string); ok {
+		switch def.typ {
+		case "@id":
+			id, _ := p.expandIRI(active, s, true, false, nil)
+
+			return map[string]interface{}{"@id": id}, nil
+		case "@vocab":
+			id, _ := p.expandIRI(active, s, true, true, nil)
+
+			return map[string]interface{}{"@id": id}, nil
+		}
+	}
+
+	result := map[string]interface{}{"@value": value}
+
+	switch {
+	case def.typ != "" && def.typ != "@id" && def.typ != "@vocab" && def.typ != "@none":
+		result["@type"] = def.typ
+	case isString(value):
+		language := active.language
+		if def.language != nil {
+			language = *def.language
+		}
+
+		if language != "" {
+			result["@language"] = language
+		}
+	}
+
+	return result, nil
+}
+
+// dropped reports data dropped by the expansion, which is an error in safe mode.
+func (p *processor) dropped(format string, args ...interface{}) error {
+	if p.opts.safeMode {
+		return invalidf("safe mode: dropped "+format, args...)
+	}
+
+	return nil
+}
+
+func addValue(m map[string]interface{}, key string, value interface{}) {
+	m[key] = append(asArray(m[key]), asArray(value)...)
+}
+
+func asArray(v interface{}) []interface{} {
+	switch a := v.(type) {
+	case nil:
+		return []interface{}{}
+	case []interface{}:
+		return a
+	default:
+		return []interface{}{v}
+	}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func isMap(v interface{}) bool {
+	_, ok := v.(map[string]interface{})
+
+	return ok
+}
+
+func isString(v interface{}) bool {
+	_, ok := v.(string)
+
+	return ok
+}
+
+func stringValue(v interface{}) string {
+	s, _ := v.(string)
+
+	return s
+}
+
+func isValueObject(v interface{}) bool {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	_, ok = m["@value"]
+
+	return ok
+}
+
+func isListObject(v interface{}) bool {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	_, ok = m["@list"]
+
+	return ok
+}
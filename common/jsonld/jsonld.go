@@ -0,0 +1,121 @@
+// Package jsonld implements the JSON-LD 1.1 expansion and RDF serialization algorithms, and the URDNA2015
+// RDF dataset canonicalization algorithm used by linked data proofs.
+//
+// Remote contexts are loaded by a DocumentLoader. The default loader serves embedded copies of the DID,
+// verifiable credentials, status list and signature suite contexts only, so documents using them are processed
+// without network access.
+package jsonld
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidDocument is returned when a document is not valid JSON-LD, or when safe mode is enabled and
+// processing would drop data.
+var ErrInvalidDocument = errors.New("invalid JSON-LD document")
+
+type options struct {
+	loader   DocumentLoader
+	base     string
+	safeMode bool
+}
+
+// Option configures JSON-LD processing.
+type Option func(opts *options)
+
+// WithDocumentLoader sets the loader of remote contexts. It defaults to a Loader serving the embedded
+// contexts.
+func WithDocumentLoader(loader DocumentLoader) Option {
+	return func(opts *options) {
+		opts.loader = loader
+	}
+}
+
+// WithBase sets the base IRI of the document.
+func WithBase(base string) Option {
+	return func(opts *options) {
+		opts.base = base
+	}
+}
+
+// WithSafeMode fails processing instead of silently dropping properties, types, values and relative IRIs
+// which have no RDF representation. Signed data must be processed in safe mode, as dropped data is not signed.
+func WithSafeMode() Option {
+	return func(opts *options) {
+		opts.safeMode = true
+	}
+}
+
+func getOptions(opts []Option) *options {
+	o := &options{}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.loader == nil {
+		o.loader = NewDocumentLoader()
+	}
+
+	return o
+}
+
+// Expand expands a JSON-LD document, given as JSON bytes or as a decoded JSON value.
+func Expand(doc interface{}, opts ...Option) ([]interface{}, error) {
+	return expand(doc, getOptions(opts))
+}
+
+// ToRDF converts a JSON-LD document, given as JSON bytes or as a decoded JSON value, to an RDF dataset.
+func ToRDF(doc interface{}, opts ...Option) ([]*Quad, error) {
+	o := getOptions(opts)
+
+	expanded, err := expand(doc, o)
+	if err != nil {
+		return nil, err
+	}
+
+	return toRDF(expanded, o.safeMode)
+}
+
+// Canonicalize converts a JSON-LD document to an RDF dataset and returns the canonical N-Quads of the
+// dataset, as produced by the URDNA2015 algorithm.
+func Canonicalize(doc interface{}, opts ...Option) ([]byte, error) {
+	quads, err := ToRDF(doc, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(SerializeNQuads(CanonicalizeQuads(quads))), nil
+}
+
+func expand(doc interface{}, o *options) ([]interface{}, error) {
+	input, err := decodeInput(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &processor{opts: o}
+
+	return p.expandDocument(input)
+}
+
+func decodeInput(doc interface{}) (interface{}, error) {
+	switch d := doc.(type) {
+	case []byte:
+		v, err := decode(d)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidDocument, err)
+		}
+
+		return v, nil
+	case string:
+		return decodeInput([]byte(d))
+	default:
+		return doc, nil
+	}
+}
+
+func invalidf(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidDocument, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,255 @@
+package jsonld
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const didDocument = `{
+  "@context": ["https://www.w3.org/ns/did/v1", {"@vocab": "https://example.com/vocab#"}],
+  "id": "did:example:123",
+  "verificationMethod": [{
+    "id": "did:example:123#key-1",
+    "type": "Ed25519VerificationKey2018",
+    "controller": "did:example:123",
+    "publicKeyBase58": "H3C2AVvLMv6gmMNam3uVAjZpfkcJCwDwnZn6z3wXmqPV"
+  }],
+  "authentication": ["did:example:123#key-1"],
+  "service": [{
+    "id": "did:example:123#hub",
+    "type": "IdentityHub",
+    "serviceEndpoint": "https://hub.example.com/"
+  }]
+}`
+
+const expectedNQuads = `<did:example:123#hub> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <https://example.com/vocab#IdentityHub> .
+<did:example:123#hub> <https://www.w3.org/ns/did#serviceEndpoint> <https://hub.example.com/> .
+<did:example:123#key-1> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <https://w3id.org/security#Ed25519VerificationKey2018> .
+<did:example:123#key-1> <https://w3id.org/security#controller> <did:example:123> .
+<did:example:123#key-1> <https://w3id.org/security#publicKeyBase58> "H3C2AVvLMv6gmMNam3uVAjZpfkcJCwDwnZn6z3wXmqPV" .
+<did:example:123> <https://w3id.org/security#authenticationMethod> <did:example:123#key-1> .
+<did:example:123> <https://w3id.org/security#verificationMethod> <did:example:123#key-1> .
+<did:example:123> <https://www.w3.org/ns/did#service> <did:example:123#hub> .
+`
+
+func TestExpand(t *testing.T) {
+	t.Run("DID document", func(t *testing.T) {
+		expanded, err := Expand([]byte(didDocument))
+		require.NoError(t, err)
+		require.Len(t, expanded, 1)
+
+		node := expanded[0].(map[string]interface{})
+		require.Equal(t, "did:example:123", node["@id"])
+		require.Equal(t, []interface{}{map[string]interface{}{"@id": "did:example:123#key-1"}},
+			node["https://w3id.org/security#authenticationMethod"])
+	})
+
+	t.Run("terms, prefixes and values", func(t *testing.T) {
+		expanded, err := Expand(`{
+			"@context": {
+				"ex": "http://example.com/",
+				"name": "ex:name",
+				"age": {"@id": "ex:age", "@type": "http://www.w3.org/2001/XMLSchema#integer"},
+				"knows": {"@id": "ex:knows", "@type": "@id"},
+				"tags": {"@id": "ex:tags", "@container": "@list"},
+				"label": {"@id": "ex:label", "@container": "@language"}
+			},
+			"@id": "ex:alice",
+			"@type": "ex:Person",
+			"name": "Alice",
+			"age": "42",
+			"knows": "ex:bob",
+			"tags": ["a", "b"],
+			"label": {"en": "Alice", "FR": "Alice"}
+		}`)
+		require.NoError(t, err)
+
+		require.Equal(t, []interface{}{map[string]interface{}{
+			"@id":                     "http://example.com/alice",
+			"@type":                   []interface{}{"http://example.com/Person"},
+			"http://example.com/name": []interface{}{map[string]interface{}{"@value": "Alice"}},
+			"http://example.com/age": []interface{}{map[string]interface{}{
+				"@value": "42", "@type": "http://www.w3.org/2001/XMLSchema#integer",
+			}},
+			"http://example.com/knows": []interface{}{map[string]interface{}{"@id": "http://example.com/bob"}},
+			"http://example.com/tags": []interface{}{map[string]interface{}{"@list": []interface{}{
+				map[string]interface{}{"@value": "a"}, map[string]interface{}{"@value": "b"},
+			}}},
+			"http://example.com/label": []interface{}{
+				map[string]interface{}{"@value": "Alice", "@language": "fr"},
+				map[string]interface{}{"@value": "Alice", "@language": "en"},
+			},
+		}}, expanded)
+	})
+
+	t.Run("undefined terms are dropped", func(t *testing.T) {
+		doc := `{"@context": {"name": "http://schema.org/name"}, "name": "Alice", "unknown": "value"}`
+
+		expanded, err := Expand(doc)
+		require.NoError(t, err)
+		require.Equal(t, []interface{}{map[string]interface{}{
+			"http://schema.org/name": []interface{}{map[string]interface{}{"@value": "Alice"}},
+		}}, expanded)
+
+		_, err = Expand(doc, WithSafeMode())
+		require.ErrorIs(t, err, ErrInvalidDocument)
+		require.Contains(t, err.Error(), `dropped property "unknown"`)
+	})
+
+	t.Run("protected terms", func(t *testing.T) {
+		_, err := Expand(`{"@context": ["https://www.w3.org/ns/did/v1", {"id": "http://example.com/id"}]}`)
+		require.ErrorIs(t, err, ErrInvalidDocument)
+		require.Contains(t, err.Error(), `protected term redefinition of "id"`)
+
+		_, err = Expand(`{"@context": ["https://www.w3.org/ns/did/v1", null]}`)
+		require.Contains(t, err.Error(), "invalid context nullification")
+	})
+
+	t.Run("scoped contexts", func(t *testing.T) {
+		expanded, err := Expand(`{
+			"@context": {
+				"@vocab": "http://example.com/",
+				"Person": {"@context": {"name": "http://schema.org/name"}},
+				"address": {"@context": {"city": "http://schema.org/city"}}
+			},
+			"@type": "Person",
+			"name": "Alice",
+			"address": {"city": "Paris", "pet": {"name": "Rex"}}
+		}`)
+		require.NoError(t, err)
+
+		node := expanded[0].(map[string]interface{})
+		require.Contains(t, node, "http://schema.org/name")
+
+		address := node["http://example.com/address"].([]interface{})[0].(map[string]interface{})
+		require.Contains(t, address, "http://schema.org/city")
+
+		// type-scoped contexts do not propagate to nested nodes.
+		pet := address["http://example.com/pet"].([]interface{})[0].(map[string]interface{})
+		require.Contains(t, pet, "http://example.com/name")
+	})
+
+	t.Run("property-scoped null context", func(t *testing.T) {
+		expanded, err := Expand(`{
+			"@context": {
+				"@protected": true,
+				"name": "http://schema.org/name",
+				"embedded": {"@id": "http://example.com/embedded", "@context": null}
+			},
+			"embedded": {"@context": {"name": "http://example.com/name"}, "name": "Rex"}
+		}`)
+		require.NoError(t, err)
+
+		embedded := expanded[0].(map[string]interface{})["http://example.com/embedded"].([]interface{})
+		require.Contains(t, embedded[0], "http://example.com/name")
+	})
+
+	t.Run("base IRI", func(t *testing.T) {
+		doc := `{"@context": {"@vocab": "http://example.com/"}, "@id": "#node", "p": "v"}`
+
+		expanded, err := Expand(doc, WithBase("did:example:123"))
+		require.NoError(t, err)
+		require.Equal(t, "did:example:123#node", expanded[0].(map[string]interface{})["@id"])
+
+		quads, err := ToRDF(doc)
+		require.NoError(t, err)
+		require.Empty(t, quads)
+
+		_, err = ToRDF(doc, WithSafeMode())
+		require.ErrorIs(t, err, ErrInvalidDocument)
+		require.Contains(t, err.Error(), `dropped relative IRI "#node"`)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		for _, doc := range []string{
+			`{`,
+			`{"@context": "https://example.com/unknown"}`,
+			`{"@context": {"@vocab": 1}}`,
+			`{"@context": {"a": {"@id": "a"}, "@vocab": null}, "a": 1}`,
+			`{"@context": {"a": "b:c", "b": "a:"}, "a": 1}`,
+			`{"@context": {"@id": "http://example.com/"}}`,
+			`{"@context": {"a": {"@id": "http://example.com/", "@container": "@unknown"}}}`,
+			`{"@id": 1}`,
+			`{"@context": {"@vocab": "http://example.com/"}, "p": {"@value": "v", "@id": "http://example.com/"}}`,
+		} {
+			_, err := Expand(doc)
+			require.Error(t, err, doc)
+		}
+	})
+}
+
+func TestToRDF(t *testing.T) {
+	quads, err := ToRDF(`{
+		"@context": {
+			"@vocab": "http://example.com/",
+			"list": {"@container": "@list"},
+			"data": {"@type": "@json"},
+			"graph": {"@container": "@graph"}
+		},
+		"@id": "http://example.com/s",
+		"bool": true,
+		"int": 5,
+		"double": 1.5,
+		"large": 1e21,
+		"list": [1, "two"],
+		"empty": {"@list": []},
+		"data": {"b": [1, 2], "a": "<x>"},
+		"relative": {"@id": "relative"},
+		"graph": {"@id": "http://example.com/o", "p": "in graph"}
+	}`)
+	require.NoError(t, err)
+
+	nquads := SerializeNQuads(CanonicalizeQuads(quads))
+
+	for _, expected := range []string{
+		`<http://example.com/s> <http://example.com/bool> "true"^^<http://www.w3.org/2001/XMLSchema#boolean> .`,
+		`<http://example.com/s> <http://example.com/int> "5"^^<http://www.w3.org/2001/XMLSchema#integer> .`,
+		`<http://example.com/s> <http://example.com/double> "1.5E0"^^<http://www.w3.org/2001/XMLSchema#double> .`,
+		`<http://example.com/s> <http://example.com/large> "1.0E21"^^<http://www.w3.org/2001/XMLSchema#double> .`,
+		`<http://example.com/s> <http://example.com/empty> <http://www.w3.org/1999/02/22-rdf-syntax-ns#nil> .`,
+		`<http://example.com/s> <http://example.com/data> "{\"a\":\"<x>\",\"b\":[1,2]}"^^<http://www.w3.org/1999/02/22-rdf-syntax-ns#JSON> .`,
+		`_:c14n1 <http://www.w3.org/1999/02/22-rdf-syntax-ns#first> "two" .`,
+		`<http://example.com/o> <http://example.com/p> "in graph" _:c14n2 .`,
+	} {
+		require.Contains(t, nquads, expected+"\n")
+	}
+
+	require.NotContains(t, nquads, "relative")
+	require.Len(t, strings.Split(strings.TrimSpace(nquads), "\n"), 13)
+}
+
+func TestCanonicalize(t *testing.T) {
+	canonical, err := Canonicalize([]byte(didDocument), WithSafeMode())
+	require.NoError(t, err)
+	require.Equal(t, expectedNQuads, string(canonical))
+
+	// member order and blank node labels do not change the canonical form.
+	reordered := `{
+	  "service": [{"serviceEndpoint": "https://hub.example.com/", "type": "IdentityHub", "id": "did:example:123#hub"}],
+	  "authentication": ["did:example:123#key-1"],
+	  "verificationMethod": [{
+	    "publicKeyBase58": "H3C2AVvLMv6gmMNam3uVAjZpfkcJCwDwnZn6z3wXmqPV",
+	    "controller": "did:example:123",
+	    "type": "Ed25519VerificationKey2018",
+	    "id": "did:example:123#key-1"
+	  }],
+	  "id": "did:example:123",
+	  "@context": ["https://www.w3.org/ns/did/v1", {"@vocab": "https://example.com/vocab#"}]
+	}`
+
+	canonical, err = Canonicalize(reordered)
+	require.NoError(t, err)
+	require.Equal(t, expectedNQuads, string(canonical))
+
+	first, err := Canonicalize(`{"@context": {"@vocab": "http://example.com/"}, "@id": "_:x", "p": {"@id": "_:y", "q": "v"}}`)
+	require.NoError(t, err)
+
+	second, err := Canonicalize(`{"@context": {"@vocab": "http://example.com/"}, "p": {"q": "v"}}`)
+	require.NoError(t, err)
+	require.Equal(t, string(first), string(second))
+
+	_, err = Canonicalize(`{"@context": "https://example.com/unknown"}`)
+	require.ErrorIs(t, err, ErrContextNotFound)
+}
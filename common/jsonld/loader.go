@@ -0,0 +1,235 @@
+package jsonld
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/zRich/zFusion/storage/spi"
+)
+
+// URLs of the embedded contexts.
+const (
+	ContextDIDV1                = "https://www.w3.org/ns/did/v1"
+	ContextDIDV1Legacy          = "https://w3id.org/did/v1"
+	ContextDIDV011              = "https://w3id.org/did/v0.11"
+	ContextDIDV12019            = "https://www.w3.org/2019/did/v1"
+	ContextCredentialsV1        = "https://www.w3.org/2018/credentials/v1"
+	ContextCredentialsV2        = "https://www.w3.org/ns/credentials/v2"
+	ContextEd25519Signature2018 = "https://w3id.org/security/suites/ed25519-2018/v1"
+	ContextEd25519Signature2020 = "https://w3id.org/security/suites/ed25519-2020/v1"
+	ContextSecp256k1Signature   = "https://w3id.org/security/suites/secp256k1-2019/v1"
+	ContextJSONWebSignature2020 = "https://w3id.org/security/suites/jws-2020/v1"
+	ContextStatusList2021       = "https://w3id.org/vc/status-list/2021/v1"
+	ContextMultikey             = "https://w3id.org/security/multikey/v1"
+)
+
+// ErrContextNotFound is returned when a document loader has no copy of a context and may not fetch it.
+var ErrContextNotFound = errors.New("JSON-LD context not found")
+
+//go:embed contexts/*.jsonld
+var embeddedFS embed.FS
+
+// embeddedContexts maps context URLs to their embedded copy.
+var embeddedContexts = map[string]string{ //nolint:gochecknoglobals
+	ContextDIDV1:       "contexts/did-v1.jsonld",
+	ContextDIDV1Legacy: "contexts/did-v1.jsonld",
+	ContextDIDV011:     "contexts/did-v0.11.jsonld",
+	ContextDIDV12019:   "contexts/did-2019.jsonld",
+
+	ContextCredentialsV1:        "contexts/credentials-v1.jsonld",
+	ContextCredentialsV2:        "contexts/credentials-v2.jsonld",
+	ContextEd25519Signature2018: "contexts/suites-ed25519-2018.jsonld",
+	ContextEd25519Signature2020: "contexts/suites-ed25519-2020.jsonld",
+	ContextSecp256k1Signature:   "contexts/suites-secp256k1-2019.jsonld",
+	ContextJSONWebSignature2020: "contexts/suites-jws-2020.jsonld",
+	ContextStatusList2021:       "contexts/vc-status-list-2021.jsonld",
+	ContextMultikey:             "contexts/multikey-v1.jsonld",
+}
+
+// RemoteDocument is a document retrieved by a DocumentLoader.
+type RemoteDocument struct {
+	DocumentURL string
+	Document    interface{}
+}
+
+// DocumentLoader loads the remote contexts referenced by JSON-LD documents.
+type DocumentLoader interface {
+	LoadDocument(url string) (*RemoteDocument, error)
+}
+
+// Loader is a DocumentLoader serving embedded contexts, contexts added with WithContext and contexts cached
+// in a store. Other contexts are fetched by the remote loader if one is set, and cached in the store.
+type Loader struct {
+	contexts map[string][]byte
+	store    spi.Store
+	remote   DocumentLoader
+}
+
+// LoaderOption configures a Loader.
+type LoaderOption func(l *Loader)
+
+// WithContext serves the given content for a context URL, overriding any embedded copy.
+func WithContext(url string, content []byte) LoaderOption {
+	return func(l *Loader) {
+		l.contexts[url] = content
+	}
+}
+
+// WithStore caches contexts in store. Contexts already in the store are served without calling the remote
+// loader.
+func WithStore(store spi.Store) LoaderOption {
+	return func(l *Loader) {
+		l.store = store
+	}
+}
+
+// WithRemoteLoader sets the loader of contexts which are neither embedded nor cached.
+func WithRemoteLoader(remote DocumentLoader) LoaderOption {
+	return func(l *Loader) {
+		l.remote = remote
+	}
+}
+
+// NewDocumentLoader creates a Loader. Without options it serves the embedded contexts only and never
+// accesses the network.
+func NewDocumentLoader(opts ...LoaderOption) *Loader {
+	l := &Loader{contexts: map[string][]byte{}}
+
+	for name, file := range embeddedContexts {
+		content, err := embeddedFS.ReadFile(file)
+		if err != nil {
+			panic(err)
+		}
+
+		l.contexts[name] = content
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
+}
+
+// LoadDocument loads the context at url.
+func (l *Loader) LoadDocument(url string) (*RemoteDocument, error) {
+	if content, ok := l.contexts[url]; ok {
+		return newRemoteDocument(url, content)
+	}
+
+	if l.store != nil {
+		content, err := l.store.Get(url)
+		if err == nil {
+			return newRemoteDocument(url, content)
+		}
+
+		if !errors.Is(err, spi.ErrDataNotFound) {
+			return nil, fmt.Errorf("get cached context %s: %w", url, err)
+		}
+	}
+
+	if l.remote == nil {
+		return nil, fmt.Errorf("%w: %s", ErrContextNotFound, url)
+	}
+
+	doc, err := l.remote.LoadDocument(url)
+	if err != nil {
+		return nil, err
+	}
+
+	if l.store != nil {
+		content, err := json.Marshal(doc.Document)
+		if err != nil {
+			return nil, fmt.Errorf("marshal context %s: %w", url, err)
+		}
+
+		if err := l.store.Put(url, content); err != nil {
+			return nil, fmt.Errorf("cache context %s: %w", url, err)
+		}
+	}
+
+	return doc, nil
+}
+
+// HTTPLoader is a DocumentLoader fetching documents over HTTP(S).
+type HTTPLoader struct {
+	client *http.Client
+}
+
+// NewHTTPLoader creates an HTTPLoader using client, or http.DefaultClient if client is nil.
+func NewHTTPLoader(client *http.Client) *HTTPLoader {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &HTTPLoader{client: client}
+}
+
+// LoadDocument fetches the document at url.
+func (l *HTTPLoader) LoadDocument(url string) (*RemoteDocument, error) {
+	if !strings.HasPrefix(url, "https://") && !strings.HasPrefix(url, "http://") {
+		return nil, fmt.Errorf("%w: unsupported URL %s", ErrContextNotFound, url)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request for %s: %w", url, err)
+	}
+
+	req.Header.Set("Accept", "application/ld+json, application/json")
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch %s: %w", url, err)
+	}
+
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrContextNotFound, url)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch %s: unexpected status %s", url, resp.Status)
+	}
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", url, err)
+	}
+
+	return newRemoteDocument(url, content)
+}
+
+func newRemoteDocument(url string, content []byte) (*RemoteDocument, error) {
+	doc, err := decode(content)
+	if err != nil {
+		return nil, fmt.Errorf("parse context %s: %w", url, err)
+	}
+
+	return &RemoteDocument{DocumentURL: url, Document: doc}, nil
+}
+
+// decode decodes JSON keeping numbers as json.Number.
+func decode(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v interface{}
+
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	if dec.More() {
+		return nil, errors.New("unexpected data after JSON value")
+	}
+
+	return v, nil
+}
@@ -0,0 +1,118 @@
+package jsonld
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zRich/zFusion/storage/leveldb"
+)
+
+type countingLoader struct {
+	loader DocumentLoader
+	calls  int
+}
+
+func (l *countingLoader) LoadDocument(url string) (*RemoteDocument, error) {
+	l.calls++
+
+	return l.loader.LoadDocument(url)
+}
+
+func TestLoader_Embedded(t *testing.T) {
+	loader := NewDocumentLoader()
+
+	for url := range embeddedContexts {
+		doc, err := loader.LoadDocument(url)
+		require.NoError(t, err, url)
+		require.Equal(t, url, doc.DocumentURL)
+		require.Contains(t, doc.Document, "@context")
+	}
+
+	_, err := loader.LoadDocument("https://example.com/context")
+	require.ErrorIs(t, err, ErrContextNotFound)
+}
+
+func TestLoader_WithContext(t *testing.T) {
+	loader := NewDocumentLoader(WithContext("https://example.com/context", []byte(`{"@context":{"a":"urn:a"}}`)))
+
+	doc, err := loader.LoadDocument("https://example.com/context")
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"@context": map[string]interface{}{"a": "urn:a"}}, doc.Document)
+
+	loader = NewDocumentLoader(WithContext("https://example.com/context", []byte(`{`)))
+
+	_, err = loader.LoadDocument("https://example.com/context")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "parse context https://example.com/context")
+}
+
+func TestLoader_Cache(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/context" {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		_, err := w.Write([]byte(`{"@context":{"name":"http://schema.org/name"}}`))
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	p := leveldb.NewProvider(filepath.Join(t.TempDir(), "db"))
+	t.Cleanup(func() { require.NoError(t, p.Close()) })
+
+	store, err := p.OpenStore("jsonld")
+	require.NoError(t, err)
+
+	remote := &countingLoader{loader: NewHTTPLoader(server.Client())}
+	loader := NewDocumentLoader(WithStore(store), WithRemoteLoader(remote))
+
+	for i := 0; i < 2; i++ {
+		doc, err := loader.LoadDocument(server.URL + "/context")
+		require.NoError(t, err)
+		require.Contains(t, doc.Document, "@context")
+	}
+
+	require.Equal(t, 1, remote.calls)
+
+	// the cached context is served without a remote loader.
+	offline := NewDocumentLoader(WithStore(store))
+
+	_, err = offline.LoadDocument(server.URL + "/context")
+	require.NoError(t, err)
+
+	_, err = loader.LoadDocument(server.URL + "/missing")
+	require.ErrorIs(t, err, ErrContextNotFound)
+	require.Equal(t, 2, remote.calls)
+}
+
+func TestHTTPLoader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/invalid":
+			_, err := w.Write([]byte("not JSON"))
+			require.NoError(t, err)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	loader := NewHTTPLoader(nil)
+
+	_, err := loader.LoadDocument(server.URL + "/invalid")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "parse context")
+
+	_, err = loader.LoadDocument(server.URL + "/error")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unexpected status 500")
+
+	_, err = loader.LoadDocument("did:example:123")
+	require.True(t, errors.Is(err, ErrContextNotFound))
+}
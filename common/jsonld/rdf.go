@@ -0,0 +1,579 @@
+package jsonld
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// IRIs of the RDF vocabulary and XML Schema datatypes used by the RDF serialization.
+const (
+	RDFType       = "http://www.w3.org/1999/02/22-rdf-syntax-ns#type"
+	RDFFirst      = "http://www.w3.org/1999/02/22-rdf-syntax-ns#first"
+	RDFRest       = "http://www.w3.org/1999/02/22-rdf-syntax-ns#rest"
+	RDFNil        = "http://www.w3.org/1999/02/22-rdf-syntax-ns#nil"
+	RDFLangString = "http://www.w3.org/1999/02/22-rdf-syntax-ns#langString"
+	RDFJSON       = "http://www.w3.org/1999/02/22-rdf-syntax-ns#JSON"
+	XSDString     = "http://www.w3.org/2001/XMLSchema#string"
+	XSDBoolean    = "http://www.w3.org/2001/XMLSchema#boolean"
+	XSDInteger    = "http://www.w3.org/2001/XMLSchema#integer"
+	XSDDouble     = "http://www.w3.org/2001/XMLSchema#double"
+)
+
+// TermKind is the kind of an RDF term.
+type TermKind int
+
+// Kinds of RDF terms. The zero value is the default graph, used as graph name of the quads of the default
+// graph.
+const (
+	DefaultGraph TermKind = iota
+	IRI
+	BlankNode
+	Literal
+)
+
+// Term is an RDF term. The Value of blank nodes includes the "_:" prefix.
+type Term struct {
+	Kind     TermKind
+	Value    string
+	Datatype string
+	Language string
+}
+
+// Quad is an RDF quad.
+type Quad struct {
+	Subject   Term
+	Predicate Term
+	Object    Term
+	Graph     Term
+}
+
+// toRDF implements the deserialize JSON-LD to RDF algorithm over an expanded document. Blank node labels of
+// the document are replaced, and duplicate quads are removed.
+func toRDF(expanded []interface{}, safeMode bool) ([]*Quad, error) {
+	c := &rdfConverter{issuer: newIssuer("_:b"), seen: map[string]bool{}, safeMode: safeMode}
+
+	for _, item := range expanded {
+		node, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, invalidf("invalid top-level node")
+		}
+
+		if _, err := c.node(node, Term{}); err != nil {
+			return nil, err
+		}
+	}
+
+	return c.quads, nil
+}
+
+type rdfConverter struct {
+	issuer   *issuer
+	quads    []*Quad
+	seen     map[string]bool
+	safeMode bool
+}
+
+func (c *rdfConverter) add(subject, predicate, object, graph Term) {
+	q := &Quad{Subject: subject, Predicate: predicate, Object: object, Graph: graph}
+
+	line := q.String()
+	if c.seen[line] {
+		return
+	}
+
+	c.seen[line] = true
+	c.quads = append(c.quads, q)
+}
+
+// subject returns the term of a node identifier. It returns false for relative IRIs, which have no RDF
+// representation and are dropped, unless in safe mode.
+func (c *rdfConverter) subject(id interface{}) (Term, bool, error) {
+	s, ok := id.(string)
+	if !ok {
+		return Term{Kind: BlankNode, Value: c.issuer.issue("")}, true, nil
+	}
+
+	if isBlankNode(s) {
+		return Term{Kind: BlankNode, Value: c.issuer.issue(s)}, true, nil
+	}
+
+	if isAbsoluteIRI(s) {
+		return Term{Kind: IRI, Value: s}, true, nil
+	}
+
+	if c.safeMode {
+		return Term{}, false, invalidf("safe mode: dropped relative IRI %q", s)
+	}
+
+	return Term{}, false, nil
+}
+
+// node converts a node object of graph and returns its subject.
+//
+//nolint:gocyclo
+func (c *rdfConverter) node(node map[string]interface{}, graph Term) (*Term, error) {
+	subject, ok, err := c.subject(node["@id"])
+	if err != nil {
+		return nil, err
+	}
+
+	if graphItems, hasGraph := node["@graph"]; hasGraph && ok {
+		for _, item := range asArray(graphItems) {
+			n, isNode := item.(map[string]interface{})
+			if !isNode {
+				return nil, invalidf("invalid @graph value")
+			}
+
+			if _, err := c.node(n, subject); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, item := range asArray(node["@included"]) {
+		if n, isNode := item.(map[string]interface{}); isNode {
+			if _, err := c.node(n, graph); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if !ok {
+		return nil, nil
+	}
+
+	for _, t := range asArray(node["@type"]) {
+		typ, isString := t.(string)
+		if !isString {
+			return nil, invalidf("invalid @type value")
+		}
+
+		object, ok, err := c.subject(typ)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			c.add(subject, Term{Kind: IRI, Value: RDFType}, object, graph)
+		}
+	}
+
+	if reverse, ok := node["@reverse"].(map[string]interface{}); ok {
+		for _, property := range sortedKeys(reverse) {
+			for _, item := range asArray(reverse[property]) {
+				n, isNode := item.(map[string]interface{})
+				if !isNode {
+					return nil, invalidf("invalid reverse property value")
+				}
+
+				object, err := c.node(n, graph)
+				if err != nil {
+					return nil, err
+				}
+
+				if object != nil && isAbsoluteIRI(property) {
+					c.add(*object, Term{Kind: IRI, Value: property}, subject, graph)
+				}
+			}
+		}
+	}
+
+	for _, property := range sortedKeys(node) {
+		if keywords[property] {
+			continue
+		}
+
+		for _, item := range asArray(node[property]) {
+			object, err := c.object(item, graph)
+			if err != nil {
+				return nil, err
+			}
+
+			// blank node properties would make a generalized RDF dataset.
+			if object != nil && isAbsoluteIRI(property) && !isBlankNode(property) {
+				c.add(subject, Term{Kind: IRI, Value: property}, *object, graph)
+			}
+		}
+	}
+
+	return &subject, nil
+}
+
+// object converts a value, list or node object of graph and returns its RDF term.
+func (c *rdfConverter) object(item interface{}, graph Term) (*Term, error) {
+	m, ok := item.(map[string]interface{})
+	if !ok {
+		return nil, invalidf("invalid property value")
+	}
+
+	if _, ok := m["@value"]; ok {
+		return literal(m)
+	}
+
+	if list, ok := m["@list"]; ok {
+		return c.list(asArray(list), graph)
+	}
+
+	return c.node(m, graph)
+}
+
+// list converts a list to a chain of rdf:first and rdf:rest statements and returns its head.
+func (c *rdfConverter) list(items []interface{}, graph Term) (*Term, error) {
+	if len(items) == 0 {
+		return &Term{Kind: IRI, Value: RDFNil}, nil
+	}
+
+	nodes := make([]Term, len(items))
+	for i := range items {
+		nodes[i] = Term{Kind: BlankNode, Value: c.issuer.issue("")}
+	}
+
+	for i, item := range items {
+		object, err := c.object(item, graph)
+		if err != nil {
+			return nil, err
+		}
+
+		if object != nil {
+			c.add(nodes[i], Term{Kind: IRI, Value: RDFFirst}, *object, graph)
+		}
+
+		rest := Term{Kind: IRI, Value: RDFNil}
+		if i+1 < len(items) {
+			rest = nodes[i+1]
+		}
+
+		c.add(nodes[i], Term{Kind: IRI, Value: RDFRest}, rest, graph)
+	}
+
+	return &nodes[0], nil
+}
+
+// literal converts a value object to an RDF literal.
+//
+//nolint:gocyclo
+func literal(v map[string]interface{}) (*Term, error) {
+	value := v["@value"]
+	datatype, _ := v["@type"].(string)
+	language, _ := v["@language"].(string)
+
+	if datatype == "@json" {
+		canonical, err := canonicalJSON(value)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Term{Kind: Literal, Value: canonical, Datatype: RDFJSON}, nil
+	}
+
+	if datatype != "" && !isAbsoluteIRI(datatype) {
+		return nil, nil
+	}
+
+	term := &Term{Kind: Literal, Datatype: datatype}
+
+	switch val := value.(type) {
+	case bool:
+		term.Value = strconv.FormatBool(val)
+		if datatype == "" {
+			term.Datatype = XSDBoolean
+		}
+	case json.Number, float64:
+		lexical, isInteger, err := numberLexical(val, datatype)
+		if err != nil {
+			return nil, err
+		}
+
+		term.Value = lexical
+
+		if datatype == "" {
+			term.Datatype = XSDDouble
+			if isInteger {
+				term.Datatype = XSDInteger
+			}
+		}
+	case string:
+		term.Value = val
+
+		switch {
+		case language != "":
+			term.Datatype, term.Language = RDFLangString, language
+		case datatype == "":
+			term.Datatype = XSDString
+		}
+	default:
+		return nil, invalidf("invalid value %v", value)
+	}
+
+	return term, nil
+}
+
+// numberLexical returns the canonical lexical form of a number, as an xsd:integer when it has no fractional
+// part, otherwise as an xsd:double.
+func numberLexical(value interface{}, datatype string) (string, bool, error) {
+	var (
+		f   float64
+		err error
+	)
+
+	switch n := value.(type) {
+	case json.Number:
+		f, err = strconv.ParseFloat(n.String(), 64)
+		if err != nil {
+			return "", false, invalidf("invalid number %s", n)
+		}
+	case float64:
+		f = n
+	}
+
+	if f == math.Trunc(f) && math.Abs(f) < 1e21 && datatype != XSDDouble {
+		if n, ok := value.(json.Number); ok && !strings.ContainsAny(n.String(), ".eE") {
+			return strings.TrimPrefix(n.String(), "+"), true, nil
+		}
+
+		return strconv.FormatFloat(f, 'f', -1, 64), true, nil
+	}
+
+	return canonicalDouble(f), false, nil
+}
+
+// canonicalDouble returns the canonical xsd:double form of f, such as 1.1E0.
+func canonicalDouble(f float64) string {
+	s := strconv.FormatFloat(f, 'E', -1, 64)
+
+	mantissa, exponent := s, "0"
+	if i := strings.Index(s, "E"); i >= 0 {
+		mantissa, exponent = s[:i], s[i+1:]
+	}
+
+	if !strings.Contains(mantissa, ".") {
+		mantissa += ".0"
+	}
+
+	exp, err := strconv.Atoi(exponent)
+	if err != nil {
+		return s
+	}
+
+	return fmt.Sprintf("%sE%d", mantissa, exp)
+}
+
+// canonicalJSON serializes a JSON value with sorted object members, as required for rdf:JSON literals.
+func canonicalJSON(value interface{}) (string, error) {
+	var buf bytes.Buffer
+
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+
+	if err := enc.Encode(value); err != nil {
+		return "", invalidf("invalid JSON literal: %v", err)
+	}
+
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// String returns the N-Quads statement of the quad, terminated by a newline.
+func (q *Quad) String() string {
+	var sb strings.Builder
+
+	writeTerm(&sb, q.Subject)
+	sb.WriteByte(' ')
+	writeTerm(&sb, q.Predicate)
+	sb.WriteByte(' ')
+	writeTerm(&sb, q.Object)
+
+	if q.Graph.Kind != DefaultGraph {
+		sb.WriteByte(' ')
+		writeTerm(&sb, q.Graph)
+	}
+
+	sb.WriteString(" .\n")
+
+	return sb.String()
+}
+
+func writeTerm(sb *strings.Builder, t Term) {
+	switch t.Kind {
+	case IRI:
+		sb.WriteString("<" + t.Value + ">")
+	case BlankNode:
+		sb.WriteString(t.Value)
+	case Literal:
+		sb.WriteString(`"` + escapeLiteral(t.Value) + `"`)
+
+		if t.Language != "" {
+			sb.WriteString("@" + t.Language)
+		} else if t.Datatype != "" && t.Datatype != XSDString {
+			sb.WriteString("^^<" + t.Datatype + ">")
+		}
+	case DefaultGraph:
+	}
+}
+
+var literalEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`) //nolint:gochecknoglobals
+
+func escapeLiteral(s string) string {
+	return literalEscaper.Replace(s)
+}
+
+// SerializeNQuads serializes quads to N-Quads, one statement per line, in the order of the quads.
+func SerializeNQuads(quads []*Quad) string {
+	var sb strings.Builder
+
+	for _, q := range quads {
+		sb.WriteString(q.String())
+	}
+
+	return sb.String()
+}
+
+// ParseNQuads parses N-Quads with one statement per line. Empty lines and comment lines are ignored.
+func ParseNQuads(nquads string) ([]*Quad, error) {
+	var quads []*Quad
+
+	for n, line := range strings.Split(nquads, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		q, err := parseQuad(line)
+		if err != nil {
+			return nil, fmt.Errorf("parse N-Quads line %d: %w", n+1, err)
+		}
+
+		quads = append(quads, q)
+	}
+
+	return quads, nil
+}
+
+func parseQuad(line string) (*Quad, error) {
+	var terms []Term
+
+	rest := line
+
+	for {
+		rest = strings.TrimLeft(rest, " \t")
+
+		if rest == "." {
+			break
+		}
+
+		if rest == "" || len(terms) == 4 { //nolint:gomnd
+			return nil, fmt.Errorf("%w: invalid statement", ErrInvalidDocument)
+		}
+
+		t, remaining, err := parseTerm(rest)
+		if err != nil {
+			return nil, err
+		}
+
+		terms = append(terms, t)
+		rest = remaining
+	}
+
+	if len(terms) < 3 { //nolint:gomnd
+		return nil, fmt.Errorf("%w: invalid statement", ErrInvalidDocument)
+	}
+
+	q := &Quad{Subject: terms[0], Predicate: terms[1], Object: terms[2]}
+	if len(terms) == 4 { //nolint:gomnd
+		q.Graph = terms[3]
+	}
+
+	if q.Subject.Kind == Literal || q.Predicate.Kind != IRI || q.Graph.Kind == Literal {
+		return nil, fmt.Errorf("%w: invalid statement", ErrInvalidDocument)
+	}
+
+	return q, nil
+}
+
+//nolint:gocyclo
+func parseTerm(s string) (Term, string, error) {
+	switch {
+	case strings.HasPrefix(s, "<"):
+		end := strings.Index(s, ">")
+		if end < 0 {
+			return Term{}, "", fmt.Errorf("%w: unterminated IRI", ErrInvalidDocument)
+		}
+
+		return Term{Kind: IRI, Value: s[1:end]}, s[end+1:], nil
+	case strings.HasPrefix(s, "_:"):
+		end := strings.IndexAny(s, " \t")
+		if end < 0 {
+			return Term{}, "", fmt.Errorf("%w: invalid blank node", ErrInvalidDocument)
+		}
+
+		return Term{Kind: BlankNode, Value: s[:end]}, s[end:], nil
+	case strings.HasPrefix(s, `"`):
+		var value strings.Builder
+
+		i := 1
+		for ; i < len(s) && s[i] != '"'; i++ {
+			if s[i] != '\\' {
+				value.WriteByte(s[i])
+
+				continue
+			}
+
+			i++
+			if i == len(s) {
+				break
+			}
+
+			switch s[i] {
+			case 'n':
+				value.WriteByte('\n')
+			case 'r':
+				value.WriteByte('\r')
+			case 't':
+				value.WriteByte('\t')
+			case '"', '\\':
+				value.WriteByte(s[i])
+			default:
+				return Term{}, "", fmt.Errorf("%w: invalid escape sequence", ErrInvalidDocument)
+			}
+		}
+
+		if i >= len(s) {
+			return Term{}, "", fmt.Errorf("%w: unterminated literal", ErrInvalidDocument)
+		}
+
+		t := Term{Kind: Literal, Value: value.String(), Datatype: XSDString}
+		rest := s[i+1:]
+
+		switch {
+		case strings.HasPrefix(rest, "@"):
+			end := strings.IndexAny(rest, " \t")
+			if end < 0 {
+				return Term{}, "", fmt.Errorf("%w: invalid language tag", ErrInvalidDocument)
+			}
+
+			t.Language, t.Datatype, rest = rest[1:end], RDFLangString, rest[end:]
+		case strings.HasPrefix(rest, "^^<"):
+			end := strings.Index(rest, ">")
+			if end < 0 {
+				return Term{}, "", fmt.Errorf("%w: unterminated IRI", ErrInvalidDocument)
+			}
+
+			t.Datatype, rest = rest[3:end], rest[end+1:]
+		}
+
+		return t, rest, nil
+	default:
+		return Term{}, "", fmt.Errorf("%w: invalid term", ErrInvalidDocument)
+	}
+}
+
+// sortQuads sorts quads by their N-Quads serialization.
+func sortQuads(quads []*Quad) {
+	sort.Slice(quads, func(i, j int) bool {
+		return quads[i].String() < quads[j].String()
+	})
+}
@@ -0,0 +1,389 @@
+package jsonld
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// issuer issues sequential blank node identifiers, remembering the order in which existing identifiers were
+// relabeled.
+type issuer struct {
+	prefix  string
+	counter int
+	issued  map[string]string
+	order   []string
+}
+
+func newIssuer(prefix string) *issuer {
+	return &issuer{prefix: prefix, issued: map[string]string{}}
+}
+
+// issue returns the identifier issued for existing, issuing a new one if needed. An empty existing identifier
+// always gets a new identifier.
+func (i *issuer) issue(existing string) string {
+	if id, ok := i.issued[existing]; ok && existing != "" {
+		return id
+	}
+
+	id := i.prefix + strconv.Itoa(i.counter)
+	i.counter++
+
+	if existing != "" {
+		i.issued[existing] = id
+		i.order = append(i.order, existing)
+	}
+
+	return id
+}
+
+func (i *issuer) has(existing string) bool {
+	_, ok := i.issued[existing]
+
+	return ok
+}
+
+func (i *issuer) clone() *issuer {
+	c := &issuer{prefix: i.prefix, counter: i.counter, issued: make(map[string]string, len(i.issued))}
+	c.order = append(c.order, i.order...)
+
+	for k, v := range i.issued {
+		c.issued[k] = v
+	}
+
+	return c
+}
+
+// CanonicalizeQuads relabels the blank nodes of a dataset with the URDNA2015 algorithm and returns the quads
+// sorted in canonical order.
+func CanonicalizeQuads(quads []*Quad) []*Quad {
+	c := &canonicalizer{
+		blankQuads:      map[string][]*Quad{},
+		canonicalIssuer: newIssuer("_:c14n"),
+	}
+
+	return c.canonicalize(quads)
+}
+
+type canonicalizer struct {
+	blankQuads      map[string][]*Quad
+	canonicalIssuer *issuer
+}
+
+func (c *canonicalizer) canonicalize(quads []*Quad) []*Quad {
+	for _, q := range quads {
+		for _, t := range []Term{q.Subject, q.Object, q.Graph} {
+			if t.Kind == BlankNode {
+				c.addBlankQuad(t.Value, q)
+			}
+		}
+	}
+
+	nonNormalized := make(map[string]bool, len(c.blankQuads))
+	for id := range c.blankQuads {
+		nonNormalized[id] = true
+	}
+
+	var hashToBlank map[string][]string
+
+	for simple := true; simple; {
+		simple = false
+		hashToBlank = map[string][]string{}
+
+		for _, id := range sortedSet(nonNormalized) {
+			hash := c.hashFirstDegree(id)
+			hashToBlank[hash] = append(hashToBlank[hash], id)
+		}
+
+		for _, hash := range sortedHashes(hashToBlank) {
+			ids := hashToBlank[hash]
+			if len(ids) > 1 {
+				continue
+			}
+
+			c.canonicalIssuer.issue(ids[0])
+			delete(nonNormalized, ids[0])
+			delete(hashToBlank, hash)
+
+			simple = true
+		}
+	}
+
+	for _, hash := range sortedHashes(hashToBlank) {
+		var results []nDegreeResult
+
+		for _, id := range hashToBlank[hash] {
+			if c.canonicalIssuer.has(id) {
+				continue
+			}
+
+			temp := newIssuer("_:b")
+			temp.issue(id)
+
+			results = append(results, c.hashNDegree(id, temp))
+		}
+
+		sort.SliceStable(results, func(i, j int) bool { return results[i].hash < results[j].hash })
+
+		for _, result := range results {
+			for _, existing := range result.issuer.order {
+				c.canonicalIssuer.issue(existing)
+			}
+		}
+	}
+
+	canonical := make([]*Quad, 0, len(quads))
+
+	for _, q := range quads {
+		canonical = append(canonical, &Quad{
+			Subject:   c.relabel(q.Subject),
+			Predicate: q.Predicate,
+			Object:    c.relabel(q.Object),
+			Graph:     c.relabel(q.Graph),
+		})
+	}
+
+	sortQuads(canonical)
+
+	return dedupe(canonical)
+}
+
+func (c *canonicalizer) addBlankQuad(id string, q *Quad) {
+	for _, existing := range c.blankQuads[id] {
+		if existing == q {
+			return
+		}
+	}
+
+	c.blankQuads[id] = append(c.blankQuads[id], q)
+}
+
+func (c *canonicalizer) relabel(t Term) Term {
+	if t.Kind == BlankNode {
+		t.Value = c.canonicalIssuer.issue(t.Value)
+	}
+
+	return t
+}
+
+// hashFirstDegree hashes the quads mentioning a blank node, with the blank node labeled _:a and the other
+// blank nodes labeled _:z.
+func (c *canonicalizer) hashFirstDegree(id string) string {
+	label := func(t Term) Term {
+		if t.Kind == BlankNode {
+			if t.Value == id {
+				t.Value = "_:a"
+			} else {
+				t.Value = "_:z"
+			}
+		}
+
+		return t
+	}
+
+	lines := make([]string, 0, len(c.blankQuads[id]))
+
+	for _, q := range c.blankQuads[id] {
+		relabeled := &Quad{Subject: label(q.Subject), Predicate: q.Predicate, Object: label(q.Object),
+			Graph: label(q.Graph)}
+		lines = append(lines, relabeled.String())
+	}
+
+	sort.Strings(lines)
+
+	return hash(strings.Join(lines, ""))
+}
+
+// hashRelatedBlankNode hashes a blank node related to another through quad, at position "s", "o" or "g".
+func (c *canonicalizer) hashRelatedBlankNode(related string, q *Quad, iss *issuer, position string) string {
+	input := position
+
+	if position != "g" {
+		input += "<" + q.Predicate.Value + ">"
+	}
+
+	switch {
+	case c.canonicalIssuer.has(related):
+		input += c.canonicalIssuer.issue(related)
+	case iss.has(related):
+		input += iss.issue(related)
+	default:
+		input += c.hashFirstDegree(related)
+	}
+
+	return hash(input)
+}
+
+type nDegreeResult struct {
+	hash   string
+	issuer *issuer
+}
+
+// hashNDegree implements the hash N-degree quads algorithm.
+//
+//nolint:gocyclo,funlen
+func (c *canonicalizer) hashNDegree(id string, iss *issuer) nDegreeResult {
+	hashToRelated := map[string][]string{}
+
+	for _, q := range c.blankQuads[id] {
+		for _, component := range []struct {
+			term     Term
+			position string
+		}{{q.Subject, "s"}, {q.Object, "o"}, {q.Graph, "g"}} {
+			if component.term.Kind != BlankNode || component.term.Value == id {
+				continue
+			}
+
+			related := component.term.Value
+			h := c.hashRelatedBlankNode(related, q, iss, component.position)
+			hashToRelated[h] = append(hashToRelated[h], related)
+		}
+	}
+
+	var data strings.Builder
+
+	for _, relatedHash := range sortedHashes(hashToRelated) {
+		data.WriteString(relatedHash)
+
+		var (
+			chosenPath   string
+			chosenIssuer *issuer
+		)
+
+		permute(hashToRelated[relatedHash], func(permutation []string) {
+			issuerCopy := iss.clone()
+
+			var (
+				path      strings.Builder
+				recursion []string
+			)
+
+			longer := func() bool {
+				return chosenIssuer != nil && path.Len() >= len(chosenPath) && path.String() > chosenPath
+			}
+
+			for _, related := range permutation {
+				if c.canonicalIssuer.has(related) {
+					path.WriteString(c.canonicalIssuer.issue(related))
+				} else {
+					if !issuerCopy.has(related) {
+						recursion = append(recursion, related)
+					}
+
+					path.WriteString(issuerCopy.issue(related))
+				}
+
+				if longer() {
+					return
+				}
+			}
+
+			for _, related := range recursion {
+				result := c.hashNDegree(related, issuerCopy)
+
+				path.WriteString(issuerCopy.issue(related))
+				path.WriteString("<" + result.hash + ">")
+
+				issuerCopy = result.issuer
+
+				if longer() {
+					return
+				}
+			}
+
+			if chosenIssuer == nil || path.String() < chosenPath {
+				chosenPath, chosenIssuer = path.String(), issuerCopy
+			}
+		})
+
+		data.WriteString(chosenPath)
+		iss = chosenIssuer
+	}
+
+	return nDegreeResult{hash: hash(data.String()), issuer: iss}
+}
+
+// permute calls f with every permutation of items, in lexicographic order of the permuted indexes.
+func permute(items []string, f func([]string)) {
+	indexes := make([]int, len(items))
+	for i := range indexes {
+		indexes[i] = i
+	}
+
+	permutation := make([]string, len(items))
+
+	for {
+		for i, index := range indexes {
+			permutation[i] = items[index]
+		}
+
+		f(permutation)
+
+		// next permutation of indexes.
+		i := len(indexes) - 2
+		for i >= 0 && indexes[i] >= indexes[i+1] {
+			i--
+		}
+
+		if i < 0 {
+			return
+		}
+
+		j := len(indexes) - 1
+		for indexes[j] <= indexes[i] {
+			j--
+		}
+
+		indexes[i], indexes[j] = indexes[j], indexes[i]
+
+		for l, r := i+1, len(indexes)-1; l < r; l, r = l+1, r-1 {
+			indexes[l], indexes[r] = indexes[r], indexes[l]
+		}
+	}
+}
+
+func dedupe(sorted []*Quad) []*Quad {
+	result := make([]*Quad, 0, len(sorted))
+
+	for _, q := range sorted {
+		if len(result) > 0 && q.String() == result[len(result)-1].String() {
+			continue
+		}
+
+		result = append(result, q)
+	}
+
+	return result
+}
+
+func hash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+
+	return hex.EncodeToString(sum[:])
+}
+
+func sortedSet(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+
+	for k := range set {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func sortedHashes(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,120 @@
+package jsonld
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalizeQuads(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name: "unique hashes",
+			input: `<http://example.com/#p> <http://example.com/#q> _:e0 .
+<http://example.com/#p> <http://example.com/#r> _:e1 .
+_:e0 <http://example.com/#s> <http://example.com/#u> .
+_:e1 <http://example.com/#t> <http://example.com/#u> .
+`,
+			expected: `<http://example.com/#p> <http://example.com/#q> _:c14n0 .
+<http://example.com/#p> <http://example.com/#r> _:c14n1 .
+_:c14n0 <http://example.com/#s> <http://example.com/#u> .
+_:c14n1 <http://example.com/#t> <http://example.com/#u> .
+`,
+		},
+		{
+			name: "shared hashes",
+			input: `<http://example.com/#p> <http://example.com/#q> _:e0 .
+<http://example.com/#p> <http://example.com/#q> _:e1 .
+_:e0 <http://example.com/#p> _:e2 .
+_:e1 <http://example.com/#p> _:e3 .
+_:e2 <http://example.com/#r> _:e3 .
+`,
+			expected: `<http://example.com/#p> <http://example.com/#q> _:c14n2 .
+<http://example.com/#p> <http://example.com/#q> _:c14n3 .
+_:c14n0 <http://example.com/#r> _:c14n1 .
+_:c14n2 <http://example.com/#p> _:c14n1 .
+_:c14n3 <http://example.com/#p> _:c14n0 .
+`,
+		},
+		{
+			name: "literals and named graphs",
+			input: `_:g <http://example.com/#name> "a \"quoted\"\nname"@en _:x .
+_:x <http://example.com/#count> "5"^^<http://www.w3.org/2001/XMLSchema#integer> .
+_:x <http://example.com/#label> "label" .
+`,
+			expected: `_:c14n0 <http://example.com/#name> "a \"quoted\"\nname"@en _:c14n1 .
+_:c14n1 <http://example.com/#count> "5"^^<http://www.w3.org/2001/XMLSchema#integer> .
+_:c14n1 <http://example.com/#label> "label" .
+`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			quads, err := ParseNQuads(tc.input)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.expected, SerializeNQuads(CanonicalizeQuads(quads)))
+		})
+	}
+}
+
+func TestCanonicalizeQuads_Invariance(t *testing.T) {
+	// a cycle of indistinguishable blank nodes, which requires the N-degree hash algorithm.
+	input := `_:a <http://example.com/#next> _:b .
+_:b <http://example.com/#next> _:c .
+_:c <http://example.com/#next> _:a .
+_:a <http://example.com/#value> "1" .
+_:d <http://example.com/#next> _:e .
+_:e <http://example.com/#next> _:d .
+_:d <http://example.com/#value> "1" .
+`
+
+	quads, err := ParseNQuads(input)
+	require.NoError(t, err)
+
+	expected := SerializeNQuads(CanonicalizeQuads(quads))
+
+	rnd := rand.New(rand.NewSource(1)) //nolint:gosec
+
+	for i := 0; i < 20; i++ {
+		relabeled := input
+		for j, label := range rnd.Perm(5) {
+			relabeled = strings.ReplaceAll(relabeled, "_:"+string(rune('a'+j))+" ", "_:tmp"+string(rune('a'+label))+" ")
+		}
+
+		lines := strings.Split(strings.TrimSpace(relabeled), "\n")
+		rnd.Shuffle(len(lines), func(i, j int) { lines[i], lines[j] = lines[j], lines[i] })
+
+		quads, err := ParseNQuads(strings.Join(lines, "\n"))
+		require.NoError(t, err)
+
+		require.Equal(t, expected, SerializeNQuads(CanonicalizeQuads(quads)))
+	}
+}
+
+func TestParseNQuads(t *testing.T) {
+	quads, err := ParseNQuads("# comment\n\n<urn:s> <urn:p> \"v\\\\\\r\" <urn:g> .\n")
+	require.NoError(t, err)
+	require.Len(t, quads, 1)
+	require.Equal(t, Term{Kind: IRI, Value: "urn:g"}, quads[0].Graph)
+	require.Equal(t, Term{Kind: Literal, Value: "v\\\r", Datatype: XSDString}, quads[0].Object)
+
+	for _, invalid := range []string{
+		"<urn:s> <urn:p> .",
+		"<urn:s> <urn:p> <urn:o>",
+		"\"s\" <urn:p> <urn:o> .",
+		"<urn:s> _:p <urn:o> .",
+		"<urn:s> <urn:p> \"unterminated .",
+		"<urn:s> <urn:p> <urn:o> <urn:g> <urn:x> .",
+	} {
+		_, err := ParseNQuads(invalid)
+		require.ErrorIs(t, err, ErrInvalidDocument, invalid)
+	}
+}
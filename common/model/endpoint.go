@@ -8,8 +8,17 @@ package model
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/url"
+	"strings"
+)
+
+var (
+	// ErrEndpointNotFound is returned when an endpoint has no entry matching the request.
+	ErrEndpointNotFound = errors.New("endpoint not found")
+	// ErrAllEndpointsFailed is returned by Failover when no entry could be used.
+	ErrAllEndpointsFailed = errors.New("all endpoints failed")
 )
 
 // EndpointType endpoint type.
@@ -53,8 +62,8 @@ type DIDCommV2Endpoint struct {
 	RoutingKeys []string `json:"routingKeys,omitempty"`
 }
 
-// NewDIDCommV2Endpoint creates a DIDCommV2 endpoint with the given array of endpoints. At the time of writing this
-// comment, only the first endpoint is effective in the API. Additional logic is required to use a different index.
+// NewDIDCommV2Endpoint creates a DIDCommV2 endpoint with the given array of endpoints. URI, Accept and RoutingKeys
+// return the first entry, use Endpoints, Select or Failover to use the others.
 func NewDIDCommV2Endpoint(endpoints []DIDCommV2Endpoint) Endpoint {
 	endpoint := Endpoint{rawDIDCommV2: []DIDCommV2Endpoint{}}
 	endpoint.rawDIDCommV2 = append(endpoint.rawDIDCommV2, endpoints...)
@@ -78,12 +87,11 @@ func NewDIDCoreEndpoint(genericEndpoint interface{}) Endpoint {
 
 // URI is the URI of a service endpoint.
 // It will return the value based on the underlying endpoint type in the following order:
-// 1- DIDComm V2 URI (currently the first element's URI). TODO enhance API to pass in an optional index.
+// 1- DIDComm V2 URI of the first entry, see Endpoints for the others.
 // 2- DIDComm V1 URI
 // 3- DIDCore's first element printed as string for now. (not used by AFGO at the time of this writing, but can be
 //    enhanced if needed).
 func (s *Endpoint) URI() (string, error) {
-	// TODO for now, returning URI of first element. Add mechanism to fetch from appropriate index.
 	if len(s.rawDIDCommV2) > 0 {
 		return s.rawDIDCommV2[0].URI, nil
 	}
@@ -108,9 +116,8 @@ func (s *Endpoint) URI() (string, error) {
 	return "", fmt.Errorf("endpoint URI not found")
 }
 
-// Accept is the DIDComm V2 Accept field of a service endpoint.
+// Accept is the DIDComm V2 Accept field of the first entry of a service endpoint.
 func (s *Endpoint) Accept() ([]string, error) {
-	// TODO for now, returning Accept of first element. Add mechanism to fetch appropriate value.
 	if len(s.rawDIDCommV2) > 0 {
 		return s.rawDIDCommV2[0].Accept, nil
 	}
@@ -118,9 +125,8 @@ func (s *Endpoint) Accept() ([]string, error) {
 	return nil, fmt.Errorf("endpoint Accept not found")
 }
 
-// RoutingKeys is the DIDComm V2 RoutingKeys field of a service endpoint.
+// RoutingKeys is the DIDComm V2 RoutingKeys field of the first entry of a service endpoint.
 func (s *Endpoint) RoutingKeys() ([]string, error) {
-	// TODO for now, returning RoutingKeys of first element. Add mechanism to fetch appropriate value.
 	if len(s.rawDIDCommV2) > 0 {
 		return s.rawDIDCommV2[0].RoutingKeys, nil
 	}
@@ -128,6 +134,149 @@ func (s *Endpoint) RoutingKeys() ([]string, error) {
 	return nil, fmt.Errorf("endpoint RoutingKeys not found")
 }
 
+// Endpoints returns all entries of a service endpoint, in document order. DIDComm V1 and DIDCore endpoints are
+// returned as entries with a URI only.
+func (s *Endpoint) Endpoints() ([]DIDCommV2Endpoint, error) {
+	if len(s.rawDIDCommV2) > 0 {
+		return append([]DIDCommV2Endpoint{}, s.rawDIDCommV2...), nil
+	}
+
+	if s.rawDIDCommV1 != "" {
+		return []DIDCommV2Endpoint{{URI: stripQuotes(s.rawDIDCommV1)}}, nil
+	}
+
+	if s.rawObj == nil {
+		return nil, fmt.Errorf("%w: no entries", ErrEndpointNotFound)
+	}
+
+	var endpoints []DIDCommV2Endpoint
+
+	switch o := s.rawObj.(type) {
+	case string:
+		endpoints = append(endpoints, DIDCommV2Endpoint{URI: o})
+	case []string:
+		for _, uri := range o {
+			endpoints = append(endpoints, DIDCommV2Endpoint{URI: uri})
+		}
+	case [][]byte:
+		for _, uri := range o {
+			endpoints = append(endpoints, DIDCommV2Endpoint{URI: string(uri)})
+		}
+	case []interface{}:
+		for _, entry := range o {
+			endpoint, err := genericEntry(entry)
+			if err != nil {
+				return nil, err
+			}
+
+			endpoints = append(endpoints, endpoint)
+		}
+	default:
+		return nil, fmt.Errorf("unrecognized DIDCore endpoint object %s", o)
+	}
+
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("%w: no entries", ErrEndpointNotFound)
+	}
+
+	return endpoints, nil
+}
+
+// Select returns the entries of a service endpoint which accept one of the given media type profiles, in document
+// order. Entries without Accept match any profile: per DIDComm V2 the sender then uses its preferred profile. All
+// entries are returned when no profile is given.
+func (s *Endpoint) Select(accept ...string) ([]DIDCommV2Endpoint, error) {
+	endpoints, err := s.Endpoints()
+	if err != nil {
+		return nil, err
+	}
+
+	var selected []DIDCommV2Endpoint
+
+	for _, endpoint := range endpoints {
+		if endpoint.Accepts(accept...) {
+			selected = append(selected, endpoint)
+		}
+	}
+
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("%w: no entry accepts %s", ErrEndpointNotFound, strings.Join(accept, ", "))
+	}
+
+	return selected, nil
+}
+
+// Failover calls send with the entries selected by Select in turn, until one of them succeeds.
+func (s *Endpoint) Failover(send func(endpoint DIDCommV2Endpoint) error, accept ...string) error {
+	endpoints, err := s.Select(accept...)
+	if err != nil {
+		return err
+	}
+
+	return Failover(endpoints, send)
+}
+
+// Failover calls send with the given entries in turn, until one of them succeeds. When all of them fail, the error
+// lists the failure of each entry.
+func Failover(endpoints []DIDCommV2Endpoint, send func(endpoint DIDCommV2Endpoint) error) error {
+	if len(endpoints) == 0 {
+		return fmt.Errorf("%w: no entries", ErrEndpointNotFound)
+	}
+
+	failures := make([]string, 0, len(endpoints))
+
+	for _, endpoint := range endpoints {
+		err := send(endpoint)
+		if err == nil {
+			return nil
+		}
+
+		failures = append(failures, fmt.Sprintf("%s: %v", endpoint.URI, err))
+	}
+
+	return fmt.Errorf("%w: %s", ErrAllEndpointsFailed, strings.Join(failures, "; "))
+}
+
+// Accepts reports whether the entry accepts one of the given media type profiles. Entries without Accept, and
+// requests without profiles, always match.
+func (e *DIDCommV2Endpoint) Accepts(accept ...string) bool {
+	if len(accept) == 0 || len(e.Accept) == 0 {
+		return true
+	}
+
+	for _, profile := range accept {
+		for _, accepted := range e.Accept {
+			if profile == accepted {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func genericEntry(entry interface{}) (DIDCommV2Endpoint, error) {
+	switch e := entry.(type) {
+	case string:
+		return DIDCommV2Endpoint{URI: e}, nil
+	case map[string]interface{}:
+		var endpoint DIDCommV2Endpoint
+
+		entryBytes, err := json.Marshal(e)
+		if err != nil {
+			return DIDCommV2Endpoint{}, fmt.Errorf("marshal DIDCore endpoint entry: %w", err)
+		}
+
+		if err = json.Unmarshal(entryBytes, &endpoint); err != nil || endpoint.URI == "" {
+			return DIDCommV2Endpoint{}, fmt.Errorf("unrecognized DIDCore endpoint entry %v", e)
+		}
+
+		return endpoint, nil
+	default:
+		return DIDCommV2Endpoint{URI: fmt.Sprintf("%s", e)}, nil
+	}
+}
+
 // Type return endpoint type.
 func (s *Endpoint) Type() EndpointType {
 	if len(s.rawDIDCommV2) > 0 {
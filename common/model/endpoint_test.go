@@ -134,3 +134,95 @@ func TestEndpoint_MarshalUnmarshalJSON(t *testing.T) {
 		}
 	}
 }
+
+func TestEndpoint_Select(t *testing.T) {
+	ep := NewDIDCommV2Endpoint([]DIDCommV2Endpoint{
+		{URI: "https://primary.example.com", Accept: []string{"didcomm/v2"}},
+		{URI: "https://legacy.example.com", Accept: []string{"didcomm/aip2;env=rfc19"}},
+		{URI: "wss://any.example.com", RoutingKeys: []string{"did:example:mediator#key-1"}},
+	})
+
+	endpoints, err := ep.Endpoints()
+	require.NoError(t, err)
+	require.Len(t, endpoints, 3)
+
+	selected, err := ep.Select("didcomm/aip2;env=rfc19")
+	require.NoError(t, err)
+	require.Equal(t, []string{"https://legacy.example.com", "wss://any.example.com"}, uris(selected))
+
+	selected, err = ep.Select()
+	require.NoError(t, err)
+	require.Len(t, selected, 3)
+
+	var tried []string
+
+	err = ep.Failover(func(endpoint DIDCommV2Endpoint) error {
+		tried = append(tried, endpoint.URI)
+
+		if endpoint.URI == "https://primary.example.com" {
+			return fmt.Errorf("connection refused")
+		}
+
+		return nil
+	}, "didcomm/v2")
+	require.NoError(t, err)
+	require.Equal(t, []string{"https://primary.example.com", "wss://any.example.com"}, tried)
+
+	err = ep.Failover(func(DIDCommV2Endpoint) error {
+		return fmt.Errorf("connection refused")
+	}, "didcomm/v2")
+	require.ErrorIs(t, err, ErrAllEndpointsFailed)
+	require.Contains(t, err.Error(), "https://primary.example.com: connection refused")
+	require.Contains(t, err.Error(), "wss://any.example.com: connection refused")
+
+	ep = NewDIDCommV2Endpoint([]DIDCommV2Endpoint{{URI: "https://primary.example.com", Accept: []string{"didcomm/v2"}}})
+	_, err = ep.Select("didcomm/aip1")
+	require.ErrorIs(t, err, ErrEndpointNotFound)
+
+	require.ErrorIs(t, Failover(nil, nil), ErrEndpointNotFound)
+}
+
+func TestEndpoint_Endpoints(t *testing.T) {
+	ep := NewDIDCommV1Endpoint("https://agent.example.com")
+	endpoints, err := ep.Endpoints()
+	require.NoError(t, err)
+	require.Equal(t, []DIDCommV2Endpoint{{URI: "https://agent.example.com"}}, endpoints)
+
+	ep = NewDIDCoreEndpoint([]string{"https://a.example.com", "https://b.example.com"})
+	endpoints, err = ep.Endpoints()
+	require.NoError(t, err)
+	require.Equal(t, []string{"https://a.example.com", "https://b.example.com"}, uris(endpoints))
+
+	mixed := `["https://a.example.com", {"uri": "https://b.example.com", "accept": ["didcomm/v2"]}]`
+	require.NoError(t, ep.UnmarshalJSON([]byte(mixed)))
+	endpoints, err = ep.Endpoints()
+	require.NoError(t, err)
+	require.Equal(t, []DIDCommV2Endpoint{
+		{URI: "https://a.example.com"}, {URI: "https://b.example.com", Accept: []string{"didcomm/v2"}},
+	}, endpoints)
+
+	require.NoError(t, ep.UnmarshalJSON([]byte(`["https://a.example.com", {"origins": []}]`)))
+	_, err = ep.Endpoints()
+	require.Error(t, err)
+
+	require.NoError(t, ep.UnmarshalJSON([]byte(`{"origins": ["https://a.example.com"]}`)))
+	_, err = ep.Endpoints()
+	require.Error(t, err)
+
+	ep = Endpoint{}
+	_, err = ep.Endpoints()
+	require.ErrorIs(t, err, ErrEndpointNotFound)
+
+	_, err = ep.Select()
+	require.ErrorIs(t, err, ErrEndpointNotFound)
+}
+
+func uris(endpoints []DIDCommV2Endpoint) []string {
+	var result []string
+
+	for _, endpoint := range endpoints {
+		result = append(result, endpoint.URI)
+	}
+
+	return result
+}
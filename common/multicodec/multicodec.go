@@ -0,0 +1,134 @@
+// Package multicodec implements the multicodec prefixes of public keys, as used by did:key and Multikey
+// verification methods. See https://github.com/multiformats/multicodec/blob/master/table.csv.
+package multicodec
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/zRich/zFusion/common/crypto"
+)
+
+// Code is a multicodec code.
+type Code uint64
+
+// Public key codes.
+const (
+	Ed25519Pub    Code = 0xed
+	X25519Pub     Code = 0xec
+	Secp256k1Pub  Code = 0xe7
+	P256Pub       Code = 0x1200
+	BLS12381G1Pub Code = 0xea
+	BLS12381G2Pub Code = 0xeb
+	SM2Pub        Code = 0x1206
+)
+
+// Codec describes the public keys of a multicodec code.
+type Codec struct {
+	Code Code
+	Name string
+	// KeySize is the size of the raw public key. EC keys are compressed.
+	KeySize int
+	// KeyType is the type of the key as used by crypto.Verify, empty for keys without signature support.
+	KeyType crypto.KeyType
+}
+
+// codecs is the multicodec table of public keys.
+var codecs = map[Code]Codec{ //nolint:gochecknoglobals
+	Ed25519Pub:    {Code: Ed25519Pub, Name: "ed25519-pub", KeySize: 32, KeyType: crypto.Ed25519},
+	X25519Pub:     {Code: X25519Pub, Name: "x25519-pub", KeySize: 32, KeyType: crypto.X25519},
+	Secp256k1Pub:  {Code: Secp256k1Pub, Name: "secp256k1-pub", KeySize: 33, KeyType: crypto.ECDSASecp256k1},
+	P256Pub:       {Code: P256Pub, Name: "p256-pub", KeySize: 33, KeyType: crypto.ECDSAP256},
+	BLS12381G1Pub: {Code: BLS12381G1Pub, Name: "bls12_381-g1-pub", KeySize: 48},
+	BLS12381G2Pub: {Code: BLS12381G2Pub, Name: "bls12_381-g2-pub", KeySize: 96},
+	SM2Pub:        {Code: SM2Pub, Name: "sm2-pub", KeySize: 33, KeyType: crypto.SM2},
+}
+
+var (
+	// ErrUnknownCodec is returned for codes missing from the table.
+	ErrUnknownCodec = errors.New("unknown multicodec")
+	// ErrInvalidKey is returned when a key does not match its codec.
+	ErrInvalidKey = errors.New("key does not match its multicodec")
+)
+
+// Lookup returns the codec of code.
+func Lookup(code Code) (Codec, error) {
+	codec, ok := codecs[code]
+	if !ok {
+		return Codec{}, fmt.Errorf("%w: 0x%x", ErrUnknownCodec, uint64(code))
+	}
+
+	return codec, nil
+}
+
+// ForKeyType returns the codec of the keys of the given type.
+func ForKeyType(keyType crypto.KeyType) (Codec, error) {
+	for _, codec := range codecs {
+		if codec.KeyType != "" && codec.KeyType == keyType {
+			return codec, nil
+		}
+	}
+
+	return Codec{}, fmt.Errorf("%w: no codec for %s keys", ErrUnknownCodec, keyType)
+}
+
+// String returns the name of the code.
+func (c Code) String() string {
+	if codec, ok := codecs[c]; ok {
+		return codec.Name
+	}
+
+	return fmt.Sprintf("0x%x", uint64(c))
+}
+
+// Encode prefixes key with the varint of code, after checking that the key size matches the codec.
+func Encode(code Code, key []byte) ([]byte, error) {
+	codec, err := Lookup(code)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = codec.check(key); err != nil {
+		return nil, err
+	}
+
+	prefixed := make([]byte, binary.MaxVarintLen64+len(key))
+	n := binary.PutUvarint(prefixed, uint64(code))
+
+	return append(prefixed[:n], key...), nil
+}
+
+// Decode splits data into its multicodec code and key, and checks that the key size matches the codec.
+func Decode(data []byte) (Code, []byte, error) {
+	code, n := binary.Uvarint(data)
+	if n <= 0 {
+		return 0, nil, fmt.Errorf("%w: invalid varint prefix", ErrUnknownCodec)
+	}
+
+	codec, err := Lookup(Code(code))
+	if err != nil {
+		return 0, nil, err
+	}
+
+	key := data[n:]
+	if err = codec.check(key); err != nil {
+		return 0, nil, err
+	}
+
+	return codec.Code, key, nil
+}
+
+func (c *Codec) check(key []byte) error {
+	if len(key) != c.KeySize {
+		return fmt.Errorf("%w: %d bytes %s key, expected %d", ErrInvalidKey, len(key), c.Name, c.KeySize)
+	}
+
+	if c.KeyType == crypto.ECDSAP256 || c.KeyType == crypto.ECDSASecp256k1 || c.KeyType == crypto.SM2 {
+		if _, err := crypto.ParseECDSAPublicKey(c.KeyType, key); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidKey, err)
+		}
+	}
+
+	return nil
+}
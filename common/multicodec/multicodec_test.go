@@ -0,0 +1,85 @@
+package multicodec
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zRich/zFusion/common/crypto"
+	"github.com/zRich/zFusion/common/crypto/sm2"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	privKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	p256Key := elliptic.MarshalCompressed(elliptic.P256(), privKey.X, privKey.Y)
+
+	sm2PrivKey, err := sm2.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	sm2Key := elliptic.MarshalCompressed(sm2.P256(), sm2PrivKey.X, sm2PrivKey.Y)
+
+	for _, tc := range []struct {
+		code   Code
+		key    []byte
+		prefix string
+	}{
+		{Ed25519Pub, bytes.Repeat([]byte{1}, 32), "ed01"},
+		{X25519Pub, bytes.Repeat([]byte{2}, 32), "ec01"},
+		{P256Pub, p256Key, "8024"},
+		{BLS12381G1Pub, bytes.Repeat([]byte{3}, 48), "ea01"},
+		{BLS12381G2Pub, bytes.Repeat([]byte{4}, 96), "eb01"},
+		{SM2Pub, sm2Key, "8624"},
+	} {
+		encoded, err := Encode(tc.code, tc.key)
+		require.NoError(t, err, tc.code.String())
+		require.Equal(t, tc.prefix, hex.EncodeToString(encoded[:2]), tc.code.String())
+
+		code, key, err := Decode(encoded)
+		require.NoError(t, err, tc.code.String())
+		require.Equal(t, tc.code, code)
+		require.Equal(t, tc.key, key)
+
+		_, err = Encode(tc.code, tc.key[1:])
+		require.ErrorIs(t, err, ErrInvalidKey, tc.code.String())
+
+		_, _, err = Decode(encoded[:len(encoded)-1])
+		require.ErrorIs(t, err, ErrInvalidKey, tc.code.String())
+	}
+
+	_, err = Encode(Secp256k1Pub, append([]byte{2}, bytes.Repeat([]byte{0xff}, 32)...))
+	require.ErrorIs(t, err, ErrInvalidKey)
+
+	_, err = Encode(0x55, []byte{1})
+	require.ErrorIs(t, err, ErrUnknownCodec)
+
+	for _, invalid := range []string{"", "80", "0201", "ed"} {
+		data, err := hex.DecodeString(invalid)
+		require.NoError(t, err)
+
+		_, _, err = Decode(data)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	codec, err := Lookup(Ed25519Pub)
+	require.NoError(t, err)
+	require.Equal(t, "ed25519-pub", codec.Name)
+	require.Equal(t, crypto.Ed25519, codec.KeyType)
+
+	codec, err = ForKeyType(crypto.ECDSASecp256k1)
+	require.NoError(t, err)
+	require.Equal(t, Secp256k1Pub, codec.Code)
+
+	_, err = ForKeyType(crypto.RSA)
+	require.ErrorIs(t, err, ErrUnknownCodec)
+
+	require.Equal(t, "bls12_381-g2-pub", BLS12381G2Pub.String())
+	require.Equal(t, "0x55", Code(0x55).String())
+}
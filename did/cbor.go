@@ -0,0 +1,28 @@
+package did
+
+import (
+	"fmt"
+
+	"github.com/zRich/zFusion/common/cbor"
+)
+
+// CBORBytes returns the application/did+cbor representation of the document: the deterministic CBOR encoding of
+// its JSON data model.
+func (doc *Document) CBORBytes() ([]byte, error) {
+	data, err := doc.JSONBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	return cbor.FromJSON(data)
+}
+
+// ParseDocumentCBOR parses the application/did+cbor representation of a document, see ParseDocument.
+func ParseDocumentCBOR(data []byte, opts ...ParseOption) (*Document, error) {
+	jsonData, err := cbor.ToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("decode CBOR did doc: %w", err)
+	}
+
+	return ParseDocument(jsonData, opts...)
+}
@@ -0,0 +1,38 @@
+package did
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zRich/zFusion/common/cbor"
+)
+
+func TestDocument_CBORBytes(t *testing.T) {
+	for file, doc := range testDocuments(t) {
+		t.Run(file, func(t *testing.T) {
+			expected, err := doc.JSONBytes()
+			require.NoError(t, err)
+
+			data, err := doc.CBORBytes()
+			require.NoError(t, err)
+			require.Less(t, len(data), len(expected))
+
+			result, err := ParseDocumentCBOR(data)
+			require.NoError(t, err)
+
+			actual, err := result.JSONBytes()
+			require.NoError(t, err)
+			require.JSONEq(t, string(expected), string(actual))
+
+			again, err := result.CBORBytes()
+			require.NoError(t, err)
+			require.Equal(t, data, again)
+		})
+	}
+
+	_, err := ParseDocumentCBOR([]byte{0x40})
+	require.ErrorIs(t, err, cbor.ErrInvalid)
+
+	_, err = ParseDocumentCBOR([]byte{0xa0})
+	require.Error(t, err)
+}
@@ -2,12 +2,17 @@ package did
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/url"
-	"regexp"
 	"strings"
 )
 
+// ErrInvalidDID is returned when parsing a string which does not conform to the DID or DID URL syntax.
+var ErrInvalidDID = errors.New("invalid DID")
+
+const didPrefix = "did:"
+
 // DID is parsed according to the generic syntax: https://w3c.github.io/did-core/#generic-did-syntax
 type DID struct {
 	Scheme           string `json:"scheme"`             // Scheme is always "did"
@@ -17,92 +22,347 @@ type DID struct {
 
 // String returns a string representation of this DID.
 func (d *DID) String() string {
-	return fmt.Sprintf("%s:%s:%s", d.Scheme, d.Method, d.MethodSpecificID)
+	return d.Scheme + ":" + d.Method + ":" + d.MethodSpecificID
 }
 
-// Parse parses the string according to the generic DID syntax.
-// See https://w3c.github.io/did-core/#generic-did-syntax.
-func Parse(did string) (*DID, error) {
-	// I could not find a good ABNF parser :(
-	const idChar = `a-zA-Z0-9-_\.`
-	const methodChar = `a-zA-Z0-9`
-	regex := fmt.Sprintf(`^did:[a-z]+:(:+|[:%s]+)*[%%:%s]+[^:]$`, methodChar, idChar)
+// Normalize returns the normal form of the DID, in which the hexadecimal digits of percent-encoded characters
+// are upper case and characters allowed unencoded are decoded.
+func (d *DID) Normalize() *DID {
+	return &DID{
+		Scheme:           d.Scheme,
+		Method:           d.Method,
+		MethodSpecificID: normalizePercentEncoding(d.MethodSpecificID, isIDChar),
+	}
+}
 
-	r, err := regexp.Compile(regex)
+// Equal tells whether the DIDs are equivalent, that is have the same normal form.
+func (d *DID) Equal(other *DID) bool {
+	return d.Normalize().String() == other.Normalize().String()
+}
+
+// Parse parses the string according to the DID syntax of DID Core:
+//
+//	did                = "did:" method-name ":" method-specific-id
+//	method-name        = 1*method-char
+//	method-char        = %x61-7A / DIGIT
+//	method-specific-id = *( *idchar ":" ) 1*idchar
+//	idchar             = ALPHA / DIGIT / "." / "-" / "_" / pct-encoded
+//	pct-encoded        = "%" HEXDIG HEXDIG
+//
+// See https://www.w3.org/TR/did-core/#did-syntax.
+func Parse(did string) (*DID, error) {
+	methodEnd, end, err := scanDID(did)
 	if err != nil {
-		return nil, fmt.Errorf("failed to compile regex=%s (this should not have happened!). %w", regex, err)
+		return nil, err
 	}
 
-	if !r.MatchString(did) {
-		return nil, fmt.Errorf(
-			"invalid did: %s. Make sure it conforms to the DID syntax: https://w3c.github.io/did-core/#did-syntax", did)
+	if end != len(did) {
+		return nil, syntaxError(did, end, "invalid character in method-specific-id")
 	}
 
-	parts := strings.SplitN(did, ":", 3)
-
-	return &DID{
-		Scheme:           "did",
-		Method:           parts[1],
-		MethodSpecificID: parts[2],
-	}, nil
+	return newDID(did, methodEnd, end), nil
 }
 
 func (d *DID) Marshal() ([]byte, error) {
 	return json.Marshal(d)
 }
 
-// DIDURL holds a DID URL.
+// DIDURL holds a DID URL. Path, Queries and Fragment are decoded, RawPath, RawQuery and RawFragment hold
+// them as they appear in the DID URL.
 type DIDURL struct { // nolint:golint // ignore name stutter
 	DID
-	Path     string
-	Queries  map[string][]string
-	Fragment string
+	Path        string
+	Queries     map[string][]string
+	Fragment    string
+	RawPath     string
+	RawQuery    string
+	RawFragment string
 }
 
-// ParseDIDURL parses a DID URL string into a DIDURL object.
-func ParseDIDURL(didURL string) (*DIDURL, error) {
-	split := strings.IndexAny(didURL, "?/#")
+// String returns the DID URL as parsed.
+func (u *DIDURL) String() string {
+	s := u.DID.String() + u.RawPath
 
-	didPart := didURL
-	pathQueryFragment := ""
+	if u.RawQuery != "" {
+		s += "?" + u.RawQuery
+	}
 
-	if split != -1 {
-		didPart = didURL[:split]
-		pathQueryFragment = didURL[split:]
+	if u.RawFragment != "" {
+		s += "#" + u.RawFragment
 	}
 
-	retDID, err := Parse(didPart)
+	return s
+}
+
+// Normalize returns the normal form of the DID URL, in which the hexadecimal digits of percent-encoded
+// characters are upper case and characters allowed unencoded are decoded.
+func (u *DIDURL) Normalize() *DIDURL {
+	n := *u
+	n.DID = *u.DID.Normalize()
+	n.RawPath = normalizePercentEncoding(u.RawPath, isUnreserved)
+	n.RawQuery = normalizePercentEncoding(u.RawQuery, isUnreserved)
+	n.RawFragment = normalizePercentEncoding(u.RawFragment, isUnreserved)
+
+	return &n
+}
+
+// Equal tells whether the DID URLs are equivalent, that is have the same normal form.
+func (u *DIDURL) Equal(other *DIDURL) bool {
+	return u.Normalize().String() == other.Normalize().String()
+}
+
+// ParseDIDURL parses a DID URL string into a DIDURL object, according to the DID URL syntax of DID Core:
+//
+//	did-url = did path-abempty [ "?" query ] [ "#" fragment ]
+//
+// where path-abempty, query and fragment are defined by RFC 3986. Query parameters are decoded as
+// application/x-www-form-urlencoded values. See https://www.w3.org/TR/did-core/#did-url-syntax.
+func ParseDIDURL(didURL string) (*DIDURL, error) {
+	methodEnd, end, err := scanDID(didURL)
 	if err != nil {
 		return nil, err
 	}
 
-	if pathQueryFragment == "" {
-		return &DIDURL{
-			DID:     *retDID,
-			Queries: map[string][]string{},
-		}, nil
-	}
+	ret := &DIDURL{DID: *newDID(didURL, methodEnd, end), Queries: map[string][]string{}}
 
-	hasPath := pathQueryFragment[0] == '/'
+	i := end
 
-	if !hasPath {
-		pathQueryFragment = "/" + pathQueryFragment
+	if i < len(didURL) && didURL[i] != '/' && didURL[i] != '?' && didURL[i] != '#' {
+		return nil, syntaxError(didURL, i, "invalid character in method-specific-id")
 	}
 
-	urlParts, err := url.Parse(pathQueryFragment)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse path, query, and fragment components of DID URL: %w", err)
+	pathEnd := scanURLComponent(didURL, i, isPathChar)
+	if err := checkComponentEnd(didURL, pathEnd, "?#"); err != nil {
+		return nil, err
 	}
 
-	ret := &DIDURL{
-		DID:      *retDID,
-		Queries:  urlParts.Query(),
-		Fragment: urlParts.Fragment,
+	ret.RawPath = didURL[i:pathEnd]
+	ret.Path, _ = url.PathUnescape(ret.RawPath) //nolint:errcheck // percent-encoding is validated by the scan
+	i = pathEnd
+
+	if i < len(didURL) && didURL[i] == '?' {
+		queryEnd := scanURLComponent(didURL, i+1, isQueryChar)
+		if err := checkComponentEnd(didURL, queryEnd, "#"); err != nil {
+			return nil, err
+		}
+
+		ret.RawQuery = didURL[i+1 : queryEnd]
+		ret.Queries = parseQuery(ret.RawQuery)
+		i = queryEnd
 	}
 
-	if hasPath {
-		ret.Path = urlParts.Path
+	if i < len(didURL) && didURL[i] == '#' {
+		fragmentEnd := scanURLComponent(didURL, i+1, isQueryChar)
+		if err := checkComponentEnd(didURL, fragmentEnd, ""); err != nil {
+			return nil, err
+		}
+
+		ret.RawFragment = didURL[i+1:]
+		ret.Fragment, _ = url.PathUnescape(ret.RawFragment) //nolint:errcheck // validated by the scan
 	}
 
 	return ret, nil
 }
+
+func newDID(did string, methodEnd, end int) *DID {
+	return &DID{
+		Scheme:           "did",
+		Method:           did[len(didPrefix):methodEnd],
+		MethodSpecificID: did[methodEnd+1 : end],
+	}
+}
+
+// scanDID scans the DID at the beginning of s, and returns the offsets of the colon following the method name
+// and of the end of the DID.
+func scanDID(s string) (int, int, error) {
+	if !strings.HasPrefix(s, didPrefix) {
+		return 0, 0, syntaxError(s, 0, `missing "did:" scheme`)
+	}
+
+	i := len(didPrefix)
+	for i < len(s) && isMethodChar(s[i]) {
+		i++
+	}
+
+	switch {
+	case i == len(didPrefix) && (i == len(s) || s[i] == ':'):
+		return 0, 0, syntaxError(s, i, "empty method name")
+	case i == len(s):
+		return 0, 0, syntaxError(s, i, "missing method-specific-id")
+	case s[i] != ':':
+		return 0, 0, syntaxError(s, i, "invalid character in method name")
+	}
+
+	methodEnd := i
+	last := byte(':')
+
+	for i++; i < len(s); i++ {
+		c := s[i]
+
+		if c == '%' {
+			if !isPercentEncoded(s, i) {
+				return 0, 0, syntaxError(s, i, "invalid percent-encoding")
+			}
+
+			i += 2
+		} else if c != ':' && !isIDChar(c) {
+			break
+		}
+
+		last = c
+	}
+
+	if last == ':' {
+		return 0, 0, syntaxError(s, i, "method-specific-id must end with an idchar")
+	}
+
+	return methodEnd, i, nil
+}
+
+// scanURLComponent scans the characters of a path, query or fragment from offset i, and returns the offset of
+// the first character which is neither allowed nor part of a valid percent-encoded triplet.
+func scanURLComponent(s string, i int, allowed func(c byte) bool) int {
+	for i < len(s) {
+		switch {
+		case s[i] == '%' && isPercentEncoded(s, i):
+			i += 3
+		case s[i] != '%' && allowed(s[i]):
+			i++
+		default:
+			return i
+		}
+	}
+
+	return i
+}
+
+// checkComponentEnd checks that the URL component ending at offset i is followed by one of the delimiters of
+// the next components, or ends the string.
+func checkComponentEnd(s string, i int, delimiters string) error {
+	switch {
+	case i == len(s) || strings.IndexByte(delimiters, s[i]) >= 0:
+		return nil
+	case s[i] == '%':
+		return syntaxError(s, i, "invalid percent-encoding")
+	default:
+		return syntaxError(s, i, "invalid character")
+	}
+}
+
+// parseQuery parses a validated query string.
+func parseQuery(query string) map[string][]string {
+	values := map[string][]string{}
+
+	for _, pair := range strings.Split(query, "&") {
+		if pair == "" {
+			continue
+		}
+
+		key, value := pair, ""
+		if eq := strings.IndexByte(pair, '='); eq >= 0 {
+			key, value = pair[:eq], pair[eq+1:]
+		}
+
+		// percent-encoding is validated by the scan.
+		key, _ = url.QueryUnescape(key)     //nolint:errcheck
+		value, _ = url.QueryUnescape(value) //nolint:errcheck
+
+		values[key] = append(values[key], value)
+	}
+
+	return values
+}
+
+// normalizePercentEncoding upper cases the hexadecimal digits of the percent-encoded triplets of a validated
+// string, and decodes those encoding a character for which unencoded returns true.
+func normalizePercentEncoding(s string, unencoded func(c byte) bool) string {
+	if strings.IndexByte(s, '%') < 0 {
+		return s
+	}
+
+	var b strings.Builder
+
+	b.Grow(len(s))
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			b.WriteByte(s[i])
+
+			continue
+		}
+
+		if c := unhex(s[i+1])<<4 | unhex(s[i+2]); unencoded(c) {
+			b.WriteByte(c)
+		} else {
+			b.WriteByte('%')
+			b.WriteByte(upper(s[i+1]))
+			b.WriteByte(upper(s[i+2]))
+		}
+
+		i += 2
+	}
+
+	return b.String()
+}
+
+func syntaxError(s string, offset int, reason string) error {
+	return fmt.Errorf("%w %q: %s at offset %d", ErrInvalidDID, s, reason, offset)
+}
+
+func isPercentEncoded(s string, i int) bool {
+	return i+2 < len(s) && isHexDigit(s[i+1]) && isHexDigit(s[i+2])
+}
+
+func isMethodChar(c byte) bool {
+	return 'a' <= c && c <= 'z' || isDigit(c)
+}
+
+func isIDChar(c byte) bool {
+	return isAlpha(c) || isDigit(c) || c == '.' || c == '-' || c == '_'
+}
+
+// isUnreserved tells whether c is an unreserved character of RFC 3986.
+func isUnreserved(c byte) bool {
+	return isIDChar(c) || c == '~'
+}
+
+// isPathChar tells whether c may appear unencoded in path-abempty.
+func isPathChar(c byte) bool {
+	return isUnreserved(c) || strings.IndexByte("!$&'()*+,;=:@/", c) >= 0
+}
+
+// isQueryChar tells whether c may appear unencoded in a query or fragment.
+func isQueryChar(c byte) bool {
+	return isPathChar(c) || c == '?'
+}
+
+func isAlpha(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func unhex(c byte) byte {
+	switch {
+	case isDigit(c):
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
+
+func upper(c byte) byte {
+	if 'a' <= c && c <= 'f' {
+		return c - 'a' + 'A'
+	}
+
+	return c
+}
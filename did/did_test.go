@@ -0,0 +1,41 @@
+package did
+
+import "testing"
+
+const (
+	benchmarkDID    = "did:example:123456789abcdefghi"
+	benchmarkDIDURL = "did:example:123456789abcdefghi/path/to/resource?service=agent&relativeRef=%2Fcredentials" +
+		"&versionTime=2021-05-10T17:00:00Z#key-1"
+)
+
+func BenchmarkParse(b *testing.B) {
+	for _, input := range []string{benchmarkDID, "did:web:example.com%3A8443:user:alice"} {
+		input := input
+
+		b.Run(input, func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				if _, err := Parse(input); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkParseDIDURL(b *testing.B) {
+	for _, bc := range []struct{ name, input string }{{"DID", benchmarkDID}, {"full URL", benchmarkDIDURL}} {
+		bc := bc
+
+		b.Run(bc.name, func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				if _, err := ParseDIDURL(bc.input); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
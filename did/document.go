@@ -18,8 +18,10 @@ import (
 	"github.com/btcsuite/btcutil/base58"
 	"github.com/multiformats/go-multibase"
 	"github.com/xeipuuv/gojsonschema"
+	"github.com/zRich/zFusion/common/crypto/jwk"
 	"github.com/zRich/zFusion/common/logging"
 	"github.com/zRich/zFusion/common/model"
+	"github.com/zRich/zFusion/common/multicodec"
 )
 
 const (
@@ -46,6 +48,9 @@ const (
 	jsonldDomain         = "domain"
 	jsonldNonce          = "nonce"
 	jsonldProofPurpose   = "proofPurpose"
+	jsonldJWS            = "jws"
+
+	jsonldVerificationMethod = "verificationMethod"
 
 	// various public key encodings.
 	jsonldPublicKeyBase58    = "publicKeyBase58"
@@ -85,6 +90,8 @@ type MethodMetadata struct {
 	UpdateCommitment string `json:"updateCommitment,omitempty"`
 	// RecoveryCommitment is recovery commitment key.
 	RecoveryCommitment string `json:"recoveryCommitment,omitempty"`
+	// PreviousOperation is the hash of the latest operation of DIDs without commitments.
+	PreviousOperation string `json:"previousOperation,omitempty"`
 	// Published is published key.
 	Published bool `json:"published,omitempty"`
 	// AnchorOrigin is anchor origin.
@@ -125,6 +132,12 @@ type DocumentMetadata struct {
 	CanonicalID string `json:"canonicalId,omitempty"`
 	// EquivalentID is equivalent ID array.
 	EquivalentID []string `json:"equivalentId,omitempty"`
+	// Created is the timestamp of the Create operation.
+	Created *time.Time `json:"created,omitempty"`
+	// Updated is the timestamp of the last Update operation of the resolved document version.
+	Updated *time.Time `json:"updated,omitempty"`
+	// NextVersionID is the version ID of the next version of the resolved document, if any.
+	NextVersionID string `json:"nextVersionId,omitempty"`
 	// Method is used for method metadata within did document metadata.
 	Method *MethodMetadata `json:"method,omitempty"`
 }
@@ -198,10 +211,11 @@ type VerificationMethod struct {
 
 	Value []byte
 
-	// jsonWebKey        *jwk.JWK
-	jsonWebKey        []byte
+	jsonWebKey        *jwk.JWK
 	relativeURL       bool
 	multibaseEncoding multibase.Encoding
+	// multicodec is the multicodec prefix of the publicKeyMultibase key, which is stripped from Value.
+	multicodec multicodec.Code
 }
 
 // NewVerificationMethodFromBytesWithMultibase creates a new VerificationMethod based on
@@ -244,31 +258,55 @@ func NewVerificationMethodFromBytes(id, keyType, controller string, value []byte
 }
 
 // NewVerificationMethodFromJWK creates a new VerificationMethod based on JSON Web Key.
-// func NewVerificationMethodFromJWK(id, keyType, controller string, j *jwk.JWK) (*VerificationMethod, error) {
-// 	pkBytes, err := j.PublicKeyBytes()
-// 	if err != nil {
-// 		return nil, fmt.Errorf("convert JWK to public key bytes: %w", err)
-// 	}
-
-// 	relativeURL := false
-// 	if strings.HasPrefix(id, "#") {
-// 		relativeURL = true
-// 	}
-
-// 	return &VerificationMethod{
-// 		ID:          id,
-// 		Type:        keyType,
-// 		Controller:  controller,
-// 		Value:       pkBytes,
-// 		jsonWebKey:  j,
-// 		relativeURL: relativeURL,
-// 	}, nil
-// }
+func NewVerificationMethodFromJWK(id, keyType, controller string, j *jwk.JWK) (*VerificationMethod, error) {
+	pkBytes, err := j.PublicKeyBytes()
+	if err != nil {
+		return nil, fmt.Errorf("convert JWK to public key bytes: %w", err)
+	}
+
+	relativeURL := false
+	if strings.HasPrefix(id, "#") {
+		relativeURL = true
+	}
+
+	return &VerificationMethod{
+		ID:          id,
+		Type:        keyType,
+		Controller:  controller,
+		Value:       pkBytes,
+		jsonWebKey:  j,
+		relativeURL: relativeURL,
+	}, nil
+}
+
+// NewMultikey creates a new Multikey verification method from a raw public key of the key type of code.
+// The key is encoded with its multicodec prefix in base58btc publicKeyMultibase.
+func NewMultikey(id, controller string, code multicodec.Code, value []byte) (*VerificationMethod, error) {
+	if _, err := multicodec.Encode(code, value); err != nil {
+		return nil, fmt.Errorf("new Multikey: %w", err)
+	}
+
+	return &VerificationMethod{
+		ID:                id,
+		Type:              Multikey,
+		Controller:        controller,
+		Value:             value,
+		relativeURL:       strings.HasPrefix(id, "#"),
+		multibaseEncoding: multibase.Base58BTC,
+		multicodec:        code,
+	}, nil
+}
+
+// Multicodec returns the multicodec code of a verification method whose publicKeyMultibase key is multicodec
+// prefixed.
+func (pk *VerificationMethod) Multicodec() (multicodec.Code, bool) {
+	return pk.multicodec, pk.multicodec != 0
+}
 
 // JSONWebKey returns JSON Web key if defined.
-// func (pk *VerificationMethod) JSONWebKey() *jwk.JWK {
-// 	return pk.jsonWebKey
-// }
+func (pk *VerificationMethod) JSONWebKey() *jwk.JWK {
+	return pk.jsonWebKey
+}
 
 // Service DID doc service.
 type Service struct {
@@ -356,6 +394,7 @@ type Proof struct {
 	Created      *time.Time
 	Creator      string
 	ProofValue   []byte
+	JWS          string
 	Domain       string
 	Nonce        []byte
 	ProofPurpose string
@@ -374,8 +413,38 @@ func (doc *Document) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
-// ParseDocument creates an instance of DIDDocument by reading a JSON document from bytes.
-func ParseDocument(data []byte) (*Document, error) {
+// ParseOption configures ParseDocument.
+type ParseOption func(opts *parseOpts)
+
+type parseOpts struct {
+	strict bool
+	legacy bool
+}
+
+// WithStrictValidation rejects documents with findings of error severity, see Document.Validate.
+func WithStrictValidation() ParseOption {
+	return func(opts *parseOpts) {
+		opts.strict = true
+	}
+}
+
+// WithLegacyHandling accepts the documents of agents which declare the old V1 context but use the v0.11
+// shapes, such as the documents serialized by aca-py (ProfileACAPy). Such documents are parsed as v0.11
+// documents, without JSON schema validation.
+func WithLegacyHandling() ParseOption {
+	return func(opts *parseOpts) {
+		opts.legacy = true
+	}
+}
+
+// ParseDocument creates an instance of DIDDocument by reading a JSON document from bytes. The document is
+// validated against the JSON schema of its context, and in strict mode by Document.Validate.
+func ParseDocument(data []byte, opts ...ParseOption) (*Document, error) {
+	parseOptions := &parseOpts{}
+	for _, opt := range opts {
+		opt(parseOptions)
+	}
+
 	raw := &rawDoc{}
 
 	err := json.Unmarshal(data, &raw)
@@ -387,7 +456,7 @@ func ParseDocument(data []byte) (*Document, error) {
 
 	// Interop: handle legacy did docs that incorrectly indicate they use the new format
 	// aca-py issue: https://github.com/hyperledger/aries-cloudagent-python/issues/1048
-	if doACAPYInterop && requiresLegacyHandling(raw) {
+	if parseOptions.legacy && requiresLegacyHandling(raw) {
 		raw.Context = []string{contextV011}
 	} else {
 		// validate did document
@@ -435,6 +504,12 @@ func ParseDocument(data []byte) (*Document, error) {
 
 	doc.Proof = proofs
 
+	if parseOptions.strict {
+		if err := doc.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
 	return doc, nil
 }
 
@@ -506,10 +581,14 @@ func populateProofs(context, didID, baseURI string, rawProofs []interface{}) ([]
 			proofKey = jsonldSignatureValue
 		}
 
-		proofValue, err := DecodeProofValue(stringEntry(emap[proofKey]), stringEntry(emap[jsonldType]))
+		var proofValue []byte
 
-		if err != nil {
-			return nil, errors.New("unsupported encoding")
+		// JWS proofs carry their signature in the jws property instead.
+		if _, ok := emap[jsonldJWS]; !ok || emap[proofKey] != nil {
+			proofValue, err = DecodeProofValue(stringEntry(emap[proofKey]), stringEntry(emap[jsonldType]))
+			if err != nil {
+				return nil, errors.New("unsupported encoding")
+			}
 		}
 
 		nonce, err := base64.RawURLEncoding.DecodeString(stringEntry(emap[jsonldNonce]))
@@ -531,6 +610,7 @@ func populateProofs(context, didID, baseURI string, rawProofs []interface{}) ([]
 			Created:      &timeValue,
 			Creator:      creator,
 			ProofValue:   proofValue,
+			JWS:          stringEntry(emap[jsonldJWS]),
 			ProofPurpose: stringEntry(emap[jsonldProofPurpose]),
 			Domain:       stringEntry(emap[jsonldDomain]),
 			Nonce:        nonce,
@@ -798,6 +878,14 @@ func decodeVM(vm *VerificationMethod, rawPK map[string]interface{}) error {
 		vm.Value = value
 		vm.multibaseEncoding = multibaseEncoding
 
+		// raw keys never decode as multicodec prefixed keys, whose size is checked against the codec
+		if code, key, err := multicodec.Decode(value); err == nil {
+			vm.Value = key
+			vm.multicodec = code
+		} else if vm.Type == Multikey {
+			return fmt.Errorf("invalid Multikey %s: %w", vm.ID, err)
+		}
+
 		return nil
 	}
 
@@ -823,45 +911,41 @@ func decodeVM(vm *VerificationMethod, rawPK map[string]interface{}) error {
 		return nil
 	}
 
-	// if jwkMap := mapEntry(rawPK[jsonldPublicKeyjwk]); jwkMap != nil {
-	// 	return decodeVMJwk(jwkMap, vm)
-	// }
-
 	if jwkMap := mapEntry(rawPK[jsonldPublicKeyjwk]); jwkMap != nil {
-		return nil
+		return decodeVMJwk(jwkMap, vm)
 	}
 
 	return errors.New("public key encoding not supported")
 }
 
-// func decodeVMJwk(jwkMap map[string]interface{}, vm *VerificationMethod) error {
-// 	jwkBytes, err := json.Marshal(jwkMap)
-// 	if err != nil {
-// 		return fmt.Errorf("failed to marshal '%s', cause: %w ", jsonldPublicKeyjwk, err)
-// 	}
+func decodeVMJwk(jwkMap map[string]interface{}, vm *VerificationMethod) error {
+	jwkBytes, err := json.Marshal(jwkMap)
+	if err != nil {
+		return fmt.Errorf("failed to marshal '%s', cause: %w ", jsonldPublicKeyjwk, err)
+	}
 
-// 	if string(jwkBytes) == "{}" {
-// 		vm.Value = []byte("")
-// 		return nil
-// 	}
+	if string(jwkBytes) == "{}" {
+		vm.Value = []byte("")
+		return nil
+	}
 
-// 	var j jwk.JWK
+	var j jwk.JWK
 
-// 	err = json.Unmarshal(jwkBytes, &j)
-// 	if err != nil {
-// 		return fmt.Errorf("unmarshal JWK: %w", err)
-// 	}
+	err = json.Unmarshal(jwkBytes, &j)
+	if err != nil {
+		return fmt.Errorf("unmarshal JWK: %w", err)
+	}
 
-// 	pkBytes, err := j.PublicKeyBytes()
-// 	if err != nil {
-// 		return fmt.Errorf("failed to decode public key from JWK: %w", err)
-// 	}
+	pkBytes, err := j.PublicKeyBytes()
+	if err != nil {
+		return fmt.Errorf("failed to decode public key from JWK: %w", err)
+	}
 
-// 	vm.Value = pkBytes
-// 	vm.jsonWebKey = &j
+	vm.Value = pkBytes
+	vm.jsonWebKey = &j
 
-// 	return nil
-// }
+	return nil
+}
 
 func parseContext(context Context) (Context, string) {
 	context = ContextCopy(context)
@@ -1018,56 +1102,71 @@ func (docResolution *DocResolution) JSONBytes() ([]byte, error) {
 	return byteDoc, nil
 }
 
-// JSONBytes converts document to json bytes.
-func (doc *Document) JSONBytes() ([]byte, error) {
-	context, ok := ContextPeekString(doc.Context)
-	if !ok {
-		context = ContextV1
+// JSONBytes converts document to json bytes. The document is serialized as specified by DID Core unless another
+// profile is selected with WithProfile.
+func (doc *Document) JSONBytes(opts ...SerializeOption) ([]byte, error) {
+	serializeOptions := &serializeOpts{profile: ProfileDefault}
+	for _, opt := range opts {
+		opt(serializeOptions)
+	}
+
+	profile := serializeOptions.profile
+
+	context, err := profile.context(doc)
+	if err != nil {
+		return nil, err
 	}
 
-	aka := populateRawAlsoKnownAs(doc.AlsoKnownAs)
+	populateVerification := profile.verificationPopulator()
 
 	vm, err := populateRawVM(context, doc.ID, doc.processingMeta.baseURI, doc.VerificationMethod)
 	if err != nil {
 		return nil, fmt.Errorf("JSON unmarshalling of Verification Method failed: %w", err)
 	}
 
-	auths, err := populateRawVerification(context, doc.processingMeta.baseURI, doc.ID, doc.Authentication)
+	auths, err := populateVerification(context, doc.processingMeta.baseURI, doc.ID, doc.Authentication)
 	if err != nil {
 		return nil, fmt.Errorf("JSON unmarshalling of Authentication failed: %w", err)
 	}
 
-	assertionMethods, err := populateRawVerification(context, doc.processingMeta.baseURI, doc.ID,
+	assertionMethods, err := populateVerification(context, doc.processingMeta.baseURI, doc.ID,
 		doc.AssertionMethod)
 	if err != nil {
 		return nil, fmt.Errorf("JSON unmarshalling of AssertionMethod failed: %w", err)
 	}
 
-	capabilityDelegations, err := populateRawVerification(context, doc.processingMeta.baseURI, doc.ID,
+	capabilityDelegations, err := populateVerification(context, doc.processingMeta.baseURI, doc.ID,
 		doc.CapabilityDelegation)
 	if err != nil {
 		return nil, fmt.Errorf("JSON unmarshalling of CapabilityDelegation failed: %w", err)
 	}
 
-	capabilityInvocations, err := populateRawVerification(context, doc.processingMeta.baseURI, doc.ID,
+	capabilityInvocations, err := populateVerification(context, doc.processingMeta.baseURI, doc.ID,
 		doc.CapabilityInvocation)
 	if err != nil {
 		return nil, fmt.Errorf("JSON unmarshalling of CapabilityInvocation failed: %w", err)
 	}
 
-	keyAgreements, err := populateRawVerification(context, doc.processingMeta.baseURI, doc.ID, doc.KeyAgreement)
+	keyAgreements, err := populateVerification(context, doc.processingMeta.baseURI, doc.ID, doc.KeyAgreement)
 	if err != nil {
 		return nil, fmt.Errorf("JSON unmarshalling of KeyAgreement failed: %w", err)
 	}
 
+	services, err := profile.services(doc)
+	if err != nil {
+		return nil, fmt.Errorf("JSON unmarshalling of Service failed: %w", err)
+	}
+
 	raw := &rawDoc{
-		Context: doc.Context, ID: doc.ID, AlsoKnownAs: aka, VerificationMethod: vm,
+		Context: doc.Context, ID: doc.ID, AlsoKnownAs: populateRawAlsoKnownAs(doc.AlsoKnownAs), VerificationMethod: vm,
 		Authentication: auths, AssertionMethod: assertionMethods, CapabilityDelegation: capabilityDelegations,
 		CapabilityInvocation: capabilityInvocations, KeyAgreement: keyAgreements,
-		Service: populateRawServices(doc.Service, doc.ID, doc.processingMeta.baseURI), Created: doc.Created,
+		Service: services, Created: doc.Created,
 		Proof: populateRawProofs(context, doc.ID, doc.processingMeta.baseURI, doc.Proof), Updated: doc.Updated,
 	}
 
+	profile.shape(raw, context)
+
 	if doc.processingMeta.baseURI != "" {
 		raw.Context = contextWithBase(doc)
 	}
@@ -1109,25 +1208,6 @@ func (doc *Document) MarshalJSON() ([]byte, error) {
 	return doc.JSONBytes()
 }
 
-// VerifyProof verifies document proofs.
-// func (doc *Doc) VerifyProof(suites []verifier.SignatureSuite, jsonldOpts ...jsonld.ProcessorOpts) error {
-// 	if len(doc.Proof) == 0 {
-// 		return ErrProofNotFound
-// 	}
-
-// 	docBytes, err := doc.JSONBytes()
-// 	if err != nil {
-// 		return err
-// 	}
-
-// 	v, err := verifier.New(&didKeyResolver{doc.VerificationMethod}, suites...)
-// 	if err != nil {
-// 		return fmt.Errorf("create verifier: %w", err)
-// 	}
-
-// 	return v.Verify(docBytes, jsonldOpts...)
-// }
-
 // VerificationMethods returns verification methods of DID Doc of certain relationship.
 // If customVerificationRelationships is empty, all verification methods are returned.
 // Public keys which are not referred by any verification method are put into special VerificationRelationshipGeneral
@@ -1192,25 +1272,6 @@ func (doc *Document) VerificationMethods(customVerificationRelationships ...Veri
 // ErrProofNotFound is returned when proof is not found.
 var ErrProofNotFound = errors.New("proof not found")
 
-// didKeyResolver implements public key resolution for DID public keys.
-type didKeyResolver struct {
-	PubKeys []VerificationMethod
-}
-
-// func (r *didKeyResolver) Resolve(id string) (*verifier.PublicKey, error) {
-// 	for _, key := range r.PubKeys {
-// 		if key.ID == id {
-// 			return &verifier.PublicKey{
-// 				Type:  key.Type,
-// 				Value: key.Value,
-// 				JWK:   key.jsonWebKey,
-// 			}, nil
-// 		}
-// 	}
-
-// 	return nil, ErrKeyNotFound
-// }
-
 // ErrKeyNotFound is returned when key is not found.
 var ErrKeyNotFound = errors.New("key not found")
 
@@ -1369,10 +1430,18 @@ func populateRawVerificationMethod(context, didID, baseURI string,
 		}
 
 		rawVM[jsonldPublicKeyjwk] = json.RawMessage(jwkBytes)
-	} else if vm.Type == "Ed25519VerificationKey2020" {
+	} else if vm.Type == "Ed25519VerificationKey2020" || vm.multicodec != 0 {
+		value := vm.Value
+
 		var err error
 
-		rawVM[jsonldPublicKeyMultibase], err = multibase.Encode(vm.multibaseEncoding, vm.Value)
+		if vm.multicodec != 0 {
+			if value, err = multicodec.Encode(vm.multicodec, vm.Value); err != nil {
+				return nil, err
+			}
+		}
+
+		rawVM[jsonldPublicKeyMultibase], err = multibase.Encode(vm.multibaseEncoding, value)
 		if err != nil {
 			return nil, err
 		}
@@ -1422,16 +1491,22 @@ func populateRawProofs(context, didID, baseURI string, proofs []Proof) []interfa
 			creator = makeRelativeDIDURL(p.Creator, baseURI, didID)
 		}
 
-		rawProofs = append(rawProofs, map[string]interface{}{
-			jsonldType:    p.Type,
-			jsonldCreated: p.Created,
-			jsonldCreator: creator,
-			// k:                  sigproof.EncodeProofValue(p.ProofValue, p.Type),
-			k:                  EncodeProofValue(p.ProofValue, p.Type),
+		rawProof := map[string]interface{}{
+			jsonldType:         p.Type,
+			jsonldCreated:      p.Created,
+			jsonldCreator:      creator,
 			jsonldDomain:       p.Domain,
 			jsonldNonce:        base64.RawURLEncoding.EncodeToString(p.Nonce),
 			jsonldProofPurpose: p.ProofPurpose,
-		})
+		}
+
+		if p.JWS != "" {
+			rawProof[jsonldJWS] = p.JWS
+		} else {
+			rawProof[k] = EncodeProofValue(p.ProofValue, p.Type)
+		}
+
+		rawProofs = append(rawProofs, rawProof)
 	}
 
 	return rawProofs
@@ -1468,6 +1543,22 @@ func WithKeyAgreement(keyAgreement []Verification) DocOption {
 	}
 }
 
+// WithCapabilityDelegation sets the verification methods for capability delegation:
+// https://w3c.github.io/did-core/#capability-delegation.
+func WithCapabilityDelegation(capabilityDelegation []Verification) DocOption {
+	return func(opts *Document) {
+		opts.CapabilityDelegation = capabilityDelegation
+	}
+}
+
+// WithCapabilityInvocation sets the verification methods for capability invocation:
+// https://w3c.github.io/did-core/#capability-invocation.
+func WithCapabilityInvocation(capabilityInvocation []Verification) DocOption {
+	return func(opts *Document) {
+		opts.CapabilityInvocation = capabilityInvocation
+	}
+}
+
 // WithService DID doc services.
 func WithService(svc []Service) DocOption {
 	return func(opts *Document) {
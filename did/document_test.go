@@ -8,6 +8,7 @@ package did
 
 import (
 	"crypto/ed25519"
+	"crypto/rand"
 	_ "embed"
 	"encoding/base64"
 	"encoding/hex"
@@ -15,11 +16,14 @@ import (
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/btcsuite/btcutil/base58"
 	"github.com/stretchr/testify/require"
+	"github.com/zRich/zFusion/common/crypto"
+	"github.com/zRich/zFusion/common/crypto/jwk"
 	"github.com/zRich/zFusion/common/model"
 )
 
@@ -98,7 +102,7 @@ func TestValidWithDocBase(t *testing.T) {
 					ID:          "did:example:123456789abcdefghi#keys-1",
 					Type:        "Secp256k1VerificationKey2018",
 					Controller:  "did:example:123456789abcdefghi",
-					Value:       base58.Decode("H3C2AVvLMv6gmMNam3uVAjZpfkcJCwDwnZn6z3wXmqPV"),
+					Value:       base58.Decode("28577HQhHssBvUxSaMFY8JZ5uS2GkSSaGpAnrmEJcCSNL"),
 					relativeURL: true,
 				},
 				Relationship: Authentication,
@@ -107,7 +111,7 @@ func TestValidWithDocBase(t *testing.T) {
 				VerificationMethod: VerificationMethod{
 					ID:          "did:example:123456789abcdefghi#key3",
 					Controller:  "did:example:123456789abcdefghi",
-					Type:        "RsaVerificationKey2018",
+					Type:        "EcdsaSecp256k1VerificationKey2019",
 					Value:       hexDecodeValue,
 					relativeURL: true,
 				},
@@ -122,7 +126,7 @@ func TestValidWithDocBase(t *testing.T) {
 				ID:          "did:example:123456789abcdefghi#keys-1",
 				Controller:  "did:example:123456789abcdefghi",
 				Type:        "Secp256k1VerificationKey2018",
-				Value:       base58.Decode("H3C2AVvLMv6gmMNam3uVAjZpfkcJCwDwnZn6z3wXmqPV"),
+				Value:       base58.Decode("28577HQhHssBvUxSaMFY8JZ5uS2GkSSaGpAnrmEJcCSNL"),
 				relativeURL: true,
 			},
 			{
@@ -365,11 +369,11 @@ func TestValid(t *testing.T) {
 			{VerificationMethod: *NewVerificationMethodFromBytes("did:example:123456789abcdefghi#keys-1",
 				"Secp256k1VerificationKey2018",
 				"did:example:123456789abcdefghi",
-				base58.Decode("H3C2AVvLMv6gmMNam3uVAjZpfkcJCwDwnZn6z3wXmqPV")), Relationship: Authentication},
+				base58.Decode("28577HQhHssBvUxSaMFY8JZ5uS2GkSSaGpAnrmEJcCSNL")), Relationship: Authentication},
 			{VerificationMethod: VerificationMethod{
 				ID:         "did:example:123456789abcdefghs#key3",
 				Controller: "did:example:123456789abcdefghs",
-				Type:       "RsaVerificationKey2018",
+				Type:       "EcdsaSecp256k1VerificationKey2019",
 				Value:      hexDecodeValue,
 			}, Relationship: Authentication, Embedded: true},
 		}
@@ -381,7 +385,7 @@ func TestValid(t *testing.T) {
 				ID:         "did:example:123456789abcdefghi#keys-1",
 				Controller: "did:example:123456789abcdefghi",
 				Type:       "Secp256k1VerificationKey2018",
-				Value:      base58.Decode("H3C2AVvLMv6gmMNam3uVAjZpfkcJCwDwnZn6z3wXmqPV"),
+				Value:      base58.Decode("28577HQhHssBvUxSaMFY8JZ5uS2GkSSaGpAnrmEJcCSNL"),
 			},
 			{
 				ID:         "did:example:123456789abcdefghw#key2",
@@ -1213,8 +1217,7 @@ func TestRequiresLegacyHandling(t *testing.T) {
 
 func TestJSONConversion(t *testing.T) {
 	docs := []string{
-		// validDoc, validDocV011, validDocWithProofAndJWK, docV011WithVerificationRelationships, validDocWithBase,
-		validDoc, validDocV011, docV011WithVerificationRelationships, validDocWithBase,
+		validDoc, validDocV011, validDocWithProofAndJWK, docV011WithVerificationRelationships, validDocWithBase,
 	}
 	for _, d := range docs {
 		// setup -> create Document from json byte data
@@ -1245,8 +1248,7 @@ func TestJSONConversion(t *testing.T) {
 
 func TestMarshalJSON(t *testing.T) {
 	docs := []string{
-		// validDoc, validDocV011, validDocWithProofAndJWK, docV011WithVerificationRelationships, validDocWithBase,
-		validDoc, validDocV011, docV011WithVerificationRelationships, validDocWithBase,
+		validDoc, validDocV011, validDocWithProofAndJWK, docV011WithVerificationRelationships, validDocWithBase,
 	}
 	for _, d := range docs {
 		// setup -> create Document from json byte data
@@ -1285,174 +1287,158 @@ func TestUnmarshalJSON(t *testing.T) {
 	})
 }
 
-// func TestNewPublicKeyFromJWK(t *testing.T) {
-// 	pubKey, _, err := ed25519.GenerateKey(rand.Reader)
-// 	require.NoError(t, err)
-
-// 	j := &jwk.JWK{
-// 		JSONWebKey: gojose.JSONWebKey{
-// 			Key:   pubKey,
-// 			KeyID: "_Qq0UL2Fq651Q0Fjd6TvnYE-faHiOpRlPVQcY_-tA4A",
-// 		},
-// 	}
-
-// 	// Success.
-// 	signingKey, err := NewVerificationMethodFromJWK(creator, keyType, did, j)
-// 	require.NoError(t, err)
-// 	require.Equal(t, j, signingKey.JSONWebKey())
-// 	require.Equal(t, []byte(pubKey), signingKey.Value)
-
-// 	// Error - invalid JWK.
-// 	j = &jwk.JWK{
-// 		JSONWebKey: gojose.JSONWebKey{
-// 			Key:   nil,
-// 			KeyID: "_Qq0UL2Fq651Q0Fjd6TvnYE-faHiOpRlPVQcY_-tA4A",
-// 		},
-// 	}
-// 	signingKey, err = NewVerificationMethodFromJWK(creator, keyType, did, j)
-// 	require.Error(t, err)
-// 	require.Contains(t, err.Error(), "convert JWK to public key bytes")
-// 	require.Nil(t, signingKey)
-// }
-
-// func TestJSONWebKey(t *testing.T) {
-// 	const didContext = "https://w3id.org/did/v1"
-
-// 	pubKey, _, err := ed25519.GenerateKey(rand.Reader)
-// 	require.NoError(t, err)
-
-// 	j := &jwk.JWK{
-// 		JSONWebKey: gojose.JSONWebKey{
-// 			Key:   pubKey,
-// 			KeyID: "_Qq0UL2Fq651Q0Fjd6TvnYE-faHiOpRlPVQcY_-tA4A",
-// 		},
-// 	}
-
-// 	signingKey, err := NewVerificationMethodFromJWK(creator, keyType, did, j)
-// 	require.NoError(t, err)
-// 	require.Equal(t, j, signingKey.JSONWebKey())
-
-// 	createdTime := time.Now()
-
-// 	didDoc := &Doc{
-// 		Context:            []string{didContext},
-// 		ID:                 did,
-// 		VerificationMethod: []VerificationMethod{*signingKey},
-// 		Created:            &createdTime,
-// 		Updated:            &createdTime,
-// 	}
-
-// 	didDocBytes, err := didDoc.JSONBytes()
-// 	require.NoError(t, err)
-
-// 	parsedDidDoc, err := ParseDocument(didDocBytes)
-// 	require.NoError(t, err)
-
-// 	parsedDidDocBytes, err := parsedDidDoc.JSONBytes()
-// 	require.NoError(t, err)
-// 	require.Equal(t, didDocBytes, parsedDidDocBytes)
-// }
-
-// func TestVerifyProof(t *testing.T) {
-// 	docs := []string{validDoc, validDocV011}
-// 	for _, d := range docs {
-// 		pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
-// 		if err != nil {
-// 			panic(err)
-// 		}
-
-// 		signedDoc := createSignedDidDocument(t, privKey, pubKey)
-
-// 		s := ed25519signature2018.New(suite.WithVerifier(ed25519signature2018.NewPublicKeyVerifier()))
-
-// 		// happy path - valid signed document
-// 		doc, err := ParseDocument(signedDoc)
-// 		require.Nil(t, err)
-// 		require.NotNil(t, doc)
-// 		err = doc.VerifyProof([]verifier.SignatureSuite{s}, ldtestutil.WithDocumentLoader(t))
-// 		require.NoError(t, err)
-
-// 		// error - no suites are passed, verifier is not created
-// 		err = doc.VerifyProof([]verifier.SignatureSuite{}, ldtestutil.WithDocumentLoader(t))
-// 		require.Error(t, err)
-// 		require.Contains(t, err.Error(), "create verifier")
-
-// 		// error - doc with invalid proof value
-// 		doc.Proof[0].ProofValue = []byte("invalid")
-// 		err = doc.VerifyProof([]verifier.SignatureSuite{s}, ldtestutil.WithDocumentLoader(t))
-// 		require.NotNil(t, err)
-// 		require.Contains(t, err.Error(), "ed25519: invalid signature")
-
-// 		// error - doc with no proof
-// 		doc, err = ParseDocument([]byte(d))
-// 		require.NoError(t, err)
-// 		require.NotNil(t, doc)
-// 		err = doc.VerifyProof([]verifier.SignatureSuite{s}, ldtestutil.WithDocumentLoader(t))
-// 		require.Equal(t, ErrProofNotFound, err)
-// 		require.Contains(t, err.Error(), "proof not found")
-// 	}
-// }
-
-// func TestVerifyProofWithEd25519signature2020(t *testing.T) {
-// 	docs := []string{validDoc}
-// 	for _, d := range docs {
-// 		pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
-// 		if err != nil {
-// 			panic(err)
-// 		}
-
-// 		signedDoc := createSignedDidDocumentWithEd25519signature2020(t, privKey, pubKey)
-
-// 		s := ed25519signature2020.New(suite.WithVerifier(ed25519signature2020.NewPublicKeyVerifier()))
-
-// 		// happy path - valid signed document
-// 		doc, err := ParseDocument(signedDoc)
-// 		require.Nil(t, err)
-// 		require.NotNil(t, doc)
-// 		err = doc.VerifyProof([]verifier.SignatureSuite{s}, ldtestutil.WithDocumentLoader(t))
-// 		require.NoError(t, err)
-
-// 		// error - no suites are passed, verifier is not created
-// 		err = doc.VerifyProof([]verifier.SignatureSuite{}, ldtestutil.WithDocumentLoader(t))
-// 		require.Error(t, err)
-// 		require.Contains(t, err.Error(), "create verifier")
-
-// 		// error - doc with invalid proof value
-// 		doc.Proof[0].ProofValue = []byte("invalid")
-// 		err = doc.VerifyProof([]verifier.SignatureSuite{s}, ldtestutil.WithDocumentLoader(t))
-// 		require.NotNil(t, err)
-// 		require.Contains(t, err.Error(), "ed25519: invalid signature")
-
-// 		// error - doc with no proof
-// 		doc, err = ParseDocument([]byte(d))
-// 		require.NoError(t, err)
-// 		require.NotNil(t, doc)
-// 		err = doc.VerifyProof([]verifier.SignatureSuite{s}, ldtestutil.WithDocumentLoader(t))
-// 		require.Equal(t, ErrProofNotFound, err)
-// 		require.Contains(t, err.Error(), "proof not found")
-// 	}
-// }
-
-// func TestDidKeyResolver_Resolve(t *testing.T) {
-// 	// error - key not found
-// 	keyResolver := didKeyResolver{}
-// 	key, err := keyResolver.Resolve("id")
-// 	require.Equal(t, ErrKeyNotFound, err)
-// 	require.Nil(t, key)
-
-// 	testKeyVal := []byte("pub key")
-// 	pubKeys := []VerificationMethod{{
-// 		ID:    "id",
-// 		Value: testKeyVal,
-// 		Type:  keyType,
-// 	}}
-
-// 	// happy path - key found
-// 	keyResolver = didKeyResolver{PubKeys: pubKeys}
-// 	key, err = keyResolver.Resolve("id")
-// 	require.NoError(t, err)
-// 	require.Equal(t, testKeyVal, key.Value)
-// }
+func TestNewPublicKeyFromJWK(t *testing.T) {
+	pubKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	j, err := jwk.New(pubKey)
+	require.NoError(t, err)
+
+	j.KeyID = "_Qq0UL2Fq651Q0Fjd6TvnYE-faHiOpRlPVQcY_-tA4A"
+
+	// Success.
+	signingKey, err := NewVerificationMethodFromJWK(creator, keyType, did, j)
+	require.NoError(t, err)
+	require.Equal(t, j, signingKey.JSONWebKey())
+	require.Equal(t, []byte(pubKey), signingKey.Value)
+
+	// Error - invalid JWK.
+	j = &jwk.JWK{
+		Key:   nil,
+		KeyID: "_Qq0UL2Fq651Q0Fjd6TvnYE-faHiOpRlPVQcY_-tA4A",
+	}
+	signingKey, err = NewVerificationMethodFromJWK(creator, keyType, did, j)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "convert JWK to public key bytes")
+	require.Nil(t, signingKey)
+}
+
+func TestJSONWebKey(t *testing.T) {
+	const didContext = "https://w3id.org/did/v1"
+
+	pubKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	j, err := jwk.New(pubKey)
+	require.NoError(t, err)
+
+	j.KeyID = "_Qq0UL2Fq651Q0Fjd6TvnYE-faHiOpRlPVQcY_-tA4A"
+
+	signingKey, err := NewVerificationMethodFromJWK(creator, "JsonWebKey2020", did, j)
+	require.NoError(t, err)
+	require.Equal(t, j, signingKey.JSONWebKey())
+
+	createdTime := time.Now()
+
+	didDoc := &Document{
+		Context:            []string{didContext},
+		ID:                 did,
+		VerificationMethod: []VerificationMethod{*signingKey},
+		Created:            &createdTime,
+		Updated:            &createdTime,
+	}
+
+	didDocBytes, err := didDoc.JSONBytes()
+	require.NoError(t, err)
+
+	parsedDidDoc, err := ParseDocument(didDocBytes)
+	require.NoError(t, err)
+	require.Equal(t, j, parsedDidDoc.VerificationMethod[0].JSONWebKey())
+	require.Equal(t, []byte(pubKey), parsedDidDoc.VerificationMethod[0].Value)
+
+	parsedDidDocBytes, err := parsedDidDoc.JSONBytes()
+	require.NoError(t, err)
+	require.Equal(t, didDocBytes, parsedDidDocBytes)
+
+	t.Run("invalid JWK", func(t *testing.T) {
+		invalidDoc := strings.Replace(validDocWithProofAndJWK, `"crv": "Ed25519"`, `"crv": "Ed448"`, 1)
+
+		_, err := ParseDocument([]byte(invalidDoc))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal JWK")
+	})
+}
+
+func TestVerifyProof(t *testing.T) {
+	docs := []string{validDoc, validDocV011}
+	for _, d := range docs {
+		pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			panic(err)
+		}
+
+		signedDoc := createSignedDidDocument(t, privKey, pubKey)
+
+		// happy path - valid signed document
+		doc, err := ParseDocument(signedDoc)
+		require.Nil(t, err)
+		require.NotNil(t, doc)
+		err = doc.VerifyProof()
+		require.NoError(t, err)
+
+		// error - proof purpose is not expected
+		err = doc.VerifyProof(WithProofPurpose(PurposeAuthentication))
+		require.ErrorIs(t, err, ErrInvalidProof)
+
+		// error - doc with invalid signature
+		doc.Proof[0].JWS = doc.Proof[0].JWS[:strings.LastIndex(doc.Proof[0].JWS, ".")+1] + "aW52YWxpZA"
+		err = doc.VerifyProof()
+		require.NotNil(t, err)
+		require.Contains(t, err.Error(), "invalid signature")
+
+		// error - doc with no proof
+		doc, err = ParseDocument([]byte(d))
+		require.NoError(t, err)
+		require.NotNil(t, doc)
+		err = doc.VerifyProof()
+		require.Equal(t, ErrProofNotFound, err)
+		require.Contains(t, err.Error(), "proof not found")
+	}
+}
+
+func TestVerifyProofWithEd25519signature2020(t *testing.T) {
+	pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+
+	signedDoc := createSignedDidDocumentWithEd25519signature2020(t, privKey, pubKey)
+	require.Contains(t, string(signedDoc), `"proofValue":"z`)
+
+	// happy path - valid signed document
+	doc, err := ParseDocument(signedDoc)
+	require.Nil(t, err)
+	require.NotNil(t, doc)
+	err = doc.VerifyProof()
+	require.NoError(t, err)
+
+	// error - doc with invalid proof value
+	doc.Proof[0].ProofValue = []byte("invalid")
+	err = doc.VerifyProof()
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "invalid signature")
+}
+
+func TestDidKeyResolver_Resolve(t *testing.T) {
+	// error - key not found
+	keyResolver := didKeyResolver{}
+	key, err := keyResolver.Resolve("id")
+	require.Equal(t, ErrKeyNotFound, err)
+	require.Nil(t, key)
+
+	testKeyVal := []byte("pub key")
+	pubKeys := []VerificationMethod{{
+		ID:    "id",
+		Value: testKeyVal,
+		Type:  keyType,
+	}}
+
+	// happy path - key found
+	keyResolver = didKeyResolver{PubKeys: pubKeys}
+	key, err = keyResolver.Resolve("id")
+	require.NoError(t, err)
+	require.Equal(t, testKeyVal, key.Value)
+}
 
 func TestBuildDoc(t *testing.T) {
 	ti := time.Now()
@@ -1550,7 +1536,10 @@ func TestParseDIDURL(t *testing.T) {
 					"query1": {"value1", "value3"},
 					"query2": {"value2"},
 				},
-				Fragment: "fragment",
+				Fragment:    "fragment",
+				RawPath:     "/path/a/b/c",
+				RawQuery:    "query1=value1&query2=value2&query1=value3",
+				RawFragment: "fragment",
 			},
 		},
 		{
@@ -1563,19 +1552,20 @@ func TestParseDIDURL(t *testing.T) {
 					Method:           "test",
 					MethodSpecificID: "abc",
 				},
-				Queries:  map[string][]string{},
-				Fragment: "fragment",
+				Queries:     map[string][]string{},
+				Fragment:    "fragment",
+				RawFragment: "fragment",
 			},
 		},
 		{
 			name:      "fail: error parsing DID",
 			input:     "foo",
-			expectErr: "invalid did",
+			expectErr: "invalid DID",
 		},
 		{
 			name:      "fail: DID URL doesn't satisfy URL format",
 			input:     "did:test:abc/\t",
-			expectErr: "failed to parse",
+			expectErr: "invalid character at offset 13",
 		},
 	}
 
@@ -1602,6 +1592,107 @@ func Test_DID_String(t *testing.T) {
 	require.Equal(t, expected, did.String())
 }
 
+func TestParseDIDSyntax(t *testing.T) {
+	for _, valid := range []string{
+		"did:example:123",
+		"did:web3:abc",
+		"did:example:a",
+		"did:example::a",
+		"did:example:a::b",
+		"did:example:A.b-C_d",
+		"did:example:%3Aa%2fb",
+	} {
+		parsed, err := Parse(valid)
+		require.NoError(t, err, valid)
+		require.Equal(t, valid, parsed.String())
+	}
+
+	for invalid, reason := range map[string]string{
+		"":                     `missing "did:" scheme at offset 0`,
+		"DID:example:123":      `missing "did:" scheme at offset 0`,
+		"did:":                 "empty method name at offset 4",
+		"did::123":             "empty method name at offset 4",
+		"did:Example:123":      "invalid character in method name at offset 4",
+		"did:ex-ample:123":     "invalid character in method name at offset 6",
+		"did:example":          "missing method-specific-id at offset 11",
+		"did:example::":        "method-specific-id must end with an idchar at offset 13",
+		"did:example:a%2":      "invalid percent-encoding at offset 13",
+		"did:example:a%zz":     "invalid percent-encoding at offset 13",
+		"did:example:a b":      "invalid character in method-specific-id at offset 13",
+		"did:example:a~b":      "invalid character in method-specific-id at offset 13",
+		"did:example:123/path": "invalid character in method-specific-id at offset 15",
+	} {
+		_, err := Parse(invalid)
+		require.ErrorIs(t, err, ErrInvalidDID, invalid)
+		require.Contains(t, err.Error(), reason, invalid)
+	}
+}
+
+func TestParseDIDURLSyntax(t *testing.T) {
+	const input = "did:example:123/a%2Fb/c;p=1?z=1&a=%41+b&a;x=2&flag#frag%20ment/?"
+
+	parsed, err := ParseDIDURL(input)
+	require.NoError(t, err)
+	require.Equal(t, input, parsed.String())
+	require.Equal(t, "/a/b/c;p=1", parsed.Path)
+	require.Equal(t, "/a%2Fb/c;p=1", parsed.RawPath)
+	require.Equal(t, "z=1&a=%41+b&a;x=2&flag", parsed.RawQuery)
+	require.Equal(t, map[string][]string{"z": {"1"}, "a": {"A b"}, "a;x": {"2"}, "flag": {""}}, parsed.Queries)
+	require.Equal(t, "frag ment/?", parsed.Fragment)
+	require.Equal(t, "frag%20ment/?", parsed.RawFragment)
+
+	for _, valid := range []string{"did:example:123/", "did:example:123?", "did:example:123#", "did:example:123//a"} {
+		_, err := ParseDIDURL(valid)
+		require.NoError(t, err, valid)
+	}
+
+	for invalid, reason := range map[string]string{
+		"did:example:123/a b":     "invalid character at offset 17",
+		"did:example:123/%g0":     "invalid percent-encoding at offset 16",
+		"did:example:123?a=%":     "invalid percent-encoding at offset 18",
+		"did:example:123#a#b":     "invalid character at offset 17",
+		"did:example:123#<b>":     "invalid character at offset 16",
+		"did:example:123?a=[1]":   "invalid character at offset 18",
+		"did:example:123 /a":      "invalid character in method-specific-id at offset 15",
+		"did:example:/path":       "method-specific-id must end with an idchar at offset 12",
+		"did:example:123?q=\"x\"": "invalid character at offset 18",
+	} {
+		_, err := ParseDIDURL(invalid)
+		require.ErrorIs(t, err, ErrInvalidDID, invalid)
+		require.Contains(t, err.Error(), reason, invalid)
+	}
+}
+
+func TestDIDNormalize(t *testing.T) {
+	a, err := Parse("did:example:%3a%41%2e")
+	require.NoError(t, err)
+	require.Equal(t, "did:example:%3AA.", a.Normalize().String())
+
+	b, err := Parse("did:example:%3AA.")
+	require.NoError(t, err)
+	require.True(t, a.Equal(b))
+
+	c, err := Parse("did:example:%3Aa.")
+	require.NoError(t, err)
+	require.False(t, a.Equal(c))
+
+	u, err := ParseDIDURL("did:example:%41/%7e%2f?q=%7E%3d#%2D")
+	require.NoError(t, err)
+
+	normalized := u.Normalize()
+	require.Equal(t, "did:example:A/~%2F?q=~%3D#-", normalized.String())
+	require.Equal(t, u.Path, normalized.Path)
+	require.Equal(t, u.Queries, normalized.Queries)
+
+	v, err := ParseDIDURL("did:example:A/~%2F?q=~%3D#-")
+	require.NoError(t, err)
+	require.True(t, u.Equal(v))
+
+	w, err := ParseDIDURL("did:example:A/~%2F?q=~%3D")
+	require.NoError(t, err)
+	require.False(t, u.Equal(w))
+}
+
 func TestDIDSchemas(t *testing.T) {
 	t.Run("Test decode public key", func(t *testing.T) {
 		tests := []struct {
@@ -1957,76 +2048,55 @@ func TestDoc_SerializeInterop(t *testing.T) {
 }
 
 func createDidDocumentWithSigningKey(vm VerificationMethod, context []string) *Document { //nolint: gocritic
-	createdTime := time.Now()
-
 	didDoc := &Document{
 		Context:            context,
 		ID:                 did,
 		VerificationMethod: []VerificationMethod{vm},
-		Created:            &createdTime,
+		AssertionMethod:    []Verification{*NewReferencedVerification(&vm, AssertionMethod)},
 	}
 
 	return didDoc
 }
 
-// func createSignedDidDocument(t *testing.T, privKey, pubKey []byte) []byte {
-// 	const (
-// 		didContext      = "https://w3id.org/did/v1"
-// 		securityContext = "https://w3id.org/security/v1"
-// 	)
-
-// 	vm := VerificationMethod{
-// 		ID:         creator,
-// 		Type:       keyType,
-// 		Controller: did,
-// 		Value:      pubKey,
-// 	}
-
-// 	didDoc := createDidDocumentWithSigningKey(vm, []string{didContext, securityContext})
-
-// 	jsonDoc, err := didDoc.JSONBytes()
-// 	require.NoError(t, err)
+func createSignedDidDocument(t *testing.T, privKey, pubKey []byte) []byte {
+	const didContext = "https://w3id.org/did/v1"
 
-// 	context := &signer.Context{
-// 		Creator:       creator,
-// 		SignatureType: signatureType,
-// 	}
-
-// 	s := signer.New(ed25519signature2018.New(
-// 		suite.WithSigner(getSigner(privKey))))
+	vm := VerificationMethod{
+		ID:         creator,
+		Type:       keyType,
+		Controller: did,
+		Value:      pubKey,
+	}
 
-// 	signedDoc, err := s.Sign(context, jsonDoc, ldtestutil.WithDocumentLoader(t))
-// 	require.NoError(t, err)
+	didDoc := createDidDocumentWithSigningKey(vm, []string{didContext})
 
-// 	return signedDoc
-// }
+	err := didDoc.AddProof(getSigner(privKey), creator, WithProofType(signatureType))
+	require.NoError(t, err)
 
-// func createSignedDidDocumentWithEd25519signature2020(t *testing.T, privKey, pubKey []byte) []byte {
-// 	const (
-// 		didContext      = "https://w3id.org/did/v1"
-// 		securityContext = "https://w3id.org/security/suites/ed25519-2020/v1"
-// 	)
+	signedDoc, err := didDoc.JSONBytes()
+	require.NoError(t, err)
 
-// 	vm := NewVerificationMethodFromBytes(creator, keyType2020, did, pubKey)
+	return signedDoc
+}
 
-// 	didDoc := createDidDocumentWithSigningKey(*vm, []string{didContext, securityContext})
+func createSignedDidDocumentWithEd25519signature2020(t *testing.T, privKey, pubKey []byte) []byte {
+	const (
+		didContext      = "https://w3id.org/did/v1"
+		securityContext = "https://w3id.org/security/suites/ed25519-2020/v1"
+	)
 
-// 	jsonDoc, err := didDoc.JSONBytes()
-// 	require.NoError(t, err)
+	vm := NewVerificationMethodFromBytes(creator, keyType2020, did, pubKey)
 
-// 	context := &signer.Context{
-// 		Creator:       creator,
-// 		SignatureType: signatureType2020,
-// 	}
+	didDoc := createDidDocumentWithSigningKey(*vm, []string{didContext, securityContext})
 
-// 	s := signer.New(ed25519signature2020.New(
-// 		suite.WithSigner(getSigner(privKey))))
+	err := didDoc.AddProof(getSigner(privKey), creator, WithProofType(signatureType2020))
+	require.NoError(t, err)
 
-// 	signedDoc, err := s.Sign(context, jsonDoc, ldtestutil.WithDocumentLoader(t))
-// 	require.NoError(t, err)
+	signedDoc, err := didDoc.JSONBytes()
+	require.NoError(t, err)
 
-// 	return signedDoc
-// }
+	return signedDoc
+}
 
 func getSigner(privKey []byte) *testSigner {
 	return &testSigner{privateKey: privKey}
@@ -2044,6 +2114,14 @@ func (s *testSigner) Sign(doc []byte) ([]byte, error) {
 	return ed25519.Sign(s.privateKey, doc), nil
 }
 
+func (s *testSigner) KeyType() crypto.KeyType {
+	return crypto.Ed25519
+}
+
+func (s *testSigner) PublicKeyBytes() []byte {
+	return ed25519.PrivateKey(s.privateKey).Public().(ed25519.PublicKey)
+}
+
 const validDocWithProof = `{
 	"@context": ["https://w3id.org/did/v1"],
 	"created": "2019-09-23T14:16:59.261024-04:00",
@@ -5,6 +5,14 @@ SPDX-License-Identifier: Apache-2.0
 
 package did
 
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/zRich/zFusion/common/model"
+)
+
 // ContextCleanup performs non-intrusive cleanup of the given context by
 // converting `[]string(nil)` and `[]interface{}(nil)` to the empty string, and
 // converting `[]interface{}` to `[]string` if it contains only string values.
@@ -151,6 +159,91 @@ func LookupService(didDoc *Document, serviceType string) (*Service, bool) {
 	return &didDoc.Service[index], true
 }
 
+// RankedEndpoint is an entry of a service endpoint of a DID document.
+type RankedEndpoint struct {
+	model.DIDCommV2Endpoint
+	// Service is the service of the entry.
+	Service *Service
+}
+
+// RankServices returns the services of the DID doc with one of the given types, or all services when no type is
+// given, by ascending Priority. Services of equal priority keep their document order.
+func (doc *Document) RankServices(serviceTypes ...string) []*Service {
+	var services []*Service
+
+	for i := range doc.Service {
+		if len(serviceTypes) == 0 || containsString(serviceTypes, doc.Service[i].Type) {
+			services = append(services, &doc.Service[i])
+		}
+	}
+
+	sort.SliceStable(services, func(i, j int) bool {
+		return services[i].Priority < services[j].Priority
+	})
+
+	return services
+}
+
+// RankEndpoints returns the endpoint entries of the services ranked by RankServices which accept one of the given
+// media type profiles, see model.Endpoint.Select. Entries of DIDComm V1 and DIDCore endpoints take the Accept and
+// RoutingKeys of their service.
+func (doc *Document) RankEndpoints(accept []string, serviceTypes ...string) []RankedEndpoint {
+	var ranked []RankedEndpoint
+
+	for _, service := range doc.RankServices(serviceTypes...) {
+		endpoints, err := service.ServiceEndpoint.Endpoints()
+		if err != nil {
+			continue
+		}
+
+		for _, endpoint := range endpoints {
+			if service.ServiceEndpoint.Type() != model.DIDCommV2 {
+				endpoint.Accept = service.Accept
+				endpoint.RoutingKeys = service.RoutingKeys
+			}
+
+			if endpoint.Accepts(accept...) {
+				ranked = append(ranked, RankedEndpoint{DIDCommV2Endpoint: endpoint, Service: service})
+			}
+		}
+	}
+
+	return ranked
+}
+
+// FailoverEndpoints calls send with the entries ranked by RankEndpoints in turn, until one of them succeeds.
+func (doc *Document) FailoverEndpoints(send func(endpoint RankedEndpoint) error, accept []string,
+	serviceTypes ...string) error {
+	ranked := doc.RankEndpoints(accept, serviceTypes...)
+	if len(ranked) == 0 {
+		return fmt.Errorf("%w: no service of %s accepts %s", model.ErrEndpointNotFound, doc.ID,
+			strings.Join(accept, ", "))
+	}
+
+	failures := make([]string, 0, len(ranked))
+
+	for _, endpoint := range ranked {
+		err := send(endpoint)
+		if err == nil {
+			return nil
+		}
+
+		failures = append(failures, fmt.Sprintf("%s: %v", endpoint.URI, err))
+	}
+
+	return fmt.Errorf("%w: %s", model.ErrAllEndpointsFailed, strings.Join(failures, "; "))
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
 // LookupDIDCommRecipientKeys gets the DIDComm recipient keys from the did doc which match the given parameters.
 // DIDComm recipient keys are encoded as did:key identifiers.
 // See:
@@ -169,13 +262,51 @@ func LookupDIDCommRecipientKeys(didDoc *Document) ([]string, bool) {
 	return didCommService.RecipientKeys, true
 }
 
-// LookupPublicKey returns the public key with the given id from the given DID Doc.
+// LookupPublicKey returns the verification method with the given id from the given DID Doc, listed in its
+// verificationMethod or embedded in one of its verification relationships. id and the method IDs match when
+// they are equal once resolved against the document ID, so that "#key-1" matches "did:example:123#key-1".
 func LookupPublicKey(id string, didDoc *Document) (*VerificationMethod, bool) {
 	for _, key := range didDoc.VerificationMethod {
-		if key.ID == id {
+		if AbsoluteID(didDoc.ID, key.ID) == AbsoluteID(didDoc.ID, id) {
 			return &key, true
 		}
 	}
 
+	for _, relationship := range [][]Verification{
+		didDoc.Authentication, didDoc.AssertionMethod, didDoc.CapabilityDelegation, didDoc.CapabilityInvocation,
+		didDoc.KeyAgreement,
+	} {
+		for i := range relationship {
+			if relationship[i].Embedded &&
+				AbsoluteID(didDoc.ID, relationship[i].VerificationMethod.ID) == AbsoluteID(didDoc.ID, id) {
+				key := relationship[i].VerificationMethod
+
+				return &key, true
+			}
+		}
+	}
+
 	return nil, false
 }
+
+// LookupServiceByID returns the service with the given id from the given DID Doc, IDs matching as for
+// LookupPublicKey.
+func LookupServiceByID(id string, didDoc *Document) (*Service, bool) {
+	for i := range didDoc.Service {
+		if AbsoluteID(didDoc.ID, didDoc.Service[i].ID) == AbsoluteID(didDoc.ID, id) {
+			return &didDoc.Service[i], true
+		}
+	}
+
+	return nil, false
+}
+
+// AbsoluteID returns the absolute form of id, the ID of a verification method or service of the document of
+// the DID didID. Relative IDs such as "#key-1" are resolved against didID, absolute ones are returned as is.
+func AbsoluteID(didID, id string) string {
+	if strings.HasPrefix(id, "#") {
+		return didID + id
+	}
+
+	return id
+}
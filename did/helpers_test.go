@@ -6,10 +6,12 @@ SPDX-License-Identifier: Apache-2.0
 package did_test
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"github.com/zRich/zFusion/common/model"
 
 	. "github.com/zRich/zFusion/did"
 )
@@ -214,3 +216,117 @@ func TestContextContainsString(t *testing.T) {
 // 		require.Nil(t, s)
 // 	})
 // }
+
+func TestRankEndpoints(t *testing.T) {
+	doc := &Document{
+		ID: "did:example:123",
+		Service: []Service{
+			{
+				ID: "did:example:123#backup", Type: "DIDCommMessaging", Priority: 1,
+				ServiceEndpoint: model.NewDIDCommV2Endpoint([]model.DIDCommV2Endpoint{
+					{URI: "https://backup.example.com", Accept: []string{"didcomm/v2"}},
+				}),
+			},
+			{
+				ID: "did:example:123#hub", Type: "IdentityHub",
+				ServiceEndpoint: model.NewDIDCoreEndpoint([]string{"https://hub.example.com"}),
+			},
+			{
+				ID: "did:example:123#primary", Type: "DIDCommMessaging",
+				ServiceEndpoint: model.NewDIDCommV2Endpoint([]model.DIDCommV2Endpoint{
+					{URI: "https://primary.example.com", Accept: []string{"didcomm/v2"}},
+					{URI: "https://primary-aip2.example.com", Accept: []string{"didcomm/aip2;env=rfc19"}},
+				}),
+			},
+			{
+				ID: "did:example:123#legacy", Type: "did-communication", Priority: 2,
+				ServiceEndpoint: model.NewDIDCommV1Endpoint("https://legacy.example.com"),
+				Accept:          []string{"didcomm/aip2;env=rfc19"}, RoutingKeys: []string{"did:key:z6Mk"},
+			},
+		},
+	}
+
+	var ids []string
+	for _, service := range doc.RankServices() {
+		ids = append(ids, service.ID)
+	}
+
+	require.Equal(t, []string{
+		"did:example:123#hub", "did:example:123#primary", "did:example:123#backup", "did:example:123#legacy",
+	}, ids)
+	require.Len(t, doc.RankServices("DIDCommMessaging", "did-communication"), 3)
+
+	ranked := doc.RankEndpoints([]string{"didcomm/aip2;env=rfc19"}, "DIDCommMessaging", "did-communication")
+	require.Len(t, ranked, 2)
+	require.Equal(t, "https://primary-aip2.example.com", ranked[0].URI)
+	require.Equal(t, "https://legacy.example.com", ranked[1].URI)
+	require.Equal(t, []string{"did:key:z6Mk"}, ranked[1].RoutingKeys)
+	require.Equal(t, "did:example:123#legacy", ranked[1].Service.ID)
+
+	var tried []string
+
+	err := doc.FailoverEndpoints(func(endpoint RankedEndpoint) error {
+		tried = append(tried, endpoint.URI)
+
+		if endpoint.Service.Priority == 0 {
+			return errors.New("connection refused")
+		}
+
+		return nil
+	}, []string{"didcomm/v2"}, "DIDCommMessaging")
+	require.NoError(t, err)
+	require.Equal(t, []string{"https://primary.example.com", "https://backup.example.com"}, tried)
+
+	err = doc.FailoverEndpoints(func(RankedEndpoint) error {
+		return errors.New("connection refused")
+	}, []string{"didcomm/v2"}, "DIDCommMessaging")
+	require.ErrorIs(t, err, model.ErrAllEndpointsFailed)
+
+	err = doc.FailoverEndpoints(func(RankedEndpoint) error { return nil }, []string{"didcomm/aip1"},
+		"DIDCommMessaging")
+	require.ErrorIs(t, err, model.ErrEndpointNotFound)
+}
+
+func TestLookupByID(t *testing.T) {
+	doc := &Document{
+		ID:                 "did:example:123",
+		VerificationMethod: []VerificationMethod{{ID: "#key-1"}, {ID: "did:example:123#key-2"}},
+		KeyAgreement: []Verification{
+			{VerificationMethod: VerificationMethod{ID: "#key-3"}, Relationship: KeyAgreement, Embedded: true},
+		},
+		Service: []Service{{ID: "#agent"}, {ID: "did:example:123#hub"}},
+	}
+
+	for id, expected := range map[string]string{
+		"#key-1":                "#key-1",
+		"did:example:123#key-1": "#key-1",
+		"#key-2":                "did:example:123#key-2",
+		"did:example:123#key-3": "#key-3",
+	} {
+		vm, ok := LookupPublicKey(id, doc)
+		require.True(t, ok, id)
+		require.Equal(t, expected, vm.ID)
+	}
+
+	_, ok := LookupPublicKey("did:example:456#key-1", doc)
+	require.False(t, ok)
+
+	for id, expected := range map[string]string{
+		"#agent":                "#agent",
+		"did:example:123#agent": "#agent",
+		"#hub":                  "did:example:123#hub",
+	} {
+		service, ok := LookupServiceByID(id, doc)
+		require.True(t, ok, id)
+		require.Equal(t, expected, service.ID)
+	}
+
+	_, ok = LookupServiceByID("#key-1", doc)
+	require.False(t, ok)
+}
+
+func TestAbsoluteID(t *testing.T) {
+	require.Equal(t, "did:example:123#key-1", AbsoluteID("did:example:123", "#key-1"))
+	require.Equal(t, "did:example:456#key-1", AbsoluteID("did:example:123", "did:example:456#key-1"))
+	require.Equal(t, "https://example.com/key", AbsoluteID("did:example:123", "https://example.com/key"))
+}
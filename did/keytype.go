@@ -0,0 +1,66 @@
+package did
+
+import (
+	"fmt"
+
+	"github.com/zRich/zFusion/common/crypto"
+	"github.com/zRich/zFusion/common/multicodec"
+)
+
+// Multikey is the type of verification methods whose publicKeyMultibase key is prefixed with the multicodec
+// code of its key type.
+const Multikey = "Multikey"
+
+// Sm2VerificationKey is the type of verification methods holding SM2 public keys of GB/T 32918.
+const Sm2VerificationKey = "Sm2VerificationKey"
+
+// keyTypes maps verification method types to the type of the key they hold.
+var keyTypes = map[string]crypto.KeyType{ //nolint:gochecknoglobals
+	"Ed25519VerificationKey2018":        crypto.Ed25519,
+	"Ed25519VerificationKey2020":        crypto.Ed25519,
+	"X25519KeyAgreementKey2019":         crypto.X25519,
+	"X25519KeyAgreementKey2020":         crypto.X25519,
+	"EcdsaSecp256k1VerificationKey2019": crypto.ECDSASecp256k1,
+	"Secp256k1VerificationKey2018":      crypto.ECDSASecp256k1,
+	"EcdsaSecp256r1VerificationKey2019": crypto.ECDSAP256,
+	"RsaVerificationKey2018":            crypto.RSA,
+	Sm2VerificationKey:                  crypto.SM2,
+}
+
+// KeyType returns the type of the public key held by the verification method. The type of a JSON Web Key
+// takes precedence over the verification method type, and the key type of Multikey verification methods, or of
+// multicodec prefixed keys of other types, is inferred from their multicodec.
+func (pk *VerificationMethod) KeyType() (crypto.KeyType, error) {
+	if pk.jsonWebKey != nil {
+		return pk.jsonWebKey.KeyType()
+	}
+
+	if keyType, ok := keyTypes[pk.Type]; ok {
+		return keyType, nil
+	}
+
+	if pk.multicodec == 0 {
+		return "", fmt.Errorf("unsupported verification method type: %s", pk.Type)
+	}
+
+	codec, err := multicodec.Lookup(pk.multicodec)
+	if err != nil {
+		return "", err
+	}
+
+	if codec.KeyType == "" {
+		return "", fmt.Errorf("unsupported %s key of verification method %s", codec.Name, pk.ID)
+	}
+
+	return codec.KeyType, nil
+}
+
+// Verify verifies the signature sig over msg with the public key of the verification method.
+func (pk *VerificationMethod) Verify(msg, sig []byte) error {
+	keyType, err := pk.KeyType()
+	if err != nil {
+		return err
+	}
+
+	return crypto.Verify(keyType, pk.Value, msg, sig)
+}
@@ -0,0 +1,196 @@
+package did
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/stretchr/testify/require"
+	"github.com/zRich/zFusion/common/crypto"
+	"github.com/zRich/zFusion/common/crypto/jwk"
+	"github.com/zRich/zFusion/common/crypto/sm2"
+	"github.com/zRich/zFusion/common/multicodec"
+)
+
+func TestVerificationMethod_Verify(t *testing.T) {
+	pubKey, privKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	msg := []byte("message")
+	sig := ed25519.Sign(privKey, msg)
+
+	vm := NewVerificationMethodFromBytes(creator, keyType, did, pubKey)
+
+	keyType, err := vm.KeyType()
+	require.NoError(t, err)
+	require.Equal(t, crypto.Ed25519, keyType)
+
+	require.NoError(t, vm.Verify(msg, sig))
+	require.ErrorIs(t, vm.Verify([]byte("other"), sig), crypto.ErrInvalidSignature)
+
+	vm.Type = "UnknownKey2022"
+	_, err = vm.KeyType()
+	require.EqualError(t, err, "unsupported verification method type: UnknownKey2022")
+	require.Error(t, vm.Verify(msg, sig))
+}
+
+func TestVerificationMethod_VerifySM2(t *testing.T) {
+	privKey, err := sm2.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	signer, err := crypto.NewSigner(privKey)
+	require.NoError(t, err)
+
+	msg := []byte("message")
+
+	sig, err := signer.Sign(msg)
+	require.NoError(t, err)
+
+	vm := NewVerificationMethodFromBytes(creator, Sm2VerificationKey, did, signer.PublicKeyBytes())
+
+	keyType, err := vm.KeyType()
+	require.NoError(t, err)
+	require.Equal(t, crypto.SM2, keyType)
+
+	require.NoError(t, vm.Verify(msg, sig))
+	require.ErrorIs(t, vm.Verify([]byte("other"), sig), crypto.ErrInvalidSignature)
+
+	multikey, err := NewMultikey(creator, did, multicodec.SM2Pub, signer.PublicKeyBytes())
+	require.NoError(t, err)
+	require.NoError(t, multikey.Verify(msg, sig))
+}
+
+func TestVerificationMethod_VerifyJWK(t *testing.T) {
+	privKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	signer, err := crypto.NewSigner(privKey)
+	require.NoError(t, err)
+
+	msg := []byte("message")
+
+	sig, err := signer.Sign(msg)
+	require.NoError(t, err)
+
+	j, err := jwk.New(&privKey.PublicKey)
+	require.NoError(t, err)
+
+	vm, err := NewVerificationMethodFromJWK(creator, "JsonWebKey2020", did, j)
+	require.NoError(t, err)
+
+	keyType, err := vm.KeyType()
+	require.NoError(t, err)
+	require.Equal(t, crypto.ECDSAP256, keyType)
+
+	require.NoError(t, vm.Verify(msg, sig))
+	require.ErrorIs(t, vm.Verify([]byte("other"), sig), crypto.ErrInvalidSignature)
+}
+
+func TestMultikey(t *testing.T) {
+	const ed25519Multikey = "z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK"
+
+	x25519Key, err := NewMultikey("#key-2", "", multicodec.X25519Pub, bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+
+	blsKey, err := NewMultikey("#key-3", "", multicodec.BLS12381G2Pub, bytes.Repeat([]byte{2}, 96))
+	require.NoError(t, err)
+
+	doc := BuildDoc(WithVerificationMethod([]VerificationMethod{*x25519Key, *blsKey}))
+	doc.ID = "did:example:123"
+
+	docBytes, err := doc.JSONBytes()
+	require.NoError(t, err)
+
+	raw := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(docBytes, &raw))
+
+	vms, ok := raw["verificationMethod"].([]interface{})
+	require.True(t, ok)
+	require.Len(t, vms, 2)
+	require.Equal(t, "z6LS", vms[0].(map[string]interface{})["publicKeyMultibase"].(string)[:4])
+
+	vms = append(vms,
+		map[string]interface{}{"id": "#key-1", "type": Multikey, "controller": "", "publicKeyMultibase": ed25519Multikey},
+		map[string]interface{}{
+			"id": "#key-4", "type": "Ed25519VerificationKey2020", "controller": "", "publicKeyMultibase": ed25519Multikey,
+		},
+	)
+	raw["verificationMethod"] = vms
+
+	docBytes, err = json.Marshal(raw)
+	require.NoError(t, err)
+
+	parsed, err := ParseDocument(docBytes, WithStrictValidation())
+	require.NoError(t, err)
+	require.Len(t, parsed.VerificationMethod, 4)
+
+	for i, expected := range []struct {
+		code    multicodec.Code
+		keyType crypto.KeyType
+	}{
+		{multicodec.X25519Pub, crypto.X25519},
+		{multicodec.BLS12381G2Pub, ""},
+		{multicodec.Ed25519Pub, crypto.Ed25519},
+		{multicodec.Ed25519Pub, crypto.Ed25519},
+	} {
+		vm := &parsed.VerificationMethod[i]
+
+		code, ok := vm.Multicodec()
+		require.True(t, ok, vm.ID)
+		require.Equal(t, expected.code, code, vm.ID)
+
+		codec, err := multicodec.Lookup(code)
+		require.NoError(t, err)
+		require.Len(t, vm.Value, codec.KeySize, vm.ID)
+
+		keyType, err := vm.KeyType()
+		if expected.keyType == "" {
+			require.EqualError(t, err, "unsupported bls12_381-g2-pub key of verification method "+vm.ID)
+
+			continue
+		}
+
+		require.NoError(t, err, vm.ID)
+		require.Equal(t, expected.keyType, keyType, vm.ID)
+	}
+
+	// the multicodec prefix is kept when the document is serialized again
+	reserialized, err := parsed.JSONBytes()
+	require.NoError(t, err)
+	require.Contains(t, string(reserialized), ed25519Multikey)
+
+	t.Run("invalid Multikeys", func(t *testing.T) {
+		_, err = NewMultikey("#key-1", "", multicodec.Ed25519Pub, []byte{1, 2, 3})
+		require.ErrorIs(t, err, multicodec.ErrInvalidKey)
+
+		vms[2] = map[string]interface{}{
+			"id": "#key-1", "type": Multikey, "controller": "",
+			"publicKeyMultibase": "z" + base58.Encode(bytes.Repeat([]byte{1}, 32)),
+		}
+
+		docBytes, err = json.Marshal(raw)
+		require.NoError(t, err)
+
+		_, err = ParseDocument(docBytes)
+		require.ErrorIs(t, err, multicodec.ErrUnknownCodec)
+	})
+
+	t.Run("codec mismatching the verification method type", func(t *testing.T) {
+		vms[2] = map[string]interface{}{
+			"id": "#key-1", "type": "Ed25519VerificationKey2020", "controller": "",
+			"publicKeyMultibase": vms[0].(map[string]interface{})["publicKeyMultibase"],
+		}
+
+		docBytes, err = json.Marshal(raw)
+		require.NoError(t, err)
+
+		_, err = ParseDocument(docBytes, WithStrictValidation())
+		require.ErrorIs(t, err, ErrInvalidDocument)
+		require.Contains(t, err.Error(), "x25519-pub key in a Ed25519VerificationKey2020 verification method")
+	})
+}
@@ -0,0 +1,265 @@
+package did
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/multiformats/go-multibase"
+	"github.com/zRich/zFusion/common/crypto"
+	"github.com/zRich/zFusion/common/multicodec"
+)
+
+// ErrInvalidDocument is returned when a DID document has findings of error severity.
+var ErrInvalidDocument = errors.New("invalid DID document")
+
+// Severity is the severity of a Finding.
+type Severity string
+
+const (
+	// SeverityError marks findings which make the document unusable or ambiguous.
+	SeverityError Severity = "error"
+	// SeverityWarning marks findings which are suspicious but do not prevent the use of the document.
+	SeverityWarning Severity = "warning"
+)
+
+// Codes of the findings reported by Lint.
+const (
+	// CodeInvalidID is reported when the document ID is not a DID.
+	CodeInvalidID = "invalid-id"
+	// CodeDuplicateID is reported when verification methods or services share an ID.
+	CodeDuplicateID = "duplicate-id"
+	// CodeMissingVerificationMethod is reported when a verification relationship or the keys of a service
+	// reference a verification method which is not in the document.
+	CodeMissingVerificationMethod = "missing-verification-method"
+	// CodeInvalidController is reported when the controller of a verification method is not a DID.
+	CodeInvalidController = "invalid-controller"
+	// CodeMissingServiceEndpoint is reported when a service has no endpoint.
+	CodeMissingServiceEndpoint = "missing-service-endpoint"
+	// CodeUnknownKeyType is reported when the type of a verification method is not known.
+	CodeUnknownKeyType = "unknown-key-type"
+	// CodeKeyTypeMismatch is reported when the public key of a verification method does not match its type.
+	CodeKeyTypeMismatch = "key-type-mismatch"
+)
+
+// Finding is an issue found in a DID document by Lint. Path locates the offending member of the document,
+// for instance "verificationMethod[1]" or "service[0]".
+type Finding struct {
+	Severity Severity `json:"severity"`
+	Code     string   `json:"code"`
+	Path     string   `json:"path,omitempty"`
+	Message  string   `json:"message"`
+}
+
+// String returns the finding as "severity: path: message (code)".
+func (f Finding) String() string {
+	if f.Path == "" {
+		return fmt.Sprintf("%s: %s (%s)", f.Severity, f.Message, f.Code)
+	}
+
+	return fmt.Sprintf("%s: %s: %s (%s)", f.Severity, f.Path, f.Message, f.Code)
+}
+
+// ValidationError is returned by Validate with the findings of error severity.
+type ValidationError struct {
+	Findings []Finding
+}
+
+// Error lists the findings of the error.
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Findings))
+	for i, f := range e.Findings {
+		messages[i] = f.String()
+	}
+
+	return fmt.Sprintf("%s: %s", ErrInvalidDocument, strings.Join(messages, "; "))
+}
+
+// Unwrap returns ErrInvalidDocument.
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidDocument
+}
+
+// Validate checks the semantics of the document, and returns a *ValidationError with the findings of Lint of
+// error severity, if any.
+func (doc *Document) Validate() error {
+	var errs []Finding
+
+	for _, f := range doc.Lint() {
+		if f.Severity == SeverityError {
+			errs = append(errs, f)
+		}
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Findings: errs}
+	}
+
+	return nil
+}
+
+// Lint checks the semantics of the document, beyond the JSON schema validated by ParseDocument, and returns
+// its findings in document order:
+//   - the document ID must be a DID;
+//   - verification methods and services must have distinct IDs;
+//   - verification relationships must reference verification methods of the document;
+//   - the controllers of verification methods must be DIDs;
+//   - services must have an endpoint;
+//   - public keys must be of the type and size implied by the verification method type.
+func (doc *Document) Lint() []Finding {
+	l := &linter{doc: doc, ids: map[string]string{}}
+
+	if _, err := Parse(doc.ID); err != nil {
+		l.report(SeverityError, CodeInvalidID, "id", "document ID is not a DID: %v", err)
+	}
+
+	for i := range doc.VerificationMethod {
+		l.lintVerificationMethod(fmt.Sprintf("verificationMethod[%d]", i), &doc.VerificationMethod[i])
+	}
+
+	for _, relationship := range []struct {
+		name          string
+		verifications []Verification
+	}{
+		{"authentication", doc.Authentication},
+		{"assertionMethod", doc.AssertionMethod},
+		{"capabilityDelegation", doc.CapabilityDelegation},
+		{"capabilityInvocation", doc.CapabilityInvocation},
+		{"keyAgreement", doc.KeyAgreement},
+	} {
+		for i := range relationship.verifications {
+			path := fmt.Sprintf("%s[%d]", relationship.name, i)
+			vm := &relationship.verifications[i].VerificationMethod
+
+			if relationship.verifications[i].Embedded {
+				l.lintVerificationMethod(path, vm)
+			} else if _, ok := LookupPublicKey(vm.ID, doc); !ok {
+				l.report(SeverityError, CodeMissingVerificationMethod, path,
+					"verification method %s is not in the document", vm.ID)
+			}
+		}
+	}
+
+	for i := range doc.Service {
+		l.lintService(fmt.Sprintf("service[%d]", i), &doc.Service[i])
+	}
+
+	return l.findings
+}
+
+type linter struct {
+	doc      *Document
+	ids      map[string]string // paths of the verification methods and services by ID.
+	findings []Finding
+}
+
+func (l *linter) report(severity Severity, code, path, format string, args ...interface{}) {
+	l.findings = append(l.findings, Finding{
+		Severity: severity, Code: code, Path: path, Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (l *linter) checkID(path, id string) {
+	if prev, ok := l.ids[id]; ok {
+		l.report(SeverityError, CodeDuplicateID, path, "ID %s is already used by %s", id, prev)
+
+		return
+	}
+
+	l.ids[id] = path
+}
+
+func (l *linter) lintVerificationMethod(path string, vm *VerificationMethod) {
+	l.checkID(path, vm.ID)
+
+	if _, err := Parse(vm.Controller); err != nil {
+		l.report(SeverityError, CodeInvalidController, path, "controller %q is not a DID", vm.Controller)
+	}
+
+	expected, known := keyTypes[vm.Type]
+	code, prefixed := vm.Multicodec()
+
+	if vm.jsonWebKey == nil && !known && !prefixed {
+		l.report(SeverityWarning, CodeUnknownKeyType, path, "unknown verification method type %s", vm.Type)
+
+		return
+	}
+
+	codec, err := multicodec.Lookup(code)
+	if prefixed && !known && err == nil && codec.KeyType == "" {
+		// keys without signature support, such as BLS keys, whose size was checked against their codec
+		return
+	}
+
+	keyType, err := vm.KeyType()
+	if err != nil {
+		l.report(SeverityError, CodeKeyTypeMismatch, path, "%v", err)
+
+		return
+	}
+
+	if known && keyType != expected {
+		l.report(SeverityError, CodeKeyTypeMismatch, path, "%s key in a %s verification method", keyType, vm.Type)
+
+		return
+	}
+
+	if prefixed && codec.KeyType != keyType {
+		l.report(SeverityError, CodeKeyTypeMismatch, path, "%s key in a %s verification method", codec.Name, vm.Type)
+
+		return
+	}
+
+	if err := checkPublicKey(keyType, vm.Value); err != nil {
+		l.report(SeverityError, CodeKeyTypeMismatch, path, "%v", err)
+	}
+
+	if vm.jsonWebKey == nil && (vm.Type == "Ed25519VerificationKey2020" || vm.Type == Multikey) &&
+		vm.multibaseEncoding != multibase.Base58BTC {
+		l.report(SeverityWarning, CodeKeyTypeMismatch, path,
+			"%s keys should be base58btc encoded in publicKeyMultibase", vm.Type)
+	}
+}
+
+func (l *linter) lintService(path string, service *Service) {
+	l.checkID(path, service.ID)
+
+	if uri, err := service.ServiceEndpoint.URI(); err != nil || uri == "" {
+		l.report(SeverityError, CodeMissingServiceEndpoint, path, "service %s has no endpoint", service.ID)
+	}
+
+	for _, keys := range [][]string{service.RecipientKeys, service.RoutingKeys} {
+		for _, key := range keys {
+			if !strings.HasPrefix(key, l.doc.ID+"#") {
+				continue
+			}
+
+			if _, ok := l.ids[key]; !ok {
+				l.report(SeverityWarning, CodeMissingVerificationMethod, path,
+					"key %s is not a verification method of the document", key)
+			}
+		}
+	}
+}
+
+// checkPublicKey checks that pubKey is encoded as expected by crypto.Verify for keyType.
+func checkPublicKey(keyType crypto.KeyType, pubKey []byte) error {
+	var err error
+
+	switch keyType {
+	case crypto.Ed25519, crypto.X25519:
+		if len(pubKey) != 32 { //nolint:gomnd
+			err = fmt.Errorf("invalid %s public key size %d", keyType, len(pubKey))
+		}
+	case crypto.ECDSAP256, crypto.ECDSAP384, crypto.ECDSASecp256k1, crypto.SM2:
+		_, err = crypto.ParseECDSAPublicKey(keyType, pubKey)
+	case crypto.RSA:
+		_, err = crypto.ParseRSAPublicKey(pubKey)
+	}
+
+	if err != nil {
+		return fmt.Errorf("public key does not match its type: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,217 @@
+package did
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const lintedDoc = `{
+  "@context": ["https://www.w3.org/ns/did/v1"],
+  "id": "did:example:123",
+  "verificationMethod": [{
+    "id": "did:example:123#key-1",
+    "type": "Ed25519VerificationKey2018",
+    "controller": "did:example:123",
+    "publicKeyBase58": "H3C2AVvLMv6gmMNam3uVAjZpfkcJCwDwnZn6z3wXmqPV"
+  }],
+  "authentication": ["did:example:123#key-1"],
+  "keyAgreement": [{
+    "id": "#key-2",
+    "type": "X25519KeyAgreementKey2019",
+    "controller": "did:example:123",
+    "publicKeyBase58": "4zvwRjXUKGfvwnParsHAS3HuSVzV5cA4McphgmoCtajS"
+  }],
+  "service": [{
+    "id": "did:example:123#agent",
+    "type": "did-communication",
+    "serviceEndpoint": "https://agent.example.com/",
+    "recipientKeys": ["#key-2"]
+  }]
+}`
+
+func parseLintedDoc(t *testing.T, mutate func(doc map[string]interface{})) []byte {
+	t.Helper()
+
+	doc := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal([]byte(lintedDoc), &doc))
+
+	mutate(doc)
+
+	data, err := json.Marshal(doc)
+	require.NoError(t, err)
+
+	return data
+}
+
+func TestDocument_Lint(t *testing.T) {
+	doc, err := ParseDocument([]byte(lintedDoc), WithStrictValidation())
+	require.NoError(t, err)
+	require.Empty(t, doc.Lint())
+	require.NoError(t, doc.Validate())
+
+	vm := func(doc map[string]interface{}) map[string]interface{} {
+		return doc["verificationMethod"].([]interface{})[0].(map[string]interface{})
+	}
+
+	for name, tc := range map[string]struct {
+		mutate   func(doc map[string]interface{})
+		expected Finding
+	}{
+		"duplicate verification method": {
+			mutate: func(doc map[string]interface{}) {
+				doc["verificationMethod"] = append(doc["verificationMethod"].([]interface{}), vm(doc))
+			},
+			expected: Finding{
+				Severity: SeverityError, Code: CodeDuplicateID, Path: "verificationMethod[1]",
+				Message: "ID did:example:123#key-1 is already used by verificationMethod[0]",
+			},
+		},
+		"duplicate service": {
+			mutate: func(doc map[string]interface{}) {
+				doc["service"].([]interface{})[0].(map[string]interface{})["id"] = "#key-2"
+			},
+			expected: Finding{
+				Severity: SeverityError, Code: CodeDuplicateID, Path: "service[0]",
+				Message: "ID did:example:123#key-2 is already used by keyAgreement[0]",
+			},
+		},
+		"controller": {
+			mutate: func(doc map[string]interface{}) { vm(doc)["controller"] = "https://example.com" },
+			expected: Finding{
+				Severity: SeverityError, Code: CodeInvalidController, Path: "verificationMethod[0]",
+				Message: `controller "https://example.com" is not a DID`,
+			},
+		},
+		"service endpoint": {
+			mutate: func(doc map[string]interface{}) {
+				doc["service"].([]interface{})[0].(map[string]interface{})["serviceEndpoint"] = []interface{}{}
+			},
+			expected: Finding{
+				Severity: SeverityError, Code: CodeMissingServiceEndpoint, Path: "service[0]",
+				Message: "service did:example:123#agent has no endpoint",
+			},
+		},
+		"recipient key": {
+			mutate: func(doc map[string]interface{}) {
+				doc["service"].([]interface{})[0].(map[string]interface{})["recipientKeys"] = []string{"#key-3"}
+			},
+			expected: Finding{
+				Severity: SeverityWarning, Code: CodeMissingVerificationMethod, Path: "service[0]",
+				Message: "key did:example:123#key-3 is not a verification method of the document",
+			},
+		},
+		"key size": {
+			mutate: func(doc map[string]interface{}) { vm(doc)["publicKeyBase58"] = "3mJr7AoUXx2Wqd" },
+			expected: Finding{
+				Severity: SeverityError, Code: CodeKeyTypeMismatch, Path: "verificationMethod[0]",
+				Message: "public key does not match its type: invalid Ed25519 public key size 10",
+			},
+		},
+		"key type": {
+			mutate: func(doc map[string]interface{}) { vm(doc)["type"] = "EcdsaSecp256k1VerificationKey2019" },
+			expected: Finding{
+				Severity: SeverityError, Code: CodeKeyTypeMismatch, Path: "verificationMethod[0]",
+				Message: "public key does not match its type: secp256k1: invalid public key encoding",
+			},
+		},
+		"JWK type": {
+			mutate: func(doc map[string]interface{}) {
+				delete(vm(doc), "publicKeyBase58")
+				vm(doc)["publicKeyJwk"] = map[string]interface{}{
+					"kty": "OKP", "crv": "X25519", "x": "hSDwCYkwp1R0i33ctD73Wg2_Og0mOBr066SpjqqbTmo",
+				}
+			},
+			expected: Finding{
+				Severity: SeverityError, Code: CodeKeyTypeMismatch, Path: "verificationMethod[0]",
+				Message: "X25519 key in a Ed25519VerificationKey2018 verification method",
+			},
+		},
+		"unknown type": {
+			mutate: func(doc map[string]interface{}) { vm(doc)["type"] = "UnknownKey2022" },
+			expected: Finding{
+				Severity: SeverityWarning, Code: CodeUnknownKeyType, Path: "verificationMethod[0]",
+				Message: "unknown verification method type UnknownKey2022",
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			data := parseLintedDoc(t, tc.mutate)
+
+			doc, err := ParseDocument(data)
+			require.NoError(t, err)
+			require.Equal(t, []Finding{tc.expected}, doc.Lint())
+
+			_, err = ParseDocument(data, WithStrictValidation())
+
+			if tc.expected.Severity == SeverityWarning {
+				require.NoError(t, err)
+
+				return
+			}
+
+			require.ErrorIs(t, err, ErrInvalidDocument)
+
+			validationErr := &ValidationError{}
+			require.ErrorAs(t, err, &validationErr)
+			require.Equal(t, []Finding{tc.expected}, validationErr.Findings)
+		})
+	}
+
+	t.Run("documents built in code", func(t *testing.T) {
+		pubKey, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		key := NewVerificationMethodFromBytes("did:example:123#key-1", "Ed25519VerificationKey2020",
+			"did:example:123", pubKey)
+		missing := NewVerificationMethodFromBytes("did:example:123#key-2", "Ed25519VerificationKey2018",
+			"did:example:123", pubKey)
+
+		doc := BuildDoc(WithVerificationMethod([]VerificationMethod{*key}), WithAuthentication([]Verification{
+			{VerificationMethod: *key, Relationship: Authentication},
+			{VerificationMethod: *missing, Relationship: Authentication},
+		}))
+		doc.ID = "example:123"
+
+		require.Equal(t, []Finding{{
+			Severity: SeverityError, Code: CodeInvalidID, Path: "id",
+			Message: `document ID is not a DID: invalid DID "example:123": missing "did:" scheme at offset 0`,
+		}, {
+			Severity: SeverityError, Code: CodeMissingVerificationMethod, Path: "authentication[1]",
+			Message: "verification method did:example:123#key-2 is not in the document",
+		}}, doc.Lint())
+
+		require.EqualError(t, doc.Validate(), `invalid DID document: error: id: document ID is not a DID: `+
+			`invalid DID "example:123": missing "did:" scheme at offset 0 (invalid-id); `+
+			`error: authentication[1]: verification method did:example:123#key-2 is not in the document `+
+			`(missing-verification-method)`)
+	})
+}
+
+func TestDocument_LintValidDocuments(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "valid_*.jsonld"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+
+		doc, err := ParseDocument(data, WithStrictValidation())
+		if err != nil {
+			// DID resolution results are not documents, their document is linted.
+			resolution, resolutionErr := ParseDocumentResolution(data)
+			require.NoError(t, resolutionErr, "%s: %v", file, err)
+
+			doc = resolution.DIDDocument
+		}
+
+		require.Empty(t, doc.Lint(), file)
+		require.NoError(t, doc.Validate(), file)
+	}
+}
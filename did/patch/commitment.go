@@ -0,0 +1,92 @@
+package patch
+
+import (
+	"bytes"
+	stdcrypto "crypto"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/zRich/zFusion/common/crypto/jwk"
+)
+
+// multihashSHA256 is the multihash prefix of SHA-256 digests: the sha2-256 code and the digest length.
+var multihashSHA256 = []byte{0x12, sha256.Size} //nolint:gochecknoglobals
+
+// ErrCommitmentMismatch is returned when a key or reveal value does not match a commitment.
+var ErrCommitmentMismatch = errors.New("reveal value does not match the commitment")
+
+// RevealValue returns the value revealing key when it is used: the base64url encoded sha2-256 multihash of
+// the JCS canonical form of its required public members, which is the input of its RFC 7638 thumbprint.
+func RevealValue(key *jwk.JWK) (string, error) {
+	thumbprint, err := key.Thumbprint(stdcrypto.SHA256)
+	if err != nil {
+		return "", fmt.Errorf("reveal value: %w", err)
+	}
+
+	return encodeMultihash(thumbprint), nil
+}
+
+// Commitment returns the commitment to key, which is published before the key is used: the base64url
+// encoded sha2-256 multihash of the digest of its reveal value. The commitment does not disclose the key.
+func Commitment(key *jwk.JWK) (string, error) {
+	revealValue, err := RevealValue(key)
+	if err != nil {
+		return "", err
+	}
+
+	return CommitmentOf(revealValue)
+}
+
+// CommitmentOf returns the commitment opened by the given reveal value.
+func CommitmentOf(revealValue string) (string, error) {
+	digest, err := decodeMultihash(revealValue)
+	if err != nil {
+		return "", err
+	}
+
+	commitment := sha256.Sum256(digest)
+
+	return encodeMultihash(commitment[:]), nil
+}
+
+// CheckReveal checks that revealValue reveals key and opens commitment.
+func CheckReveal(key *jwk.JWK, revealValue, commitment string) error {
+	expected, err := RevealValue(key)
+	if err != nil {
+		return err
+	}
+
+	if revealValue != expected {
+		return fmt.Errorf("%w: the reveal value does not match the key", ErrCommitmentMismatch)
+	}
+
+	opened, err := CommitmentOf(revealValue)
+	if err != nil {
+		return err
+	}
+
+	if opened != commitment {
+		return ErrCommitmentMismatch
+	}
+
+	return nil
+}
+
+func encodeMultihash(digest []byte) string {
+	return base64.RawURLEncoding.EncodeToString(append(append([]byte(nil), multihashSHA256...), digest...))
+}
+
+func decodeMultihash(value string) ([]byte, error) {
+	multihash, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid multihash: %v", ErrCommitmentMismatch, err)
+	}
+
+	if len(multihash) != len(multihashSHA256)+sha256.Size || !bytes.HasPrefix(multihash, multihashSHA256) {
+		return nil, fmt.Errorf("%w: not a sha2-256 multihash", ErrCommitmentMismatch)
+	}
+
+	return multihash[len(multihashSHA256):], nil
+}
@@ -0,0 +1,51 @@
+package patch
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zRich/zFusion/common/crypto"
+	"github.com/zRich/zFusion/common/crypto/jwk"
+)
+
+func TestCommitment(t *testing.T) {
+	// RFC 8037 appendix A.1 key, whose RFC 7638 thumbprint is kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k.
+	key, err := jwk.NewFromBytes(crypto.Ed25519, []byte{
+		0xd7, 0x5a, 0x98, 0x01, 0x82, 0xb1, 0x0a, 0xb7, 0xd5, 0x4b, 0xfe, 0xd3, 0xc9, 0x64, 0x07, 0x3a,
+		0x0e, 0xe1, 0x72, 0xf3, 0xda, 0xa6, 0x23, 0x25, 0xaf, 0x02, 0x1a, 0x68, 0xf7, 0x07, 0x51, 0x1a,
+	})
+	require.NoError(t, err)
+
+	revealValue, err := RevealValue(key)
+	require.NoError(t, err)
+	require.Equal(t, "EiCQ-sr-qbFVZphUD3DAEXoi6je9XPPtPEcJPBcHKCtLiQ", revealValue)
+
+	commitment, err := Commitment(key)
+	require.NoError(t, err)
+	require.NotEqual(t, revealValue, commitment)
+
+	opened, err := CommitmentOf(revealValue)
+	require.NoError(t, err)
+	require.Equal(t, commitment, opened)
+
+	require.NoError(t, CheckReveal(key, revealValue, commitment))
+
+	pubKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	other, err := jwk.New(pubKey)
+	require.NoError(t, err)
+
+	otherCommitment, err := Commitment(other)
+	require.NoError(t, err)
+
+	require.ErrorIs(t, CheckReveal(key, revealValue, otherCommitment), ErrCommitmentMismatch)
+	require.ErrorIs(t, CheckReveal(other, revealValue, commitment), ErrCommitmentMismatch)
+
+	for _, invalid := range []string{"", "not base64!", "EiA", commitment[:10]} {
+		_, err = CommitmentOf(invalid)
+		require.ErrorIs(t, err, ErrCommitmentMismatch, invalid)
+	}
+}
@@ -0,0 +1,316 @@
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// JSON Patch operations (RFC 6902).
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+	OpMove    = "move"
+	OpCopy    = "copy"
+	OpTest    = "test"
+)
+
+// Operation is a JSON Patch operation (RFC 6902). Path and From are JSON Pointers (RFC 6901).
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+var errPathNotFound = errors.New("path not found")
+
+// applyJSONPatch applies the operations in order to doc and returns the patched document. doc is modified.
+func applyJSONPatch(doc interface{}, ops []Operation) (interface{}, error) {
+	if len(ops) == 0 {
+		return nil, fmt.Errorf("%w: no JSON Patch operations", ErrInvalidPatch)
+	}
+
+	for i := range ops {
+		var err error
+
+		doc, err = ops[i].apply(doc)
+		if err != nil {
+			return nil, fmt.Errorf("%w: operation %d (%s %s): %v", ErrInvalidPatch, i, ops[i].Op, ops[i].Path, err)
+		}
+	}
+
+	return doc, nil
+}
+
+func (op *Operation) apply(doc interface{}) (interface{}, error) {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case OpAdd, OpReplace, OpTest:
+		var value interface{}
+
+		if len(op.Value) == 0 {
+			return nil, errors.New("missing value")
+		}
+
+		if err = json.Unmarshal(op.Value, &value); err != nil {
+			return nil, fmt.Errorf("invalid value: %w", err)
+		}
+
+		switch op.Op {
+		case OpAdd:
+			return add(doc, path, value)
+		case OpReplace:
+			if len(path) == 0 {
+				return value, nil
+			}
+
+			if doc, err = remove(doc, path); err != nil {
+				return nil, err
+			}
+
+			return add(doc, path, value)
+		default:
+			current, err := get(doc, path)
+			if err != nil {
+				return nil, err
+			}
+
+			if !reflect.DeepEqual(current, value) {
+				return nil, errors.New("test failed")
+			}
+
+			return doc, nil
+		}
+	case OpRemove:
+		return remove(doc, path)
+	case OpMove, OpCopy:
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := get(doc, from)
+		if err != nil {
+			return nil, err
+		}
+
+		if op.Op == OpCopy {
+			return add(doc, path, deepCopy(value))
+		}
+
+		if strings.HasPrefix(op.Path+"/", op.From+"/") && op.Path != op.From {
+			return nil, errors.New("cannot move a value into one of its children")
+		}
+
+		if doc, err = remove(doc, from); err != nil {
+			return nil, err
+		}
+
+		return add(doc, path, value)
+	default:
+		return nil, fmt.Errorf("unsupported operation %q", op.Op)
+	}
+}
+
+// parsePointer parses a JSON Pointer into its unescaped reference tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON Pointer %q", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(tokens[i], "~1", "/"), "~0", "~")
+	}
+
+	return tokens, nil
+}
+
+func get(doc interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch node := doc.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, errPathNotFound
+			}
+
+			doc = value
+		case []interface{}:
+			i, err := arrayIndex(token, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+
+			doc = node[i]
+		default:
+			return nil, errPathNotFound
+		}
+	}
+
+	return doc, nil
+}
+
+// add adds value at path and returns the updated document.
+func add(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	token := path[0]
+
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		if len(path) == 1 {
+			node[token] = value
+
+			return node, nil
+		}
+
+		child, ok := node[token]
+		if !ok {
+			return nil, errPathNotFound
+		}
+
+		child, err := add(child, path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+
+		node[token] = child
+
+		return node, nil
+	case []interface{}:
+		if len(path) == 1 {
+			i := len(node)
+
+			if token != "-" {
+				var err error
+
+				if i, err = arrayIndex(token, len(node)); err != nil {
+					return nil, err
+				}
+			}
+
+			node = append(node, nil)
+			copy(node[i+1:], node[i:])
+			node[i] = value
+
+			return node, nil
+		}
+
+		i, err := arrayIndex(token, len(node)-1)
+		if err != nil {
+			return nil, err
+		}
+
+		if node[i], err = add(node[i], path[1:], value); err != nil {
+			return nil, err
+		}
+
+		return node, nil
+	default:
+		return nil, errPathNotFound
+	}
+}
+
+// remove removes the value at path and returns the updated document.
+func remove(doc interface{}, path []string) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, errors.New("cannot remove the whole document")
+	}
+
+	token := path[0]
+
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		child, ok := node[token]
+		if !ok {
+			return nil, errPathNotFound
+		}
+
+		if len(path) == 1 {
+			delete(node, token)
+
+			return node, nil
+		}
+
+		child, err := remove(child, path[1:])
+		if err != nil {
+			return nil, err
+		}
+
+		node[token] = child
+
+		return node, nil
+	case []interface{}:
+		i, err := arrayIndex(token, len(node)-1)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(path) == 1 {
+			return append(node[:i], node[i+1:]...), nil
+		}
+
+		if node[i], err = remove(node[i], path[1:]); err != nil {
+			return nil, err
+		}
+
+		return node, nil
+	default:
+		return nil, errPathNotFound
+	}
+}
+
+// arrayIndex parses an array index token, which must not exceed max.
+func arrayIndex(token string, max int) (int, error) {
+	if token == "" || len(token) > 1 && token[0] == '0' {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+
+	if i > max {
+		return 0, fmt.Errorf("%w: array index %d out of bounds", errPathNotFound, i)
+	}
+
+	return i, nil
+}
+
+func deepCopy(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[k] = deepCopy(e)
+		}
+
+		return m
+	case []interface{}:
+		a := make([]interface{}, len(v))
+		for i, e := range v {
+			a[i] = deepCopy(e)
+		}
+
+		return a
+	default:
+		return value
+	}
+}
@@ -0,0 +1,145 @@
+package patch
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyJSONPatch(t *testing.T) {
+	// examples of RFC 6902 appendix A.
+	for _, tc := range []struct {
+		name     string
+		doc      string
+		patch    string
+		expected string
+	}{
+		{
+			"add an object member", `{"foo": "bar"}`,
+			`[{"op": "add", "path": "/baz", "value": "qux"}]`,
+			`{"baz": "qux", "foo": "bar"}`,
+		},
+		{
+			"add an array element", `{"foo": ["bar", "baz"]}`,
+			`[{"op": "add", "path": "/foo/1", "value": "qux"}]`,
+			`{"foo": ["bar", "qux", "baz"]}`,
+		},
+		{
+			"remove an object member", `{"baz": "qux", "foo": "bar"}`,
+			`[{"op": "remove", "path": "/baz"}]`,
+			`{"foo": "bar"}`,
+		},
+		{
+			"remove an array element", `{"foo": ["bar", "qux", "baz"]}`,
+			`[{"op": "remove", "path": "/foo/1"}]`,
+			`{"foo": ["bar", "baz"]}`,
+		},
+		{
+			"replace a value", `{"baz": "qux", "foo": "bar"}`,
+			`[{"op": "replace", "path": "/baz", "value": "boo"}]`,
+			`{"baz": "boo", "foo": "bar"}`,
+		},
+		{
+			"move a value", `{"foo": {"bar": "baz", "waldo": "fred"}, "qux": {"corge": "grault"}}`,
+			`[{"op": "move", "from": "/foo/waldo", "path": "/qux/thud"}]`,
+			`{"foo": {"bar": "baz"}, "qux": {"corge": "grault", "thud": "fred"}}`,
+		},
+		{
+			"move an array element", `{"foo": ["all", "grass", "cows", "eat"]}`,
+			`[{"op": "move", "from": "/foo/1", "path": "/foo/3"}]`,
+			`{"foo": ["all", "cows", "eat", "grass"]}`,
+		},
+		{
+			"test a value", `{"baz": "qux", "foo": ["a", 2, "c"]}`,
+			`[{"op": "test", "path": "/baz", "value": "qux"}, {"op": "test", "path": "/foo/1", "value": 2}]`,
+			`{"baz": "qux", "foo": ["a", 2, "c"]}`,
+		},
+		{
+			"add a nested member object", `{"foo": "bar"}`,
+			`[{"op": "add", "path": "/child", "value": {"grandchild": {}}}]`,
+			`{"foo": "bar", "child": {"grandchild": {}}}`,
+		},
+		{
+			"escape ordering", `{"/": 9, "~1": 10}`,
+			`[{"op": "test", "path": "/~01", "value": 10}]`,
+			`{"/": 9, "~1": 10}`,
+		},
+		{
+			"add to a nonexistent target", `{"foo": "bar"}`,
+			`[{"op": "add", "path": "/baz/bat", "value": "qux"}]`,
+			``,
+		},
+		{
+			"test a value failure", `{"baz": "qux"}`,
+			`[{"op": "test", "path": "/baz", "value": "bar"}]`,
+			``,
+		},
+		{
+			"add an array value", `{"foo": ["bar"]}`,
+			`[{"op": "add", "path": "/foo/-", "value": ["abc", "def"]}]`,
+			`{"foo": ["bar", ["abc", "def"]]}`,
+		},
+		{
+			"invalid array index", `{"foo": ["bar"]}`,
+			`[{"op": "add", "path": "/foo/01", "value": "baz"}]`,
+			``,
+		},
+		{
+			"array index out of bounds", `{"foo": ["bar"]}`,
+			`[{"op": "remove", "path": "/foo/1"}]`,
+			``,
+		},
+		{
+			"copy a value", `{"foo": {"bar": 1}}`,
+			`[{"op": "copy", "from": "/foo", "path": "/baz"}, {"op": "replace", "path": "/baz/bar", "value": 2}]`,
+			`{"foo": {"bar": 1}, "baz": {"bar": 2}}`,
+		},
+		{
+			"replace the document", `{"foo": "bar"}`,
+			`[{"op": "replace", "path": "", "value": {"baz": "qux"}}]`,
+			`{"baz": "qux"}`,
+		},
+		{
+			"move into a child", `{"foo": {"bar": 1}}`,
+			`[{"op": "move", "from": "/foo", "path": "/foo/baz"}]`,
+			``,
+		},
+		{
+			"missing value", `{"foo": "bar"}`,
+			`[{"op": "add", "path": "/baz"}]`,
+			``,
+		},
+		{
+			"invalid pointer", `{"foo": "bar"}`,
+			`[{"op": "remove", "path": "foo"}]`,
+			``,
+		},
+		{
+			"unsupported operation", `{"foo": "bar"}`,
+			`[{"op": "merge", "path": "/foo"}]`,
+			``,
+		},
+	} {
+		var doc interface{}
+
+		require.NoError(t, json.Unmarshal([]byte(tc.doc), &doc), tc.name)
+
+		var ops []Operation
+
+		require.NoError(t, json.Unmarshal([]byte(tc.patch), &ops), tc.name)
+
+		patched, err := applyJSONPatch(doc, ops)
+		if tc.expected == "" {
+			require.ErrorIs(t, err, ErrInvalidPatch, tc.name)
+
+			continue
+		}
+
+		require.NoError(t, err, tc.name)
+
+		patchedBytes, err := json.Marshal(patched)
+		require.NoError(t, err)
+		require.JSONEq(t, tc.expected, string(patchedBytes), tc.name)
+	}
+}
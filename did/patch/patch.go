@@ -0,0 +1,430 @@
+// Package patch updates DID documents with Sidetree-style patches, and binds successive updates to keys
+// with reveal and commit values.
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/zRich/zFusion/did"
+)
+
+// Action is the action of a patch.
+type Action string
+
+// Patch actions.
+const (
+	// ActionAddVerificationMethods adds verification methods to the document.
+	ActionAddVerificationMethods Action = "add-verification-methods"
+	// ActionRemoveVerificationMethods removes verification methods, and their references, from the document.
+	ActionRemoveVerificationMethods Action = "remove-verification-methods"
+	// ActionAddServices adds services to the document.
+	ActionAddServices Action = "add-services"
+	// ActionRemoveServices removes services from the document.
+	ActionRemoveServices Action = "remove-services"
+	// ActionAddRelationships adds verification methods to a verification relationship.
+	ActionAddRelationships Action = "add-relationships"
+	// ActionRemoveRelationships removes verification methods from a verification relationship.
+	ActionRemoveRelationships Action = "remove-relationships"
+	// ActionReplace replaces the whole document.
+	ActionReplace Action = "replace"
+	// ActionJSONPatch applies a JSON Patch (RFC 6902) to the document.
+	ActionJSONPatch Action = "ietf-json-patch"
+)
+
+const (
+	jsonldID                 = "id"
+	jsonldVerificationMethod = "verificationMethod"
+	jsonldService            = "service"
+)
+
+// relationships maps verification relationships to their document members.
+var relationships = map[did.VerificationRelationship]string{ //nolint:gochecknoglobals
+	did.Authentication:       "authentication",
+	did.AssertionMethod:      "assertionMethod",
+	did.CapabilityDelegation: "capabilityDelegation",
+	did.CapabilityInvocation: "capabilityInvocation",
+	did.KeyAgreement:         "keyAgreement",
+}
+
+// ErrInvalidPatch is returned when a patch is malformed or does not apply to the document.
+var ErrInvalidPatch = errors.New("invalid patch")
+
+// Patch is a change to a DID document. Verification methods and services are in their DID document JSON
+// representation, and IDs may be relative to the document DID, such as "#key-1".
+type Patch struct {
+	Action Action `json:"action"`
+	// VerificationMethods are the verification methods added by ActionAddVerificationMethods.
+	VerificationMethods []map[string]interface{} `json:"verificationMethods,omitempty"`
+	// Services are the services added by ActionAddServices.
+	Services []map[string]interface{} `json:"services,omitempty"`
+	// Relationship is the verification relationship of ActionAddRelationships and ActionRemoveRelationships,
+	// such as "authentication".
+	Relationship string `json:"relationship,omitempty"`
+	// IDs are the verification methods or services removed, or the verification methods added to or removed
+	// from Relationship.
+	IDs []string `json:"ids,omitempty"`
+	// Document is the new document of ActionReplace.
+	Document map[string]interface{} `json:"document,omitempty"`
+	// Patches are the operations of ActionJSONPatch.
+	Patches []Operation `json:"patches,omitempty"`
+}
+
+// AddVerificationMethods returns a patch adding the given verification methods.
+func AddVerificationMethods(vms ...did.VerificationMethod) (Patch, error) {
+	raw, err := rawMembers(did.BuildDoc(did.WithVerificationMethod(vms)), jsonldVerificationMethod)
+	if err != nil {
+		return Patch{}, err
+	}
+
+	return Patch{Action: ActionAddVerificationMethods, VerificationMethods: raw}, nil
+}
+
+// RemoveVerificationMethods returns a patch removing the given verification methods.
+func RemoveVerificationMethods(ids ...string) Patch {
+	return Patch{Action: ActionRemoveVerificationMethods, IDs: ids}
+}
+
+// AddServices returns a patch adding the given services.
+func AddServices(services ...did.Service) (Patch, error) {
+	raw, err := rawMembers(did.BuildDoc(did.WithService(services)), jsonldService)
+	if err != nil {
+		return Patch{}, err
+	}
+
+	return Patch{Action: ActionAddServices, Services: raw}, nil
+}
+
+// RemoveServices returns a patch removing the given services.
+func RemoveServices(ids ...string) Patch {
+	return Patch{Action: ActionRemoveServices, IDs: ids}
+}
+
+// AddRelationships returns a patch adding references to the given verification methods to relationship r.
+func AddRelationships(r did.VerificationRelationship, ids ...string) (Patch, error) {
+	name, ok := relationships[r]
+	if !ok {
+		return Patch{}, fmt.Errorf("%w: unsupported verification relationship %d", ErrInvalidPatch, r)
+	}
+
+	return Patch{Action: ActionAddRelationships, Relationship: name, IDs: ids}, nil
+}
+
+// RemoveRelationships returns a patch removing the given verification methods from relationship r.
+func RemoveRelationships(r did.VerificationRelationship, ids ...string) (Patch, error) {
+	name, ok := relationships[r]
+	if !ok {
+		return Patch{}, fmt.Errorf("%w: unsupported verification relationship %d", ErrInvalidPatch, r)
+	}
+
+	return Patch{Action: ActionRemoveRelationships, Relationship: name, IDs: ids}, nil
+}
+
+// Replace returns a patch replacing the document with doc. doc may omit its ID.
+func Replace(doc *did.Document) (Patch, error) {
+	raw, err := rawDocument(doc)
+	if err != nil {
+		return Patch{}, err
+	}
+
+	return Patch{Action: ActionReplace, Document: raw}, nil
+}
+
+// JSONPatch returns a patch applying the given JSON Patch operations.
+func JSONPatch(ops ...Operation) Patch {
+	return Patch{Action: ActionJSONPatch, Patches: ops}
+}
+
+// Apply applies the patches in order to doc and returns the new document, which must be valid in the sense of
+// did.WithStrictValidation and keep the ID of doc. doc is not modified.
+func Apply(doc *did.Document, patches ...Patch) (*did.Document, error) {
+	raw, err := rawDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range patches {
+		raw, err = patches[i].apply(doc.ID, raw)
+		if err != nil {
+			return nil, fmt.Errorf("apply %s patch: %w", patches[i].Action, err)
+		}
+	}
+
+	if id, _ := raw[jsonldID].(string); id != doc.ID { //nolint:errcheck
+		return nil, fmt.Errorf("%w: the document ID cannot be changed", ErrInvalidPatch)
+	}
+
+	docBytes, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("marshal patched document: %w", err)
+	}
+
+	patched, err := did.ParseDocument(docBytes, did.WithStrictValidation())
+	if err != nil {
+		return nil, fmt.Errorf("patched document: %w", err)
+	}
+
+	return patched, nil
+}
+
+func (p *Patch) apply(didID string, raw map[string]interface{}) (map[string]interface{}, error) {
+	switch p.Action {
+	case ActionAddVerificationMethods:
+		return raw, addMembers(didID, raw, jsonldVerificationMethod, p.VerificationMethods)
+	case ActionRemoveVerificationMethods:
+		return raw, removeVerificationMethods(didID, raw, p.IDs)
+	case ActionAddServices:
+		return raw, addMembers(didID, raw, jsonldService, p.Services)
+	case ActionRemoveServices:
+		return raw, removeServices(didID, raw, p.IDs)
+	case ActionAddRelationships, ActionRemoveRelationships:
+		if !isRelationship(p.Relationship) {
+			return nil, fmt.Errorf("%w: unsupported verification relationship %q", ErrInvalidPatch, p.Relationship)
+		}
+
+		if p.Action == ActionAddRelationships {
+			return raw, addRelationships(didID, raw, p.Relationship, p.IDs)
+		}
+
+		return raw, removeRelationships(didID, raw, p.Relationship, p.IDs)
+	case ActionReplace:
+		return replace(didID, p.Document)
+	case ActionJSONPatch:
+		patched, err := applyJSONPatch(raw, p.Patches)
+		if err != nil {
+			return nil, err
+		}
+
+		doc, ok := patched.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%w: the patched document is not a JSON object", ErrInvalidPatch)
+		}
+
+		return doc, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported action %q", ErrInvalidPatch, p.Action)
+	}
+}
+
+func addMembers(didID string, raw map[string]interface{}, member string, values []map[string]interface{}) error {
+	if len(values) == 0 {
+		return fmt.Errorf("%w: nothing to add", ErrInvalidPatch)
+	}
+
+	entries := arrayEntry(raw, member)
+
+	for _, value := range values {
+		id, _ := value[jsonldID].(string) //nolint:errcheck
+		if id == "" {
+			return fmt.Errorf("%w: %s without ID", ErrInvalidPatch, member)
+		}
+
+		if indexOf(didID, entries, id) >= 0 || member == jsonldVerificationMethod && embedded(didID, raw, id) {
+			return fmt.Errorf("%w: %s %s already exists", ErrInvalidPatch, member, id)
+		}
+
+		entries = append(entries, deepCopy(value))
+	}
+
+	raw[member] = entries
+
+	return nil
+}
+
+func removeVerificationMethods(didID string, raw map[string]interface{}, ids []string) error {
+	if len(ids) == 0 {
+		return fmt.Errorf("%w: nothing to remove", ErrInvalidPatch)
+	}
+
+	for _, id := range ids {
+		found := removeEntry(didID, raw, jsonldVerificationMethod, id)
+
+		for _, member := range relationships {
+			for removeEntry(didID, raw, member, id) {
+				found = true
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("%w: verification method %s not found", ErrInvalidPatch, id)
+		}
+	}
+
+	return nil
+}
+
+func removeServices(didID string, raw map[string]interface{}, ids []string) error {
+	if len(ids) == 0 {
+		return fmt.Errorf("%w: nothing to remove", ErrInvalidPatch)
+	}
+
+	for _, id := range ids {
+		if !removeEntry(didID, raw, jsonldService, id) {
+			return fmt.Errorf("%w: service %s not found", ErrInvalidPatch, id)
+		}
+	}
+
+	return nil
+}
+
+func addRelationships(didID string, raw map[string]interface{}, member string, ids []string) error {
+	if len(ids) == 0 {
+		return fmt.Errorf("%w: nothing to add", ErrInvalidPatch)
+	}
+
+	entries := arrayEntry(raw, member)
+
+	for _, id := range ids {
+		if indexOf(didID, entries, id) >= 0 {
+			return fmt.Errorf("%w: %s is already in %s", ErrInvalidPatch, id, member)
+		}
+
+		if indexOf(didID, arrayEntry(raw, jsonldVerificationMethod), id) < 0 {
+			return fmt.Errorf("%w: verification method %s not found", ErrInvalidPatch, id)
+		}
+
+		entries = append(entries, id)
+	}
+
+	raw[member] = entries
+
+	return nil
+}
+
+func removeRelationships(didID string, raw map[string]interface{}, member string, ids []string) error {
+	if len(ids) == 0 {
+		return fmt.Errorf("%w: nothing to remove", ErrInvalidPatch)
+	}
+
+	for _, id := range ids {
+		if !removeEntry(didID, raw, member, id) {
+			return fmt.Errorf("%w: %s is not in %s", ErrInvalidPatch, id, member)
+		}
+	}
+
+	return nil
+}
+
+func replace(didID string, doc map[string]interface{}) (map[string]interface{}, error) {
+	if doc == nil {
+		return nil, fmt.Errorf("%w: no document", ErrInvalidPatch)
+	}
+
+	replaced, ok := deepCopy(doc).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: invalid document", ErrInvalidPatch)
+	}
+
+	if id, ok := replaced[jsonldID]; !ok || id == "" {
+		replaced[jsonldID] = didID
+	}
+
+	return replaced, nil
+}
+
+// removeEntry removes the first entry with the given ID, referenced or embedded, from the member array.
+func removeEntry(didID string, raw map[string]interface{}, member, id string) bool {
+	entries := arrayEntry(raw, member)
+
+	i := indexOf(didID, entries, id)
+	if i < 0 {
+		return false
+	}
+
+	entries = append(entries[:i], entries[i+1:]...)
+	if len(entries) == 0 {
+		delete(raw, member)
+	} else {
+		raw[member] = entries
+	}
+
+	return true
+}
+
+// embedded tells whether a verification method with the given ID is embedded in a verification relationship.
+func embedded(didID string, raw map[string]interface{}, id string) bool {
+	for _, member := range relationships {
+		for _, entry := range arrayEntry(raw, member) {
+			if m, ok := entry.(map[string]interface{}); ok && sameID(didID, m[jsonldID], id) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// indexOf returns the index of the entry with the given ID, either a reference or an object, or -1.
+func indexOf(didID string, entries []interface{}, id string) int {
+	for i, entry := range entries {
+		if m, ok := entry.(map[string]interface{}); ok {
+			entry = m[jsonldID]
+		}
+
+		if sameID(didID, entry, id) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// sameID compares DID URLs, relative ones being resolved against the document DID.
+func sameID(didID string, entry interface{}, id string) bool {
+	s, ok := entry.(string)
+
+	return ok && did.AbsoluteID(didID, s) == did.AbsoluteID(didID, id)
+}
+
+func arrayEntry(raw map[string]interface{}, member string) []interface{} {
+	entries, _ := raw[member].([]interface{}) //nolint:errcheck
+
+	return entries
+}
+
+func isRelationship(member string) bool {
+	for _, name := range relationships {
+		if name == member {
+			return true
+		}
+	}
+
+	return false
+}
+
+func rawDocument(doc *did.Document) (map[string]interface{}, error) {
+	docBytes, err := doc.JSONBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	raw := map[string]interface{}{}
+	if err = json.Unmarshal(docBytes, &raw); err != nil {
+		return nil, fmt.Errorf("unmarshal document: %w", err)
+	}
+
+	return raw, nil
+}
+
+// rawMembers returns the JSON representation of the given member of doc, which are either verification
+// methods or services.
+func rawMembers(doc *did.Document, member string) ([]map[string]interface{}, error) {
+	raw, err := rawDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var members []map[string]interface{}
+
+	for _, entry := range arrayEntry(raw, member) {
+		m, ok := entry.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%w: invalid %s", ErrInvalidPatch, member)
+		}
+
+		members = append(members, m)
+	}
+
+	return members, nil
+}
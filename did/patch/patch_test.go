@@ -0,0 +1,201 @@
+package patch
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zRich/zFusion/common/model"
+	"github.com/zRich/zFusion/did"
+)
+
+const testDID = "did:example:123456789abcdefghi"
+
+func newKey(t *testing.T, id string) *did.VerificationMethod {
+	t.Helper()
+
+	pubKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	return did.NewVerificationMethodFromBytes(id, "Ed25519VerificationKey2018", testDID, pubKey)
+}
+
+func newDoc(t *testing.T) *did.Document {
+	t.Helper()
+
+	key1 := newKey(t, testDID+"#key-1")
+
+	doc := did.BuildDoc(
+		did.WithVerificationMethod([]did.VerificationMethod{*key1}),
+		did.WithAuthentication([]did.Verification{*did.NewReferencedVerification(key1, did.Authentication)}),
+		did.WithCapabilityInvocation([]did.Verification{
+			*did.NewReferencedVerification(key1, did.CapabilityInvocation),
+		}),
+		did.WithService([]did.Service{{
+			ID: testDID + "#hub", Type: "IdentityHub",
+			ServiceEndpoint: model.NewDIDCoreEndpoint([]string{"https://hub.example.com"}),
+		}}),
+	)
+	doc.ID = testDID
+
+	return doc
+}
+
+func TestApply(t *testing.T) {
+	doc := newDoc(t)
+	key2 := newKey(t, "#key-2")
+
+	t.Run("key rotation", func(t *testing.T) {
+		addKey, err := AddVerificationMethods(*key2)
+		require.NoError(t, err)
+
+		addAuth, err := AddRelationships(did.Authentication, "#key-2")
+		require.NoError(t, err)
+
+		addInvocation, err := AddRelationships(did.CapabilityInvocation, testDID+"#key-2")
+		require.NoError(t, err)
+
+		rotated, err := Apply(doc, addKey, addAuth, addInvocation, RemoveVerificationMethods("#key-1"))
+		require.NoError(t, err)
+
+		require.Equal(t, testDID, rotated.ID)
+		require.Len(t, rotated.VerificationMethod, 1)
+		require.Equal(t, testDID+"#key-2", rotated.VerificationMethod[0].ID)
+		require.Equal(t, key2.Value, rotated.VerificationMethod[0].Value)
+		require.Len(t, rotated.Authentication, 1)
+		require.Equal(t, testDID+"#key-2", rotated.Authentication[0].VerificationMethod.ID)
+		require.Len(t, rotated.CapabilityInvocation, 1)
+
+		// the original document is not modified
+		require.Len(t, doc.VerificationMethod, 1)
+		require.Equal(t, testDID+"#key-1", doc.VerificationMethod[0].ID)
+	})
+
+	t.Run("services", func(t *testing.T) {
+		addService, err := AddServices(did.Service{
+			ID: "#agent", Type: "DIDCommMessaging",
+			ServiceEndpoint: model.NewDIDCoreEndpoint([]string{"https://agent.example.com"}),
+		})
+		require.NoError(t, err)
+
+		patched, err := Apply(doc, addService, RemoveServices(testDID+"#hub"))
+		require.NoError(t, err)
+		require.Len(t, patched.Service, 1)
+		require.Equal(t, testDID+"#agent", patched.Service[0].ID)
+
+		uri, err := patched.Service[0].ServiceEndpoint.URI()
+		require.NoError(t, err)
+		require.Equal(t, "https://agent.example.com", uri)
+	})
+
+	t.Run("remove relationship", func(t *testing.T) {
+		removeAuth, err := RemoveRelationships(did.Authentication, "#key-1")
+		require.NoError(t, err)
+
+		patched, err := Apply(doc, removeAuth)
+		require.NoError(t, err)
+		require.Empty(t, patched.Authentication)
+		require.Len(t, patched.VerificationMethod, 1)
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		replacement := did.BuildDoc(
+			did.WithVerificationMethod([]did.VerificationMethod{*key2}),
+			did.WithCapabilityInvocation([]did.Verification{
+				*did.NewReferencedVerification(key2, did.CapabilityInvocation),
+			}),
+		)
+
+		replace, err := Replace(replacement)
+		require.NoError(t, err)
+
+		replaced, err := Apply(doc, replace)
+		require.NoError(t, err)
+		require.Equal(t, testDID, replaced.ID)
+		require.Len(t, replaced.VerificationMethod, 1)
+		require.Equal(t, testDID+"#key-2", replaced.VerificationMethod[0].ID)
+		require.Empty(t, replaced.Service)
+	})
+
+	t.Run("JSON patch", func(t *testing.T) {
+		patched, err := Apply(doc, JSONPatch(
+			Operation{Op: OpTest, Path: "/service/0/type", Value: json.RawMessage(`"IdentityHub"`)},
+			Operation{Op: OpAdd, Path: "/alsoKnownAs", Value: json.RawMessage(`["https://example.com/me"]`)},
+			Operation{
+				Op: OpReplace, Path: "/service/0/serviceEndpoint", Value: json.RawMessage(`"https://new.example.com"`),
+			},
+		))
+		require.NoError(t, err)
+		require.Equal(t, []string{"https://example.com/me"}, patched.AlsoKnownAs)
+
+		uri, err := patched.Service[0].ServiceEndpoint.URI()
+		require.NoError(t, err)
+		require.Equal(t, "https://new.example.com", uri)
+	})
+
+	t.Run("patches round trip through JSON", func(t *testing.T) {
+		addKey, err := AddVerificationMethods(*key2)
+		require.NoError(t, err)
+
+		patchBytes, err := json.Marshal([]Patch{addKey, RemoveServices("#hub")})
+		require.NoError(t, err)
+
+		var patches []Patch
+		require.NoError(t, json.Unmarshal(patchBytes, &patches))
+
+		patched, err := Apply(doc, patches...)
+		require.NoError(t, err)
+		require.Len(t, patched.VerificationMethod, 2)
+		require.Empty(t, patched.Service)
+	})
+
+	t.Run("invalid patches", func(t *testing.T) {
+		addKey1, err := AddVerificationMethods(*newKey(t, "#key-1"))
+		require.NoError(t, err)
+
+		addAuth, err := AddRelationships(did.Authentication, "#key-1")
+		require.NoError(t, err)
+
+		addUnknown, err := AddRelationships(did.AssertionMethod, "#key-9")
+		require.NoError(t, err)
+
+		replace, err := Replace(did.BuildDoc())
+		require.NoError(t, err)
+
+		replace.Document["id"] = "did:example:other"
+
+		for name, p := range map[string]Patch{
+			"duplicate verification method": addKey1,
+			"duplicate relationship":        addAuth,
+			"unknown relationship key":      addUnknown,
+			"unknown verification method":   RemoveVerificationMethods("#key-9"),
+			"unknown service":               RemoveServices("#key-1"),
+			"empty removal":                 RemoveServices(),
+			"unsupported relationship": {
+				Action: ActionAddRelationships, Relationship: "controller", IDs: []string{"#key-1"},
+			},
+			"unsupported action":       {Action: "add-public-keys"},
+			"replace without document": {Action: ActionReplace},
+			"replace changing the DID": replace,
+			"JSON patch changing the DID": JSONPatch(
+				Operation{Op: OpReplace, Path: "/id", Value: json.RawMessage(`"did:example:other"`)},
+			),
+			"failed JSON patch test": JSONPatch(
+				Operation{Op: OpTest, Path: "/id", Value: json.RawMessage(`"did:example:other"`)},
+			),
+		} {
+			_, err = Apply(doc, p)
+			require.ErrorIs(t, err, ErrInvalidPatch, name)
+		}
+
+		_, err = AddRelationships(did.VerificationRelationshipGeneral, "#key-1")
+		require.ErrorIs(t, err, ErrInvalidPatch)
+
+		// dangling references make the patched document invalid
+		invalid, err := Apply(doc, JSONPatch(Operation{Op: OpRemove, Path: "/verificationMethod/0"}))
+		require.Error(t, err)
+		require.Nil(t, invalid)
+	})
+}
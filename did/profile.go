@@ -0,0 +1,246 @@
+package did
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/zRich/zFusion/common/multicodec"
+)
+
+// Profile is a serialization profile of DID documents. Profiles other than ProfileDefault contain interop fixes
+// that break compliance with the DID spec, for partners which have not caught up with it yet.
+type Profile string
+
+const (
+	// ProfileDefault serializes documents as specified by DID Core.
+	ProfileDefault Profile = "default"
+	// ProfileACAPy serializes documents for aca-py, with the old V1 context, key references serialized as
+	// {"publicKey": <key reference>} (aca-py issue #1104) and did:key service keys serialized as raw base58 keys
+	// (aca-py issue #1106). Parse such documents with WithLegacyHandling.
+	ProfileACAPy Profile = "aca-py"
+	// ProfileLegacy serializes documents in the v0.11 shape, with publicKey verification methods that have an
+	// owner, key references serialized as {"type": <key type>, "publicKey": <key reference>} and signatureValue
+	// proofs.
+	ProfileLegacy Profile = "legacy"
+)
+
+// SerializeOption configures Document.JSONBytes.
+type SerializeOption func(opts *serializeOpts)
+
+type serializeOpts struct {
+	profile Profile
+}
+
+// WithProfile selects the serialization profile.
+func WithProfile(profile Profile) SerializeOption {
+	return func(opts *serializeOpts) {
+		opts.profile = profile
+	}
+}
+
+// SerializeInterop serializes the DID doc with the default profile.
+//
+// Deprecated: select a profile with JSONBytes(WithProfile(ProfileACAPy)).
+func (doc *Document) SerializeInterop() ([]byte, error) {
+	return doc.JSONBytes()
+}
+
+// context returns the context which drives the serialization of verification methods and proofs.
+func (p Profile) context(doc *Document) (string, error) {
+	switch p {
+	case ProfileDefault:
+		context, ok := ContextPeekString(doc.Context)
+		if !ok {
+			context = ContextV1
+		}
+
+		return context, nil
+	case ProfileACAPy:
+		return ContextV1Old, nil
+	case ProfileLegacy:
+		return contextV011, nil
+	default:
+		return "", fmt.Errorf("unsupported serialization profile: %s", p)
+	}
+}
+
+type verificationPopulator func(context, baseURI, didID string, verifications []Verification) ([]interface{}, error)
+
+func (p Profile) verificationPopulator() verificationPopulator {
+	switch p {
+	case ProfileACAPy:
+		return populateRawVerificationInterop
+	case ProfileLegacy:
+		return populateRawVerificationLegacy
+	default:
+		return populateRawVerification
+	}
+}
+
+func (p Profile) services(doc *Document) ([]map[string]interface{}, error) {
+	if p == ProfileACAPy {
+		return populateRawServicesInterop(doc.Service, doc.ID, doc.processingMeta.baseURI)
+	}
+
+	return populateRawServices(doc.Service, doc.ID, doc.processingMeta.baseURI), nil
+}
+
+// shape adapts the top level members of the serialized document to the profile.
+func (p Profile) shape(raw *rawDoc, context string) {
+	switch p {
+	case ProfileACAPy:
+		raw.Context = []string{context}
+		raw.AlsoKnownAs = nil
+	case ProfileLegacy:
+		raw.Context = []string{context}
+		raw.PublicKey = raw.VerificationMethod
+		raw.VerificationMethod = nil
+	}
+}
+
+func populateRawVerificationInterop(
+	context, baseURI, didID string, verifications []Verification,
+) ([]interface{}, error) {
+	var rawVerifications []interface{}
+
+	for _, v := range verifications {
+		if v.Embedded {
+			vm, err := populateRawVerificationMethod(context, didID, baseURI, &v.VerificationMethod)
+			if err != nil {
+				return nil, err
+			}
+
+			rawVerifications = append(rawVerifications, vm)
+		} else {
+			// Interop: emit key reference as {"publicKey":<key reference>} instead of <key reference>
+			// see aca-py issue https://github.com/hyperledger/aries-cloudagent-python/issues/1104
+			keyRef := map[string]interface{}{jsonldPublicKey: rawKeyReference(&v.VerificationMethod, baseURI, didID)}
+
+			rawVerifications = append(rawVerifications, keyRef)
+		}
+	}
+
+	return rawVerifications, nil
+}
+
+func populateRawVerificationLegacy(
+	context, baseURI, didID string, verifications []Verification,
+) ([]interface{}, error) {
+	var rawVerifications []interface{}
+
+	for _, v := range verifications {
+		if v.Embedded {
+			vm, err := populateRawVerificationMethod(context, didID, baseURI, &v.VerificationMethod)
+			if err != nil {
+				return nil, err
+			}
+
+			rawVerifications = append(rawVerifications, vm)
+
+			continue
+		}
+
+		rawVerifications = append(rawVerifications, map[string]interface{}{
+			jsonldType:      v.VerificationMethod.Type,
+			jsonldPublicKey: rawKeyReference(&v.VerificationMethod, baseURI, didID),
+		})
+	}
+
+	return rawVerifications, nil
+}
+
+func rawKeyReference(vm *VerificationMethod, baseURI, didID string) string {
+	if vm.relativeURL {
+		return makeRelativeDIDURL(vm.ID, baseURI, didID)
+	}
+
+	return vm.ID
+}
+
+func populateRawServicesInterop(services []Service, didID, baseURI string) ([]map[string]interface{}, error) {
+	var rawServices []map[string]interface{}
+
+	for i := range services {
+		rawService := make(map[string]interface{})
+
+		for k, v := range services[i].Properties {
+			rawService[k] = v
+		}
+
+		routingKeys, err := rawKeysInterop(services[i].RoutingKeys, services[i].routingKeysRelativeURL, didID, baseURI)
+		if err != nil {
+			return nil, err
+		}
+
+		recipientKeys, err := rawKeysInterop(services[i].RecipientKeys, services[i].recipientKeysRelativeURL, didID,
+			baseURI)
+		if err != nil {
+			return nil, err
+		}
+
+		rawService[jsonldID] = services[i].ID
+		if services[i].relativeURL {
+			rawService[jsonldID] = makeRelativeDIDURL(services[i].ID, baseURI, didID)
+		}
+
+		uri, _ := services[i].ServiceEndpoint.URI() //nolint:errcheck
+
+		rawService[jsonldType] = services[i].Type
+		rawService[jsonldServicePoint] = uri
+		rawService[jsonldRecipientKeys] = recipientKeys
+		rawService[jsonldRoutingKeys] = routingKeys
+		rawService[jsonldPriority] = services[i].Priority
+
+		rawServices = append(rawServices, rawService)
+	}
+
+	return rawServices, nil
+}
+
+func rawKeysInterop(keys []string, relativeURL map[string]bool, didID, baseURI string) ([]string, error) {
+	rawKeys := make([]string, 0, len(keys))
+
+	for _, v := range keys {
+		if relativeURL[v] {
+			rawKeys = append(rawKeys, makeRelativeDIDURL(v, baseURI, didID))
+
+			continue
+		}
+
+		// Interop: convert did:key to raw base58 key
+		// aca-py issue: https://github.com/hyperledger/aries-cloudagent-python/issues/1106
+		if strings.HasPrefix(v, "did:key:") {
+			key, err := pubKeyFromDIDKey(v)
+			if err != nil {
+				return nil, err
+			}
+
+			v = base58.Encode(key)
+		}
+
+		rawKeys = append(rawKeys, v)
+	}
+
+	return rawKeys, nil
+}
+
+// pubKeyFromDIDKey returns the raw public key of a did:key, without importing did/vdr/key which imports did.
+func pubKeyFromDIDKey(didKey string) ([]byte, error) {
+	id, err := Parse(didKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse did:key [%s]: %w", didKey, err)
+	}
+
+	fingerprint := id.MethodSpecificID
+	if len(fingerprint) < 2 || fingerprint[0] != 'z' {
+		return nil, fmt.Errorf("unknown key encoding of %s", didKey)
+	}
+
+	_, key, err := multicodec.Decode(base58.Decode(fingerprint[1:]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid did:key [%s]: %w", didKey, err)
+	}
+
+	return key, nil
+}
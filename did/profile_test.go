@@ -0,0 +1,121 @@
+package did
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/stretchr/testify/require"
+	"github.com/zRich/zFusion/common/model"
+	"github.com/zRich/zFusion/common/multicodec"
+)
+
+func newProfileTestDoc(t *testing.T) (*Document, ed25519.PublicKey) {
+	t.Helper()
+
+	pubKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	recipientKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	fingerprint, err := multicodec.Encode(multicodec.Ed25519Pub, recipientKey)
+	require.NoError(t, err)
+
+	id := "did:example:123456789abcdefghi"
+	vm := NewVerificationMethodFromBytes(id+"#key-1", "Ed25519VerificationKey2018", id, pubKey)
+
+	doc := BuildDoc(
+		WithVerificationMethod([]VerificationMethod{*vm}),
+		WithAuthentication([]Verification{*NewReferencedVerification(vm, Authentication)}),
+		WithService([]Service{{
+			ID:              id + "#agent",
+			Type:            "did-communication",
+			ServiceEndpoint: model.NewDIDCommV1Endpoint("https://agent.example.com"),
+			RecipientKeys:   []string{"did:key:z" + base58.Encode(fingerprint)},
+		}}),
+	)
+	doc.ID = id
+	doc.AlsoKnownAs = []string{"https://example.com/me"}
+
+	return doc, recipientKey
+}
+
+func TestProfileACAPy(t *testing.T) {
+	doc, recipientKey := newProfileTestDoc(t)
+
+	docBytes, err := doc.JSONBytes(WithProfile(ProfileACAPy))
+	require.NoError(t, err)
+
+	raw := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(docBytes, &raw))
+
+	require.Equal(t, []interface{}{ContextV1Old}, raw["@context"])
+	require.NotContains(t, raw, "alsoKnownAs")
+	require.Equal(t, []interface{}{map[string]interface{}{"publicKey": doc.ID + "#key-1"}}, raw["authentication"])
+
+	service := raw["service"].([]interface{})[0].(map[string]interface{})
+	require.Equal(t, "https://agent.example.com", service["serviceEndpoint"])
+	require.Equal(t, []interface{}{base58.Encode(recipientKey)}, service["recipientKeys"])
+
+	_, err = ParseDocument(docBytes)
+	require.Error(t, err)
+
+	parsed, err := ParseDocument(docBytes, WithLegacyHandling())
+	require.NoError(t, err)
+	require.Equal(t, doc.ID, parsed.ID)
+	require.Len(t, parsed.Authentication, 1)
+	require.Equal(t, doc.VerificationMethod[0].Value, parsed.Authentication[0].VerificationMethod.Value)
+	require.Equal(t, []string{base58.Encode(recipientKey)}, parsed.Service[0].RecipientKeys)
+
+	// the default profile is unaffected
+	docBytes, err = doc.JSONBytes()
+	require.NoError(t, err)
+
+	parsed, err = ParseDocument(docBytes)
+	require.NoError(t, err)
+	require.Equal(t, doc.AlsoKnownAs, parsed.AlsoKnownAs)
+	require.Equal(t, doc.Service[0].RecipientKeys, parsed.Service[0].RecipientKeys)
+
+	t.Run("invalid did:key", func(t *testing.T) {
+		doc.Service[0].RecipientKeys = []string{"did:key:z6Mk"}
+
+		_, err = doc.JSONBytes(WithProfile(ProfileACAPy))
+		require.Error(t, err)
+	})
+}
+
+func TestProfileLegacy(t *testing.T) {
+	doc, _ := newProfileTestDoc(t)
+
+	docBytes, err := doc.JSONBytes(WithProfile(ProfileLegacy))
+	require.NoError(t, err)
+
+	raw := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(docBytes, &raw))
+
+	require.Equal(t, []interface{}{contextV011}, raw["@context"])
+	require.NotContains(t, raw, "verificationMethod")
+	require.Len(t, raw["publicKey"], 1)
+	require.Equal(t, doc.ID, raw["publicKey"].([]interface{})[0].(map[string]interface{})["owner"])
+	require.Equal(t, []interface{}{map[string]interface{}{
+		"type": "Ed25519VerificationKey2018", "publicKey": doc.ID + "#key-1",
+	}}, raw["authentication"])
+
+	parsed, err := ParseDocument(docBytes)
+	require.NoError(t, err)
+	require.Equal(t, doc.ID, parsed.ID)
+	require.Len(t, parsed.VerificationMethod, 1)
+	require.Equal(t, doc.ID, parsed.VerificationMethod[0].Controller)
+	require.Len(t, parsed.Authentication, 1)
+	require.Equal(t, doc.VerificationMethod[0].Value, parsed.Authentication[0].VerificationMethod.Value)
+}
+
+func TestProfileUnsupported(t *testing.T) {
+	doc, _ := newProfileTestDoc(t)
+
+	_, err := doc.JSONBytes(WithProfile("aries-0.1"))
+	require.EqualError(t, err, "unsupported serialization profile: aries-0.1")
+}
@@ -0,0 +1,481 @@
+package did
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zRich/zFusion/common/crypto"
+	"github.com/zRich/zFusion/common/crypto/jwk"
+	"github.com/zRich/zFusion/common/jsonld"
+)
+
+// Proof types supported by AddProof and VerifyProof.
+const (
+	Ed25519Signature2018        = "Ed25519Signature2018"
+	Ed25519Signature2020        = ed25519Signature2020
+	EcdsaSecp256k1Signature2019 = "EcdsaSecp256k1Signature2019"
+	JSONWebSignature2020        = "JsonWebSignature2020"
+)
+
+// Proof purposes, each requiring the proof creator key to be part of the verification relationship of the
+// same name.
+const (
+	PurposeAuthentication       = "authentication"
+	PurposeAssertionMethod      = "assertionMethod"
+	PurposeCapabilityDelegation = "capabilityDelegation"
+	PurposeCapabilityInvocation = "capabilityInvocation"
+)
+
+// proofContexts maps proof types to the context of their suite, which defines the terms of the proof options.
+var proofContexts = map[string]string{ //nolint:gochecknoglobals
+	Ed25519Signature2018:        jsonld.ContextEd25519Signature2018,
+	Ed25519Signature2020:        jsonld.ContextEd25519Signature2020,
+	EcdsaSecp256k1Signature2019: jsonld.ContextSecp256k1Signature,
+	JSONWebSignature2020:        jsonld.ContextJSONWebSignature2020,
+}
+
+var (
+	// ErrInvalidProof is returned when a proof does not verify.
+	ErrInvalidProof = errors.New("invalid proof")
+	// ErrKeyNotAuthorized is returned when a key is not part of the verification relationship it is used for.
+	ErrKeyNotAuthorized = errors.New("key is not authorized for the verification relationship")
+)
+
+// defaultLoader serves the embedded contexts to proofs created or verified without WithDocumentLoader. It is
+// shared, as the contexts it serves never change.
+var defaultLoader = jsonld.NewDocumentLoader() //nolint:gochecknoglobals
+
+// proofPurposes maps proof purposes to verification relationships.
+var proofPurposes = map[string]VerificationRelationship{ //nolint:gochecknoglobals
+	PurposeAuthentication:       Authentication,
+	PurposeAssertionMethod:      AssertionMethod,
+	PurposeCapabilityDelegation: CapabilityDelegation,
+	PurposeCapabilityInvocation: CapabilityInvocation,
+}
+
+// DocumentResolver resolves the DID documents controlling proof creator keys which are not part of the
+// document being verified.
+type DocumentResolver interface {
+	ResolveDocument(did string) (*Document, error)
+}
+
+type proofOpts struct {
+	proofType string
+	purpose   string
+	domain    string
+	nonce     []byte
+	created   time.Time
+	resolver  DocumentResolver
+	loader    jsonld.DocumentLoader
+}
+
+// ProofOption configures proof creation and verification.
+type ProofOption func(opts *proofOpts)
+
+// WithProofType sets the type of the created proof. It defaults to the DefaultProofType of the signer key type.
+func WithProofType(proofType string) ProofOption {
+	return func(opts *proofOpts) {
+		opts.proofType = proofType
+	}
+}
+
+// WithProofPurpose sets the purpose of the created proof, or the purpose a verified proof must have.
+// It defaults to PurposeAssertionMethod.
+func WithProofPurpose(purpose string) ProofOption {
+	return func(opts *proofOpts) {
+		opts.purpose = purpose
+	}
+}
+
+// WithProofDomain sets the domain of the created proof, or the domain a verified proof must have.
+func WithProofDomain(domain string) ProofOption {
+	return func(opts *proofOpts) {
+		opts.domain = domain
+	}
+}
+
+// WithProofNonce sets the nonce of the created proof, or the nonce a verified proof must have.
+func WithProofNonce(nonce []byte) ProofOption {
+	return func(opts *proofOpts) {
+		opts.nonce = nonce
+	}
+}
+
+// WithProofCreated sets the creation time of the created proof. It defaults to the current time.
+func WithProofCreated(created time.Time) ProofOption {
+	return func(opts *proofOpts) {
+		opts.created = created
+	}
+}
+
+// WithDocumentResolver sets the resolver of proof creators whose DID is not the ID of the verified document.
+func WithDocumentResolver(resolver DocumentResolver) ProofOption {
+	return func(opts *proofOpts) {
+		opts.resolver = resolver
+	}
+}
+
+// WithDocumentLoader sets the loader of the JSON-LD contexts of the document, which is canonicalized with the
+// URDNA2015 algorithm. It defaults to a loader of the embedded contexts.
+func WithDocumentLoader(loader jsonld.DocumentLoader) ProofOption {
+	return func(opts *proofOpts) {
+		opts.loader = loader
+	}
+}
+
+func getProofOpts(opts []ProofOption) *proofOpts {
+	o := &proofOpts{purpose: PurposeAssertionMethod}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.loader == nil {
+		o.loader = defaultLoader
+	}
+
+	return o
+}
+
+// AddProof signs the document with the key of signer and appends the proof. creator is the ID of the
+// verification method holding the public key of signer, relative IDs are resolved against the document ID.
+// Existing proofs are not covered by the new proof. Ed25519Signature2020 proofs carry a multibase proof value,
+// proofs of other types a detached JWS.
+func (doc *Document) AddProof(signer crypto.Signer, creator string, opts ...ProofOption) error {
+	o := getProofOpts(opts)
+
+	if o.proofType == "" {
+		o.proofType = DefaultProofType(signer.KeyType())
+	}
+
+	if err := CheckProofKeyType(o.proofType, signer.KeyType()); err != nil {
+		return err
+	}
+
+	if o.created.IsZero() {
+		o.created = time.Now().UTC().Truncate(time.Second)
+	}
+
+	proof := Proof{
+		Type:         o.proofType,
+		Created:      &o.created,
+		Creator:      AbsoluteID(doc.ID, creator),
+		Domain:       o.domain,
+		Nonce:        o.nonce,
+		ProofPurpose: o.purpose,
+		relativeURL:  strings.HasPrefix(creator, "#"),
+	}
+
+	tbs, err := doc.proofSigningInput(&proof, o)
+	if err != nil {
+		return err
+	}
+
+	if proof.Type == Ed25519Signature2020 {
+		proof.ProofValue, err = signer.Sign(tbs)
+		if err != nil {
+			return fmt.Errorf("sign document: %w", err)
+		}
+	} else {
+		proof.JWS, err = SignDetachedJWS(signer, tbs)
+		if err != nil {
+			return fmt.Errorf("sign document: %w", err)
+		}
+	}
+
+	doc.Proof = append(doc.Proof, proof)
+
+	return nil
+}
+
+// VerifyProof verifies all document proofs. Each proof must have the expected purpose, domain and nonce,
+// and be created by a key of the matching verification relationship of the creator's DID document.
+func (doc *Document) VerifyProof(opts ...ProofOption) error {
+	if len(doc.Proof) == 0 {
+		return ErrProofNotFound
+	}
+
+	o := getProofOpts(opts)
+
+	for i := range doc.Proof {
+		if err := doc.verifyProof(&doc.Proof[i], o); err != nil {
+			return fmt.Errorf("verify proof %s: %w", doc.Proof[i].Creator, err)
+		}
+	}
+
+	return nil
+}
+
+func (doc *Document) verifyProof(proof *Proof, o *proofOpts) error {
+	if proof.ProofPurpose != o.purpose {
+		return fmt.Errorf("%w: proof purpose %q, expected %q", ErrInvalidProof, proof.ProofPurpose, o.purpose)
+	}
+
+	if proof.Domain != o.domain {
+		return fmt.Errorf("%w: proof domain %q, expected %q", ErrInvalidProof, proof.Domain, o.domain)
+	}
+
+	if !bytes.Equal(proof.Nonce, o.nonce) {
+		return fmt.Errorf("%w: nonce mismatch", ErrInvalidProof)
+	}
+
+	if proof.Created == nil {
+		return fmt.Errorf("%w: missing creation time", ErrInvalidProof)
+	}
+
+	relationship, ok := proofPurposes[proof.ProofPurpose]
+	if !ok {
+		return fmt.Errorf("%w: unsupported proof purpose %q", ErrInvalidProof, proof.ProofPurpose)
+	}
+
+	vm, err := doc.proofKey(proof.Creator, relationship, o.resolver)
+	if err != nil {
+		return err
+	}
+
+	keyType, err := vm.KeyType()
+	if err != nil {
+		return err
+	}
+
+	if err = CheckProofKeyType(proof.Type, keyType); err != nil {
+		return err
+	}
+
+	tbs, err := doc.proofSigningInput(proof, o)
+	if err != nil {
+		return err
+	}
+
+	if proof.Type == Ed25519Signature2020 {
+		return vm.Verify(tbs, proof.ProofValue)
+	}
+
+	return VerifyDetachedJWS(proof.JWS, tbs, vm)
+}
+
+// proofKey looks the creator key up in the verification relationship of the DID document of its controller.
+func (doc *Document) proofKey(creator string, relationship VerificationRelationship,
+	resolver DocumentResolver) (*VerificationMethod, error) {
+	didURL, err := ParseDIDURL(creator)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid creator: %v", ErrInvalidProof, err)
+	}
+
+	controller := didURL.DID.String()
+	controllerDoc := doc
+
+	if controller != doc.ID {
+		if resolver == nil {
+			return nil, fmt.Errorf("no document resolver to resolve creator %s", creator)
+		}
+
+		controllerDoc, err = resolver.ResolveDocument(controller)
+		if err != nil {
+			return nil, fmt.Errorf("resolve creator %s: %w", creator, err)
+		}
+	}
+
+	vm, err := controllerDoc.AuthorizedVerificationMethod(creator, relationship)
+	if errors.Is(err, ErrKeyNotAuthorized) {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProof, err)
+	}
+
+	return vm, err
+}
+
+// AuthorizedVerificationMethod returns the verification method with the given ID if it is part of the
+// verification relationship of the document. Relative IDs of the document are resolved against its ID.
+// It returns ErrKeyNotFound if the document has no such method, and ErrKeyNotAuthorized if the method
+// is not part of the relationship.
+func (doc *Document) AuthorizedVerificationMethod(id string, relationship VerificationRelationship) (
+	*VerificationMethod, error) {
+	id = AbsoluteID(doc.ID, id)
+
+	for _, v := range doc.VerificationMethods(relationship)[relationship] {
+		if AbsoluteID(doc.ID, v.VerificationMethod.ID) == id {
+			vm := v.VerificationMethod
+
+			return &vm, nil
+		}
+	}
+
+	pubKeys := make([]VerificationMethod, len(doc.VerificationMethod))
+
+	for i, pubKey := range doc.VerificationMethod {
+		pubKey.ID = AbsoluteID(doc.ID, pubKey.ID)
+		pubKeys[i] = pubKey
+	}
+
+	keyResolver := &didKeyResolver{PubKeys: pubKeys}
+	if _, err := keyResolver.Resolve(id); err != nil {
+		return nil, err
+	}
+
+	return nil, fmt.Errorf("%w: key %s", ErrKeyNotAuthorized, id)
+}
+
+// proofSigningInput returns the signed digest of the proof: the SHA-256 hash of the canonical proof options
+// followed by the SHA-256 hash of the canonical document without proofs. Both are canonicalized with the
+// URDNA2015 algorithm, like the proofs of verifiable credentials.
+func (doc *Document) proofSigningInput(proof *Proof, o *proofOpts) ([]byte, error) {
+	unsigned := *doc
+	unsigned.Proof = nil
+
+	docBytes, err := unsigned.JSONBytes()
+	if err != nil {
+		return nil, fmt.Errorf("marshal document: %w", err)
+	}
+
+	canonicalDoc, err := jsonld.Canonicalize(docBytes, jsonld.WithDocumentLoader(o.loader), jsonld.WithBase(doc.ID),
+		jsonld.WithSafeMode())
+	if err != nil {
+		return nil, fmt.Errorf("canonicalize document: %w", err)
+	}
+
+	canonicalOptions, err := jsonld.Canonicalize(proofOptions(proof), jsonld.WithDocumentLoader(o.loader),
+		jsonld.WithSafeMode())
+	if err != nil {
+		return nil, fmt.Errorf("canonicalize proof options: %w", err)
+	}
+
+	optionsHash := sha256.Sum256(canonicalOptions)
+	docHash := sha256.Sum256(canonicalDoc)
+
+	return append(optionsHash[:], docHash[:]...), nil
+}
+
+// proofOptions returns the proof without signature in the terms of the context of its suite, where the creator
+// is the verification method of the proof.
+func proofOptions(proof *Proof) map[string]interface{} {
+	options := map[string]interface{}{
+		"@context":               proofContexts[proof.Type],
+		jsonldType:               proof.Type,
+		jsonldCreated:            proof.Created.UTC().Format(time.RFC3339Nano),
+		jsonldVerificationMethod: proof.Creator,
+		jsonldProofPurpose:       proof.ProofPurpose,
+	}
+
+	if proof.Domain != "" {
+		options[jsonldDomain] = proof.Domain
+	}
+
+	if len(proof.Nonce) > 0 {
+		options[jsonldNonce] = base64.RawURLEncoding.EncodeToString(proof.Nonce)
+	}
+
+	return options
+}
+
+// DefaultProofType returns the default type of proofs created with keys of keyType: Ed25519Signature2018 for
+// Ed25519 keys, EcdsaSecp256k1Signature2019 for secp256k1 keys and JsonWebSignature2020 for other keys.
+func DefaultProofType(keyType crypto.KeyType) string {
+	switch keyType {
+	case crypto.Ed25519:
+		return Ed25519Signature2018
+	case crypto.ECDSASecp256k1:
+		return EcdsaSecp256k1Signature2019
+	default:
+		return JSONWebSignature2020
+	}
+}
+
+// CheckProofKeyType checks that proofType is a supported proof type whose proofs can be created with keys of
+// keyType.
+func CheckProofKeyType(proofType string, keyType crypto.KeyType) error {
+	var ok bool
+
+	switch proofType {
+	case Ed25519Signature2018, Ed25519Signature2020:
+		ok = keyType == crypto.Ed25519
+	case EcdsaSecp256k1Signature2019:
+		ok = keyType == crypto.ECDSASecp256k1
+	case JSONWebSignature2020:
+		_, err := jwk.SignatureAlgorithm(keyType)
+		ok = err == nil
+	default:
+		return fmt.Errorf("unsupported proof type: %s", proofType)
+	}
+
+	if !ok {
+		return fmt.Errorf("%s proofs do not support %s keys", proofType, keyType)
+	}
+
+	return nil
+}
+
+// SignDetachedJWS signs payload with signer and returns the detached JWS with unencoded payload (RFC 7797) of
+// proofs.
+func SignDetachedJWS(signer crypto.Signer, payload []byte) (string, error) {
+	header, err := jwsHeader(signer.KeyType())
+	if err != nil {
+		return "", err
+	}
+
+	sig, err := signer.Sign(jwsSigningInput(header, payload))
+	if err != nil {
+		return "", err
+	}
+
+	return header + ".." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// VerifyDetachedJWS verifies jws, a detached JWS of payload created by SignDetachedJWS, with the public key of vm.
+func VerifyDetachedJWS(jws string, payload []byte, vm *VerificationMethod) error {
+	keyType, err := vm.KeyType()
+	if err != nil {
+		return err
+	}
+
+	parts := strings.Split(jws, ".")
+	if len(parts) != 3 || parts[1] != "" { //nolint:gomnd
+		return fmt.Errorf("%w: invalid detached JWS", ErrInvalidProof)
+	}
+
+	if expected, _ := jwsHeader(keyType); parts[0] != expected { //nolint:errcheck
+		return fmt.Errorf("%w: unsupported JWS header", ErrInvalidProof)
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return fmt.Errorf("%w: decode JWS signature: %v", ErrInvalidProof, err)
+	}
+
+	return vm.Verify(jwsSigningInput(parts[0], payload), sig)
+}
+
+// jwsHeader returns the encoded protected header of a detached JWS with unencoded payload (RFC 7797).
+func jwsHeader(keyType crypto.KeyType) (string, error) {
+	alg, err := jwk.SignatureAlgorithm(keyType)
+	if err != nil {
+		return "", err
+	}
+
+	header := fmt.Sprintf(`{"alg":%q,"b64":false,"crit":["b64"]}`, alg)
+
+	return base64.RawURLEncoding.EncodeToString([]byte(header)), nil
+}
+
+func jwsSigningInput(header string, payload []byte) []byte {
+	return append([]byte(header+"."), payload...)
+}
+
+// didKeyResolver implements public key resolution for DID public keys.
+type didKeyResolver struct {
+	PubKeys []VerificationMethod
+}
+
+func (r *didKeyResolver) Resolve(id string) (*VerificationMethod, error) {
+	for _, key := range r.PubKeys {
+		if key.ID == id {
+			return &key, nil
+		}
+	}
+
+	return nil, ErrKeyNotFound
+}
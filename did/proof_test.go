@@ -0,0 +1,299 @@
+package did
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zRich/zFusion/common/crypto"
+	"github.com/zRich/zFusion/common/crypto/jwk"
+	"github.com/zRich/zFusion/common/crypto/secp256k1"
+	"github.com/zRich/zFusion/common/jsonld"
+)
+
+type mockDocumentResolver map[string]*Document
+
+func (r mockDocumentResolver) ResolveDocument(didID string) (*Document, error) {
+	doc, ok := r[didID]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+
+	return doc, nil
+}
+
+func newSigner(t *testing.T, keyType crypto.KeyType) crypto.Signer {
+	t.Helper()
+
+	var (
+		privKey interface{}
+		err     error
+	)
+
+	switch keyType {
+	case crypto.Ed25519:
+		_, privKey, err = ed25519.GenerateKey(rand.Reader)
+	case crypto.ECDSAP256:
+		privKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case crypto.ECDSASecp256k1:
+		privKey, err = ecdsa.GenerateKey(secp256k1.S256(), rand.Reader)
+	case crypto.RSA:
+		privKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	}
+
+	require.NoError(t, err)
+
+	signer, err := crypto.NewSigner(privKey)
+	require.NoError(t, err)
+
+	return signer
+}
+
+// newProofDoc creates a document whose key-1 is an assertion method and key-2 an authentication key. Keys of
+// verification method types without base58 encoding are JWKs.
+func newProofDoc(t *testing.T, vmType string, signer crypto.Signer) *Document {
+	t.Helper()
+
+	newKey := func(id string) *VerificationMethod {
+		if vmType == keyType || vmType == keyType2020 {
+			return NewVerificationMethodFromBytes(id, vmType, "", signer.PublicKeyBytes())
+		}
+
+		j, err := jwk.NewFromBytes(signer.KeyType(), signer.PublicKeyBytes())
+		require.NoError(t, err)
+
+		vm, err := NewVerificationMethodFromJWK(id, vmType, "", j)
+		require.NoError(t, err)
+
+		return vm
+	}
+
+	assertionKey, authKey := newKey("#key-1"), newKey("#key-2")
+
+	doc := BuildDoc(
+		WithVerificationMethod([]VerificationMethod{*assertionKey, *authKey}),
+		WithAssertion([]Verification{*NewReferencedVerification(assertionKey, AssertionMethod)}),
+		WithAuthentication([]Verification{*NewReferencedVerification(authKey, Authentication)}),
+	)
+	doc.ID = did
+
+	if vmType == keyType2020 {
+		doc.Context = []string{ContextV1, jsonld.ContextEd25519Signature2020}
+	}
+
+	return doc
+}
+
+func TestAddProof(t *testing.T) {
+	tests := []struct {
+		keyType   crypto.KeyType
+		vmType    string
+		proofType string
+	}{
+		{crypto.Ed25519, keyType, Ed25519Signature2018},
+		{crypto.Ed25519, keyType2020, Ed25519Signature2020},
+		{crypto.Ed25519, keyType, JSONWebSignature2020},
+		{crypto.ECDSASecp256k1, "EcdsaSecp256k1VerificationKey2019", EcdsaSecp256k1Signature2019},
+		{crypto.ECDSASecp256k1, "JsonWebKey2020", JSONWebSignature2020},
+		{crypto.ECDSAP256, "JsonWebKey2020", JSONWebSignature2020},
+		{crypto.RSA, "RsaVerificationKey2018", JSONWebSignature2020},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.proofType+" "+string(tc.keyType), func(t *testing.T) {
+			signer := newSigner(t, tc.keyType)
+			doc := newProofDoc(t, tc.vmType, signer)
+
+			require.NoError(t, doc.AddProof(signer, "#key-1", WithProofType(tc.proofType)))
+			require.Equal(t, tc.proofType, doc.Proof[0].Type)
+			// only Ed25519Signature2020 proofs carry a proof value.
+			require.Equal(t, tc.proofType == Ed25519Signature2020, doc.Proof[0].JWS == "")
+			require.Equal(t, did+"#key-1", doc.Proof[0].Creator)
+			require.NoError(t, doc.VerifyProof())
+
+			docBytes, err := doc.JSONBytes()
+			require.NoError(t, err)
+			require.Contains(t, string(docBytes), `"creator":"#key-1"`)
+
+			parsed, err := ParseDocument(docBytes)
+			require.NoError(t, err)
+			require.Len(t, parsed.Proof, 1)
+			require.Equal(t, doc.Proof[0].Creator, parsed.Proof[0].Creator)
+			require.Equal(t, doc.Proof[0].ProofValue, parsed.Proof[0].ProofValue)
+			require.Equal(t, doc.Proof[0].JWS, parsed.Proof[0].JWS)
+			require.NoError(t, parsed.VerifyProof())
+
+			parsed.Authentication = nil
+			require.ErrorIs(t, parsed.VerifyProof(), crypto.ErrInvalidSignature)
+		})
+	}
+}
+
+func TestAddProofDefaults(t *testing.T) {
+	for keyType, proofType := range map[crypto.KeyType]string{
+		crypto.Ed25519:        Ed25519Signature2018,
+		crypto.ECDSASecp256k1: EcdsaSecp256k1Signature2019,
+		crypto.ECDSAP256:      JSONWebSignature2020,
+	} {
+		signer := newSigner(t, keyType)
+		doc := &Document{Context: []string{ContextV1}, ID: did}
+
+		require.NoError(t, doc.AddProof(signer, creator))
+		require.Equal(t, proofType, doc.Proof[0].Type)
+		require.Equal(t, PurposeAssertionMethod, doc.Proof[0].ProofPurpose)
+		require.WithinDuration(t, time.Now(), *doc.Proof[0].Created, time.Minute)
+	}
+
+	signer := newSigner(t, crypto.ECDSAP256)
+	doc := &Document{ID: did}
+
+	err := doc.AddProof(signer, creator, WithProofType(Ed25519Signature2018))
+	require.EqualError(t, err, "Ed25519Signature2018 proofs do not support P-256 keys")
+
+	err = doc.AddProof(signer, creator, WithProofType("UnknownSignature2022"))
+	require.EqualError(t, err, "unsupported proof type: UnknownSignature2022")
+}
+
+func TestVerifyProofOptions(t *testing.T) {
+	signer := newSigner(t, crypto.Ed25519)
+	nonce := []byte("nonce")
+
+	doc := newProofDoc(t, keyType, signer)
+	require.NoError(t, doc.AddProof(signer, "#key-2", WithProofPurpose(PurposeAuthentication),
+		WithProofDomain("example.com"), WithProofNonce(nonce)))
+
+	require.NoError(t, doc.VerifyProof(WithProofPurpose(PurposeAuthentication), WithProofDomain("example.com"),
+		WithProofNonce(nonce)))
+
+	for name, opts := range map[string][]ProofOption{
+		"purpose": {WithProofDomain("example.com"), WithProofNonce(nonce)},
+		"domain":  {WithProofPurpose(PurposeAuthentication), WithProofNonce(nonce)},
+		"nonce": {WithProofPurpose(PurposeAuthentication), WithProofDomain("example.com"),
+			WithProofNonce([]byte("other"))},
+	} {
+		require.ErrorIs(t, doc.VerifyProof(opts...), ErrInvalidProof, name)
+	}
+
+	t.Run("key not authorized for purpose", func(t *testing.T) {
+		doc := newProofDoc(t, keyType, signer)
+		require.NoError(t, doc.AddProof(signer, "#key-2"))
+
+		err := doc.VerifyProof()
+		require.ErrorIs(t, err, ErrInvalidProof)
+		require.Contains(t, err.Error(), "is not authorized")
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		doc := newProofDoc(t, keyType, signer)
+		require.NoError(t, doc.AddProof(signer, "#key-3"))
+
+		require.ErrorIs(t, doc.VerifyProof(), ErrKeyNotFound)
+	})
+
+	t.Run("proof type does not match key type", func(t *testing.T) {
+		doc := newProofDoc(t, keyType, signer)
+		require.NoError(t, doc.AddProof(signer, "#key-1"))
+
+		doc.Proof[0].Type = EcdsaSecp256k1Signature2019
+		require.EqualError(t, doc.VerifyProof(),
+			"verify proof did:method:abc#key-1: EcdsaSecp256k1Signature2019 proofs do not support Ed25519 keys")
+	})
+
+	t.Run("invalid JWS", func(t *testing.T) {
+		doc := newProofDoc(t, keyType, signer)
+		require.NoError(t, doc.AddProof(signer, "#key-1", WithProofType(JSONWebSignature2020)))
+
+		doc.Proof[0].JWS = "invalid"
+		require.ErrorIs(t, doc.VerifyProof(), ErrInvalidProof)
+	})
+}
+
+func TestVerifyProofWithResolver(t *testing.T) {
+	const controllerDID = "did:example:controller"
+
+	signer := newSigner(t, crypto.ECDSAP256)
+
+	j, err := jwk.NewFromBytes(crypto.ECDSAP256, signer.PublicKeyBytes())
+	require.NoError(t, err)
+
+	vm, err := NewVerificationMethodFromJWK(controllerDID+"#key-1", "JsonWebKey2020", controllerDID, j)
+	require.NoError(t, err)
+
+	controllerDoc := BuildDoc(
+		WithVerificationMethod([]VerificationMethod{*vm}),
+		WithAssertion([]Verification{*NewReferencedVerification(vm, AssertionMethod)}),
+	)
+	controllerDoc.ID = controllerDID
+
+	doc := &Document{Context: []string{ContextV1}, ID: did}
+	require.NoError(t, doc.AddProof(signer, controllerDID+"#key-1"))
+
+	err = doc.VerifyProof()
+	require.EqualError(t, err, "verify proof did:example:controller#key-1: "+
+		"no document resolver to resolve creator did:example:controller#key-1")
+
+	require.NoError(t, doc.VerifyProof(WithDocumentResolver(mockDocumentResolver{controllerDID: controllerDoc})))
+
+	err = doc.VerifyProof(WithDocumentResolver(mockDocumentResolver{}))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "resolve creator")
+}
+
+func TestProofWithDocumentLoader(t *testing.T) {
+	loader := jsonld.NewDocumentLoader()
+	signer := newSigner(t, crypto.Ed25519)
+	doc := newProofDoc(t, keyType, signer)
+
+	require.NoError(t, doc.AddProof(signer, "#key-1", WithDocumentLoader(loader)))
+	require.NoError(t, doc.VerifyProof(WithDocumentLoader(loader)))
+	require.NoError(t, doc.VerifyProof())
+
+	// proofs without a loader share the default one.
+	require.Same(t, defaultLoader, getProofOpts(nil).loader)
+	require.Same(t, loader, getProofOpts([]ProofOption{WithDocumentLoader(loader)}).loader)
+
+	docBytes, err := doc.JSONBytes()
+	require.NoError(t, err)
+
+	parsed, err := ParseDocument(docBytes)
+	require.NoError(t, err)
+	require.NoError(t, parsed.VerifyProof(WithDocumentLoader(loader)))
+
+	parsed.VerificationMethod[1].Value = signer.PublicKeyBytes()[1:]
+	require.ErrorIs(t, parsed.VerifyProof(WithDocumentLoader(loader)), crypto.ErrInvalidSignature)
+
+	// terms undefined by the document contexts would not be signed.
+	doc = newProofDoc(t, keyType2020, signer)
+	doc.Context = []string{ContextV1}
+
+	err = doc.AddProof(signer, "#key-1", WithDocumentLoader(loader))
+	require.ErrorIs(t, err, jsonld.ErrInvalidDocument)
+	require.Contains(t, err.Error(), "canonicalize document")
+}
+
+func TestAuthorizedVerificationMethod(t *testing.T) {
+	doc := newProofDoc(t, keyType, newSigner(t, crypto.Ed25519))
+
+	vm, err := doc.AuthorizedVerificationMethod(did+"#key-1", AssertionMethod)
+	require.NoError(t, err)
+	require.Equal(t, "#key-1", vm.ID)
+
+	vm, err = doc.AuthorizedVerificationMethod("#key-2", Authentication)
+	require.NoError(t, err)
+	require.Equal(t, "#key-2", vm.ID)
+
+	_, err = doc.AuthorizedVerificationMethod("#key-2", AssertionMethod)
+	require.ErrorIs(t, err, ErrKeyNotAuthorized)
+
+	_, err = doc.AuthorizedVerificationMethod("#key-3", AssertionMethod)
+	require.ErrorIs(t, err, ErrKeyNotFound)
+
+	_, err = doc.AuthorizedVerificationMethod("did:example:other#key-1", AssertionMethod)
+	require.ErrorIs(t, err, ErrKeyNotFound)
+}
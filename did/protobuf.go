@@ -0,0 +1,418 @@
+package did
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/multiformats/go-multibase"
+	"github.com/zRich/zFusion/common/crypto/jwk"
+	"github.com/zRich/zFusion/common/model"
+	"github.com/zRich/zFusion/common/multicodec"
+	"github.com/zRich/zFusion/did/didpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ToProto converts the document to its protobuf representation. The conversion is lossless: DocumentFromProto
+// returns a document with the same JSON serialization.
+func (doc *Document) ToProto() (*didpb.DidDocument, error) {
+	context, err := json.Marshal(doc.Context)
+	if err != nil {
+		return nil, fmt.Errorf("marshal context: %w", err)
+	}
+
+	msg := &didpb.DidDocument{
+		Context:     context,
+		Id:          doc.ID,
+		AlsoKnownAs: doc.AlsoKnownAs,
+		Created:     timestampToProto(doc.Created),
+		Updated:     timestampToProto(doc.Updated),
+		BaseUri:     doc.processingMeta.baseURI,
+	}
+
+	for i := range doc.VerificationMethod {
+		vm, err := verificationMethodToProto(&doc.VerificationMethod[i])
+		if err != nil {
+			return nil, err
+		}
+
+		msg.VerificationMethod = append(msg.VerificationMethod, vm)
+	}
+
+	for i := range doc.Service {
+		service, err := serviceToProto(&doc.Service[i])
+		if err != nil {
+			return nil, err
+		}
+
+		msg.Service = append(msg.Service, service)
+	}
+
+	for _, relationship := range []struct {
+		verifications []Verification
+		msg           *[]*didpb.Verification
+	}{
+		{doc.Authentication, &msg.Authentication},
+		{doc.AssertionMethod, &msg.AssertionMethod},
+		{doc.CapabilityDelegation, &msg.CapabilityDelegation},
+		{doc.CapabilityInvocation, &msg.CapabilityInvocation},
+		{doc.KeyAgreement, &msg.KeyAgreement},
+	} {
+		for i := range relationship.verifications {
+			vm, err := verificationMethodToProto(&relationship.verifications[i].VerificationMethod)
+			if err != nil {
+				return nil, err
+			}
+
+			*relationship.msg = append(*relationship.msg, &didpb.Verification{
+				VerificationMethod: vm,
+				Embedded:           relationship.verifications[i].Embedded,
+			})
+		}
+	}
+
+	for i := range doc.Proof {
+		p := &doc.Proof[i]
+
+		msg.Proof = append(msg.Proof, &didpb.DocumentProof{
+			Type:            p.Type,
+			Created:         timestampToProto(p.Created),
+			Creator:         p.Creator,
+			ProofValue:      p.ProofValue,
+			Jws:             p.JWS,
+			Domain:          p.Domain,
+			Nonce:           p.Nonce,
+			ProofPurpose:    p.ProofPurpose,
+			RelativeCreator: p.relativeURL,
+		})
+	}
+
+	return msg, nil
+}
+
+// DocumentFromProto converts the protobuf representation of a document, see Document.ToProto.
+func DocumentFromProto(msg *didpb.DidDocument) (*Document, error) {
+	var context Context
+
+	if len(msg.Context) > 0 {
+		if err := json.Unmarshal(msg.Context, &context); err != nil {
+			return nil, fmt.Errorf("unmarshal context: %w", err)
+		}
+	}
+
+	context, _ = parseContext(context)
+
+	created, err := timestampFromProto(msg.Created)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := timestampFromProto(msg.Updated)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &Document{
+		Context:        context,
+		ID:             msg.Id,
+		AlsoKnownAs:    msg.AlsoKnownAs,
+		Created:        created,
+		Updated:        updated,
+		processingMeta: processingMeta{baseURI: msg.BaseUri},
+	}
+
+	for _, m := range msg.VerificationMethod {
+		vm, err := verificationMethodFromProto(m)
+		if err != nil {
+			return nil, err
+		}
+
+		doc.VerificationMethod = append(doc.VerificationMethod, *vm)
+	}
+
+	for _, m := range msg.Service {
+		service, err := serviceFromProto(m)
+		if err != nil {
+			return nil, err
+		}
+
+		doc.Service = append(doc.Service, *service)
+	}
+
+	for _, relationship := range []struct {
+		msg           []*didpb.Verification
+		relationship  VerificationRelationship
+		verifications *[]Verification
+	}{
+		{msg.Authentication, Authentication, &doc.Authentication},
+		{msg.AssertionMethod, AssertionMethod, &doc.AssertionMethod},
+		{msg.CapabilityDelegation, CapabilityDelegation, &doc.CapabilityDelegation},
+		{msg.CapabilityInvocation, CapabilityInvocation, &doc.CapabilityInvocation},
+		{msg.KeyAgreement, KeyAgreement, &doc.KeyAgreement},
+	} {
+		for _, m := range relationship.msg {
+			vm, err := verificationMethodFromProto(m.VerificationMethod)
+			if err != nil {
+				return nil, err
+			}
+
+			*relationship.verifications = append(*relationship.verifications, Verification{
+				VerificationMethod: *vm,
+				Relationship:       relationship.relationship,
+				Embedded:           m.Embedded,
+			})
+		}
+	}
+
+	for _, m := range msg.Proof {
+		created, err := timestampFromProto(m.Created)
+		if err != nil {
+			return nil, err
+		}
+
+		doc.Proof = append(doc.Proof, Proof{
+			Type:         m.Type,
+			Created:      created,
+			Creator:      m.Creator,
+			ProofValue:   m.ProofValue,
+			JWS:          m.Jws,
+			Domain:       m.Domain,
+			Nonce:        m.Nonce,
+			ProofPurpose: m.ProofPurpose,
+			relativeURL:  m.RelativeCreator,
+		})
+	}
+
+	return doc, nil
+}
+
+func verificationMethodToProto(vm *VerificationMethod) (*didpb.VerificationMethod, error) {
+	msg := &didpb.VerificationMethod{
+		Id:                vm.ID,
+		Type:              vm.Type,
+		Controller:        vm.Controller,
+		PublicKey:         vm.Value,
+		MultibaseEncoding: int32(vm.multibaseEncoding),
+		RelativeId:        vm.relativeURL,
+		Multicodec:        uint64(vm.multicodec),
+	}
+
+	if vm.jsonWebKey != nil {
+		var err error
+
+		msg.PublicKeyJwk, err = json.Marshal(vm.jsonWebKey)
+		if err != nil {
+			return nil, fmt.Errorf("marshal JWK of verification method %s: %w", vm.ID, err)
+		}
+	}
+
+	return msg, nil
+}
+
+func verificationMethodFromProto(msg *didpb.VerificationMethod) (*VerificationMethod, error) {
+	if msg == nil {
+		return nil, errors.New("missing verification method")
+	}
+
+	vm := &VerificationMethod{
+		ID:                msg.Id,
+		Type:              msg.Type,
+		Controller:        msg.Controller,
+		Value:             msg.PublicKey,
+		relativeURL:       msg.RelativeId,
+		multibaseEncoding: multibase.Encoding(msg.MultibaseEncoding),
+		multicodec:        multicodec.Code(msg.Multicodec),
+	}
+
+	if len(msg.PublicKeyJwk) > 0 {
+		vm.jsonWebKey = &jwk.JWK{}
+
+		if err := json.Unmarshal(msg.PublicKeyJwk, vm.jsonWebKey); err != nil {
+			return nil, fmt.Errorf("unmarshal JWK of verification method %s: %w", msg.Id, err)
+		}
+	}
+
+	return vm, nil
+}
+
+func serviceToProto(service *Service) (*didpb.Service, error) {
+	msg := &didpb.Service{
+		Id:            service.ID,
+		Type:          service.Type,
+		Priority:      uint64(service.Priority),
+		RecipientKeys: service.RecipientKeys,
+		RoutingKeys:   service.RoutingKeys,
+		Accept:        service.Accept,
+		RelativeId:    service.relativeURL,
+
+		RelativeRecipientKeys: relativeKeys(service.recipientKeysRelativeURL),
+		RelativeRoutingKeys:   relativeKeys(service.routingKeysRelativeURL),
+	}
+
+	if service.Properties != nil {
+		var err error
+
+		msg.Properties, err = json.Marshal(service.Properties)
+		if err != nil {
+			return nil, fmt.Errorf("marshal properties of service %s: %w", service.ID, err)
+		}
+	}
+
+	endpoint, err := serviceEndpointToProto(&service.ServiceEndpoint)
+	if err != nil {
+		return nil, fmt.Errorf("service %s: %w", service.ID, err)
+	}
+
+	msg.ServiceEndpoint = endpoint
+
+	return msg, nil
+}
+
+func serviceFromProto(msg *didpb.Service) (*Service, error) {
+	service := &Service{
+		ID:            msg.Id,
+		Type:          msg.Type,
+		Priority:      uint(msg.Priority),
+		RecipientKeys: msg.RecipientKeys,
+		RoutingKeys:   msg.RoutingKeys,
+		Accept:        msg.Accept,
+		relativeURL:   msg.RelativeId,
+
+		recipientKeysRelativeURL: relativeKeysURL(msg.RelativeRecipientKeys),
+		routingKeysRelativeURL:   relativeKeysURL(msg.RelativeRoutingKeys),
+	}
+
+	if len(msg.Properties) > 0 {
+		if err := json.Unmarshal(msg.Properties, &service.Properties); err != nil {
+			return nil, fmt.Errorf("unmarshal properties of service %s: %w", msg.Id, err)
+		}
+	}
+
+	endpoint, err := serviceEndpointFromProto(msg.ServiceEndpoint)
+	if err != nil {
+		return nil, fmt.Errorf("service %s: %w", msg.Id, err)
+	}
+
+	service.ServiceEndpoint = endpoint
+
+	return service, nil
+}
+
+// relativeKeys returns the sorted keys represented as relative DID URLs.
+func relativeKeys(keysRelativeURL map[string]bool) []string {
+	var keys []string
+
+	for key, relative := range keysRelativeURL {
+		if relative {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func relativeKeysURL(keys []string) map[string]bool {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	keysRelativeURL := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		keysRelativeURL[key] = true
+	}
+
+	return keysRelativeURL
+}
+
+func serviceEndpointToProto(endpoint *model.Endpoint) (*didpb.ServiceEndpoint, error) {
+	if endpoint.Type() == model.DIDCommV1 {
+		uri, err := endpoint.URI()
+		if err != nil {
+			return nil, err
+		}
+
+		return &didpb.ServiceEndpoint{Endpoint: &didpb.ServiceEndpoint_Uri{Uri: uri}}, nil
+	}
+
+	data, err := endpoint.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("marshal endpoint: %w", err)
+	}
+
+	switch endpoint.Type() {
+	case model.DIDCommV2:
+		var endpoints []model.DIDCommV2Endpoint
+
+		if err := json.Unmarshal(data, &endpoints); err != nil {
+			return nil, fmt.Errorf("unmarshal DIDComm V2 endpoint: %w", err)
+		}
+
+		msg := &didpb.DidCommV2Endpoints{}
+		for _, e := range endpoints {
+			msg.Endpoints = append(msg.Endpoints, &didpb.DidCommV2Endpoint{
+				Uri: e.URI, Accept: e.Accept, RoutingKeys: e.RoutingKeys,
+			})
+		}
+
+		return &didpb.ServiceEndpoint{Endpoint: &didpb.ServiceEndpoint_DidcommV2{DidcommV2: msg}}, nil
+	default:
+		if string(data) == "null" {
+			return nil, nil
+		}
+
+		return &didpb.ServiceEndpoint{Endpoint: &didpb.ServiceEndpoint_Generic{Generic: data}}, nil
+	}
+}
+
+func serviceEndpointFromProto(msg *didpb.ServiceEndpoint) (model.Endpoint, error) {
+	switch endpoint := msg.GetEndpoint().(type) {
+	case *didpb.ServiceEndpoint_Uri:
+		return model.NewDIDCommV1Endpoint(endpoint.Uri), nil
+	case *didpb.ServiceEndpoint_DidcommV2:
+		var endpoints []model.DIDCommV2Endpoint
+
+		for _, e := range endpoint.DidcommV2.Endpoints {
+			endpoints = append(endpoints, model.DIDCommV2Endpoint{
+				URI: e.Uri, Accept: e.Accept, RoutingKeys: e.RoutingKeys,
+			})
+		}
+
+		return model.NewDIDCommV2Endpoint(endpoints), nil
+	case *didpb.ServiceEndpoint_Generic:
+		var generic interface{}
+
+		if err := json.Unmarshal(endpoint.Generic, &generic); err != nil {
+			return model.Endpoint{}, fmt.Errorf("unmarshal generic endpoint: %w", err)
+		}
+
+		return model.NewDIDCoreEndpoint(generic), nil
+	default:
+		return model.Endpoint{}, nil
+	}
+}
+
+func timestampToProto(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+
+	return timestamppb.New(*t)
+}
+
+func timestampFromProto(ts *timestamppb.Timestamp) (*time.Time, error) {
+	if ts == nil {
+		return nil, nil
+	}
+
+	if err := ts.CheckValid(); err != nil {
+		return nil, fmt.Errorf("invalid timestamp: %w", err)
+	}
+
+	t := ts.AsTime()
+
+	return &t, nil
+}
@@ -0,0 +1,137 @@
+package did
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zRich/zFusion/common/model"
+	"github.com/zRich/zFusion/did/didpb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// testDocuments returns the valid documents of testdata.
+func testDocuments(t *testing.T) map[string]*Document {
+	t.Helper()
+
+	files, err := filepath.Glob("testdata/valid_*.jsonld")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	docs := map[string]*Document{}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+
+		doc, err := ParseDocument(data)
+		if err != nil {
+			resolution, err := ParseDocumentResolution(data)
+			require.NoError(t, err, file)
+
+			doc = resolution.DIDDocument
+		}
+
+		docs[file] = doc
+	}
+
+	return docs
+}
+
+const keysDoc = `{
+  "@context": ["https://www.w3.org/ns/did/v1"],
+  "id": "did:example:123",
+  "verificationMethod": [{
+    "id": "#key-1",
+    "type": "JsonWebKey2020",
+    "controller": "did:example:123",
+    "publicKeyJwk": {"kty": "OKP", "crv": "Ed25519", "x": "PUAXw-hDiVqStwqnTRt-vJyYLM8uxJaMwM1V8Sr0Zgw"}
+  }, {
+    "id": "#key-2",
+    "type": "Ed25519VerificationKey2020",
+    "controller": "did:example:123",
+    "publicKeyMultibase": "z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK"
+  }],
+  "assertionMethod": ["#key-2"],
+  "created": "2022-01-01T12:30:00.5Z"
+}`
+
+func TestDocument_ToProto(t *testing.T) {
+	docs := testDocuments(t)
+
+	doc, err := ParseDocument([]byte(keysDoc))
+	require.NoError(t, err)
+
+	docs["keys"] = doc
+
+	for name, doc := range docs {
+		t.Run(name, func(t *testing.T) {
+			expected, err := doc.JSONBytes()
+			require.NoError(t, err)
+
+			msg, err := doc.ToProto()
+			require.NoError(t, err)
+
+			data, err := proto.Marshal(msg)
+			require.NoError(t, err)
+
+			decoded := &didpb.DidDocument{}
+			require.NoError(t, proto.Unmarshal(data, decoded))
+
+			result, err := DocumentFromProto(decoded)
+			require.NoError(t, err)
+
+			actual, err := result.JSONBytes()
+			require.NoError(t, err)
+			require.JSONEq(t, string(expected), string(actual))
+		})
+	}
+
+	t.Run("service endpoints", func(t *testing.T) {
+		doc := &Document{Context: ContextV1, ID: "did:example:123", Service: []Service{
+			{ID: "did:example:123#v2", Type: "DIDCommMessaging", ServiceEndpoint: model.NewDIDCommV2Endpoint(
+				[]model.DIDCommV2Endpoint{
+					{URI: "https://a.example.com", Accept: []string{"didcomm/v2"}},
+					{URI: "https://b.example.com", RoutingKeys: []string{"did:example:mediator#key-1"}},
+				})},
+			{ID: "did:example:123#generic", Type: "LinkedDomains",
+				ServiceEndpoint: model.NewDIDCoreEndpoint([]string{"https://c.example.com"})},
+			{ID: "did:example:123#none", Type: "None"},
+		}}
+
+		msg, err := doc.ToProto()
+		require.NoError(t, err)
+		require.Len(t, msg.Service[0].ServiceEndpoint.GetDidcommV2().Endpoints, 2)
+		require.JSONEq(t, `["https://c.example.com"]`, string(msg.Service[1].ServiceEndpoint.GetGeneric()))
+		require.Nil(t, msg.Service[2].ServiceEndpoint)
+
+		result, err := DocumentFromProto(msg)
+		require.NoError(t, err)
+
+		for i := range doc.Service {
+			expected, err := doc.Service[i].ServiceEndpoint.MarshalJSON()
+			require.NoError(t, err)
+
+			actual, err := result.Service[i].ServiceEndpoint.MarshalJSON()
+			require.NoError(t, err)
+			require.JSONEq(t, string(expected), string(actual))
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		for name, msg := range map[string]*didpb.DidDocument{
+			"context":   {Context: []byte("{")},
+			"timestamp": {Created: &timestamppb.Timestamp{Nanos: -1}},
+			"JWK": {VerificationMethod: []*didpb.VerificationMethod{
+				{Id: "did:example:123#key-1", PublicKeyJwk: []byte(`{"kty":"unknown"}`)},
+			}},
+			"verification": {Authentication: []*didpb.Verification{{}}},
+			"properties":   {Service: []*didpb.Service{{Id: "did:example:123#s", Properties: []byte("[")}}},
+		} {
+			_, err := DocumentFromProto(msg)
+			require.Error(t, err, name)
+		}
+	})
+}
@@ -99,7 +99,11 @@ const (
   "definitions": {
     "proof": {
       "type": "object",
-      "required": [ "type", "creator", "created", "proofValue"],
+      "required": [ "type", "creator", "created"],
+      "anyOf": [
+        {"required": ["proofValue"]},
+        {"required": ["jws"]}
+      ],
       "properties": {
         "type": {
           "type": "string",
@@ -115,6 +119,9 @@ const (
         "proofValue": {
           "type": "string"
         },
+        "jws": {
+          "type": "string"
+        },
         "domain": {
           "type": "string"
         },
@@ -463,7 +470,11 @@ const (
   "definitions": {
 	"proof": {
       "type": "object",
-      "required": [ "type", "creator", "created", "proofValue"],
+      "required": [ "type", "creator", "created"],
+      "anyOf": [
+        {"required": ["proofValue"]},
+        {"required": ["jws"]}
+      ],
       "properties": {
         "type": {
           "type": "string",
@@ -479,6 +490,9 @@ const (
         "proofValue": {
           "type": "string"
         },
+        "jws": {
+          "type": "string"
+        },
         "domain": {
           "type": "string"
         },
@@ -1,16 +0,0 @@
-//go:build !ACAPyInterop
-// +build !ACAPyInterop
-
-/*
-Copyright SecureKey Technologies Inc. All Rights Reserved.
-SPDX-License-Identifier: Apache-2.0
-*/
-
-package did
-
-const doACAPYInterop = false
-
-// SerializeInterop serializes the DID doc, using normal serialization unless the `interop` build flag is set.
-func (doc *Document) SerializeInterop() ([]byte, error) {
-	return doc.JSONBytes()
-}
@@ -0,0 +1,500 @@
+// Package vc implements W3C Verifiable Credentials, Data Model 1.1 and 2.0, secured with linked data proofs
+// created by keys of the issuer's DID document.
+package vc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zRich/zFusion/common/jsonld"
+)
+
+// Base contexts of credentials and presentations.
+const (
+	ContextV1 = jsonld.ContextCredentialsV1
+	ContextV2 = jsonld.ContextCredentialsV2
+)
+
+// TypeVerifiableCredential is the type every credential has.
+const TypeVerifiableCredential = "VerifiableCredential"
+
+var (
+	// ErrInvalidCredential is returned when a credential does not conform to the data model.
+	ErrInvalidCredential = errors.New("invalid credential")
+	// ErrExpired is returned when a credential is past its expiration date.
+	ErrExpired = errors.New("credential expired")
+	// ErrNotYetValid is returned when a credential is used before its issuance date.
+	ErrNotYetValid = errors.New("credential not yet valid")
+)
+
+// Issuer is the issuer of a credential. It is serialized as its ID unless it has custom fields.
+type Issuer struct {
+	ID           string
+	CustomFields map[string]interface{}
+}
+
+// Subject is a credential subject with its claims.
+type Subject struct {
+	ID     string
+	Claims map[string]interface{}
+}
+
+// TypedID is an object with an ID and a type, such as a credential schema or status.
+type TypedID struct {
+	ID           string
+	Type         string
+	CustomFields map[string]interface{}
+}
+
+// Credential is a verifiable credential. Issued and Expired are serialized as issuanceDate and expirationDate
+// by version 1.1 credentials, and as validFrom and validUntil by version 2.0 credentials.
+type Credential struct {
+	Context       []string
+	CustomContext []interface{}
+	ID            string
+	Types         []string
+	Issuer        Issuer
+	Issued        *time.Time
+	Expired       *time.Time
+	Subjects      []Subject
+	Schemas       []TypedID
+	Status        *TypedID
+	Proofs        []Proof
+	CustomFields  map[string]interface{}
+}
+
+// credential fields with a dedicated Credential field.
+const (
+	fieldContext        = "@context"
+	fieldID             = "id"
+	fieldType           = "type"
+	fieldIssuer         = "issuer"
+	fieldIssuanceDate   = "issuanceDate"
+	fieldExpirationDate = "expirationDate"
+	fieldValidFrom      = "validFrom"
+	fieldValidUntil     = "validUntil"
+	fieldSubject        = "credentialSubject"
+	fieldSchema         = "credentialSchema"
+	fieldStatus         = "credentialStatus"
+	fieldProof          = "proof"
+)
+
+// IsV2 reports whether the credential follows the Verifiable Credentials Data Model 2.0.
+func (c *Credential) IsV2() bool {
+	return len(c.Context) > 0 && c.Context[0] == ContextV2
+}
+
+// ParseCredential parses a credential and checks it conforms to the data model. Proofs are not verified,
+// see VerifyCredential.
+func ParseCredential(data []byte) (*Credential, error) {
+	raw, err := decodeObject(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredential, err)
+	}
+
+	return credentialFromMap(raw)
+}
+
+//nolint:gocyclo,funlen
+func credentialFromMap(raw map[string]interface{}) (*Credential, error) {
+	c := &Credential{CustomFields: map[string]interface{}{}}
+
+	var err error
+
+	c.Context, c.CustomContext, err = parseContext(raw[fieldContext])
+	if err != nil {
+		return nil, err
+	}
+
+	c.ID, err = optionalString(raw, fieldID)
+	if err != nil {
+		return nil, err
+	}
+
+	c.Types, err = stringOrArray(raw[fieldType])
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid type: %v", ErrInvalidCredential, err)
+	}
+
+	switch issuer := raw[fieldIssuer].(type) {
+	case string:
+		c.Issuer.ID = issuer
+	case map[string]interface{}:
+		c.Issuer.ID, _ = issuer[fieldID].(string) //nolint:errcheck
+		c.Issuer.CustomFields = without(issuer, fieldID)
+	}
+
+	issuedField, expiredField := fieldIssuanceDate, fieldExpirationDate
+	if c.IsV2() {
+		issuedField, expiredField = fieldValidFrom, fieldValidUntil
+	}
+
+	if c.Issued, err = optionalTime(raw, issuedField); err != nil {
+		return nil, err
+	}
+
+	if c.Expired, err = optionalTime(raw, expiredField); err != nil {
+		return nil, err
+	}
+
+	for _, s := range objectOrArray(raw[fieldSubject]) {
+		subject, ok := s.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%w: invalid credential subject", ErrInvalidCredential)
+		}
+
+		id, _ := subject[fieldID].(string) //nolint:errcheck
+		c.Subjects = append(c.Subjects, Subject{ID: id, Claims: without(subject, fieldID)})
+	}
+
+	for _, s := range objectOrArray(raw[fieldSchema]) {
+		schema, err := parseTypedID(s)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid credential schema: %v", ErrInvalidCredential, err)
+		}
+
+		c.Schemas = append(c.Schemas, *schema)
+	}
+
+	if status, ok := raw[fieldStatus]; ok {
+		c.Status, err = parseTypedID(status)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid credential status: %v", ErrInvalidCredential, err)
+		}
+	}
+
+	c.Proofs, err = parseProofs(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range raw {
+		switch k {
+		case fieldContext, fieldID, fieldType, fieldIssuer, issuedField, expiredField, fieldSubject, fieldSchema,
+			fieldStatus, fieldProof:
+		default:
+			c.CustomFields[k] = v
+		}
+	}
+
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// validate checks the credential has the properties required by the data model.
+func (c *Credential) validate() error {
+	if len(c.Context) == 0 || (c.Context[0] != ContextV1 && c.Context[0] != ContextV2) {
+		return fmt.Errorf("%w: first context must be %s or %s", ErrInvalidCredential, ContextV1, ContextV2)
+	}
+
+	if !contains(c.Types, TypeVerifiableCredential) {
+		return fmt.Errorf("%w: type must include %s", ErrInvalidCredential, TypeVerifiableCredential)
+	}
+
+	if c.Issuer.ID == "" {
+		return fmt.Errorf("%w: missing issuer", ErrInvalidCredential)
+	}
+
+	if c.Issued == nil && !c.IsV2() {
+		return fmt.Errorf("%w: missing issuance date", ErrInvalidCredential)
+	}
+
+	if len(c.Subjects) == 0 {
+		return fmt.Errorf("%w: missing credential subject", ErrInvalidCredential)
+	}
+
+	for _, schema := range c.Schemas {
+		if schema.ID == "" || schema.Type == "" {
+			return fmt.Errorf("%w: credential schema requires id and type", ErrInvalidCredential)
+		}
+	}
+
+	return nil
+}
+
+// MarshalJSON marshals the credential.
+func (c *Credential) MarshalJSON() ([]byte, error) {
+	raw, err := c.toMap()
+	if err != nil {
+		return nil, err
+	}
+
+	return marshal(raw)
+}
+
+func (c *Credential) toMap() (map[string]interface{}, error) {
+	raw := make(map[string]interface{}, len(c.CustomFields))
+
+	for k, v := range c.CustomFields {
+		raw[k] = v
+	}
+
+	raw[fieldContext] = contextValue(c.Context, c.CustomContext)
+	raw[fieldType] = c.Types
+
+	if c.ID != "" {
+		raw[fieldID] = c.ID
+	}
+
+	raw[fieldIssuer] = c.Issuer.ID
+	if len(c.Issuer.CustomFields) > 0 {
+		raw[fieldIssuer] = with(c.Issuer.CustomFields, fieldID, c.Issuer.ID)
+	}
+
+	issuedField, expiredField := fieldIssuanceDate, fieldExpirationDate
+	if c.IsV2() {
+		issuedField, expiredField = fieldValidFrom, fieldValidUntil
+	}
+
+	if c.Issued != nil {
+		raw[issuedField] = formatTime(*c.Issued)
+	}
+
+	if c.Expired != nil {
+		raw[expiredField] = formatTime(*c.Expired)
+	}
+
+	subjects := make([]interface{}, len(c.Subjects))
+	for i, s := range c.Subjects {
+		subjects[i] = with(s.Claims, fieldID, s.ID)
+	}
+
+	raw[fieldSubject] = singleOrArray(subjects)
+
+	if len(c.Schemas) > 0 {
+		schemas := make([]interface{}, len(c.Schemas))
+		for i, s := range c.Schemas {
+			schemas[i] = s.toMap()
+		}
+
+		raw[fieldSchema] = singleOrArray(schemas)
+	}
+
+	if c.Status != nil {
+		raw[fieldStatus] = c.Status.toMap()
+	}
+
+	if len(c.Proofs) > 0 {
+		proofs, err := proofsValue(c.Proofs)
+		if err != nil {
+			return nil, err
+		}
+
+		raw[fieldProof] = proofs
+	}
+
+	return raw, nil
+}
+
+func (t *TypedID) toMap() map[string]interface{} {
+	m := with(t.CustomFields, fieldID, t.ID)
+	m[fieldType] = t.Type
+
+	return m
+}
+
+func parseTypedID(v interface{}) (*TypedID, error) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("not an object")
+	}
+
+	id, _ := m[fieldID].(string)    //nolint:errcheck
+	typ, _ := m[fieldType].(string) //nolint:errcheck
+
+	return &TypedID{ID: id, Type: typ, CustomFields: without(without(m, fieldID), fieldType)}, nil
+}
+
+func parseContext(v interface{}) ([]string, []interface{}, error) {
+	var (
+		contexts []string
+		custom   []interface{}
+	)
+
+	for _, c := range objectOrArray(v) {
+		switch ctx := c.(type) {
+		case string:
+			if len(custom) > 0 {
+				return nil, nil, fmt.Errorf("%w: context URLs must precede embedded contexts", ErrInvalidCredential)
+			}
+
+			contexts = append(contexts, ctx)
+		case map[string]interface{}:
+			custom = append(custom, ctx)
+		default:
+			return nil, nil, fmt.Errorf("%w: invalid context", ErrInvalidCredential)
+		}
+	}
+
+	return contexts, custom, nil
+}
+
+func contextValue(contexts []string, custom []interface{}) []interface{} {
+	value := make([]interface{}, 0, len(contexts)+len(custom))
+
+	for _, c := range contexts {
+		value = append(value, c)
+	}
+
+	return append(value, custom...)
+}
+
+func optionalString(raw map[string]interface{}, field string) (string, error) {
+	v, ok := raw[field]
+	if !ok {
+		return "", nil
+	}
+
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("%w: %s must be a string", ErrInvalidCredential, field)
+	}
+
+	return s, nil
+}
+
+func optionalTime(raw map[string]interface{}, field string) (*time.Time, error) {
+	s, err := optionalString(raw, field)
+	if err != nil || s == "" {
+		return nil, err
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid %s: %v", ErrInvalidCredential, field, err)
+	}
+
+	return &t, nil
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func stringOrArray(v interface{}) ([]string, error) {
+	var result []string
+
+	for _, item := range objectOrArray(v) {
+		s, ok := item.(string)
+		if !ok {
+			return nil, errors.New("not a string")
+		}
+
+		result = append(result, s)
+	}
+
+	return result, nil
+}
+
+func objectOrArray(v interface{}) []interface{} {
+	switch a := v.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		return a
+	default:
+		return []interface{}{v}
+	}
+}
+
+func singleOrArray(values []interface{}) interface{} {
+	if len(values) == 1 {
+		return values[0]
+	}
+
+	return values
+}
+
+// with returns a copy of m with key set to value, unless value is empty.
+func with(m map[string]interface{}, key, value string) map[string]interface{} {
+	c := make(map[string]interface{}, len(m)+1)
+
+	for k, v := range m {
+		c[k] = v
+	}
+
+	if value != "" {
+		c[key] = value
+	}
+
+	return c
+}
+
+// without returns a copy of m without key.
+func without(m map[string]interface{}, key string) map[string]interface{} {
+	c := make(map[string]interface{}, len(m))
+
+	for k, v := range m {
+		if k != key {
+			c[k] = v
+		}
+	}
+
+	return c
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// decodeObject decodes a JSON object keeping numbers as json.Number, so that they are signed as issued.
+func decodeObject(data []byte) (map[string]interface{}, error) {
+	v, err := decodeValue(data)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("not a JSON object")
+	}
+
+	return raw, nil
+}
+
+// decodeValue decodes JSON keeping numbers as json.Number.
+func decodeValue(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v interface{}
+
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// isJWT reports whether data is a compact JWT rather than JSON.
+func isJWT(data []byte) bool {
+	data = bytes.TrimSpace(data)
+
+	return len(data) > 0 && data[0] != '{' && bytes.Count(data, []byte(".")) == 2
+}
+
+// marshal marshals v without HTML escaping.
+func marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
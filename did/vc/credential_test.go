@@ -0,0 +1,103 @@
+package vc
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const credentialV1 = `{
+  "@context": ["https://www.w3.org/2018/credentials/v1", {"@vocab": "https://example.com/vocab#"}],
+  "id": "urn:uuid:3978344f-8596-4c3a-a978-8fcaba3903c5",
+  "type": ["VerifiableCredential", "AssetOwnershipCredential"],
+  "issuer": {"id": "did:example:issuer", "name": "Example Registry"},
+  "issuanceDate": "2022-01-01T00:00:00Z",
+  "expirationDate": "2032-01-01T00:00:00Z",
+  "credentialSubject": {"id": "did:example:owner", "asset": "urn:asset:42", "share": 100},
+  "credentialSchema": {"id": "https://example.com/schemas/asset.json", "type": "JsonSchemaValidator2018"},
+  "evidence": [{"type": "DocumentVerification"}]
+}`
+
+func TestParseCredential(t *testing.T) {
+	cred, err := ParseCredential([]byte(credentialV1))
+	require.NoError(t, err)
+
+	require.Equal(t, []string{ContextV1}, cred.Context)
+	require.Len(t, cred.CustomContext, 1)
+	require.Equal(t, "urn:uuid:3978344f-8596-4c3a-a978-8fcaba3903c5", cred.ID)
+	require.Equal(t, []string{TypeVerifiableCredential, "AssetOwnershipCredential"}, cred.Types)
+	require.Equal(t, Issuer{ID: "did:example:issuer", CustomFields: map[string]interface{}{
+		"name": "Example Registry",
+	}}, cred.Issuer)
+	require.Equal(t, time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), cred.Issued.UTC())
+	require.Equal(t, time.Date(2032, 1, 1, 0, 0, 0, 0, time.UTC), cred.Expired.UTC())
+	require.Equal(t, []Subject{{ID: "did:example:owner", Claims: map[string]interface{}{
+		"asset": "urn:asset:42", "share": json.Number("100"),
+	}}}, cred.Subjects)
+	require.Equal(t, []TypedID{{
+		ID: "https://example.com/schemas/asset.json", Type: JSONSchemaValidator2018, CustomFields: map[string]interface{}{},
+	}}, cred.Schemas)
+	require.Contains(t, cred.CustomFields, "evidence")
+	require.False(t, cred.IsV2())
+
+	data, err := json.Marshal(cred)
+	require.NoError(t, err)
+	require.JSONEq(t, credentialV1, string(data))
+}
+
+func TestParseCredentialV2(t *testing.T) {
+	data := `{
+	  "@context": ["https://www.w3.org/ns/credentials/v2"],
+	  "type": "VerifiableCredential",
+	  "issuer": "did:example:issuer",
+	  "validFrom": "2022-01-01T00:00:00Z",
+	  "validUntil": "2032-01-01T00:00:00Z",
+	  "credentialSubject": [{"id": "did:example:a"}, {"id": "did:example:b"}]
+	}`
+
+	cred, err := ParseCredential([]byte(data))
+	require.NoError(t, err)
+	require.True(t, cred.IsV2())
+	require.Equal(t, "did:example:issuer", cred.Issuer.ID)
+	require.NotNil(t, cred.Issued)
+	require.NotNil(t, cred.Expired)
+	require.Len(t, cred.Subjects, 2)
+
+	marshaled, err := json.Marshal(cred)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+	  "@context": ["https://www.w3.org/ns/credentials/v2"],
+	  "type": ["VerifiableCredential"],
+	  "issuer": "did:example:issuer",
+	  "validFrom": "2022-01-01T00:00:00Z",
+	  "validUntil": "2032-01-01T00:00:00Z",
+	  "credentialSubject": [{"id": "did:example:a"}, {"id": "did:example:b"}]
+	}`, string(marshaled))
+}
+
+func TestParseCredentialErrors(t *testing.T) {
+	for name, data := range map[string]string{
+		"not JSON":        `{`,
+		"no context":      `{"type": "VerifiableCredential", "issuer": "did:example:issuer"}`,
+		"unknown context": `{"@context": "https://example.com/context", "type": "VerifiableCredential"}`,
+		"context order":   `{"@context": [{"@vocab": "https://example.com/"}, "https://www.w3.org/2018/credentials/v1"]}`,
+		"type": `{"@context": "https://www.w3.org/2018/credentials/v1", "type": "Credential",
+			"issuer": "did:example:issuer", "issuanceDate": "2022-01-01T00:00:00Z", "credentialSubject": {}}`,
+		"no issuer": `{"@context": "https://www.w3.org/2018/credentials/v1", "type": "VerifiableCredential",
+			"issuanceDate": "2022-01-01T00:00:00Z", "credentialSubject": {}}`,
+		"no issuance date": `{"@context": "https://www.w3.org/2018/credentials/v1", "type": "VerifiableCredential",
+			"issuer": "did:example:issuer", "credentialSubject": {}}`,
+		"invalid date": `{"@context": "https://www.w3.org/2018/credentials/v1", "type": "VerifiableCredential",
+			"issuer": "did:example:issuer", "issuanceDate": "2022-01-01", "credentialSubject": {}}`,
+		"no subject": `{"@context": "https://www.w3.org/2018/credentials/v1", "type": "VerifiableCredential",
+			"issuer": "did:example:issuer", "issuanceDate": "2022-01-01T00:00:00Z"}`,
+		"schema": `{"@context": "https://www.w3.org/2018/credentials/v1", "type": "VerifiableCredential",
+			"issuer": "did:example:issuer", "issuanceDate": "2022-01-01T00:00:00Z", "credentialSubject": {},
+			"credentialSchema": {"id": "https://example.com/schema.json"}}`,
+	} {
+		_, err := ParseCredential([]byte(data))
+		require.ErrorIs(t, err, ErrInvalidCredential, name)
+	}
+}
@@ -0,0 +1,75 @@
+package vc
+
+import (
+	"fmt"
+
+	"github.com/zRich/zFusion/common/crypto"
+	"github.com/zRich/zFusion/did"
+)
+
+// Issue signs the credential with signer and appends the proof. keyID is the ID of the assertion method of
+// the issuer DID document holding the public key of signer, relative IDs are resolved against the document
+// ID. The credential issuer defaults to the document ID, and its issuance date to the proof creation time.
+//
+// Proof types whose terms are not defined by the credential contexts add their context to the credential,
+// which is only possible for a credential without proofs.
+func Issue(cred *Credential, issuerDoc *did.Document, keyID string, signer crypto.Signer, opts ...Option) error {
+	o := getOptions(opts)
+
+	if err := cred.prepareIssuance(issuerDoc, o); err != nil {
+		return err
+	}
+
+	vm, err := issuerDoc.AuthorizedVerificationMethod(keyID, did.AssertionMethod)
+	if err != nil {
+		return fmt.Errorf("issuer key %s: %w", keyID, err)
+	}
+
+	proof := Proof{
+		Type:               o.proofType,
+		Created:            &o.created,
+		VerificationMethod: did.AbsoluteID(issuerDoc.ID, keyID),
+		ProofPurpose:       did.PurposeAssertionMethod,
+	}
+
+	if err := signDocument(&cred.Context, cred.Proofs, cred.toMap, signer, vm, &proof, o.loader); err != nil {
+		return err
+	}
+
+	cred.Proofs = append(cred.Proofs, proof)
+
+	return nil
+}
+
+// prepareIssuance sets the default issuer and issuance date of the credential, and validates it, against its
+// credential schemas too when a schema loader is set.
+func (c *Credential) prepareIssuance(issuerDoc *did.Document, o *options) error {
+	if c.Issuer.ID == "" {
+		c.Issuer.ID = issuerDoc.ID
+	}
+
+	if c.Issuer.ID != issuerDoc.ID {
+		return fmt.Errorf("%w: issuer %s is not the subject of the DID document %s", ErrInvalidCredential,
+			c.Issuer.ID, issuerDoc.ID)
+	}
+
+	if c.Issued == nil && !c.IsV2() {
+		issued := o.created
+		c.Issued = &issued
+	}
+
+	if err := c.validate(); err != nil {
+		return err
+	}
+
+	if o.schemaLoader == nil {
+		return nil
+	}
+
+	raw, err := c.toMap()
+	if err != nil {
+		return err
+	}
+
+	return validateSchemas(raw, c.Schemas, o.schemaLoader)
+}
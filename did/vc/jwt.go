@@ -0,0 +1,484 @@
+package vc
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zRich/zFusion/common/crypto"
+	"github.com/zRich/zFusion/common/crypto/jwk"
+	"github.com/zRich/zFusion/did"
+)
+
+// JWT header parameters and registered claims of VC-JWT.
+const (
+	jwtAlg   = "alg"
+	jwtKid   = "kid"
+	jwtTyp   = "typ"
+	jwtIss   = "iss"
+	jwtSub   = "sub"
+	jwtAud   = "aud"
+	jwtJti   = "jti"
+	jwtNbf   = "nbf"
+	jwtExp   = "exp"
+	jwtIat   = "iat"
+	jwtNonce = "nonce"
+	jwtVC    = "vc"
+	jwtVP    = "vp"
+)
+
+// typJWT is the type of credential and presentation JWTs.
+const typJWT = "JWT"
+
+// ErrInvalidJWT is returned when a JWT is malformed.
+var ErrInvalidJWT = errors.New("invalid JWT")
+
+// IssueJWT encodes the credential as a JWT signed by signer, following the JWT encoding of the Verifiable
+// Credentials Data Model 1.1. keyID is the ID of the assertion method of the issuer DID document holding the
+// public key of signer; its absolute form is the kid header of the JWT. The credential issuer and issuance date
+// default as for Issue. Existing proofs of the credential are not encoded.
+func IssueJWT(cred *Credential, issuerDoc *did.Document, keyID string, signer crypto.Signer,
+	opts ...Option) (string, error) {
+	claims, vm, err := credentialClaims(cred, issuerDoc, keyID, getOptions(opts))
+	if err != nil {
+		return "", err
+	}
+
+	return signJWT(typJWT, did.AbsoluteID(issuerDoc.ID, keyID), claims, signer, vm)
+}
+
+// credentialClaims prepares the credential for issuance and returns its JWT claims, and the issuer key.
+func credentialClaims(cred *Credential, issuerDoc *did.Document, keyID string,
+	o *options) (map[string]interface{}, *did.VerificationMethod, error) {
+	if err := cred.prepareIssuance(issuerDoc, o); err != nil {
+		return nil, nil, err
+	}
+
+	vm, err := issuerDoc.AuthorizedVerificationMethod(keyID, did.AssertionMethod)
+	if err != nil {
+		return nil, nil, fmt.Errorf("issuer key %s: %w", keyID, err)
+	}
+
+	vc, err := cred.toMap()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	delete(vc, fieldProof)
+
+	claims := map[string]interface{}{jwtIss: cred.Issuer.ID, jwtVC: vc}
+
+	if cred.ID != "" {
+		claims[jwtJti] = cred.ID
+	}
+
+	if len(cred.Subjects) == 1 && cred.Subjects[0].ID != "" {
+		claims[jwtSub] = cred.Subjects[0].ID
+	}
+
+	if cred.Issued != nil {
+		claims[jwtNbf] = cred.Issued.Unix()
+	}
+
+	if cred.Expired != nil {
+		claims[jwtExp] = cred.Expired.Unix()
+	}
+
+	return claims, vm, nil
+}
+
+// ParseCredentialJWT parses a credential JWT without verifying it, see VerifyCredentialJWT.
+func ParseCredentialJWT(token string) (*Credential, error) {
+	jwt, err := parseCredentialJWT(token)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := credentialFromClaims(jwt.claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return credentialFromMap(raw)
+}
+
+// VerifyCredentialJWT parses and verifies a credential JWT: its kid header must identify an assertion method of
+// the DID document of the iss claim, resolved with the resolver set by WithDocumentResolver. The validity period,
+// status and credential schemas of the credential are then checked as by VerifyCredential.
+func VerifyCredentialJWT(token string, opts ...Option) (*Credential, error) {
+	cred, _, err := verifyCredentialJWT(token, getOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	return cred, nil
+}
+
+// verifyCredentialJWT verifies a credential JWT. The parsed credential is returned with verification errors
+// that occur after parsing.
+func verifyCredentialJWT(token string, o *options) (*Credential, map[string]interface{}, error) {
+	jwt, err := parseCredentialJWT(token)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	raw, err := credentialFromClaims(jwt.claims)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cred, err := credentialFromMap(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := jwt.verify(cred.Issuer.ID, did.AssertionMethod, o); err != nil {
+		return cred, raw, err
+	}
+
+	if err := cred.checkValidityPeriod(o.now); err != nil {
+		return cred, raw, err
+	}
+
+	if err := cred.checkStatus(o); err != nil {
+		return cred, raw, err
+	}
+
+	return cred, raw, validateSchemas(raw, cred.Schemas, o.schemaLoader)
+}
+
+// parseCredentialJWT parses a credential JWT, which must not be an SD-JWT whose claims are not all disclosed.
+func parseCredentialJWT(token string) (*jwt, error) {
+	jwt, err := parseJWT(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := jwt.claims[sdAlg]; ok {
+		return nil, fmt.Errorf("%w: selectively disclosable JWT, see VerifySDJWT", ErrInvalidJWT)
+	}
+
+	return jwt, nil
+}
+
+// credentialFromClaims returns the credential of a JWT, completed with the registered claims it may omit.
+func credentialFromClaims(claims map[string]interface{}) (map[string]interface{}, error) {
+	vc, ok := claims[jwtVC].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: missing vc claim", ErrInvalidJWT)
+	}
+
+	iss, _ := claims[jwtIss].(string) //nolint:errcheck
+
+	switch issuer := vc[fieldIssuer].(type) {
+	case nil:
+		vc[fieldIssuer] = iss
+	case string:
+		if issuer != iss {
+			return nil, fmt.Errorf("%w: iss claim does not match the credential issuer", ErrInvalidJWT)
+		}
+	case map[string]interface{}:
+		if issuer[fieldID] != iss {
+			return nil, fmt.Errorf("%w: iss claim does not match the credential issuer", ErrInvalidJWT)
+		}
+	}
+
+	if jti, ok := claims[jwtJti].(string); ok {
+		if _, ok := vc[fieldID]; !ok {
+			vc[fieldID] = jti
+		}
+	}
+
+	if sub, ok := claims[jwtSub].(string); ok {
+		if subject, ok := vc[fieldSubject].(map[string]interface{}); ok {
+			if _, ok := subject[fieldID]; !ok {
+				subject[fieldID] = sub
+			}
+		}
+	}
+
+	issuedField, expiredField := fieldIssuanceDate, fieldExpirationDate
+
+	contexts, _, err := parseContext(vc[fieldContext])
+	if err != nil {
+		return nil, err
+	}
+
+	if (&Credential{Context: contexts}).IsV2() {
+		issuedField, expiredField = fieldValidFrom, fieldValidUntil
+	}
+
+	for claim, field := range map[string]string{jwtNbf: issuedField, jwtExp: expiredField} {
+		if _, ok := vc[field]; ok {
+			continue
+		}
+
+		if date, ok, err := numericDate(claims, claim); err != nil {
+			return nil, err
+		} else if ok {
+			vc[field] = formatTime(date)
+		}
+	}
+
+	return vc, nil
+}
+
+// SignPresentationJWT encodes the presentation as a JWT signed by signer, following the JWT encoding of the
+// Verifiable Credentials Data Model 1.1. The challenge and domain set by WithChallenge and WithDomain are
+// required, and encoded as the nonce and aud claims. keyID is the ID of the authentication method of the holder
+// DID document holding the public key of signer. Credentials may be embedded or JWTs.
+func SignPresentationJWT(p *Presentation, holderDoc *did.Document, keyID string, signer crypto.Signer,
+	opts ...Option) (string, error) {
+	o := getOptions(opts)
+
+	if o.challenge == "" || o.domain == "" {
+		return "", errors.New("presentation proofs require a challenge and a domain")
+	}
+
+	if err := p.prepareSigning(holderDoc); err != nil {
+		return "", err
+	}
+
+	vm, err := holderDoc.AuthorizedVerificationMethod(keyID, did.Authentication)
+	if err != nil {
+		return "", fmt.Errorf("holder key %s: %w", keyID, err)
+	}
+
+	vp, err := p.toMap()
+	if err != nil {
+		return "", err
+	}
+
+	delete(vp, fieldProof)
+
+	claims := map[string]interface{}{
+		jwtIss:   p.Holder,
+		jwtAud:   o.domain,
+		jwtNonce: o.challenge,
+		jwtIat:   o.created.Unix(),
+		jwtVP:    vp,
+	}
+
+	if p.ID != "" {
+		claims[jwtJti] = p.ID
+	}
+
+	return signJWT(typJWT, did.AbsoluteID(holderDoc.ID, keyID), claims, signer, vm)
+}
+
+// VerifyPresentationJWT parses and verifies a presentation JWT: its kid header must identify an authentication
+// method of the DID document of the iss claim, and its nonce and aud claims must be the challenge and domain
+// set by WithChallenge and WithDomain, which are required. Its credentials are then verified as by
+// VerifyPresentation.
+func VerifyPresentationJWT(token string, opts ...Option) (*PresentationResult, error) {
+	o := getOptions(opts)
+
+	if o.challenge == "" || o.domain == "" {
+		return nil, errors.New("presentation verification requires a challenge and a domain")
+	}
+
+	jwt, err := parseJWT(token)
+	if err != nil {
+		return nil, err
+	}
+
+	vp, ok := jwt.claims[jwtVP].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: missing vp claim", ErrInvalidJWT)
+	}
+
+	p, err := presentationFromMap(vp)
+	if err != nil {
+		return nil, err
+	}
+
+	iss, _ := jwt.claims[jwtIss].(string) //nolint:errcheck
+
+	if p.Holder == "" {
+		p.Holder = iss
+	}
+
+	if p.Holder != iss {
+		return nil, fmt.Errorf("%w: iss claim does not match the presentation holder", ErrInvalidJWT)
+	}
+
+	if nonce, _ := jwt.claims[jwtNonce].(string); nonce != o.challenge { //nolint:errcheck
+		return nil, fmt.Errorf("%w: nonce %q, expected %q", did.ErrInvalidProof, nonce, o.challenge)
+	}
+
+	if !jwt.hasAudience(o.domain) {
+		return nil, fmt.Errorf("%w: audience does not include %q", did.ErrInvalidProof, o.domain)
+	}
+
+	if err := jwt.verify(p.Holder, did.Authentication, o); err != nil {
+		return nil, err
+	}
+
+	return &PresentationResult{Presentation: p, Credentials: verifyPresentedCredentials(vp, p.Holder, o)}, nil
+}
+
+// jwt is a parsed JWS compact serialization with a JSON payload.
+type jwt struct {
+	header       map[string]interface{}
+	claims       map[string]interface{}
+	signingInput string
+	signature    []byte
+}
+
+// signJWT signs a JWT with signer, whose public key must be the one of vm.
+func signJWT(typ, kid string, claims map[string]interface{}, signer crypto.Signer,
+	vm *did.VerificationMethod) (string, error) {
+	alg, err := jwk.SignatureAlgorithm(signer.KeyType())
+	if err != nil {
+		return "", err
+	}
+
+	header, err := marshal(map[string]interface{}{jwtAlg: alg, jwtKid: kid, jwtTyp: typ})
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." +
+		base64.RawURLEncoding.EncodeToString(payload)
+
+	sig, err := signer.Sign([]byte(signingInput))
+	if err != nil {
+		return "", fmt.Errorf("sign JWT: %w", err)
+	}
+
+	// the signer is not trusted to hold the key of the verification method.
+	if err := vm.Verify([]byte(signingInput), sig); err != nil {
+		return "", fmt.Errorf("signer does not hold the key of %s: %w", kid, err)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+func parseJWT(token string) (*jwt, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 { //nolint:gomnd
+		return nil, fmt.Errorf("%w: expected 3 parts, got %d", ErrInvalidJWT, len(parts))
+	}
+
+	header, err := decodeJWTPart(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrInvalidJWT, err)
+	}
+
+	claims, err := decodeJWTPart(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("%w: payload: %v", ErrInvalidJWT, err)
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: signature: %v", ErrInvalidJWT, err)
+	}
+
+	return &jwt{header: header, claims: claims, signingInput: parts[0] + "." + parts[1], signature: sig}, nil
+}
+
+func decodeJWTPart(part string) (map[string]interface{}, error) {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeObject(data)
+}
+
+// verify verifies the JWT signature with the key identified by its kid header, which must be part of the
+// verification relationship of the DID document of controller.
+func (j *jwt) verify(controller string, relationship did.VerificationRelationship, o *options) error {
+	vm, err := j.key(controller, relationship, o)
+	if err != nil {
+		return err
+	}
+
+	return vm.Verify([]byte(j.signingInput), j.signature)
+}
+
+// key resolves the key identified by the kid header in the DID document of controller, and checks the alg
+// header is the algorithm of the key.
+func (j *jwt) key(controller string, relationship did.VerificationRelationship,
+	o *options) (*did.VerificationMethod, error) {
+	kid, _ := j.header[jwtKid].(string) //nolint:errcheck
+	if kid == "" {
+		return nil, fmt.Errorf("%w: missing kid header", ErrInvalidJWT)
+	}
+
+	kid = did.AbsoluteID(controller, kid)
+
+	didURL, err := did.ParseDIDURL(kid)
+	if err != nil || didURL.DID.String() != controller {
+		return nil, fmt.Errorf("%w: kid %s is not a key of %s", did.ErrInvalidProof, kid, controller)
+	}
+
+	if o.resolver == nil {
+		return nil, fmt.Errorf("no document resolver to resolve %s", controller)
+	}
+
+	doc, err := o.resolver.ResolveDocument(controller)
+	if err != nil {
+		return nil, fmt.Errorf("resolve %s: %w", controller, err)
+	}
+
+	vm, err := doc.AuthorizedVerificationMethod(kid, relationship)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", did.ErrInvalidProof, err)
+	}
+
+	keyType, err := vm.KeyType()
+	if err != nil {
+		return nil, err
+	}
+
+	if alg, _ := jwk.SignatureAlgorithm(keyType); alg != j.header[jwtAlg] { //nolint:errcheck
+		return nil, fmt.Errorf("%w: alg %v does not match key %s", did.ErrInvalidProof, j.header[jwtAlg], kid)
+	}
+
+	return vm, nil
+}
+
+func (j *jwt) hasAudience(audience string) bool {
+	switch aud := j.claims[jwtAud].(type) {
+	case string:
+		return aud == audience
+	case []interface{}:
+		for _, a := range aud {
+			if a == audience {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// numericDate returns the time of a NumericDate claim.
+func numericDate(claims map[string]interface{}, claim string) (time.Time, bool, error) {
+	v, ok := claims[claim]
+	if !ok {
+		return time.Time{}, false, nil
+	}
+
+	n, ok := v.(json.Number)
+	if !ok {
+		return time.Time{}, false, fmt.Errorf("%w: %s claim is not a number", ErrInvalidJWT, claim)
+	}
+
+	seconds, err := n.Int64()
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("%w: %s claim: %v", ErrInvalidJWT, claim, err)
+	}
+
+	return time.Unix(seconds, 0).UTC(), true, nil
+}
@@ -0,0 +1,232 @@
+package vc
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zRich/zFusion/common/crypto"
+	"github.com/zRich/zFusion/did"
+)
+
+func decodeJWT(t *testing.T, token string) (map[string]interface{}, map[string]interface{}) {
+	t.Helper()
+
+	parts := strings.Split(token, ".")
+	require.Len(t, parts, 3)
+
+	var header, claims map[string]interface{}
+
+	for i, v := range []*map[string]interface{}{&header, &claims} {
+		data, err := base64.RawURLEncoding.DecodeString(parts[i])
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(data, v))
+	}
+
+	return header, claims
+}
+
+func TestCredentialJWT(t *testing.T) {
+	for _, keyType := range []crypto.KeyType{crypto.Ed25519, crypto.ECDSASecp256k1, crypto.ECDSAP256} {
+		t.Run(string(keyType), func(t *testing.T) {
+			signer := newSigner(t, keyType)
+			issuerDoc := newDoc(t, issuerDID, signer)
+			resolver := WithDocumentResolver(mockDocumentResolver{issuerDID: issuerDoc})
+
+			issued := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+			expired := issued.AddDate(10, 0, 0)
+
+			cred := newCredential(ContextV1)
+			cred.Issued = &issued
+			cred.Expired = &expired
+
+			token, err := IssueJWT(cred, issuerDoc, "#key-1", signer)
+			require.NoError(t, err)
+
+			header, claims := decodeJWT(t, token)
+			require.Equal(t, issuerDID+"#key-1", header["kid"])
+			require.Equal(t, "JWT", header["typ"])
+			require.Equal(t, issuerDID, claims["iss"])
+			require.Equal(t, "did:example:owner", claims["sub"])
+			require.Equal(t, cred.ID, claims["jti"])
+			require.Equal(t, float64(issued.Unix()), claims["nbf"])
+			require.Equal(t, float64(expired.Unix()), claims["exp"])
+			require.Contains(t, claims, "vc")
+
+			verified, err := VerifyCredentialJWT(token, resolver)
+			require.NoError(t, err)
+			require.Equal(t, cred.ID, verified.ID)
+			require.Equal(t, issuerDID, verified.Issuer.ID)
+			require.Equal(t, issued, verified.Issued.UTC())
+			require.Equal(t, "urn:asset:42", verified.Subjects[0].Claims["asset"])
+
+			parsed, err := ParseCredentialJWT(token)
+			require.NoError(t, err)
+			require.Equal(t, verified, parsed)
+
+			_, err = VerifyCredentialJWT(token, resolver, WithVerificationTime(expired))
+			require.ErrorIs(t, err, ErrExpired)
+
+			// the signature covers header and claims.
+			parts := strings.Split(token, ".")
+			other, err := IssueJWT(newCredential(ContextV1), issuerDoc, "#key-1", signer)
+			require.NoError(t, err)
+
+			_, err = VerifyCredentialJWT(parts[0]+"."+strings.Split(other, ".")[1]+"."+parts[2], resolver)
+			require.ErrorIs(t, err, crypto.ErrInvalidSignature)
+		})
+	}
+}
+
+func TestCredentialJWTClaims(t *testing.T) {
+	signer := newSigner(t, crypto.Ed25519)
+	issuerDoc := newDoc(t, issuerDID, signer)
+	resolver := WithDocumentResolver(mockDocumentResolver{issuerDID: issuerDoc})
+
+	// registered claims stand for the credential properties they replace.
+	token, err := signJWT(typJWT, "#key-1", map[string]interface{}{
+		"iss": issuerDID,
+		"sub": "did:example:owner",
+		"jti": "urn:uuid:1",
+		"nbf": 1640995200,
+		"vc": map[string]interface{}{
+			"@context":          []string{ContextV1},
+			"type":              []string{TypeVerifiableCredential},
+			"credentialSubject": map[string]interface{}{"asset": "urn:asset:42"},
+		},
+	}, signer, &issuerDoc.VerificationMethod[0])
+	require.NoError(t, err)
+
+	cred, err := VerifyCredentialJWT(token, resolver)
+	require.NoError(t, err)
+	require.Equal(t, "urn:uuid:1", cred.ID)
+	require.Equal(t, issuerDID, cred.Issuer.ID)
+	require.Equal(t, "did:example:owner", cred.Subjects[0].ID)
+	require.Equal(t, time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), cred.Issued.UTC())
+
+	for name, claims := range map[string]map[string]interface{}{
+		"no vc": {"iss": issuerDID},
+		"issuer mismatch": {"iss": "did:example:other", "vc": map[string]interface{}{
+			"@context": []string{ContextV1}, "issuer": issuerDID,
+		}},
+		"nbf": {"iss": issuerDID, "nbf": "2022", "vc": map[string]interface{}{"@context": []string{ContextV1}}},
+	} {
+		token, err := signJWT(typJWT, "#key-1", claims, signer, &issuerDoc.VerificationMethod[0])
+		require.NoError(t, err)
+
+		_, err = VerifyCredentialJWT(token, resolver)
+		require.ErrorIs(t, err, ErrInvalidJWT, name)
+	}
+}
+
+func TestCredentialJWTKey(t *testing.T) {
+	signer := newSigner(t, crypto.Ed25519)
+	issuerDoc := newDoc(t, issuerDID, signer)
+	resolver := WithDocumentResolver(mockDocumentResolver{issuerDID: issuerDoc})
+
+	_, err := IssueJWT(newCredential(ContextV1), issuerDoc, "#key-2", signer)
+	require.ErrorIs(t, err, did.ErrKeyNotAuthorized)
+
+	_, err = IssueJWT(newCredential(ContextV1), issuerDoc, "#key-1", newSigner(t, crypto.Ed25519))
+	require.ErrorIs(t, err, crypto.ErrInvalidSignature)
+
+	claims := map[string]interface{}{"iss": issuerDID, "vc": newCredentialMap(t)}
+
+	for kid, expected := range map[string]string{
+		"":                             "missing kid header",
+		issuerDID + "#key-2":           "key is not authorized",
+		"did:example:other#key-1":      "is not a key of did:example:issuer",
+		issuerDID + "#key-3":           "key not found",
+		"did:example:issuer#key-1#bad": "is not a key of did:example:issuer",
+	} {
+		token, err := signJWT(typJWT, kid, claims, signer, &issuerDoc.VerificationMethod[0])
+		require.NoError(t, err)
+
+		_, err = VerifyCredentialJWT(token, resolver)
+		require.Error(t, err, kid)
+		require.Contains(t, err.Error(), expected, kid)
+	}
+
+	token, err := IssueJWT(newCredential(ContextV1), issuerDoc, "#key-1", signer)
+	require.NoError(t, err)
+
+	_, err = VerifyCredentialJWT(token)
+	require.EqualError(t, err, "no document resolver to resolve did:example:issuer")
+
+	// the alg header must be the algorithm of the resolved key.
+	p256Doc := newDoc(t, issuerDID, newSigner(t, crypto.ECDSAP256))
+
+	_, err = VerifyCredentialJWT(token, WithDocumentResolver(mockDocumentResolver{issuerDID: p256Doc}))
+	require.ErrorIs(t, err, did.ErrInvalidProof)
+	require.Contains(t, err.Error(), "alg EdDSA does not match key")
+
+	_, err = VerifyCredentialJWT("a.b", resolver)
+	require.ErrorIs(t, err, ErrInvalidJWT)
+}
+
+func newCredentialMap(t *testing.T) map[string]interface{} {
+	t.Helper()
+
+	cred := newCredential(ContextV1)
+	cred.Issuer.ID = issuerDID
+	cred.Issued = &time.Time{}
+
+	raw, err := cred.toMap()
+	require.NoError(t, err)
+
+	return raw
+}
+
+func TestPresentationJWT(t *testing.T) {
+	issuerSigner := newSigner(t, crypto.Ed25519)
+	holderSigner := newSigner(t, crypto.ECDSASecp256k1)
+	holderDoc := newDoc(t, holderDID, holderSigner)
+	resolver := WithDocumentResolver(mockDocumentResolver{
+		issuerDID: newDoc(t, issuerDID, issuerSigner),
+		holderDID: holderDoc,
+	})
+
+	credJWT, err := IssueJWT(newCredential(ContextV1), newDoc(t, issuerDID, issuerSigner), "#key-1", issuerSigner)
+	require.NoError(t, err)
+
+	p, err := NewPresentation(holderDID, []byte(credJWT), issue(t, newCredential(ContextV1), issuerSigner))
+	require.NoError(t, err)
+
+	_, err = SignPresentationJWT(p, holderDoc, "#key-2", holderSigner)
+	require.EqualError(t, err, "presentation proofs require a challenge and a domain")
+
+	token, err := SignPresentationJWT(p, holderDoc, "#key-2", holderSigner, WithChallenge(challenge),
+		WithDomain(domain))
+	require.NoError(t, err)
+
+	header, claims := decodeJWT(t, token)
+	require.Equal(t, "ES256K", header["alg"])
+	require.Equal(t, holderDID+"#key-2", header["kid"])
+	require.Equal(t, holderDID, claims["iss"])
+	require.Equal(t, domain, claims["aud"])
+	require.Equal(t, challenge, claims["nonce"])
+
+	result, err := VerifyPresentationJWT(token, resolver, WithChallenge(challenge), WithDomain(domain))
+	require.NoError(t, err)
+	require.True(t, result.Verified())
+	require.Len(t, result.Credentials, 2)
+
+	for _, r := range result.Credentials {
+		require.True(t, r.SubjectIsHolder)
+	}
+
+	_, err = VerifyPresentationJWT(token, resolver, WithChallenge("other"), WithDomain(domain))
+	require.ErrorIs(t, err, did.ErrInvalidProof)
+
+	_, err = VerifyPresentationJWT(token, resolver, WithChallenge(challenge), WithDomain("other.example.com"))
+	require.ErrorIs(t, err, did.ErrInvalidProof)
+
+	_, err = VerifyPresentationJWT(token, resolver, WithDomain(domain))
+	require.EqualError(t, err, "presentation verification requires a challenge and a domain")
+
+	err = SignPresentation(p, holderDoc, "#key-2", holderSigner, WithChallenge(challenge), WithDomain(domain))
+	require.ErrorIs(t, err, ErrInvalidPresentation)
+}
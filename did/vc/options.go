@@ -0,0 +1,116 @@
+package vc
+
+import (
+	"time"
+
+	"github.com/zRich/zFusion/common/jsonld"
+	"github.com/zRich/zFusion/did"
+)
+
+type options struct {
+	proofType    string
+	created      time.Time
+	loader       jsonld.DocumentLoader
+	resolver     did.DocumentResolver
+	schemaLoader SchemaLoader
+	statusLoader StatusListLoader
+	now          time.Time
+	challenge    string
+	domain       string
+}
+
+// Option configures issuance, presentation and verification.
+type Option func(opts *options)
+
+// WithProofType sets the type of created proofs. It defaults to Ed25519Signature2018 for Ed25519 keys,
+// EcdsaSecp256k1Signature2019 for secp256k1 keys and JsonWebSignature2020 for other keys.
+func WithProofType(proofType string) Option {
+	return func(opts *options) {
+		opts.proofType = proofType
+	}
+}
+
+// WithProofCreated sets the creation time of created proofs. It defaults to the current time.
+func WithProofCreated(created time.Time) Option {
+	return func(opts *options) {
+		opts.created = created
+	}
+}
+
+// WithDocumentLoader sets the loader of the JSON-LD contexts of signed documents. It defaults to a loader
+// serving the embedded contexts of package jsonld.
+func WithDocumentLoader(loader jsonld.DocumentLoader) Option {
+	return func(opts *options) {
+		opts.loader = loader
+	}
+}
+
+// WithDocumentResolver sets the resolver of the DID documents of issuers and holders. It is required for
+// verification.
+func WithDocumentResolver(resolver did.DocumentResolver) Option {
+	return func(opts *options) {
+		opts.resolver = resolver
+	}
+}
+
+// WithSchemaLoader sets the loader of the JSON schemas credentials refer to in their credentialSchema.
+// Verifying a credential with a schema fails without a schema loader. Issuing with a schema loader validates
+// the credential against its schemas before signing it.
+func WithSchemaLoader(loader SchemaLoader) Option {
+	return func(opts *options) {
+		opts.schemaLoader = loader
+	}
+}
+
+// WithStatusListLoader sets the loader of the status list credentials credentials refer to in their
+// credentialStatus. Verifying a credential with a status fails without a status list loader.
+func WithStatusListLoader(loader StatusListLoader) Option {
+	return func(opts *options) {
+		opts.statusLoader = loader
+	}
+}
+
+// WithVerificationTime checks the validity period of credentials at t instead of the current time.
+func WithVerificationTime(t time.Time) Option {
+	return func(opts *options) {
+		opts.now = t
+	}
+}
+
+// WithChallenge sets the challenge of created presentation proofs, or the challenge a verified presentation
+// proof must have. Verifiers issue a fresh challenge for each presentation they request, to prevent replays.
+func WithChallenge(challenge string) Option {
+	return func(opts *options) {
+		opts.challenge = challenge
+	}
+}
+
+// WithDomain sets the domain of created presentation proofs, or the domain a verified presentation proof must
+// have. It identifies the verifier a presentation is intended for.
+func WithDomain(domain string) Option {
+	return func(opts *options) {
+		opts.domain = domain
+	}
+}
+
+func getOptions(opts []Option) *options {
+	o := &options{}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.loader == nil {
+		o.loader = jsonld.NewDocumentLoader()
+	}
+
+	if o.created.IsZero() {
+		o.created = time.Now().UTC().Truncate(time.Second)
+	}
+
+	if o.now.IsZero() {
+		o.now = time.Now()
+	}
+
+	return o
+}
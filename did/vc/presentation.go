@@ -0,0 +1,388 @@
+package vc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/zRich/zFusion/common/crypto"
+	"github.com/zRich/zFusion/did"
+)
+
+// TypeVerifiablePresentation is the type every presentation has.
+const TypeVerifiablePresentation = "VerifiablePresentation"
+
+const fieldHolder = "holder"
+
+// fieldCredential holds the credentials of a presentation.
+const fieldCredential = "verifiableCredential"
+
+// ErrInvalidPresentation is returned when a presentation does not conform to the data model.
+var ErrInvalidPresentation = errors.New("invalid presentation")
+
+// Presentation is a verifiable presentation of credentials by their holder. Credentials are kept as issued, as
+// marshaling a parsed Credential again may invalidate its proofs: JSON objects, or JSON strings holding JWTs.
+type Presentation struct {
+	Context       []string
+	CustomContext []interface{}
+	ID            string
+	Types         []string
+	Holder        string
+	Credentials   []json.RawMessage
+	Proofs        []Proof
+	CustomFields  map[string]interface{}
+}
+
+// NewPresentation creates a presentation of credentials by holder, given as JSON or as JWTs. Its base context
+// is the 2.0 context if an embedded credential uses it, since the 1.1 context cannot embed such credentials.
+func NewPresentation(holder string, credentials ...[]byte) (*Presentation, error) {
+	p := &Presentation{
+		Context: []string{ContextV1},
+		Types:   []string{TypeVerifiablePresentation},
+		Holder:  holder,
+	}
+
+	for _, data := range credentials {
+		if isJWT(data) {
+			token := strings.TrimSpace(string(data))
+			if _, err := ParseCredentialJWT(token); err != nil {
+				return nil, err
+			}
+
+			encoded, err := json.Marshal(token)
+			if err != nil {
+				return nil, err
+			}
+
+			p.Credentials = append(p.Credentials, encoded)
+
+			continue
+		}
+
+		cred, err := ParseCredential(data)
+		if err != nil {
+			return nil, err
+		}
+
+		if cred.IsV2() {
+			p.Context[0] = ContextV2
+		}
+
+		p.Credentials = append(p.Credentials, json.RawMessage(data))
+	}
+
+	return p, nil
+}
+
+// ParsePresentation parses a presentation and checks it conforms to the data model. Neither its proofs nor its
+// credentials are verified, see VerifyPresentation.
+func ParsePresentation(data []byte) (*Presentation, error) {
+	raw, err := decodeObject(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPresentation, err)
+	}
+
+	return presentationFromMap(raw)
+}
+
+func presentationFromMap(raw map[string]interface{}) (*Presentation, error) {
+	p := &Presentation{CustomFields: map[string]interface{}{}}
+
+	var err error
+
+	p.Context, p.CustomContext, err = parseContext(raw[fieldContext])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPresentation, err)
+	}
+
+	if p.ID, err = optionalString(raw, fieldID); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPresentation, err)
+	}
+
+	p.Types, err = stringOrArray(raw[fieldType])
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid type: %v", ErrInvalidPresentation, err)
+	}
+
+	switch holder := raw[fieldHolder].(type) {
+	case string:
+		p.Holder = holder
+	case map[string]interface{}:
+		p.Holder, _ = holder[fieldID].(string) //nolint:errcheck
+	}
+
+	for _, c := range objectOrArray(raw[fieldCredential]) {
+		data, err := marshal(c)
+		if err != nil {
+			return nil, err
+		}
+
+		p.Credentials = append(p.Credentials, data)
+	}
+
+	if p.Proofs, err = parseProofs(raw); err != nil {
+		return nil, err
+	}
+
+	for k, v := range raw {
+		switch k {
+		case fieldContext, fieldID, fieldType, fieldHolder, fieldCredential, fieldProof:
+		default:
+			p.CustomFields[k] = v
+		}
+	}
+
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func (p *Presentation) validate() error {
+	if len(p.Context) == 0 || (p.Context[0] != ContextV1 && p.Context[0] != ContextV2) {
+		return fmt.Errorf("%w: first context must be %s or %s", ErrInvalidPresentation, ContextV1, ContextV2)
+	}
+
+	if !contains(p.Types, TypeVerifiablePresentation) {
+		return fmt.Errorf("%w: type must include %s", ErrInvalidPresentation, TypeVerifiablePresentation)
+	}
+
+	return nil
+}
+
+// MarshalJSON marshals the presentation.
+func (p *Presentation) MarshalJSON() ([]byte, error) {
+	raw, err := p.toMap()
+	if err != nil {
+		return nil, err
+	}
+
+	return marshal(raw)
+}
+
+func (p *Presentation) toMap() (map[string]interface{}, error) {
+	raw := make(map[string]interface{}, len(p.CustomFields))
+
+	for k, v := range p.CustomFields {
+		raw[k] = v
+	}
+
+	raw[fieldContext] = contextValue(p.Context, p.CustomContext)
+	raw[fieldType] = p.Types
+
+	if p.ID != "" {
+		raw[fieldID] = p.ID
+	}
+
+	if p.Holder != "" {
+		raw[fieldHolder] = p.Holder
+	}
+
+	if len(p.Credentials) > 0 {
+		credentials := make([]interface{}, len(p.Credentials))
+
+		for i, data := range p.Credentials {
+			c, err := decodeValue(data)
+			if err != nil {
+				return nil, fmt.Errorf("%w: credential %d: %v", ErrInvalidPresentation, i, err)
+			}
+
+			credentials[i] = c
+		}
+
+		raw[fieldCredential] = singleOrArray(credentials)
+	}
+
+	if len(p.Proofs) > 0 {
+		proofs, err := proofsValue(p.Proofs)
+		if err != nil {
+			return nil, err
+		}
+
+		raw[fieldProof] = proofs
+	}
+
+	return raw, nil
+}
+
+// SignPresentation signs the presentation with signer and appends the proof, bound to the challenge and domain
+// set by WithChallenge and WithDomain, which are required. keyID is the ID of the authentication method of the
+// holder DID document holding the public key of signer, relative IDs are resolved against the document ID.
+// The presentation holder defaults to the document ID. Presentations of JWT credentials are signed with
+// SignPresentationJWT, as linked data proofs cannot cover them.
+func SignPresentation(p *Presentation, holderDoc *did.Document, keyID string, signer crypto.Signer,
+	opts ...Option) error {
+	o := getOptions(opts)
+
+	if o.challenge == "" || o.domain == "" {
+		return errors.New("presentation proofs require a challenge and a domain")
+	}
+
+	if err := p.prepareSigning(holderDoc); err != nil {
+		return err
+	}
+
+	for _, cred := range p.Credentials {
+		if isJWT(cred) {
+			return fmt.Errorf("%w: linked data proofs cannot cover JWT credentials", ErrInvalidPresentation)
+		}
+	}
+
+	vm, err := holderDoc.AuthorizedVerificationMethod(keyID, did.Authentication)
+	if err != nil {
+		return fmt.Errorf("holder key %s: %w", keyID, err)
+	}
+
+	proof := Proof{
+		Type:               o.proofType,
+		Created:            &o.created,
+		VerificationMethod: did.AbsoluteID(holderDoc.ID, keyID),
+		ProofPurpose:       did.PurposeAuthentication,
+		Challenge:          o.challenge,
+		Domain:             o.domain,
+	}
+
+	if err := signDocument(&p.Context, p.Proofs, p.toMap, signer, vm, &proof, o.loader); err != nil {
+		return err
+	}
+
+	p.Proofs = append(p.Proofs, proof)
+
+	return nil
+}
+
+// prepareSigning sets the default holder of the presentation, and validates it.
+func (p *Presentation) prepareSigning(holderDoc *did.Document) error {
+	if p.Holder == "" {
+		p.Holder = holderDoc.ID
+	}
+
+	if p.Holder != holderDoc.ID {
+		return fmt.Errorf("%w: holder %s is not the subject of the DID document %s", ErrInvalidPresentation,
+			p.Holder, holderDoc.ID)
+	}
+
+	return p.validate()
+}
+
+// PresentationResult is the result of the verification of a presentation.
+type PresentationResult struct {
+	Presentation *Presentation
+	// Credentials holds the verification results of the presentation credentials, in order.
+	Credentials []CredentialResult
+}
+
+// CredentialResult is the verification result of a presented credential.
+type CredentialResult struct {
+	// Credential is the parsed credential, nil if it could not be parsed.
+	Credential *Credential
+	// SubjectIsHolder is set if a subject of the credential is the presentation holder.
+	SubjectIsHolder bool
+	// Err is the reason the credential failed verification, nil if it passed.
+	Err error
+}
+
+// Verified reports whether every presented credential passed verification.
+func (r *PresentationResult) Verified() bool {
+	for _, c := range r.Credentials {
+		if c.Err != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// VerifyPresentation parses a presentation and verifies its proofs: each must be created by an authentication
+// method of the holder DID document, with the challenge and domain set by WithChallenge and WithDomain, which
+// are required. An error is returned if the presentation fails verification. Otherwise its credentials are
+// verified as by VerifyCredential, and the result of each is reported in the returned PresentationResult.
+func VerifyPresentation(data []byte, opts ...Option) (*PresentationResult, error) {
+	o := getOptions(opts)
+
+	if o.challenge == "" || o.domain == "" {
+		return nil, errors.New("presentation verification requires a challenge and a domain")
+	}
+
+	raw, err := decodeObject(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPresentation, err)
+	}
+
+	p, err := presentationFromMap(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.verifyProofs(raw, o); err != nil {
+		return nil, err
+	}
+
+	return &PresentationResult{Presentation: p, Credentials: verifyPresentedCredentials(raw, p.Holder, o)}, nil
+}
+
+// verifyPresentedCredentials verifies the embedded and JWT credentials of a decoded presentation.
+func verifyPresentedCredentials(raw map[string]interface{}, holder string, o *options) []CredentialResult {
+	var results []CredentialResult
+
+	for _, c := range objectOrArray(raw[fieldCredential]) {
+		var r CredentialResult
+
+		switch credential := c.(type) {
+		case map[string]interface{}:
+			if r.Credential, r.Err = credentialFromMap(credential); r.Err == nil {
+				r.Err = r.Credential.verify(credential, o)
+			}
+		case string:
+			r.Credential, _, r.Err = verifyCredentialJWT(credential, o)
+		default:
+			r.Err = fmt.Errorf("%w: unsupported credential format", ErrInvalidCredential)
+		}
+
+		r.SubjectIsHolder = r.Credential != nil && isSubject(r.Credential, holder)
+
+		results = append(results, r)
+	}
+
+	return results
+}
+
+func (p *Presentation) verifyProofs(raw map[string]interface{}, o *options) error {
+	proofs, err := rawProofs(raw)
+	if err != nil {
+		return err
+	}
+
+	if len(proofs) == 0 {
+		return did.ErrProofNotFound
+	}
+
+	if p.Holder == "" {
+		return fmt.Errorf("%w: missing holder", ErrInvalidPresentation)
+	}
+
+	if o.resolver == nil {
+		return errors.New("no document resolver to resolve the presentation holder")
+	}
+
+	holderDoc, err := o.resolver.ResolveDocument(p.Holder)
+	if err != nil {
+		return fmt.Errorf("resolve holder %s: %w", p.Holder, err)
+	}
+
+	if holderDoc.ID != p.Holder {
+		return fmt.Errorf("resolve holder %s: got DID document %s", p.Holder, holderDoc.ID)
+	}
+
+	for i, rawProof := range proofs {
+		expected := &Proof{ProofPurpose: did.PurposeAuthentication, Challenge: o.challenge, Domain: o.domain}
+
+		if err := verifyControllerProof(raw, rawProof, holderDoc, expected, o.loader); err != nil {
+			return fmt.Errorf("verify proof %s: %w", p.Proofs[i].VerificationMethod, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,164 @@
+package vc
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zRich/zFusion/common/crypto"
+	"github.com/zRich/zFusion/did"
+)
+
+const (
+	holderDID = "did:example:owner"
+	challenge = "99612b24-63d9-11ea-b99f-4f66f3e4f81a"
+	domain    = "transfer.example.com"
+)
+
+func TestPresentation(t *testing.T) {
+	issuerSigner := newSigner(t, crypto.Ed25519)
+	holderSigner := newSigner(t, crypto.ECDSAP256)
+	holderDoc := newDoc(t, holderDID, holderSigner)
+	resolver := WithDocumentResolver(mockDocumentResolver{
+		issuerDID: newDoc(t, issuerDID, issuerSigner),
+		holderDID: holderDoc,
+	})
+
+	owned := issue(t, newCredential(ContextV1), issuerSigner)
+
+	other := newCredential(ContextV1)
+	other.Subjects[0].ID = "did:example:other"
+	notOwned := issue(t, other, issuerSigner)
+
+	expired := newCredential(ContextV1)
+	expiration := time.Now().Add(-time.Hour)
+	expired.Expired = &expiration
+	expiredData := issue(t, expired, issuerSigner)
+
+	p, err := NewPresentation("", owned, notOwned, expiredData)
+	require.NoError(t, err)
+	require.Equal(t, []string{ContextV1}, p.Context)
+
+	err = SignPresentation(p, holderDoc, "#key-2", holderSigner, WithChallenge(challenge))
+	require.EqualError(t, err, "presentation proofs require a challenge and a domain")
+
+	err = SignPresentation(p, holderDoc, "#key-1", holderSigner, WithChallenge(challenge), WithDomain(domain))
+	require.ErrorIs(t, err, did.ErrKeyNotAuthorized)
+
+	require.NoError(t, SignPresentation(p, holderDoc, "#key-2", holderSigner, WithChallenge(challenge),
+		WithDomain(domain)))
+	require.Equal(t, holderDID, p.Holder)
+	require.Equal(t, []string{ContextV1, "https://w3id.org/security/suites/jws-2020/v1"}, p.Context)
+
+	data, err := json.Marshal(p)
+	require.NoError(t, err)
+
+	result, err := VerifyPresentation(data, resolver, WithChallenge(challenge), WithDomain(domain))
+	require.NoError(t, err)
+	require.False(t, result.Verified())
+	require.Equal(t, holderDID, result.Presentation.Holder)
+	require.Len(t, result.Credentials, 3)
+
+	require.NoError(t, result.Credentials[0].Err)
+	require.True(t, result.Credentials[0].SubjectIsHolder)
+
+	require.NoError(t, result.Credentials[1].Err)
+	require.False(t, result.Credentials[1].SubjectIsHolder)
+
+	require.ErrorIs(t, result.Credentials[2].Err, ErrExpired)
+	require.NotNil(t, result.Credentials[2].Credential)
+
+	parsed, err := ParsePresentation(data)
+	require.NoError(t, err)
+	require.Len(t, parsed.Credentials, 3)
+	require.Len(t, parsed.Proofs, 1)
+	require.Equal(t, challenge, parsed.Proofs[0].Challenge)
+	require.Equal(t, domain, parsed.Proofs[0].Domain)
+
+	t.Run("replay", func(t *testing.T) {
+		_, err := VerifyPresentation(data, resolver, WithChallenge("other"), WithDomain(domain))
+		require.ErrorIs(t, err, did.ErrInvalidProof)
+		require.Contains(t, err.Error(), "proof challenge")
+
+		_, err = VerifyPresentation(data, resolver, WithChallenge(challenge), WithDomain("other.example.com"))
+		require.ErrorIs(t, err, did.ErrInvalidProof)
+		require.Contains(t, err.Error(), "proof domain")
+
+		_, err = VerifyPresentation(data, resolver, WithChallenge(challenge))
+		require.EqualError(t, err, "presentation verification requires a challenge and a domain")
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		tampered := *parsed
+		tampered.Credentials = tampered.Credentials[:1]
+
+		data, err := json.Marshal(&tampered)
+		require.NoError(t, err)
+
+		_, err = VerifyPresentation(data, resolver, WithChallenge(challenge), WithDomain(domain))
+		require.ErrorIs(t, err, crypto.ErrInvalidSignature)
+	})
+
+	t.Run("holder", func(t *testing.T) {
+		_, err := VerifyPresentation(data, WithChallenge(challenge), WithDomain(domain))
+		require.EqualError(t, err, "no document resolver to resolve the presentation holder")
+
+		// an issuer key is not authorized to authenticate the holder.
+		_, err = VerifyPresentation(data, WithDocumentResolver(mockDocumentResolver{
+			holderDID: newDoc(t, holderDID, newSigner(t, crypto.ECDSAP256)),
+		}), WithChallenge(challenge), WithDomain(domain))
+		require.ErrorIs(t, err, crypto.ErrInvalidSignature)
+
+		p, err := NewPresentation("did:example:other", owned)
+		require.NoError(t, err)
+
+		err = SignPresentation(p, holderDoc, "#key-2", holderSigner, WithChallenge(challenge), WithDomain(domain))
+		require.ErrorIs(t, err, ErrInvalidPresentation)
+
+		p.Holder = ""
+		p.Proofs = nil
+
+		data, err := json.Marshal(p)
+		require.NoError(t, err)
+
+		_, err = VerifyPresentation(data, resolver, WithChallenge(challenge), WithDomain(domain))
+		require.ErrorIs(t, err, did.ErrProofNotFound)
+	})
+}
+
+func TestPresentationV2(t *testing.T) {
+	signer := newSigner(t, crypto.Ed25519)
+	holderDoc := newDoc(t, holderDID, signer)
+	resolver := WithDocumentResolver(mockDocumentResolver{issuerDID: newDoc(t, issuerDID, signer), holderDID: holderDoc})
+
+	p, err := NewPresentation(holderDID, issue(t, newCredential(ContextV1), signer),
+		issue(t, newCredential(ContextV2), signer, WithProofType(did.Ed25519Signature2020)))
+	require.NoError(t, err)
+	require.Equal(t, []string{ContextV2}, p.Context)
+
+	require.NoError(t, SignPresentation(p, holderDoc, "#key-2", signer, WithChallenge(challenge),
+		WithDomain(domain), WithProofType(did.Ed25519Signature2020)))
+
+	data, err := json.Marshal(p)
+	require.NoError(t, err)
+
+	result, err := VerifyPresentation(data, resolver, WithChallenge(challenge), WithDomain(domain))
+	require.NoError(t, err)
+	require.True(t, result.Verified())
+}
+
+func TestParsePresentationErrors(t *testing.T) {
+	for name, data := range map[string]string{
+		"not JSON":   `{`,
+		"no context": `{"type": "VerifiablePresentation"}`,
+		"type":       `{"@context": "https://www.w3.org/2018/credentials/v1", "type": "Presentation"}`,
+		"proof":      `{"@context": "https://www.w3.org/2018/credentials/v1", "type": "VerifiablePresentation", "proof": 1}`,
+	} {
+		_, err := ParsePresentation([]byte(data))
+		require.Error(t, err, name)
+	}
+
+	_, err := NewPresentation(holderDID, []byte(`{}`))
+	require.ErrorIs(t, err, ErrInvalidCredential)
+}
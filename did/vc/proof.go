@@ -0,0 +1,329 @@
+package vc
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/multiformats/go-multibase"
+	"github.com/zRich/zFusion/common/crypto"
+	"github.com/zRich/zFusion/common/jsonld"
+	"github.com/zRich/zFusion/did"
+)
+
+// Proof is a linked data proof. Proofs are detached JWS, except Ed25519Signature2020 proofs which carry a
+// multibase encoded proof value.
+type Proof struct {
+	Type               string     `json:"type"`
+	Created            *time.Time `json:"created,omitempty"`
+	VerificationMethod string     `json:"verificationMethod"`
+	ProofPurpose       string     `json:"proofPurpose"`
+	Challenge          string     `json:"challenge,omitempty"`
+	Domain             string     `json:"domain,omitempty"`
+	JWS                string     `json:"jws,omitempty"`
+	ProofValue         string     `json:"proofValue,omitempty"`
+}
+
+// proof fields holding the signature, which are not part of the signed proof options.
+const (
+	fieldJWS        = "jws"
+	fieldProofValue = "proofValue"
+)
+
+// suite describes a supported proof type.
+type suite struct {
+	// context defines the proof terms, if the base context does not.
+	context string
+	// definedByV1 is set if the credentials v1 context defines the proof terms.
+	definedByV1 bool
+}
+
+var suites = map[string]suite{ //nolint:gochecknoglobals
+	did.Ed25519Signature2018:        {context: jsonld.ContextEd25519Signature2018, definedByV1: true},
+	did.Ed25519Signature2020:        {context: jsonld.ContextEd25519Signature2020},
+	did.EcdsaSecp256k1Signature2019: {context: jsonld.ContextSecp256k1Signature, definedByV1: true},
+	did.JSONWebSignature2020:        {context: jsonld.ContextJSONWebSignature2020},
+}
+
+// proofContext returns the context to add to contexts for the terms of proofType, or an empty string if
+// contexts already define them.
+func proofContext(contexts []string, proofType string) (string, error) {
+	s, ok := suites[proofType]
+	if !ok {
+		return "", fmt.Errorf("unsupported proof type: %s", proofType)
+	}
+
+	if contains(contexts, s.context) || (s.definedByV1 && len(contexts) > 0 && contexts[0] == ContextV1) {
+		return "", nil
+	}
+
+	return s.context, nil
+}
+
+// signDocument signs the credential or presentation returned by toMap, after adding the context of the proof
+// type to contexts if they do not define its terms. contexts are left unchanged on failure.
+func signDocument(contexts *[]string, proofs []Proof, toMap func() (map[string]interface{}, error),
+	signer crypto.Signer, vm *did.VerificationMethod, proof *Proof, loader jsonld.DocumentLoader) error {
+	if proof.Type == "" {
+		proof.Type = did.DefaultProofType(signer.KeyType())
+	}
+
+	context, err := proofContext(*contexts, proof.Type)
+	if err != nil {
+		return err
+	}
+
+	original := *contexts
+
+	if context != "" {
+		if len(proofs) > 0 {
+			return fmt.Errorf("%s proofs require context %s", proof.Type, context)
+		}
+
+		*contexts = append(append([]string(nil), original...), context)
+	}
+
+	doc, err := toMap()
+	if err == nil {
+		err = createProof(doc, signer, vm, proof, loader)
+	}
+
+	if err != nil {
+		*contexts = original
+
+		return err
+	}
+
+	return nil
+}
+
+// createProof signs doc, a credential or presentation without proofs, with signer whose public key is the one
+// of vm.
+func createProof(doc map[string]interface{}, signer crypto.Signer, vm *did.VerificationMethod, proof *Proof,
+	loader jsonld.DocumentLoader) error {
+	if err := did.CheckProofKeyType(proof.Type, signer.KeyType()); err != nil {
+		return err
+	}
+
+	proofOptions, err := proofToMap(proof)
+	if err != nil {
+		return err
+	}
+
+	tbs, err := proofSigningInput(doc, proofOptions, loader)
+	if err != nil {
+		return err
+	}
+
+	if proof.Type == did.Ed25519Signature2020 {
+		sig, err := signer.Sign(tbs)
+		if err != nil {
+			return fmt.Errorf("sign proof: %w", err)
+		}
+
+		proof.ProofValue, err = multibase.Encode(multibase.Base58BTC, sig)
+		if err != nil {
+			return err
+		}
+	} else {
+		proof.JWS, err = did.SignDetachedJWS(signer, tbs)
+		if err != nil {
+			return fmt.Errorf("sign proof: %w", err)
+		}
+	}
+
+	// the signer is not trusted to hold the key of the verification method.
+	if err := verifySignature(proof, tbs, vm); err != nil {
+		return fmt.Errorf("signer does not hold the key of %s: %w", proof.VerificationMethod, err)
+	}
+
+	return nil
+}
+
+// verifyControllerProof verifies rawProof, a proof of doc which must have the purpose, challenge and domain of
+// expected, and be created by a key of the matching verification relationship of controllerDoc.
+func verifyControllerProof(doc, rawProof map[string]interface{}, controllerDoc *did.Document, expected *Proof,
+	loader jsonld.DocumentLoader) error {
+	proof, err := parseProof(rawProof)
+	if err != nil {
+		return err
+	}
+
+	if proof.ProofPurpose != expected.ProofPurpose {
+		return fmt.Errorf("%w: proof purpose %q, expected %q", did.ErrInvalidProof, proof.ProofPurpose,
+			expected.ProofPurpose)
+	}
+
+	if proof.Challenge != expected.Challenge {
+		return fmt.Errorf("%w: proof challenge %q, expected %q", did.ErrInvalidProof, proof.Challenge,
+			expected.Challenge)
+	}
+
+	if proof.Domain != expected.Domain {
+		return fmt.Errorf("%w: proof domain %q, expected %q", did.ErrInvalidProof, proof.Domain, expected.Domain)
+	}
+
+	relationship := did.AssertionMethod
+	if proof.ProofPurpose == did.PurposeAuthentication {
+		relationship = did.Authentication
+	}
+
+	vm, err := controllerDoc.AuthorizedVerificationMethod(proof.VerificationMethod, relationship)
+	if err != nil {
+		return fmt.Errorf("%w: %v", did.ErrInvalidProof, err)
+	}
+
+	return verifyProof(doc, rawProof, vm, loader)
+}
+
+// verifyProof verifies rawProof, a proof of doc, with the public key of vm.
+func verifyProof(doc, rawProof map[string]interface{}, vm *did.VerificationMethod,
+	loader jsonld.DocumentLoader) error {
+	proof, err := parseProof(rawProof)
+	if err != nil {
+		return err
+	}
+
+	keyType, err := vm.KeyType()
+	if err != nil {
+		return err
+	}
+
+	if err := did.CheckProofKeyType(proof.Type, keyType); err != nil {
+		return fmt.Errorf("%w: %v", did.ErrInvalidProof, err)
+	}
+
+	tbs, err := proofSigningInput(doc, rawProof, loader)
+	if err != nil {
+		return err
+	}
+
+	return verifySignature(proof, tbs, vm)
+}
+
+func verifySignature(proof *Proof, tbs []byte, vm *did.VerificationMethod) error {
+	if proof.Type == did.Ed25519Signature2020 {
+		_, sig, err := multibase.Decode(proof.ProofValue)
+		if err != nil {
+			return fmt.Errorf("%w: decode proof value: %v", did.ErrInvalidProof, err)
+		}
+
+		return vm.Verify(tbs, sig)
+	}
+
+	return did.VerifyDetachedJWS(proof.JWS, tbs, vm)
+}
+
+// proofSigningInput returns the signed digest of a proof: the SHA-256 hash of the canonical proof options,
+// the proof without its signature and with the document context, followed by the SHA-256 hash of the
+// canonical document without proofs.
+func proofSigningInput(doc, proof map[string]interface{}, loader jsonld.DocumentLoader) ([]byte, error) {
+	proofOptions := without(without(proof, fieldJWS), fieldProofValue)
+	proofOptions[fieldContext] = doc[fieldContext]
+
+	canonicalOptions, err := canonicalize(proofOptions, loader)
+	if err != nil {
+		return nil, fmt.Errorf("canonicalize proof options: %w", err)
+	}
+
+	canonicalDoc, err := canonicalize(without(doc, fieldProof), loader)
+	if err != nil {
+		return nil, fmt.Errorf("canonicalize document: %w", err)
+	}
+
+	optionsHash := sha256.Sum256(canonicalOptions)
+	docHash := sha256.Sum256(canonicalDoc)
+
+	return append(optionsHash[:], docHash[:]...), nil
+}
+
+func canonicalize(doc map[string]interface{}, loader jsonld.DocumentLoader) ([]byte, error) {
+	// the map may hold values such as times and typed IDs, which the JSON-LD processor does not know.
+	data, err := marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return jsonld.Canonicalize(data, jsonld.WithDocumentLoader(loader), jsonld.WithSafeMode())
+}
+
+func parseProof(raw map[string]interface{}) (*Proof, error) {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var proof Proof
+
+	if err := json.Unmarshal(data, &proof); err != nil {
+		return nil, fmt.Errorf("%w: %v", did.ErrInvalidProof, err)
+	}
+
+	if proof.Type == "" || proof.VerificationMethod == "" || proof.ProofPurpose == "" {
+		return nil, fmt.Errorf("%w: proof requires type, verificationMethod and proofPurpose", did.ErrInvalidProof)
+	}
+
+	return &proof, nil
+}
+
+// parseProofs parses the proofs of a decoded credential or presentation.
+func parseProofs(doc map[string]interface{}) ([]Proof, error) {
+	raws, err := rawProofs(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	proofs := make([]Proof, 0, len(raws))
+
+	for _, raw := range raws {
+		proof, err := parseProof(raw)
+		if err != nil {
+			return nil, err
+		}
+
+		proofs = append(proofs, *proof)
+	}
+
+	return proofs, nil
+}
+
+func proofToMap(proof *Proof) (map[string]interface{}, error) {
+	data, err := json.Marshal(proof)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeObject(data)
+}
+
+func proofsValue(proofs []Proof) (interface{}, error) {
+	values := make([]interface{}, len(proofs))
+
+	for i := range proofs {
+		p, err := proofToMap(&proofs[i])
+		if err != nil {
+			return nil, err
+		}
+
+		values[i] = p
+	}
+
+	return singleOrArray(values), nil
+}
+
+// rawProofs returns the proofs of a decoded credential or presentation.
+func rawProofs(doc map[string]interface{}) ([]map[string]interface{}, error) {
+	var proofs []map[string]interface{}
+
+	for _, p := range objectOrArray(doc[fieldProof]) {
+		raw, ok := p.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%w: proof is not an object", did.ErrInvalidProof)
+		}
+
+		proofs = append(proofs, raw)
+	}
+
+	return proofs, nil
+}
@@ -0,0 +1,244 @@
+package vc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// Credential schema types.
+const (
+	JSONSchemaValidator2018 = "JsonSchemaValidator2018"
+	JSONSchema              = "JsonSchema"
+)
+
+// JSON Schema dialects, the $schema of JSON schemas. Schemas without $schema are validated as draft-07 schemas.
+const (
+	JSONSchemaDraft07   = "http://json-schema.org/draft-07/schema#"
+	JSONSchemaDraft2020 = "https://json-schema.org/draft/2020-12/schema"
+)
+
+var (
+	// ErrSchemaValidation is returned when a credential is not valid against one of its credential schemas.
+	ErrSchemaValidation = errors.New("credential schema validation failed")
+	// ErrInvalidSchema is returned when a credential schema is malformed or uses unsupported JSON Schema features.
+	ErrInvalidSchema = errors.New("invalid credential schema")
+)
+
+// SchemaLoader loads the JSON schemas referenced by credentials.
+type SchemaLoader interface {
+	LoadSchema(id string) ([]byte, error)
+}
+
+// validateSchemas validates the credential without its proofs against each of its JSON schemas.
+func validateSchemas(raw map[string]interface{}, schemas []TypedID, loader SchemaLoader) error {
+	if len(schemas) == 0 {
+		return nil
+	}
+
+	if loader == nil {
+		return errors.New("no schema loader to load the credential schemas")
+	}
+
+	doc := gojsonschema.NewGoLoader(without(raw, fieldProof))
+
+	for _, s := range schemas {
+		if s.Type != JSONSchemaValidator2018 && s.Type != JSONSchema {
+			return fmt.Errorf("unsupported credential schema type: %s", s.Type)
+		}
+
+		content, err := loader.LoadSchema(s.ID)
+		if err != nil {
+			return fmt.Errorf("load credential schema %s: %w", s.ID, err)
+		}
+
+		schema, err := compileSchema(content)
+		if err != nil {
+			return fmt.Errorf("parse credential schema %s: %w", s.ID, err)
+		}
+
+		result, err := schema.Validate(doc)
+		if err != nil {
+			return fmt.Errorf("validate credential schema %s: %w", s.ID, err)
+		}
+
+		if !result.Valid() {
+			errs := make([]string, len(result.Errors()))
+			for i, desc := range result.Errors() {
+				errs[i] = desc.String()
+			}
+
+			return fmt.Errorf("%w: %s: %s", ErrSchemaValidation, s.ID, strings.Join(errs, "; "))
+		}
+	}
+
+	return nil
+}
+
+// compileSchema compiles a JSON schema of draft-04, draft-06, draft-07 or 2020-12. 2020-12 schemas are
+// translated to draft-07, which fails for the keywords without draft-07 equivalent: dynamic and recursive
+// references, anchors, unevaluated items and properties, and contains bounds.
+func compileSchema(content []byte) (*gojsonschema.Schema, error) {
+	var schema interface{}
+
+	if err := json.Unmarshal(content, &schema); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+	}
+
+	if root, ok := schema.(map[string]interface{}); ok {
+		switch dialect, _ := root["$schema"].(string); strings.TrimSuffix(dialect, "#") { //nolint:errcheck
+		case JSONSchemaDraft2020:
+			translated, err := translateDraft2020(root)
+			if err != nil {
+				return nil, err
+			}
+
+			translated["$schema"] = JSONSchemaDraft07
+			schema = translated
+		case "", "http://json-schema.org/draft-04/schema", "http://json-schema.org/draft-06/schema",
+			strings.TrimSuffix(JSONSchemaDraft07, "#"):
+		default:
+			return nil, fmt.Errorf("%w: unsupported JSON Schema dialect %s", ErrInvalidSchema, dialect)
+		}
+	}
+
+	compiled, err := gojsonschema.NewSchema(gojsonschema.NewGoLoader(schema))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+	}
+
+	return compiled, nil
+}
+
+// Keywords of 2020-12 schemas by the kind of their values.
+//
+//nolint:gochecknoglobals
+var (
+	draft2020Unsupported = []string{
+		"$dynamicRef", "$dynamicAnchor", "$recursiveRef", "$recursiveAnchor", "$anchor", "unevaluatedItems",
+		"unevaluatedProperties", "minContains", "maxContains",
+	}
+	draft2020Subschema = []string{
+		"items", "additionalProperties", "contains", "propertyNames", "not", "if", "then", "else",
+	}
+	draft2020SubschemaMaps = []string{"properties", "patternProperties", "$defs", "definitions", "dependentSchemas"}
+	draft2020SubschemaList = []string{"allOf", "anyOf", "oneOf", "prefixItems"}
+)
+
+// translateDraft2020 translates a 2020-12 schema object to draft-07.
+func translateDraft2020(schema map[string]interface{}) (map[string]interface{}, error) {
+	for _, keyword := range draft2020Unsupported {
+		if _, ok := schema[keyword]; ok {
+			return nil, fmt.Errorf("%w: unsupported JSON Schema 2020-12 keyword %s", ErrInvalidSchema, keyword)
+		}
+	}
+
+	translated := make(map[string]interface{}, len(schema))
+
+	for keyword, value := range schema {
+		var err error
+
+		switch {
+		case contains(draft2020Subschema, keyword):
+			translated[keyword], err = translateDraft2020Subschema(value)
+		case contains(draft2020SubschemaMaps, keyword):
+			translated[keyword], err = translateDraft2020Map(keyword, value)
+		case contains(draft2020SubschemaList, keyword):
+			translated[keyword], err = translateDraft2020List(keyword, value)
+		default:
+			translated[keyword] = value
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// prefixItems and items are the items and additionalItems of draft-07.
+	if prefixItems, ok := translated["prefixItems"]; ok {
+		if items, ok := translated["items"]; ok {
+			translated["additionalItems"] = items
+		}
+
+		translated["items"] = prefixItems
+		delete(translated, "prefixItems")
+	}
+
+	// dependentRequired and dependentSchemas are merged in the dependencies of draft-07.
+	for _, keyword := range []string{"dependentRequired", "dependentSchemas"} {
+		dependencies, ok := translated[keyword].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		merged, _ := translated["dependencies"].(map[string]interface{}) //nolint:errcheck
+		if merged == nil {
+			merged = make(map[string]interface{})
+		}
+
+		for property, dependency := range dependencies {
+			merged[property] = dependency
+		}
+
+		translated["dependencies"] = merged
+		delete(translated, keyword)
+	}
+
+	// the siblings of $ref are ignored by draft-07, a $ref among other keywords becomes a member of allOf.
+	if ref, ok := translated["$ref"]; ok && len(translated) > 1 {
+		allOf, _ := translated["allOf"].([]interface{}) //nolint:errcheck
+		translated["allOf"] = append(allOf, map[string]interface{}{"$ref": ref})
+		delete(translated, "$ref")
+	}
+
+	return translated, nil
+}
+
+func translateDraft2020Subschema(value interface{}) (interface{}, error) {
+	if schema, ok := value.(map[string]interface{}); ok {
+		return translateDraft2020(schema)
+	}
+
+	return value, nil
+}
+
+func translateDraft2020Map(keyword string, value interface{}) (interface{}, error) {
+	schemas, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: %s must be an object", ErrInvalidSchema, keyword)
+	}
+
+	translated := make(map[string]interface{}, len(schemas))
+
+	for name, schema := range schemas {
+		var err error
+
+		if translated[name], err = translateDraft2020Subschema(schema); err != nil {
+			return nil, err
+		}
+	}
+
+	return translated, nil
+}
+
+func translateDraft2020List(keyword string, value interface{}) (interface{}, error) {
+	schemas, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: %s must be an array", ErrInvalidSchema, keyword)
+	}
+
+	translated := make([]interface{}, len(schemas))
+
+	for i, schema := range schemas {
+		var err error
+
+		if translated[i], err = translateDraft2020Subschema(schema); err != nil {
+			return nil, err
+		}
+	}
+
+	return translated, nil
+}
@@ -0,0 +1,60 @@
+package vc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+func TestCompileSchemaDraft2020(t *testing.T) {
+	schema, err := compileSchema([]byte(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"properties": {
+			"point": {"prefixItems": [{"type": "number"}, {"type": "number"}], "items": false},
+			"tags": {"type": "array", "items": {"$ref": "#/$defs/tag"}},
+			"owner": {"$ref": "#/$defs/did", "pattern": "^did:example:"}
+		},
+		"dependentRequired": {"licensee": ["license"]},
+		"dependentSchemas": {"license": {"required": ["expires"]}},
+		"$defs": {
+			"tag": {"type": "string", "maxLength": 4},
+			"did": {"type": "string", "pattern": "^did:"}
+		}
+	}`))
+	require.NoError(t, err)
+
+	tests := []struct {
+		doc   string
+		valid bool
+	}{
+		{`{"point": [1, 2], "tags": ["a"], "owner": "did:example:alice"}`, true},
+		{`{"point": [1, 2, 3]}`, false},
+		{`{"point": [1, "2"]}`, false},
+		{`{"tags": ["toolong"]}`, false},
+		{`{"owner": "did:other:alice"}`, false},
+		{`{"owner": "urn:example:alice"}`, false},
+		{`{"licensee": "did:example:bob"}`, false},
+		{`{"licensee": "did:example:bob", "license": "x"}`, false},
+		{`{"licensee": "did:example:bob", "license": "x", "expires": "2030-01-01"}`, true},
+	}
+
+	for _, tc := range tests {
+		result, err := schema.Validate(gojsonschema.NewStringLoader(tc.doc))
+		require.NoError(t, err)
+		require.Equal(t, tc.valid, result.Valid(), tc.doc)
+	}
+
+	for _, content := range []string{
+		`{"$schema": "https://json-schema.org/draft/2020-12/schema", "unevaluatedProperties": false}`,
+		`{"$schema": "https://json-schema.org/draft/2020-12/schema", "items": {"$dynamicRef": "#item"}}`,
+		`{"$schema": "https://json-schema.org/draft/2020-12/schema", "allOf": {}}`,
+		`{"$schema": "https://json-schema.org/draft/2019-09/schema"}`,
+		`{"type": 1}`,
+		`not JSON`,
+	} {
+		_, err := compileSchema([]byte(content))
+		require.ErrorIs(t, err, ErrInvalidSchema, content)
+	}
+}
@@ -0,0 +1,370 @@
+package vc
+
+import (
+	"bytes"
+	"embed"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zRich/zFusion/did/didpb"
+	"github.com/zRich/zFusion/storage/spi"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Asset types, each with a built-in claim schema returned by AssetSchema.
+const (
+	// AssetTypePhysical is the type of physical goods, whose credentials claim credentialSubject.asset with a
+	// name and an identifier.
+	AssetTypePhysical = "PhysicalAsset"
+	// AssetTypeWork is the type of works of authorship, whose credentials claim credentialSubject.asset with a
+	// title, a creator and a work type.
+	AssetTypeWork = "DigitalWork"
+	// AssetTypeRight is the type of rights over assets, whose credentials claim credentialSubject.right with the
+	// kind of right, ownership, license, usage or access, and the asset.
+	AssetTypeRight = "DigitalRight"
+
+	// SchemaStoreName is the recommended name of the store holding credential schemas.
+	SchemaStoreName = "vcschema"
+
+	tagSchemaName      = "name"
+	tagSchemaAssetType = "assetType"
+)
+
+// ErrSchemaNotFound is returned when resolving a credential schema which is not registered.
+var ErrSchemaNotFound = errors.New("credential schema not found")
+
+//go:embed schemas/*.json
+var assetSchemas embed.FS
+
+// CredentialSchema is a version of a named credential schema, identified by its ID.
+type CredentialSchema struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Name string `json:"name"`
+	// Version is a semantic version MAJOR.MINOR.PATCH.
+	Version string `json:"version"`
+	// AssetType is the type of the assets the credentials of the schema are about, if any.
+	AssetType string `json:"assetType,omitempty"`
+	// Schema is the JSON schema, draft-04, draft-06, draft-07 or 2020-12, validating the credentials.
+	Schema  json.RawMessage `json:"schema"`
+	Created time.Time       `json:"created"`
+}
+
+// AssetSchema returns version 1.0.0 of the built-in claim schema of assetType, to be registered with the given
+// ID.
+func AssetSchema(assetType, id string) (*CredentialSchema, error) {
+	content, err := assetSchemas.ReadFile("schemas/" + assetType + ".json")
+	if err != nil {
+		return nil, fmt.Errorf("%w: no claim schema for asset type %s", ErrSchemaNotFound, assetType)
+	}
+
+	return &CredentialSchema{
+		ID:        id,
+		Type:      JSONSchema,
+		Name:      assetType,
+		Version:   "1.0.0",
+		AssetType: assetType,
+		Schema:    content,
+	}, nil
+}
+
+// TypedID returns the credentialSchema of the credentials validated by the schema.
+func (s *CredentialSchema) TypedID() TypedID {
+	return TypedID{ID: s.ID, Type: s.Type}
+}
+
+// ToProto converts the schema to its protobuf representation.
+func (s *CredentialSchema) ToProto() *didpb.Schema {
+	msg := &didpb.Schema{
+		Id:        s.ID,
+		Type:      s.Type,
+		Name:      s.Name,
+		Version:   s.Version,
+		AssetType: s.AssetType,
+		Schema:    s.Schema,
+	}
+
+	if !s.Created.IsZero() {
+		msg.Created = timestamppb.New(s.Created)
+	}
+
+	return msg
+}
+
+// CredentialSchemaFromProto converts the protobuf representation of a schema.
+func CredentialSchemaFromProto(msg *didpb.Schema) (*CredentialSchema, error) {
+	s := &CredentialSchema{
+		ID:        msg.GetId(),
+		Type:      msg.GetType(),
+		Name:      msg.GetName(),
+		Version:   msg.GetVersion(),
+		AssetType: msg.GetAssetType(),
+		Schema:    msg.GetSchema(),
+	}
+
+	if msg.GetCreated() != nil {
+		if err := msg.GetCreated().CheckValid(); err != nil {
+			return nil, fmt.Errorf("invalid timestamp: %w", err)
+		}
+
+		s.Created = msg.GetCreated().AsTime()
+	}
+
+	return s, nil
+}
+
+func (s *CredentialSchema) validate() error {
+	if s.ID == "" || s.Name == "" {
+		return fmt.Errorf("%w: credential schema requires an ID and a name", ErrInvalidSchema)
+	}
+
+	if s.Type != JSONSchemaValidator2018 && s.Type != JSONSchema {
+		return fmt.Errorf("%w: unsupported credential schema type: %s", ErrInvalidSchema, s.Type)
+	}
+
+	if _, err := parseVersion(s.Version); err != nil {
+		return err
+	}
+
+	_, err := compileSchema(s.Schema)
+
+	return err
+}
+
+// SchemaRegistry registers versions of credential schemas, persisted in a storage/spi.Store, and resolves them
+// by ID. It is the SchemaLoader of credentials referring to registered schemas.
+type SchemaRegistry struct {
+	store spi.Store
+	lock  sync.Mutex
+}
+
+// NewSchemaRegistry returns a registry persisting credential schemas in store.
+func NewSchemaRegistry(store spi.Store) *SchemaRegistry {
+	return &SchemaRegistry{store: store}
+}
+
+// Register registers a version of a schema, which must be greater than the registered versions of the schema
+// name, and keep their asset type. Registering again a registered schema is a no-op, registering another schema
+// with its ID fails with spi.ErrDuplicateKey. The creation time defaults to now.
+func (r *SchemaRegistry) Register(s *CredentialSchema) error {
+	if err := s.validate(); err != nil {
+		return err
+	}
+
+	var schema bytes.Buffer
+	if err := json.Compact(&schema, s.Schema); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+	}
+
+	registered := *s
+	registered.Schema = schema.Bytes()
+
+	if registered.Created.IsZero() {
+		registered.Created = time.Now().UTC()
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	existing, err := r.get(s.ID)
+	if err == nil {
+		if existing.Type == registered.Type && existing.Name == registered.Name &&
+			existing.Version == registered.Version && existing.AssetType == registered.AssetType &&
+			bytes.Equal(existing.Schema, registered.Schema) {
+			return nil
+		}
+
+		return fmt.Errorf("credential schema %s: %w", s.ID, spi.ErrDuplicateKey)
+	} else if !errors.Is(err, ErrSchemaNotFound) {
+		return err
+	}
+
+	versions, err := r.query(tagSchemaName, s.Name)
+	if err != nil {
+		return err
+	}
+
+	if len(versions) > 0 {
+		latest := versions[len(versions)-1]
+
+		if compareVersions(latest.Version, s.Version) >= 0 {
+			return fmt.Errorf("%w: version %s of %s is not greater than the registered version %s",
+				ErrInvalidSchema, s.Version, s.Name, latest.Version)
+		}
+
+		if latest.AssetType != s.AssetType {
+			return fmt.Errorf("%w: asset type %q of %s differs from the registered asset type %q",
+				ErrInvalidSchema, s.AssetType, s.Name, latest.AssetType)
+		}
+	}
+
+	data, err := json.Marshal(&registered)
+	if err != nil {
+		return err
+	}
+
+	tags := []spi.Tag{{Name: tagSchemaName, Value: encodeTag(s.Name)}}
+	if s.AssetType != "" {
+		tags = append(tags, spi.Tag{Name: tagSchemaAssetType, Value: encodeTag(s.AssetType)})
+	}
+
+	return r.store.Put(s.ID, data, tags...)
+}
+
+// Resolve returns the schema with the given ID.
+func (r *SchemaRegistry) Resolve(id string) (*CredentialSchema, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.get(id)
+}
+
+// LoadSchema returns the JSON schema of the schema with the given ID.
+func (r *SchemaRegistry) LoadSchema(id string) ([]byte, error) {
+	s, err := r.Resolve(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Schema, nil
+}
+
+// Versions returns the registered versions of the schema name, by ascending version.
+func (r *SchemaRegistry) Versions(name string) ([]*CredentialSchema, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.query(tagSchemaName, name)
+}
+
+// Latest returns the greatest version of the schema name.
+func (r *SchemaRegistry) Latest(name string) (*CredentialSchema, error) {
+	versions, err := r.Versions(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrSchemaNotFound, name)
+	}
+
+	return versions[len(versions)-1], nil
+}
+
+// ByAssetType returns the schemas of assetType, by name then ascending version.
+func (r *SchemaRegistry) ByAssetType(assetType string) ([]*CredentialSchema, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.query(tagSchemaAssetType, assetType)
+}
+
+func (r *SchemaRegistry) get(id string) (*CredentialSchema, error) {
+	data, err := r.store.Get(id)
+	if errors.Is(err, spi.ErrDataNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrSchemaNotFound, id)
+	} else if err != nil {
+		return nil, fmt.Errorf("get credential schema %s: %w", id, err)
+	}
+
+	s := &CredentialSchema{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("unmarshal credential schema %s: %w", id, err)
+	}
+
+	return s, nil
+}
+
+// query returns the schemas tagged with the tag value, by name then ascending version.
+func (r *SchemaRegistry) query(tag, value string) ([]*CredentialSchema, error) {
+	it, err := r.store.Query(tag + ":" + encodeTag(value))
+	if err != nil {
+		return nil, fmt.Errorf("query credential schemas: %w", err)
+	}
+	defer spi.Close(it)
+
+	var schemas []*CredentialSchema
+
+	for {
+		ok, err := it.Next()
+		if err != nil {
+			return nil, fmt.Errorf("query credential schemas: %w", err)
+		}
+
+		if !ok {
+			break
+		}
+
+		id, err := it.Key()
+		if err != nil {
+			return nil, fmt.Errorf("query credential schemas: %w", err)
+		}
+
+		s, err := r.get(id)
+		if err != nil {
+			return nil, err
+		}
+
+		schemas = append(schemas, s)
+	}
+
+	sort.Slice(schemas, func(i, j int) bool {
+		if schemas[i].Name != schemas[j].Name {
+			return schemas[i].Name < schemas[j].Name
+		}
+
+		return compareVersions(schemas[i].Version, schemas[j].Version) < 0
+	})
+
+	return schemas, nil
+}
+
+// encodeTag encodes tag values, which storage/spi stores may not allow to contain ':'.
+func encodeTag(value string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(value))
+}
+
+// parseVersion parses a semantic version MAJOR.MINOR.PATCH.
+func parseVersion(version string) ([3]int, error) {
+	var parsed [3]int
+
+	parts := strings.Split(version, ".")
+	if len(parts) != len(parsed) {
+		return parsed, fmt.Errorf("%w: version %q is not MAJOR.MINOR.PATCH", ErrInvalidSchema, version)
+	}
+
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || strings.Trim(part, "0123456789") != "" || (len(part) > 1 && part[0] == '0') {
+			return parsed, fmt.Errorf("%w: version %q is not MAJOR.MINOR.PATCH", ErrInvalidSchema, version)
+		}
+
+		parsed[i] = n
+	}
+
+	return parsed, nil
+}
+
+// compareVersions compares valid semantic versions, returning -1, 0 or 1.
+func compareVersions(a, b string) int {
+	va, _ := parseVersion(a) //nolint:errcheck
+	vb, _ := parseVersion(b) //nolint:errcheck
+
+	for i := range va {
+		switch {
+		case va[i] < vb[i]:
+			return -1
+		case va[i] > vb[i]:
+			return 1
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,143 @@
+package vc
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zRich/zFusion/common/crypto"
+	"github.com/zRich/zFusion/storage/leveldb"
+	"github.com/zRich/zFusion/storage/spi"
+)
+
+const rightSchemaID = "https://example.com/schemas/right/1.0.0"
+
+func newSchemaRegistry(t *testing.T) *SchemaRegistry {
+	t.Helper()
+
+	p := leveldb.NewProvider(filepath.Join(t.TempDir(), "db"))
+	t.Cleanup(func() { require.NoError(t, p.Close()) })
+
+	store, err := p.OpenStore(SchemaStoreName)
+	require.NoError(t, err)
+
+	return NewSchemaRegistry(store)
+}
+
+func TestSchemaRegistry(t *testing.T) {
+	registry := newSchemaRegistry(t)
+
+	for _, assetType := range []string{AssetTypePhysical, AssetTypeWork, AssetTypeRight} {
+		s, err := AssetSchema(assetType, "https://example.com/schemas/"+assetType+"/1.0.0")
+		require.NoError(t, err)
+		require.NoError(t, registry.Register(s))
+		require.NoError(t, registry.Register(s))
+	}
+
+	_, err := AssetSchema("Unknown", "https://example.com/schemas/unknown")
+	require.ErrorIs(t, err, ErrSchemaNotFound)
+
+	v2 := &CredentialSchema{
+		ID:        "https://example.com/schemas/DigitalRight/2.0.0",
+		Type:      JSONSchemaValidator2018,
+		Name:      AssetTypeRight,
+		Version:   "2.0.0",
+		AssetType: AssetTypeRight,
+		Schema:    []byte(`{"type": "object", "required": ["credentialSubject"]}`),
+	}
+	require.NoError(t, registry.Register(v2))
+
+	latest, err := registry.Latest(AssetTypeRight)
+	require.NoError(t, err)
+	require.Equal(t, v2.ID, latest.ID)
+	require.Equal(t, `{"type":"object","required":["credentialSubject"]}`, string(latest.Schema))
+	require.False(t, latest.Created.IsZero())
+
+	versions, err := registry.Versions(AssetTypeRight)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	require.Equal(t, "1.0.0", versions[0].Version)
+
+	works, err := registry.ByAssetType(AssetTypeWork)
+	require.NoError(t, err)
+	require.Len(t, works, 1)
+	require.Equal(t, "https://example.com/schemas/DigitalWork/1.0.0", works[0].ID)
+
+	resolved, err := registry.Resolve(v2.ID)
+	require.NoError(t, err)
+
+	fromProto, err := CredentialSchemaFromProto(resolved.ToProto())
+	require.NoError(t, err)
+	require.Equal(t, resolved.Created.UnixNano(), fromProto.Created.UnixNano())
+	fromProto.Created = resolved.Created
+	require.Equal(t, resolved, fromProto)
+
+	_, err = registry.Resolve("https://example.com/schemas/unknown")
+	require.ErrorIs(t, err, ErrSchemaNotFound)
+
+	_, err = registry.Latest("Unknown")
+	require.ErrorIs(t, err, ErrSchemaNotFound)
+
+	t.Run("invalid", func(t *testing.T) {
+		conflict := *v2
+		conflict.Schema = []byte(`{"type": "object"}`)
+		require.ErrorIs(t, registry.Register(&conflict), spi.ErrDuplicateKey)
+
+		for _, modify := range []func(s *CredentialSchema){
+			func(s *CredentialSchema) { s.Version = "1.5.0" },
+			func(s *CredentialSchema) { s.Version = "2.0.0" },
+			func(s *CredentialSchema) { s.Version = "3.0" },
+			func(s *CredentialSchema) { s.Version = "3.0.+1" },
+			func(s *CredentialSchema) { s.Version = "3.0.01" },
+			func(s *CredentialSchema) { s.Version, s.AssetType = "3.0.0", AssetTypeWork },
+			func(s *CredentialSchema) { s.Version, s.Type = "3.0.0", "ShaclValidator2017" },
+			func(s *CredentialSchema) { s.Version, s.Schema = "3.0.0", []byte(`{"type": 1}`) },
+			func(s *CredentialSchema) { s.Version, s.Name = "3.0.0", "" },
+		} {
+			s := *v2
+			s.ID = "https://example.com/schemas/DigitalRight/3.0.0"
+			modify(&s)
+
+			require.ErrorIs(t, registry.Register(&s), ErrInvalidSchema, s)
+		}
+	})
+}
+
+func TestIssueWithSchemaRegistry(t *testing.T) {
+	registry := newSchemaRegistry(t)
+
+	right, err := AssetSchema(AssetTypeRight, rightSchemaID)
+	require.NoError(t, err)
+	require.NoError(t, registry.Register(right))
+
+	signer := newSigner(t, crypto.Ed25519)
+	opts := []Option{
+		WithDocumentResolver(mockDocumentResolver{issuerDID: newDoc(t, issuerDID, signer)}),
+		WithSchemaLoader(registry),
+	}
+
+	newLicense := func(kind string) *Credential {
+		cred := newCredential(ContextV1)
+		cred.Schemas = []TypedID{right.TypedID()}
+		cred.Subjects[0].Claims = map[string]interface{}{
+			"right": map[string]interface{}{"kind": kind, "asset": "urn:asset:42", "scope": []string{"stream"}},
+		}
+
+		return cred
+	}
+
+	data := issue(t, newLicense("license"), signer, opts...)
+
+	cred, err := VerifyCredential(data, opts...)
+	require.NoError(t, err)
+	require.Equal(t, right.ID, cred.Schemas[0].ID)
+
+	// issuance is refused for credentials not valid against their schemas.
+	err = Issue(newLicense("lease"), newDoc(t, issuerDID, signer), "#key-1", signer, opts...)
+	require.ErrorIs(t, err, ErrSchemaValidation)
+
+	unregistered := newLicense("license")
+	unregistered.Schemas[0].ID = "https://example.com/schemas/unknown"
+	err = Issue(unregistered, newDoc(t, issuerDID, signer), "#key-1", signer, opts...)
+	require.ErrorIs(t, err, ErrSchemaNotFound)
+}
@@ -0,0 +1,450 @@
+package vc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/zRich/zFusion/common/crypto"
+	"github.com/zRich/zFusion/did"
+)
+
+// SD-JWT claims and types, see https://datatracker.ietf.org/doc/draft-ietf-oauth-selective-disclosure-jwt/.
+const (
+	sdDigests     = "_sd"
+	sdAlg         = "_sd_alg"
+	sdHash        = "sd_hash"
+	sdAlgSHA256   = "sha-256"
+	sdSeparator   = "~"
+	typSDJWT      = "vc+sd-jwt"
+	typKeyBinding = "kb+jwt"
+	saltSize      = 16
+)
+
+// ErrInvalidDisclosure is returned when an SD-JWT disclosure is malformed or not part of the signed JWT.
+var ErrInvalidDisclosure = errors.New("invalid disclosure")
+
+// Disclosure is a selectively disclosable claim of a credential subject.
+type Disclosure struct {
+	Salt  string
+	Name  string
+	Value interface{}
+	// encoded is the disclosure as issued, whose digest is signed.
+	encoded string
+}
+
+// SDJWT is a credential JWT whose subject claims are selectively disclosable, with the disclosures of the
+// claims revealed by the holder and an optional key binding JWT.
+type SDJWT struct {
+	JWT         string
+	Disclosures []Disclosure
+	KeyBinding  string
+}
+
+// IssueSDJWT encodes the credential as an SD-JWT: a credential JWT, as created by IssueJWT, in which the subject
+// claims named by disclosable are replaced by digests of their disclosures, followed by all the disclosures.
+// All subject claims are disclosable if disclosable is empty.
+func IssueSDJWT(cred *Credential, issuerDoc *did.Document, keyID string, signer crypto.Signer, disclosable []string,
+	opts ...Option) (string, error) {
+	claims, vm, err := credentialClaims(cred, issuerDoc, keyID, getOptions(opts))
+	if err != nil {
+		return "", err
+	}
+
+	vc, _ := claims[jwtVC].(map[string]interface{}) //nolint:errcheck
+
+	var disclosures []Disclosure
+
+	for _, s := range objectOrArray(vc[fieldSubject]) {
+		subject, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		var digests []string
+
+		for _, name := range sortedNames(subject) {
+			if name == fieldID || (len(disclosable) > 0 && !contains(disclosable, name)) {
+				continue
+			}
+
+			if name == sdDigests {
+				return "", fmt.Errorf("%w: claim %s is reserved", ErrInvalidCredential, sdDigests)
+			}
+
+			d, err := newDisclosure(name, subject[name])
+			if err != nil {
+				return "", err
+			}
+
+			delete(subject, name)
+
+			disclosures = append(disclosures, *d)
+			digests = append(digests, d.digest())
+		}
+
+		if len(digests) > 0 {
+			sort.Strings(digests)
+			subject[sdDigests] = digests
+		}
+	}
+
+	claims[sdAlg] = sdAlgSHA256
+
+	token, err := signJWT(typSDJWT, did.AbsoluteID(issuerDoc.ID, keyID), claims, signer, vm)
+	if err != nil {
+		return "", err
+	}
+
+	return (&SDJWT{JWT: token, Disclosures: disclosures}).String(), nil
+}
+
+// ParseSDJWT parses an SD-JWT without verifying it, see VerifySDJWT.
+func ParseSDJWT(sdJWT string) (*SDJWT, error) {
+	parts := strings.Split(sdJWT, sdSeparator)
+	if len(parts) < 2 { //nolint:gomnd
+		return nil, fmt.Errorf("%w: missing SD-JWT separator", ErrInvalidJWT)
+	}
+
+	s := &SDJWT{JWT: parts[0], KeyBinding: parts[len(parts)-1]}
+
+	for _, encoded := range parts[1 : len(parts)-1] {
+		d, err := parseDisclosure(encoded)
+		if err != nil {
+			return nil, err
+		}
+
+		s.Disclosures = append(s.Disclosures, *d)
+	}
+
+	return s, nil
+}
+
+// String returns the compact serialization of the SD-JWT.
+func (s *SDJWT) String() string {
+	var b strings.Builder
+
+	b.WriteString(s.JWT)
+	b.WriteString(sdSeparator)
+
+	for _, d := range s.Disclosures {
+		b.WriteString(d.encoded)
+		b.WriteString(sdSeparator)
+	}
+
+	b.WriteString(s.KeyBinding)
+
+	return b.String()
+}
+
+// Disclose returns a copy of the SD-JWT revealing only the named claims among the disclosed ones, without key
+// binding.
+func (s *SDJWT) Disclose(names ...string) *SDJWT {
+	disclosed := &SDJWT{JWT: s.JWT}
+
+	for _, d := range s.Disclosures {
+		if contains(names, d.Name) {
+			disclosed.Disclosures = append(disclosed.Disclosures, d)
+		}
+	}
+
+	return disclosed
+}
+
+// Credential returns the credential with the disclosed claims, without verifying it.
+func (s *SDJWT) Credential() (*Credential, error) {
+	jwt, err := parseJWT(s.JWT)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := s.disclosedCredential(jwt)
+	if err != nil {
+		return nil, err
+	}
+
+	return credentialFromMap(raw)
+}
+
+// BindKey appends a key binding JWT to the SD-JWT, proving the holder presents it to the verifier identified by
+// the domain set by WithDomain in answer to the challenge set by WithChallenge, which are required. keyID is
+// the ID of the authentication method of the holder DID document holding the public key of signer. The holder
+// must be a subject of the credential.
+func (s *SDJWT) BindKey(holderDoc *did.Document, keyID string, signer crypto.Signer, opts ...Option) error {
+	o := getOptions(opts)
+
+	if o.challenge == "" || o.domain == "" {
+		return errors.New("key binding requires a challenge and a domain")
+	}
+
+	cred, err := s.Credential()
+	if err != nil {
+		return err
+	}
+
+	if !isSubject(cred, holderDoc.ID) {
+		return fmt.Errorf("%w: %s is not a subject of the credential", ErrInvalidCredential, holderDoc.ID)
+	}
+
+	vm, err := holderDoc.AuthorizedVerificationMethod(keyID, did.Authentication)
+	if err != nil {
+		return fmt.Errorf("holder key %s: %w", keyID, err)
+	}
+
+	s.KeyBinding = ""
+
+	kb, err := signJWT(typKeyBinding, did.AbsoluteID(holderDoc.ID, keyID), map[string]interface{}{
+		jwtIat:   o.created.Unix(),
+		jwtAud:   o.domain,
+		jwtNonce: o.challenge,
+		sdHash:   s.hash(),
+	}, signer, vm)
+	if err != nil {
+		return err
+	}
+
+	s.KeyBinding = kb
+
+	return nil
+}
+
+// VerifySDJWT parses and verifies an SD-JWT: its JWT is verified as by VerifyCredentialJWT, except for credential
+// schemas which undisclosed claims may not satisfy, and every disclosure must be signed by the JWT. If a challenge
+// or domain is set by WithChallenge or WithDomain, the SD-JWT must have a key binding JWT with that challenge and
+// domain, created by an authentication method of a subject of the credential. The returned credential holds the
+// disclosed claims only.
+func VerifySDJWT(sdJWT string, opts ...Option) (*Credential, error) {
+	o := getOptions(opts)
+
+	s, err := ParseSDJWT(sdJWT)
+	if err != nil {
+		return nil, err
+	}
+
+	jwt, err := parseJWT(s.JWT)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := s.disclosedCredential(jwt)
+	if err != nil {
+		return nil, err
+	}
+
+	cred, err := credentialFromMap(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := jwt.verify(cred.Issuer.ID, did.AssertionMethod, o); err != nil {
+		return nil, err
+	}
+
+	if err := cred.checkValidityPeriod(o.now); err != nil {
+		return nil, err
+	}
+
+	if err := cred.checkStatus(o); err != nil {
+		return nil, err
+	}
+
+	if o.challenge != "" || o.domain != "" {
+		if err := s.verifyKeyBinding(cred, o); err != nil {
+			return nil, err
+		}
+	}
+
+	return cred, nil
+}
+
+// disclosedCredential returns the credential of jwt with the disclosed claims in place of their digests.
+func (s *SDJWT) disclosedCredential(jwt *jwt) (map[string]interface{}, error) {
+	if alg := jwt.claims[sdAlg]; alg != sdAlgSHA256 {
+		return nil, fmt.Errorf("%w: unsupported %s %v", ErrInvalidJWT, sdAlg, alg)
+	}
+
+	disclosures := make(map[string]*Disclosure, len(s.Disclosures))
+
+	for i := range s.Disclosures {
+		digest := s.Disclosures[i].digest()
+		if _, ok := disclosures[digest]; ok {
+			return nil, fmt.Errorf("%w: duplicate disclosure of %s", ErrInvalidDisclosure, s.Disclosures[i].Name)
+		}
+
+		disclosures[digest] = &s.Disclosures[i]
+	}
+
+	vc, _ := jwt.claims[jwtVC].(map[string]interface{}) //nolint:errcheck
+
+	for _, subject := range objectOrArray(vc[fieldSubject]) {
+		subject, ok := subject.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		digests, err := stringOrArray(subject[sdDigests])
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid %s: %v", ErrInvalidJWT, sdDigests, err)
+		}
+
+		delete(subject, sdDigests)
+
+		for _, digest := range digests {
+			d, ok := disclosures[digest]
+			if !ok {
+				continue
+			}
+
+			if _, ok := subject[d.Name]; ok {
+				return nil, fmt.Errorf("%w: claim %s is disclosed twice", ErrInvalidDisclosure, d.Name)
+			}
+
+			subject[d.Name] = d.Value
+
+			delete(disclosures, digest)
+		}
+	}
+
+	if len(disclosures) > 0 {
+		unsigned := make([]string, 0, len(disclosures))
+		for _, d := range disclosures {
+			unsigned = append(unsigned, d.Name)
+		}
+
+		sort.Strings(unsigned)
+
+		return nil, fmt.Errorf("%w: claims %s are not signed by the JWT", ErrInvalidDisclosure,
+			strings.Join(unsigned, ", "))
+	}
+
+	return credentialFromClaims(jwt.claims)
+}
+
+func (s *SDJWT) verifyKeyBinding(cred *Credential, o *options) error {
+	if s.KeyBinding == "" {
+		return fmt.Errorf("%w: missing key binding", did.ErrInvalidProof)
+	}
+
+	kb, err := parseJWT(s.KeyBinding)
+	if err != nil {
+		return err
+	}
+
+	if kb.header[jwtTyp] != typKeyBinding {
+		return fmt.Errorf("%w: key binding typ %v", ErrInvalidJWT, kb.header[jwtTyp])
+	}
+
+	if nonce, _ := kb.claims[jwtNonce].(string); nonce != o.challenge { //nolint:errcheck
+		return fmt.Errorf("%w: nonce %q, expected %q", did.ErrInvalidProof, nonce, o.challenge)
+	}
+
+	if !kb.hasAudience(o.domain) {
+		return fmt.Errorf("%w: audience does not include %q", did.ErrInvalidProof, o.domain)
+	}
+
+	if kb.claims[sdHash] != s.hash() {
+		return fmt.Errorf("%w: key binding is not bound to the disclosures", did.ErrInvalidProof)
+	}
+
+	kid, _ := kb.header[jwtKid].(string) //nolint:errcheck
+
+	didURL, err := did.ParseDIDURL(kid)
+	if err != nil {
+		return fmt.Errorf("%w: invalid kid %q", did.ErrInvalidProof, kid)
+	}
+
+	holder := didURL.DID.String()
+	if !isSubject(cred, holder) {
+		return fmt.Errorf("%w: key binding by %s, which is not a subject of the credential", did.ErrInvalidProof,
+			holder)
+	}
+
+	return kb.verify(holder, did.Authentication, o)
+}
+
+// hash returns the digest of the SD-JWT without key binding, which the key binding JWT signs.
+func (s *SDJWT) hash() string {
+	presented := *s
+	presented.KeyBinding = ""
+
+	digest := sha256.Sum256([]byte(presented.String()))
+
+	return base64.RawURLEncoding.EncodeToString(digest[:])
+}
+
+func newDisclosure(name string, value interface{}) (*Disclosure, error) {
+	salt := make([]byte, saltSize)
+
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("generate salt: %w", err)
+	}
+
+	d := &Disclosure{Salt: base64.RawURLEncoding.EncodeToString(salt), Name: name, Value: value}
+
+	data, err := marshal([]interface{}{d.Salt, d.Name, d.Value})
+	if err != nil {
+		return nil, err
+	}
+
+	d.encoded = base64.RawURLEncoding.EncodeToString(data)
+
+	return d, nil
+}
+
+func parseDisclosure(encoded string) (*Disclosure, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDisclosure, err)
+	}
+
+	v, err := decodeValue(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDisclosure, err)
+	}
+
+	array, ok := v.([]interface{})
+	if !ok || len(array) != 3 { //nolint:gomnd
+		return nil, fmt.Errorf("%w: expected an array of salt, name and value", ErrInvalidDisclosure)
+	}
+
+	salt, _ := array[0].(string) //nolint:errcheck
+	name, _ := array[1].(string) //nolint:errcheck
+
+	if salt == "" || name == "" || name == sdDigests {
+		return nil, fmt.Errorf("%w: invalid salt or name", ErrInvalidDisclosure)
+	}
+
+	return &Disclosure{Salt: salt, Name: name, Value: array[2], encoded: encoded}, nil
+}
+
+func (d *Disclosure) digest() string {
+	digest := sha256.Sum256([]byte(d.encoded))
+
+	return base64.RawURLEncoding.EncodeToString(digest[:])
+}
+
+func isSubject(cred *Credential, id string) bool {
+	for _, s := range cred.Subjects {
+		if s.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sortedNames(m map[string]interface{}) []string {
+	names := make([]string, 0, len(m))
+
+	for name := range m {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,153 @@
+package vc
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zRich/zFusion/common/crypto"
+	"github.com/zRich/zFusion/did"
+)
+
+func TestSDJWT(t *testing.T) {
+	issuerSigner := newSigner(t, crypto.ECDSAP256)
+	issuerDoc := newDoc(t, issuerDID, issuerSigner)
+	holderSigner := newSigner(t, crypto.Ed25519)
+	holderDoc := newDoc(t, holderDID, holderSigner)
+	resolver := WithDocumentResolver(mockDocumentResolver{issuerDID: issuerDoc, holderDID: holderDoc})
+
+	cred := newCredential(ContextV1)
+	cred.Subjects[0].Claims["location"] = "Shanghai"
+
+	issued, err := IssueSDJWT(cred, issuerDoc, "#key-1", issuerSigner, []string{"share", "location"})
+	require.NoError(t, err)
+	require.True(t, strings.HasSuffix(issued, "~"))
+
+	sdJWT, err := ParseSDJWT(issued)
+	require.NoError(t, err)
+	require.Len(t, sdJWT.Disclosures, 2)
+	require.Equal(t, issued, sdJWT.String())
+
+	header, claims := decodeJWT(t, sdJWT.JWT)
+	require.Equal(t, "vc+sd-jwt", header["typ"])
+	require.Equal(t, "sha-256", claims["_sd_alg"])
+
+	subject := claims["vc"].(map[string]interface{})["credentialSubject"].(map[string]interface{})
+	require.Equal(t, "urn:asset:42", subject["asset"])
+	require.NotContains(t, subject, "share")
+	require.NotContains(t, subject, "location")
+	require.Len(t, subject["_sd"], 2)
+
+	verified, err := VerifySDJWT(issued, resolver)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"asset": "urn:asset:42", "share": json100(), "location": "Shanghai",
+	}, verified.Subjects[0].Claims)
+
+	// the holder reveals the share only.
+	disclosed := sdJWT.Disclose("share")
+
+	verified, err = VerifySDJWT(disclosed.String(), resolver)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"asset": "urn:asset:42", "share": json100()}, verified.Subjects[0].Claims)
+
+	verified, err = VerifySDJWT(sdJWT.Disclose().String(), resolver)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"asset": "urn:asset:42"}, verified.Subjects[0].Claims)
+
+	// plain JWT verification does not accept undisclosed claims.
+	_, err = VerifyCredentialJWT(sdJWT.JWT, resolver)
+	require.ErrorIs(t, err, ErrInvalidJWT)
+
+	t.Run("key binding", func(t *testing.T) {
+		bound := sdJWT.Disclose("share")
+
+		err := bound.BindKey(holderDoc, "#key-2", holderSigner, WithChallenge(challenge))
+		require.EqualError(t, err, "key binding requires a challenge and a domain")
+
+		err = bound.BindKey(holderDoc, "#key-1", holderSigner, WithChallenge(challenge), WithDomain(domain))
+		require.ErrorIs(t, err, did.ErrKeyNotAuthorized)
+
+		require.NoError(t, bound.BindKey(holderDoc, "#key-2", holderSigner, WithChallenge(challenge),
+			WithDomain(domain)))
+
+		header, _ := decodeJWT(t, bound.KeyBinding)
+		require.Equal(t, "kb+jwt", header["typ"])
+
+		_, err = VerifySDJWT(bound.String(), resolver, WithChallenge(challenge), WithDomain(domain))
+		require.NoError(t, err)
+
+		_, err = VerifySDJWT(bound.String(), resolver, WithChallenge("other"), WithDomain(domain))
+		require.ErrorIs(t, err, did.ErrInvalidProof)
+
+		_, err = VerifySDJWT(bound.String(), resolver, WithChallenge(challenge), WithDomain("other.example.com"))
+		require.ErrorIs(t, err, did.ErrInvalidProof)
+
+		// the key binding covers the disclosures.
+		replayed := *bound
+		replayed.Disclosures = sdJWT.Disclosures
+
+		_, err = VerifySDJWT(replayed.String(), resolver, WithChallenge(challenge), WithDomain(domain))
+		require.ErrorIs(t, err, did.ErrInvalidProof)
+		require.Contains(t, err.Error(), "not bound to the disclosures")
+
+		_, err = VerifySDJWT(sdJWT.Disclose("share").String(), resolver, WithChallenge(challenge),
+			WithDomain(domain))
+		require.ErrorIs(t, err, did.ErrInvalidProof)
+		require.Contains(t, err.Error(), "missing key binding")
+
+		// only a subject of the credential may bind it.
+		other := newDoc(t, "did:example:other", holderSigner)
+		require.ErrorIs(t, sdJWT.BindKey(other, "#key-2", holderSigner, WithChallenge(challenge),
+			WithDomain(domain)), ErrInvalidCredential)
+	})
+
+	t.Run("disclosures", func(t *testing.T) {
+		forged, err := newDisclosure("share", 100)
+		require.NoError(t, err)
+
+		tampered := sdJWT.Disclose("location")
+		tampered.Disclosures = append(tampered.Disclosures, *forged)
+
+		_, err = VerifySDJWT(tampered.String(), resolver)
+		require.ErrorIs(t, err, ErrInvalidDisclosure)
+		require.Contains(t, err.Error(), "share are not signed by the JWT")
+
+		duplicated := sdJWT.Disclose("share")
+		duplicated.Disclosures = append(duplicated.Disclosures, duplicated.Disclosures[0])
+
+		_, err = VerifySDJWT(duplicated.String(), resolver)
+		require.ErrorIs(t, err, ErrInvalidDisclosure)
+
+		for _, encoded := range []string{"!", base64.RawURLEncoding.EncodeToString([]byte(`["salt","name"]`))} {
+			_, err = ParseSDJWT(sdJWT.JWT + "~" + encoded + "~")
+			require.ErrorIs(t, err, ErrInvalidDisclosure)
+		}
+
+		_, err = ParseSDJWT(sdJWT.JWT)
+		require.ErrorIs(t, err, ErrInvalidJWT)
+	})
+}
+
+func TestSDJWTAllClaims(t *testing.T) {
+	signer := newSigner(t, crypto.Ed25519)
+	issuerDoc := newDoc(t, issuerDID, signer)
+
+	issued, err := IssueSDJWT(newCredential(ContextV1), issuerDoc, "#key-1", signer, nil)
+	require.NoError(t, err)
+
+	sdJWT, err := ParseSDJWT(issued)
+	require.NoError(t, err)
+	require.Len(t, sdJWT.Disclosures, 2)
+
+	cred, err := sdJWT.Disclose("asset").Credential()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"asset": "urn:asset:42"}, cred.Subjects[0].Claims)
+	require.Equal(t, "did:example:owner", cred.Subjects[0].ID)
+}
+
+func json100() interface{} {
+	return json.Number("100")
+}
@@ -0,0 +1,526 @@
+package vc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+
+	"github.com/zRich/zFusion/common/crypto"
+	"github.com/zRich/zFusion/common/jsonld"
+	"github.com/zRich/zFusion/did"
+	"github.com/zRich/zFusion/storage/spi"
+)
+
+// Status List 2021 terms.
+const (
+	ContextStatusList2021        = jsonld.ContextStatusList2021
+	TypeStatusList2021Credential = "StatusList2021Credential"
+	TypeStatusList2021           = "StatusList2021"
+	TypeStatusList2021Entry      = "StatusList2021Entry"
+
+	// StatusPurposeRevocation is the purpose of lists whose set bits permanently revoke credentials.
+	StatusPurposeRevocation = "revocation"
+	// StatusPurposeSuspension is the purpose of lists whose set bits temporarily suspend credentials.
+	StatusPurposeSuspension = "suspension"
+
+	// DefaultStatusListSize is the number of entries of a status list, the minimum recommended to hide the
+	// credential a verifier checks among the others.
+	DefaultStatusListSize = 131072
+	// MaxStatusListSize is the maximum number of entries of a decoded status list, which bounds the memory used
+	// to decompress the encodedList of a status list credential.
+	MaxStatusListSize = 1 << 24
+
+	// StatusStoreName is the recommended name of the store holding status lists.
+	StatusStoreName = "vcstatus"
+
+	fieldStatusPurpose        = "statusPurpose"
+	fieldStatusListIndex      = "statusListIndex"
+	fieldStatusListCredential = "statusListCredential"
+	fieldEncodedList          = "encodedList"
+)
+
+var (
+	// ErrRevoked is returned when verifying a revoked credential.
+	ErrRevoked = errors.New("credential is revoked")
+	// ErrSuspended is returned when verifying a suspended credential.
+	ErrSuspended = errors.New("credential is suspended")
+	// ErrStatusListFull is returned when allocating an entry of a status list with no free entry left.
+	ErrStatusListFull = errors.New("status list is full")
+)
+
+// StatusList is a bitstring holding one status bit per credential, the first entry being the most significant
+// bit of the first byte.
+type StatusList struct {
+	bits []byte
+}
+
+// NewStatusList returns a status list of size entries, all unset. size is rounded up to a multiple of 8.
+func NewStatusList(size int) *StatusList {
+	return &StatusList{bits: make([]byte, (size+7)/8)}
+}
+
+// Len returns the number of entries of the list.
+func (l *StatusList) Len() int {
+	return len(l.bits) * 8
+}
+
+// Get returns the status bit at index.
+func (l *StatusList) Get(index int) (bool, error) {
+	if index < 0 || index >= l.Len() {
+		return false, fmt.Errorf("status list index %d out of range [0, %d)", index, l.Len())
+	}
+
+	return l.bits[index/8]&(0x80>>(index%8)) != 0, nil
+}
+
+// Set sets the status bit at index.
+func (l *StatusList) Set(index int, status bool) error {
+	if index < 0 || index >= l.Len() {
+		return fmt.Errorf("status list index %d out of range [0, %d)", index, l.Len())
+	}
+
+	if status {
+		l.bits[index/8] |= 0x80 >> (index % 8)
+	} else {
+		l.bits[index/8] &^= 0x80 >> (index % 8)
+	}
+
+	return nil
+}
+
+// Encode returns the GZIP-compressed, base64url encoded bitstring, the encodedList of status list credentials.
+func (l *StatusList) Encode() (string, error) {
+	var buf bytes.Buffer
+
+	w := gzip.NewWriter(&buf)
+
+	if _, err := w.Write(l.bits); err != nil {
+		return "", err
+	}
+
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// DecodeStatusList decodes the encodedList of a status list credential, of at most MaxStatusListSize entries.
+func DecodeStatusList(encoded string) (*StatusList, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("decode status list: %w", err)
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decompress status list: %w", err)
+	}
+
+	bits, err := io.ReadAll(io.LimitReader(r, MaxStatusListSize/8+1))
+	if err != nil {
+		return nil, fmt.Errorf("decompress status list: %w", err)
+	}
+
+	if len(bits) > MaxStatusListSize/8 {
+		return nil, fmt.Errorf("decompress status list: more than %d entries", MaxStatusListSize)
+	}
+
+	return &StatusList{bits: bits}, nil
+}
+
+// StatusListLoader loads the status list credentials referenced by credential statuses.
+type StatusListLoader interface {
+	LoadStatusList(url string) ([]byte, error)
+}
+
+// statusList is a stored status list.
+type statusList struct {
+	ID        string `json:"id"`
+	Issuer    string `json:"issuer"`
+	Purpose   string `json:"purpose"`
+	Statuses  []byte `json:"statuses"`
+	Allocated []byte `json:"allocated"`
+	Count     int    `json:"count"`
+}
+
+// StatusRegistry manages the status lists of an issuer, persisted in a storage/spi.Store. Each list is
+// published as a status list credential, issued by IssueStatusList, at the URL which identifies the list.
+type StatusRegistry struct {
+	store spi.Store
+	lock  sync.Mutex
+}
+
+// NewStatusRegistry returns a registry persisting status lists in store.
+func NewStatusRegistry(store spi.Store) *StatusRegistry {
+	return &StatusRegistry{store: store}
+}
+
+// CreateList creates a status list of size entries with the given purpose, to be published by issuer at
+// listID.
+func (r *StatusRegistry) CreateList(listID, issuer, purpose string, size int) error {
+	if purpose != StatusPurposeRevocation && purpose != StatusPurposeSuspension {
+		return fmt.Errorf("unsupported status purpose: %s", purpose)
+	}
+
+	if size <= 0 {
+		return fmt.Errorf("invalid status list size: %d", size)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if _, err := r.store.Get(listID); err == nil {
+		return fmt.Errorf("status list %s: %w", listID, spi.ErrDuplicateKey)
+	} else if !errors.Is(err, spi.ErrDataNotFound) {
+		return err
+	}
+
+	return r.putList(&statusList{
+		ID:        listID,
+		Issuer:    issuer,
+		Purpose:   purpose,
+		Statuses:  NewStatusList(size).bits,
+		Allocated: NewStatusList(size).bits,
+	})
+}
+
+// Allocate sets the credential status of cred to a free entry of the list listID, chosen at random so that
+// entries do not disclose the issuance order. The status list context is added to the credential contexts.
+// Allocation must precede issuance, as credential statuses are signed.
+func (r *StatusRegistry) Allocate(cred *Credential, listID string) error {
+	if len(cred.Proofs) > 0 {
+		return fmt.Errorf("%w: cannot set the status of a signed credential", ErrInvalidCredential)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	list, err := r.getList(listID)
+	if err != nil {
+		return err
+	}
+
+	if cred.Issuer.ID != "" && cred.Issuer.ID != list.Issuer {
+		return fmt.Errorf("%w: issuer %s does not publish status list %s", ErrInvalidCredential, cred.Issuer.ID,
+			listID)
+	}
+
+	allocated := &StatusList{bits: list.Allocated}
+	if list.Count >= allocated.Len() {
+		return fmt.Errorf("%w: %s", ErrStatusListFull, listID)
+	}
+
+	var seed [8]byte
+	if _, err := rand.Read(seed[:]); err != nil {
+		return err
+	}
+
+	start := int(binary.BigEndian.Uint64(seed[:]) % uint64(allocated.Len()))
+
+	index := -1
+
+	for i := 0; i < allocated.Len() && index < 0; i++ {
+		candidate := (start + i) % allocated.Len()
+
+		if used, _ := allocated.Get(candidate); !used { //nolint:errcheck
+			index = candidate
+		}
+	}
+
+	if err := allocated.Set(index, true); err != nil {
+		return err
+	}
+
+	list.Count++
+
+	if err := r.putList(list); err != nil {
+		return err
+	}
+
+	if !contains(cred.Context, ContextStatusList2021) {
+		cred.Context = append(cred.Context, ContextStatusList2021)
+	}
+
+	cred.Status = &TypedID{
+		ID:   fmt.Sprintf("%s#%d", listID, index),
+		Type: TypeStatusList2021Entry,
+		CustomFields: map[string]interface{}{
+			fieldStatusPurpose:        list.Purpose,
+			fieldStatusListIndex:      strconv.Itoa(index),
+			fieldStatusListCredential: listID,
+		},
+	}
+
+	return nil
+}
+
+// Revoke revokes cred, whose status must be an entry of a revocation list of the registry. Revocation is
+// permanent.
+func (r *StatusRegistry) Revoke(cred *Credential) error {
+	return r.setStatus(cred, StatusPurposeRevocation, true)
+}
+
+// Suspend suspends cred, whose status must be an entry of a suspension list of the registry, until it is
+// reinstated.
+func (r *StatusRegistry) Suspend(cred *Credential) error {
+	return r.setStatus(cred, StatusPurposeSuspension, true)
+}
+
+// Reinstate lifts the suspension of cred.
+func (r *StatusRegistry) Reinstate(cred *Credential) error {
+	return r.setStatus(cred, StatusPurposeSuspension, false)
+}
+
+// Status returns whether the status bit of cred is set in its status list.
+func (r *StatusRegistry) Status(cred *Credential) (bool, error) {
+	entry, err := parseStatusEntry(cred.Status)
+	if err != nil {
+		return false, err
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	list, err := r.getList(entry.listID)
+	if err != nil {
+		return false, err
+	}
+
+	return (&StatusList{bits: list.Statuses}).Get(entry.index)
+}
+
+func (r *StatusRegistry) setStatus(cred *Credential, purpose string, status bool) error {
+	entry, err := parseStatusEntry(cred.Status)
+	if err != nil {
+		return err
+	}
+
+	if entry.purpose != purpose {
+		return fmt.Errorf("%w: credential status purpose is %s, not %s", ErrInvalidCredential, entry.purpose,
+			purpose)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	list, err := r.getList(entry.listID)
+	if err != nil {
+		return err
+	}
+
+	if list.Purpose != purpose {
+		return fmt.Errorf("status list %s is a %s list", list.ID, list.Purpose)
+	}
+
+	if allocated, err := (&StatusList{bits: list.Allocated}).Get(entry.index); err != nil || !allocated {
+		return fmt.Errorf("status list %s entry %d is not allocated", list.ID, entry.index)
+	}
+
+	if err := (&StatusList{bits: list.Statuses}).Set(entry.index, status); err != nil {
+		return err
+	}
+
+	return r.putList(list)
+}
+
+// IssueStatusList issues the status list credential of the list listID, with the current statuses. Status
+// list credentials are reissued and republished after statuses change, for verifiers to observe the change.
+func (r *StatusRegistry) IssueStatusList(listID string, issuerDoc *did.Document, keyID string,
+	signer crypto.Signer, opts ...Option) (*Credential, error) {
+	r.lock.Lock()
+	list, err := r.getList(listID)
+	r.lock.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if list.Issuer != issuerDoc.ID {
+		return nil, fmt.Errorf("status list %s is published by %s, not %s", listID, list.Issuer, issuerDoc.ID)
+	}
+
+	encoded, err := (&StatusList{bits: list.Statuses}).Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	cred := &Credential{
+		Context: []string{ContextV1, ContextStatusList2021},
+		ID:      listID,
+		Types:   []string{TypeVerifiableCredential, TypeStatusList2021Credential},
+		Subjects: []Subject{{
+			ID: listID + "#list",
+			Claims: map[string]interface{}{
+				fieldType:          TypeStatusList2021,
+				fieldStatusPurpose: list.Purpose,
+				fieldEncodedList:   encoded,
+			},
+		}},
+	}
+
+	if err := Issue(cred, issuerDoc, keyID, signer, opts...); err != nil {
+		return nil, err
+	}
+
+	return cred, nil
+}
+
+func (r *StatusRegistry) getList(listID string) (*statusList, error) {
+	data, err := r.store.Get(listID)
+	if err != nil {
+		return nil, fmt.Errorf("get status list %s: %w", listID, err)
+	}
+
+	list := &statusList{}
+	if err := json.Unmarshal(data, list); err != nil {
+		return nil, fmt.Errorf("unmarshal status list %s: %w", listID, err)
+	}
+
+	return list, nil
+}
+
+func (r *StatusRegistry) putList(list *statusList) error {
+	data, err := json.Marshal(list)
+	if err != nil {
+		return err
+	}
+
+	return r.store.Put(list.ID, data)
+}
+
+// statusEntry is a parsed StatusList2021Entry.
+type statusEntry struct {
+	purpose string
+	index   int
+	listID  string
+}
+
+func parseStatusEntry(status *TypedID) (*statusEntry, error) {
+	if status == nil {
+		return nil, fmt.Errorf("%w: no credential status", ErrInvalidCredential)
+	}
+
+	if status.Type != TypeStatusList2021Entry {
+		return nil, fmt.Errorf("unsupported credential status type: %s", status.Type)
+	}
+
+	entry := &statusEntry{}
+	entry.purpose, _ = status.CustomFields[fieldStatusPurpose].(string)       //nolint:errcheck
+	entry.listID, _ = status.CustomFields[fieldStatusListCredential].(string) //nolint:errcheck
+
+	var index string
+
+	switch v := status.CustomFields[fieldStatusListIndex].(type) {
+	case string:
+		index = v
+	case json.Number:
+		index = v.String()
+	}
+
+	var err error
+
+	entry.index, err = strconv.Atoi(index)
+	if err != nil || entry.index < 0 || entry.purpose == "" || entry.listID == "" {
+		return nil, fmt.Errorf("%w: invalid %s credential status", ErrInvalidCredential, TypeStatusList2021Entry)
+	}
+
+	return entry, nil
+}
+
+// checkStatus checks the status of cred in its status list credential, loaded with the status list loader set
+// by WithStatusListLoader and verified with the same options.
+func (c *Credential) checkStatus(o *options) error {
+	if c.Status == nil {
+		return nil
+	}
+
+	entry, err := parseStatusEntry(c.Status)
+	if err != nil {
+		return err
+	}
+
+	if o.statusLoader == nil {
+		return errors.New("no status list loader to check the credential status")
+	}
+
+	data, err := o.statusLoader.LoadStatusList(entry.listID)
+	if err != nil {
+		return fmt.Errorf("load status list %s: %w", entry.listID, err)
+	}
+
+	list, err := verifyStatusList(data, o)
+	if err != nil {
+		return fmt.Errorf("verify status list %s: %w", entry.listID, err)
+	}
+
+	if list.ID != entry.listID || list.Issuer.ID != c.Issuer.ID {
+		return fmt.Errorf("%w: status list %s is not the list of %s issued by %s", ErrInvalidCredential,
+			list.ID, entry.listID, c.Issuer.ID)
+	}
+
+	if !contains(list.Types, TypeStatusList2021Credential) || len(list.Subjects) != 1 {
+		return fmt.Errorf("%w: %s is not a status list credential", ErrInvalidCredential, entry.listID)
+	}
+
+	subject := list.Subjects[0].Claims
+	if subject[fieldType] != TypeStatusList2021 || subject[fieldStatusPurpose] != entry.purpose {
+		return fmt.Errorf("%w: %s is not a %s list", ErrInvalidCredential, entry.listID, entry.purpose)
+	}
+
+	encoded, _ := subject[fieldEncodedList].(string) //nolint:errcheck
+
+	bits, err := DecodeStatusList(encoded)
+	if err != nil {
+		return err
+	}
+
+	set, err := bits.Get(entry.index)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case !set:
+		return nil
+	case entry.purpose == StatusPurposeRevocation:
+		return ErrRevoked
+	case entry.purpose == StatusPurposeSuspension:
+		return ErrSuspended
+	default:
+		return fmt.Errorf("unsupported status purpose: %s", entry.purpose)
+	}
+}
+
+// verifyStatusList verifies a status list credential, in JSON-LD or JWT form. Status lists have no status of
+// their own, so they are verified without a status list loader.
+func verifyStatusList(data []byte, o *options) (*Credential, error) {
+	listOpts := *o
+	listOpts.statusLoader = nil
+
+	if isJWT(data) {
+		cred, _, err := verifyCredentialJWT(string(bytes.TrimSpace(data)), &listOpts)
+
+		return cred, err
+	}
+
+	raw, err := decodeObject(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredential, err)
+	}
+
+	cred, err := credentialFromMap(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	return cred, cred.verify(raw, &listOpts)
+}
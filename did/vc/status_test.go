@@ -0,0 +1,234 @@
+package vc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zRich/zFusion/common/crypto"
+	"github.com/zRich/zFusion/storage/leveldb"
+	"github.com/zRich/zFusion/storage/spi"
+)
+
+const (
+	revocationList = "https://example.com/status/1"
+	suspensionList = "https://example.com/status/2"
+)
+
+type mockStatusListLoader map[string][]byte
+
+func (l mockStatusListLoader) LoadStatusList(url string) ([]byte, error) {
+	data, ok := l[url]
+	if !ok {
+		return nil, fmt.Errorf("status list %s not found", url)
+	}
+
+	return data, nil
+}
+
+func newStatusRegistry(t *testing.T) *StatusRegistry {
+	t.Helper()
+
+	p := leveldb.NewProvider(filepath.Join(t.TempDir(), "db"))
+	t.Cleanup(func() { require.NoError(t, p.Close()) })
+
+	store, err := p.OpenStore(StatusStoreName)
+	require.NoError(t, err)
+
+	return NewStatusRegistry(store)
+}
+
+func TestStatusList(t *testing.T) {
+	list := NewStatusList(DefaultStatusListSize)
+	require.Equal(t, DefaultStatusListSize, list.Len())
+
+	require.NoError(t, list.Set(0, true))
+	require.NoError(t, list.Set(9, true))
+	require.NoError(t, list.Set(DefaultStatusListSize-1, true))
+	require.NoError(t, list.Set(9, false))
+	require.Equal(t, []byte{0x80, 0}, list.bits[:2])
+	require.Error(t, list.Set(DefaultStatusListSize, true))
+
+	encoded, err := list.Encode()
+	require.NoError(t, err)
+	require.Less(t, len(encoded), 200)
+
+	decoded, err := DecodeStatusList(encoded)
+	require.NoError(t, err)
+	require.Equal(t, list, decoded)
+
+	for index, expected := range map[int]bool{0: true, 1: false, 9: false, DefaultStatusListSize - 1: true} {
+		set, err := decoded.Get(index)
+		require.NoError(t, err)
+		require.Equal(t, expected, set, index)
+	}
+
+	_, err = decoded.Get(-1)
+	require.Error(t, err)
+
+	for _, encoded := range []string{"!", "AAAA"} {
+		_, err = DecodeStatusList(encoded)
+		require.Error(t, err)
+	}
+
+	t.Run("maximum size", func(t *testing.T) {
+		encoded, err := NewStatusList(MaxStatusListSize).Encode()
+		require.NoError(t, err)
+
+		decoded, err := DecodeStatusList(encoded)
+		require.NoError(t, err)
+		require.Equal(t, MaxStatusListSize, decoded.Len())
+
+		// a small encodedList decompressing to a list larger than the maximum
+		encoded, err = NewStatusList(MaxStatusListSize + 8).Encode()
+		require.NoError(t, err)
+		require.Less(t, len(encoded), 10000)
+
+		_, err = DecodeStatusList(encoded)
+		require.EqualError(t, err, fmt.Sprintf("decompress status list: more than %d entries", MaxStatusListSize))
+	})
+}
+
+func TestStatusRegistry(t *testing.T) {
+	signer := newSigner(t, crypto.Ed25519)
+	issuerDoc := newDoc(t, issuerDID, signer)
+	registry := newStatusRegistry(t)
+
+	require.NoError(t, registry.CreateList(revocationList, issuerDID, StatusPurposeRevocation, DefaultStatusListSize))
+	require.NoError(t, registry.CreateList(suspensionList, issuerDID, StatusPurposeSuspension, 8))
+	require.ErrorIs(t, registry.CreateList(revocationList, issuerDID, StatusPurposeRevocation, 8),
+		spi.ErrDuplicateKey)
+	require.Error(t, registry.CreateList("https://example.com/status/3", issuerDID, "unknown", 8))
+
+	loader := mockStatusListLoader{}
+	publish := func(listID string) {
+		list, err := registry.IssueStatusList(listID, issuerDoc, "#key-1", signer)
+		require.NoError(t, err)
+
+		loader[listID], err = json.Marshal(list)
+		require.NoError(t, err)
+	}
+
+	publish(revocationList)
+	publish(suspensionList)
+
+	opts := []Option{
+		WithDocumentResolver(mockDocumentResolver{issuerDID: issuerDoc}),
+		WithStatusListLoader(loader),
+	}
+
+	// the ownership credential of an asset is revoked when the asset is transferred.
+	cred := newCredential(ContextV1)
+	require.NoError(t, registry.Allocate(cred, revocationList))
+	require.Contains(t, cred.Context, ContextStatusList2021)
+	require.Equal(t, TypeStatusList2021Entry, cred.Status.Type)
+	require.Equal(t, revocationList, cred.Status.CustomFields["statusListCredential"])
+
+	data := issue(t, cred, signer)
+
+	_, err := VerifyCredential(data, opts...)
+	require.NoError(t, err)
+
+	_, err = VerifyCredential(data, opts[0])
+	require.EqualError(t, err, "no status list loader to check the credential status")
+
+	require.NoError(t, registry.Revoke(cred))
+	require.ErrorIs(t, registry.Suspend(cred), ErrInvalidCredential)
+
+	revoked, err := registry.Status(cred)
+	require.NoError(t, err)
+	require.True(t, revoked)
+
+	// verifiers observe revocations once the list is republished.
+	_, err = VerifyCredential(data, opts...)
+	require.NoError(t, err)
+
+	publish(revocationList)
+
+	_, err = VerifyCredential(data, opts...)
+	require.ErrorIs(t, err, ErrRevoked)
+
+	token, err := IssueJWT(cred, issuerDoc, "#key-1", signer)
+	require.NoError(t, err)
+
+	_, err = VerifyCredentialJWT(token, opts...)
+	require.ErrorIs(t, err, ErrRevoked)
+
+	t.Run("suspension", func(t *testing.T) {
+		cred := newCredential(ContextV1)
+		require.NoError(t, registry.Allocate(cred, suspensionList))
+
+		data := issue(t, cred, signer)
+
+		require.NoError(t, registry.Suspend(cred))
+		publish(suspensionList)
+
+		_, err := VerifyCredential(data, opts...)
+		require.ErrorIs(t, err, ErrSuspended)
+
+		require.NoError(t, registry.Reinstate(cred))
+		publish(suspensionList)
+
+		_, err = VerifyCredential(data, opts...)
+		require.NoError(t, err)
+
+		for i := 1; i < 8; i++ {
+			require.NoError(t, registry.Allocate(newCredential(ContextV1), suspensionList))
+		}
+
+		require.ErrorIs(t, registry.Allocate(newCredential(ContextV1), suspensionList), ErrStatusListFull)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		signed := newCredential(ContextV1)
+		signed.Proofs = cred.Proofs
+		require.ErrorIs(t, registry.Allocate(signed, revocationList), ErrInvalidCredential)
+
+		other := newCredential(ContextV1)
+		other.Issuer.ID = "did:example:other"
+		require.ErrorIs(t, registry.Allocate(other, revocationList), ErrInvalidCredential)
+
+		require.ErrorIs(t, registry.Allocate(newCredential(ContextV1), "https://example.com/unknown"),
+			spi.ErrDataNotFound)
+
+		unallocated := newCredential(ContextV1)
+		unallocated.Status = &TypedID{Type: TypeStatusList2021Entry, CustomFields: map[string]interface{}{
+			"statusPurpose": StatusPurposeRevocation, "statusListIndex": "0", "statusListCredential": revocationList,
+		}}
+		require.Error(t, registry.Revoke(unallocated))
+
+		_, err := registry.IssueStatusList(revocationList, newDoc(t, "did:example:other", signer), "#key-1", signer)
+		require.Error(t, err)
+	})
+
+	t.Run("status list verification", func(t *testing.T) {
+		other := newDoc(t, "did:example:other", signer)
+		otherRegistry := newStatusRegistry(t)
+		require.NoError(t, otherRegistry.CreateList(revocationList, other.ID, StatusPurposeRevocation, 8))
+
+		forged, err := otherRegistry.IssueStatusList(revocationList, other, "#key-1", signer)
+		require.NoError(t, err)
+
+		forgedData, err := json.Marshal(forged)
+		require.NoError(t, err)
+
+		resolver := WithDocumentResolver(mockDocumentResolver{issuerDID: issuerDoc, other.ID: other})
+
+		_, err = VerifyCredential(data, resolver, WithStatusListLoader(mockStatusListLoader{
+			revocationList: forgedData,
+		}))
+		require.ErrorIs(t, err, ErrInvalidCredential)
+
+		_, err = VerifyCredential(data, resolver, WithStatusListLoader(mockStatusListLoader{
+			revocationList: loader[suspensionList],
+		}))
+		require.ErrorIs(t, err, ErrInvalidCredential)
+
+		_, err = VerifyCredential(data, resolver, WithStatusListLoader(mockStatusListLoader{}))
+		require.Error(t, err)
+		require.False(t, errors.Is(err, ErrRevoked))
+	})
+}
@@ -0,0 +1,97 @@
+package vc
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zRich/zFusion/did"
+)
+
+// VerifyCredential parses a credential and verifies it: it must be within its validity period, every proof
+// must be created by an assertion method of the DID document of the issuer, resolved with the resolver set by
+// WithDocumentResolver, its status must be neither revoked nor suspended, and the credential must be valid
+// against its credential schemas.
+//
+// Proofs are verified against data as given, so credentials must be stored and exchanged as issued rather
+// than marshaled again from a parsed Credential.
+func VerifyCredential(data []byte, opts ...Option) (*Credential, error) {
+	raw, err := decodeObject(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredential, err)
+	}
+
+	cred, err := credentialFromMap(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cred.verify(raw, getOptions(opts)); err != nil {
+		return nil, err
+	}
+
+	return cred, nil
+}
+
+// verify verifies the credential parsed from raw.
+func (c *Credential) verify(raw map[string]interface{}, o *options) error {
+	if err := c.checkValidityPeriod(o.now); err != nil {
+		return err
+	}
+
+	if err := c.verifyProofs(raw, o); err != nil {
+		return err
+	}
+
+	if err := c.checkStatus(o); err != nil {
+		return err
+	}
+
+	return validateSchemas(raw, c.Schemas, o.schemaLoader)
+}
+
+func (c *Credential) checkValidityPeriod(now time.Time) error {
+	if c.Issued != nil && now.Before(*c.Issued) {
+		return fmt.Errorf("%w: issued at %s", ErrNotYetValid, formatTime(*c.Issued))
+	}
+
+	if c.Expired != nil && !now.Before(*c.Expired) {
+		return fmt.Errorf("%w: expired at %s", ErrExpired, formatTime(*c.Expired))
+	}
+
+	return nil
+}
+
+func (c *Credential) verifyProofs(raw map[string]interface{}, o *options) error {
+	proofs, err := rawProofs(raw)
+	if err != nil {
+		return err
+	}
+
+	if len(proofs) == 0 {
+		return did.ErrProofNotFound
+	}
+
+	if o.resolver == nil {
+		return errors.New("no document resolver to resolve the credential issuer")
+	}
+
+	issuerDoc, err := o.resolver.ResolveDocument(c.Issuer.ID)
+	if err != nil {
+		return fmt.Errorf("resolve issuer %s: %w", c.Issuer.ID, err)
+	}
+
+	if issuerDoc.ID != c.Issuer.ID {
+		return fmt.Errorf("resolve issuer %s: got DID document %s", c.Issuer.ID, issuerDoc.ID)
+	}
+
+	for i, rawProof := range proofs {
+		expected := &Proof{ProofPurpose: did.PurposeAssertionMethod}
+
+		if err := verifyControllerProof(raw, rawProof, issuerDoc, expected, o.loader); err != nil {
+			return fmt.Errorf("verify proof %s: %w", c.Proofs[i].VerificationMethod, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,428 @@
+package vc
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zRich/zFusion/common/crypto"
+	"github.com/zRich/zFusion/common/crypto/jwk"
+	"github.com/zRich/zFusion/common/crypto/secp256k1"
+	"github.com/zRich/zFusion/common/jsonld"
+	"github.com/zRich/zFusion/did"
+)
+
+const issuerDID = "did:example:issuer"
+
+type mockDocumentResolver map[string]*did.Document
+
+func (r mockDocumentResolver) ResolveDocument(didID string) (*did.Document, error) {
+	doc, ok := r[didID]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+
+	return doc, nil
+}
+
+type mockSchemaLoader map[string]string
+
+func (l mockSchemaLoader) LoadSchema(id string) ([]byte, error) {
+	schema, ok := l[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+
+	return []byte(schema), nil
+}
+
+func newSigner(t *testing.T, keyType crypto.KeyType) crypto.Signer {
+	t.Helper()
+
+	var (
+		privKey interface{}
+		err     error
+	)
+
+	switch keyType {
+	case crypto.Ed25519:
+		_, privKey, err = ed25519.GenerateKey(rand.Reader)
+	case crypto.ECDSAP256:
+		privKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case crypto.ECDSASecp256k1:
+		privKey, err = ecdsa.GenerateKey(secp256k1.S256(), rand.Reader)
+	}
+
+	require.NoError(t, err)
+
+	signer, err := crypto.NewSigner(privKey)
+	require.NoError(t, err)
+
+	return signer
+}
+
+// newDoc creates a document whose key-1 is an assertion method and key-2 an authentication key.
+func newDoc(t *testing.T, didID string, signer crypto.Signer) *did.Document {
+	t.Helper()
+
+	var vms []*did.VerificationMethod
+
+	for _, id := range []string{"#key-1", "#key-2"} {
+		var vm *did.VerificationMethod
+
+		switch signer.KeyType() {
+		case crypto.Ed25519:
+			vm = did.NewVerificationMethodFromBytes(id, "Ed25519VerificationKey2018", didID,
+				signer.PublicKeyBytes())
+		case crypto.ECDSASecp256k1:
+			vm = did.NewVerificationMethodFromBytes(id, "EcdsaSecp256k1VerificationKey2019", didID,
+				signer.PublicKeyBytes())
+		default:
+			j, err := jwk.NewFromBytes(signer.KeyType(), signer.PublicKeyBytes())
+			require.NoError(t, err)
+
+			vm, err = did.NewVerificationMethodFromJWK(id, "JsonWebKey2020", didID, j)
+			require.NoError(t, err)
+		}
+
+		vms = append(vms, vm)
+	}
+
+	doc := did.BuildDoc(
+		did.WithVerificationMethod([]did.VerificationMethod{*vms[0], *vms[1]}),
+		did.WithAssertion([]did.Verification{*did.NewReferencedVerification(vms[0], did.AssertionMethod)}),
+		did.WithAuthentication([]did.Verification{*did.NewReferencedVerification(vms[1], did.Authentication)}),
+	)
+	doc.ID = didID
+
+	return doc
+}
+
+func newCredential(context string) *Credential {
+	return &Credential{
+		Context:       []string{context},
+		CustomContext: []interface{}{map[string]interface{}{"@vocab": "https://example.com/vocab#"}},
+		ID:            "urn:uuid:3978344f-8596-4c3a-a978-8fcaba3903c5",
+		Types:         []string{TypeVerifiableCredential, "AssetOwnershipCredential"},
+		Subjects: []Subject{{ID: "did:example:owner", Claims: map[string]interface{}{
+			"asset": "urn:asset:42",
+			"share": 100,
+		}}},
+	}
+}
+
+func issue(t *testing.T, cred *Credential, signer crypto.Signer, opts ...Option) []byte {
+	t.Helper()
+
+	require.NoError(t, Issue(cred, newDoc(t, issuerDID, signer), "#key-1", signer, opts...))
+
+	data, err := json.Marshal(cred)
+	require.NoError(t, err)
+
+	return data
+}
+
+func TestIssueAndVerify(t *testing.T) {
+	tests := []struct {
+		keyType   crypto.KeyType
+		proofType string
+		context   string
+	}{
+		{crypto.Ed25519, did.Ed25519Signature2018, ""},
+		{crypto.Ed25519, did.Ed25519Signature2020, "https://w3id.org/security/suites/ed25519-2020/v1"},
+		{crypto.Ed25519, did.JSONWebSignature2020, "https://w3id.org/security/suites/jws-2020/v1"},
+		{crypto.ECDSASecp256k1, did.EcdsaSecp256k1Signature2019, ""},
+		{crypto.ECDSAP256, did.JSONWebSignature2020, "https://w3id.org/security/suites/jws-2020/v1"},
+	}
+
+	for _, tc := range tests {
+		for _, base := range []string{ContextV1, ContextV2} {
+			t.Run(tc.proofType+" "+string(tc.keyType)+" "+base, func(t *testing.T) {
+				signer := newSigner(t, tc.keyType)
+				resolver := mockDocumentResolver{issuerDID: newDoc(t, issuerDID, signer)}
+				cred := newCredential(base)
+
+				data := issue(t, cred, signer, WithProofType(tc.proofType))
+
+				require.Equal(t, issuerDID, cred.Issuer.ID)
+				require.Len(t, cred.Proofs, 1)
+				require.Equal(t, issuerDID+"#key-1", cred.Proofs[0].VerificationMethod)
+				require.Equal(t, did.PurposeAssertionMethod, cred.Proofs[0].ProofPurpose)
+
+				switch {
+				case base == ContextV2:
+					require.Len(t, cred.Context, 2)
+				case tc.context == "":
+					require.Equal(t, []string{ContextV1}, cred.Context)
+				default:
+					require.Equal(t, []string{ContextV1, tc.context}, cred.Context)
+				}
+
+				verified, err := VerifyCredential(data, WithDocumentResolver(resolver))
+				require.NoError(t, err)
+				require.Equal(t, cred.ID, verified.ID)
+				require.Equal(t, "urn:asset:42", verified.Subjects[0].Claims["asset"])
+
+				tampered := newCredential(base)
+				tampered.Subjects[0].Claims["share"] = 50
+				tampered.Context = cred.Context
+				tampered.Issuer = cred.Issuer
+				tampered.Issued = cred.Issued
+				tampered.Proofs = cred.Proofs
+
+				data, err = json.Marshal(tampered)
+				require.NoError(t, err)
+
+				_, err = VerifyCredential(data, WithDocumentResolver(resolver))
+				require.ErrorIs(t, err, crypto.ErrInvalidSignature)
+			})
+		}
+	}
+}
+
+func TestIssueErrors(t *testing.T) {
+	signer := newSigner(t, crypto.Ed25519)
+	issuerDoc := newDoc(t, issuerDID, signer)
+
+	cred := newCredential(ContextV1)
+	cred.Issuer.ID = "did:example:other"
+	require.ErrorIs(t, Issue(cred, issuerDoc, "#key-1", signer), ErrInvalidCredential)
+
+	err := Issue(newCredential(ContextV1), issuerDoc, "#key-2", signer)
+	require.ErrorIs(t, err, did.ErrKeyNotAuthorized)
+
+	err = Issue(newCredential(ContextV1), issuerDoc, "#key-3", signer)
+	require.ErrorIs(t, err, did.ErrKeyNotFound)
+
+	err = Issue(newCredential(ContextV1), issuerDoc, "#key-1", newSigner(t, crypto.Ed25519))
+	require.ErrorIs(t, err, crypto.ErrInvalidSignature)
+	require.Contains(t, err.Error(), "signer does not hold the key of did:example:issuer#key-1")
+
+	err = Issue(newCredential(ContextV1), issuerDoc, "#key-1", signer,
+		WithProofType(did.EcdsaSecp256k1Signature2019))
+	require.EqualError(t, err, "EcdsaSecp256k1Signature2019 proofs do not support Ed25519 keys")
+
+	// claims undefined by the credential contexts would not be signed.
+	cred = newCredential(ContextV1)
+	cred.CustomContext = nil
+	err = Issue(cred, issuerDoc, "#key-1", signer, WithProofType(did.Ed25519Signature2020))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `dropped property "asset"`)
+	require.Equal(t, []string{ContextV1}, cred.Context)
+	require.Empty(t, cred.Proofs)
+
+	// adding a proof context would invalidate the existing proofs.
+	cred = newCredential(ContextV1)
+	require.NoError(t, Issue(cred, issuerDoc, "#key-1", signer))
+
+	err = Issue(cred, issuerDoc, "#key-1", signer, WithProofType(did.Ed25519Signature2020))
+	require.EqualError(t, err, "Ed25519Signature2020 proofs require context "+
+		"https://w3id.org/security/suites/ed25519-2020/v1")
+}
+
+func TestVerifyCredentialErrors(t *testing.T) {
+	signer := newSigner(t, crypto.Ed25519)
+	issuerDoc := newDoc(t, issuerDID, signer)
+	resolver := WithDocumentResolver(mockDocumentResolver{issuerDID: issuerDoc})
+
+	t.Run("validity period", func(t *testing.T) {
+		issued := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+		expired := issued.AddDate(1, 0, 0)
+
+		cred := newCredential(ContextV1)
+		cred.Issued = &issued
+		cred.Expired = &expired
+		data := issue(t, cred, signer)
+
+		_, err := VerifyCredential(data, resolver, WithVerificationTime(issued.AddDate(0, 6, 0)))
+		require.NoError(t, err)
+
+		_, err = VerifyCredential(data, resolver, WithVerificationTime(issued.Add(-time.Second)))
+		require.ErrorIs(t, err, ErrNotYetValid)
+
+		_, err = VerifyCredential(data, resolver, WithVerificationTime(expired))
+		require.ErrorIs(t, err, ErrExpired)
+
+		_, err = VerifyCredential(data, resolver)
+		require.ErrorIs(t, err, ErrExpired)
+	})
+
+	t.Run("issuer", func(t *testing.T) {
+		data := issue(t, newCredential(ContextV1), signer)
+
+		_, err := VerifyCredential(data)
+		require.EqualError(t, err, "no document resolver to resolve the credential issuer")
+
+		_, err = VerifyCredential(data, WithDocumentResolver(mockDocumentResolver{}))
+		require.EqualError(t, err, "resolve issuer did:example:issuer: not found")
+
+		// the issuer keys are looked up in the resolved document, not in the one used for issuance.
+		_, err = VerifyCredential(data, WithDocumentResolver(mockDocumentResolver{
+			issuerDID: newDoc(t, issuerDID, newSigner(t, crypto.Ed25519)),
+		}))
+		require.ErrorIs(t, err, crypto.ErrInvalidSignature)
+	})
+
+	t.Run("proof purpose", func(t *testing.T) {
+		cred := newCredential(ContextV1)
+		data := issue(t, cred, signer)
+
+		var raw map[string]interface{}
+
+		require.NoError(t, json.Unmarshal(data, &raw))
+
+		raw["proof"].(map[string]interface{})["proofPurpose"] = did.PurposeAuthentication
+		raw["proof"].(map[string]interface{})["verificationMethod"] = issuerDID + "#key-2"
+
+		data, err := json.Marshal(raw)
+		require.NoError(t, err)
+
+		_, err = VerifyCredential(data, resolver)
+		require.ErrorIs(t, err, did.ErrInvalidProof)
+		require.Contains(t, err.Error(), `proof purpose "authentication"`)
+	})
+
+	t.Run("no proof", func(t *testing.T) {
+		cred := newCredential(ContextV1)
+		cred.Issuer.ID = issuerDID
+		cred.Issued = &time.Time{}
+
+		data, err := json.Marshal(cred)
+		require.NoError(t, err)
+
+		_, err = VerifyCredential(data, resolver)
+		require.ErrorIs(t, err, did.ErrProofNotFound)
+	})
+}
+
+func TestVerifyCredentialSchema(t *testing.T) {
+	const schemaID = "https://example.com/schemas/asset.json"
+
+	schemas := WithSchemaLoader(mockSchemaLoader{schemaID: `{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"type": "object",
+		"required": ["credentialSubject"],
+		"properties": {
+			"credentialSubject": {
+				"type": "object",
+				"required": ["asset", "share"],
+				"properties": {"asset": {"type": "string"}, "share": {"type": "integer", "maximum": 100}}
+			}
+		}
+	}`})
+
+	signer := newSigner(t, crypto.Ed25519)
+	resolver := WithDocumentResolver(mockDocumentResolver{issuerDID: newDoc(t, issuerDID, signer)})
+
+	for _, schemaType := range []string{JSONSchemaValidator2018, JSONSchema} {
+		cred := newCredential(ContextV1)
+		cred.Schemas = []TypedID{{ID: schemaID, Type: schemaType}}
+		data := issue(t, cred, signer)
+
+		_, err := VerifyCredential(data, resolver, schemas)
+		require.NoError(t, err)
+
+		_, err = VerifyCredential(data, resolver)
+		require.EqualError(t, err, "no schema loader to load the credential schemas")
+
+		_, err = VerifyCredential(data, resolver, WithSchemaLoader(mockSchemaLoader{}))
+		require.EqualError(t, err, "load credential schema "+schemaID+": not found")
+	}
+
+	cred := newCredential(ContextV1)
+	cred.Subjects[0].Claims["share"] = 150
+	cred.Schemas = []TypedID{{ID: schemaID, Type: JSONSchemaValidator2018}}
+	data := issue(t, cred, signer)
+
+	_, err := VerifyCredential(data, resolver, schemas)
+	require.ErrorIs(t, err, ErrSchemaValidation)
+	require.Contains(t, err.Error(), "share")
+
+	cred = newCredential(ContextV1)
+	cred.Schemas = []TypedID{{ID: schemaID, Type: "ShaclValidator2017"}}
+	data = issue(t, cred, signer)
+
+	_, err = VerifyCredential(data, resolver, schemas)
+	require.EqualError(t, err, "unsupported credential schema type: ShaclValidator2017")
+}
+
+// TestDocumentProofs checks that DID document proofs verify as proofs of their document, and proofs of a DID
+// document verify as DID document proofs. The documents define the proof terms with their second context.
+func TestDocumentProofs(t *testing.T) {
+	const keyID = issuerDID + "#key-1"
+
+	tests := []struct {
+		proofType string
+		keyType   crypto.KeyType
+		vmType    string
+		context   string
+	}{
+		{did.Ed25519Signature2018, crypto.Ed25519, "Ed25519VerificationKey2018", ContextV1},
+		{did.Ed25519Signature2020, crypto.Ed25519, "Ed25519VerificationKey2020", jsonld.ContextEd25519Signature2020},
+		{
+			did.EcdsaSecp256k1Signature2019, crypto.ECDSASecp256k1, "EcdsaSecp256k1VerificationKey2019",
+			ContextV1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.proofType, func(t *testing.T) {
+			signer := newSigner(t, tc.keyType)
+			loader := jsonld.NewDocumentLoader()
+			created := time.Now().UTC().Truncate(time.Second)
+
+			vm := did.NewVerificationMethodFromBytes(keyID, tc.vmType, issuerDID, signer.PublicKeyBytes())
+			if tc.keyType != crypto.Ed25519 {
+				j, err := jwk.NewFromBytes(signer.KeyType(), signer.PublicKeyBytes())
+				require.NoError(t, err)
+
+				vm, err = did.NewVerificationMethodFromJWK(keyID, tc.vmType, issuerDID, j)
+				require.NoError(t, err)
+			}
+
+			doc := did.BuildDoc(
+				did.WithVerificationMethod([]did.VerificationMethod{*vm}),
+				did.WithAssertion([]did.Verification{*did.NewReferencedVerification(vm, did.AssertionMethod)}),
+			)
+			doc.ID = issuerDID
+			doc.Context = []string{did.ContextV1, tc.context}
+
+			data, err := doc.JSONBytes()
+			require.NoError(t, err)
+
+			raw, err := decodeObject(data)
+			require.NoError(t, err)
+
+			proof := &Proof{
+				Type:               tc.proofType,
+				Created:            &created,
+				VerificationMethod: keyID,
+				ProofPurpose:       did.PurposeAssertionMethod,
+			}
+			require.NoError(t, createProof(raw, signer, vm, proof, loader))
+
+			require.NoError(t, doc.AddProof(signer, keyID, did.WithProofType(tc.proofType),
+				did.WithProofCreated(created)))
+
+			// the DID document proof is a proof of the document.
+			rawProof, err := proofToMap(proof)
+			require.NoError(t, err)
+
+			rawProof[fieldJWS] = doc.Proof[0].JWS
+			rawProof[fieldProofValue] = did.EncodeProofValue(doc.Proof[0].ProofValue, tc.proofType)
+			require.NoError(t, verifyProof(raw, rawProof, vm, loader))
+
+			// and a proof of the document is a DID document proof.
+			doc.Proof[0].JWS = proof.JWS
+			doc.Proof[0].ProofValue, err = did.DecodeProofValue(proof.ProofValue, tc.proofType)
+			require.NoError(t, err)
+			require.NoError(t, doc.VerifyProof())
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package vdr
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/multiformats/go-multibase"
+	"github.com/zRich/zFusion/did"
+)
+
+// DID parameters, see https://www.w3.org/TR/did-core/#did-parameters.
+const (
+	// ServiceParam selects a service of the DID document by ID.
+	ServiceParam = "service"
+	// RelativeRefParam is a relative reference resolved against the endpoint of the selected service.
+	RelativeRefParam = "relativeRef"
+	// VersionIDParam selects a version of the DID document.
+	VersionIDParam = "versionId"
+	// VersionTimeParam selects the version of the DID document valid at a time.
+	VersionTimeParam = "versionTime"
+	// HashLinkParam is the resource hash of the DID document, to add integrity protection.
+	HashLinkParam = "hl"
+)
+
+// Content types of dereferenced resources.
+const (
+	// ContentTypeDIDLDJSON is the content type of DID documents, verification methods and services.
+	ContentTypeDIDLDJSON = "application/did+ld+json"
+	// ContentTypeURIList is the content type of service endpoint URLs.
+	ContentTypeURIList = "text/uri-list"
+)
+
+// InvalidDIDURLError is returned when the dereferenced DID URL is not a valid DID URL.
+const InvalidDIDURLError = "invalidDidUrl"
+
+// ErrHashLinkMismatch is returned when the DID document does not match the hl DID parameter.
+var ErrHashLinkMismatch = errors.New("DID document does not match the hl parameter")
+
+// DereferencingMetadata is the metadata of a dereferencing.
+type DereferencingMetadata struct {
+	ContentType string `json:"contentType,omitempty"`
+}
+
+// DereferencingResult is the result of the dereferencing of a DID URL. Content is a *did.Document, a
+// *did.VerificationMethod, a *did.Service, or the URL string of a service endpoint.
+type DereferencingResult struct {
+	Context               did.Context
+	Content               interface{}
+	ContentMetadata       *did.DocumentMetadata
+	DereferencingMetadata *DereferencingMetadata
+}
+
+// Dereference dereferences a DID URL with the DID method of its DID, see Dereference.
+func (r *Registry) Dereference(didURL string, opts ...DIDMethodOption) (*DereferencingResult, error) {
+	return Dereference(r, didURL, opts...)
+}
+
+// Dereference dereferences a DID URL, see https://w3c-ccg.github.io/did-resolution/#dereferencing. The DID is
+// resolved by resolver with the versionId and versionTime DID parameters, which must be honored by the DID
+// method, and the document must match the hl parameter. Then:
+//   - the service parameter selects a service by ID, whose endpoint URL is returned after resolving the
+//     relativeRef parameter against it and appending the fragment;
+//   - otherwise the fragment selects a verification method or a service by ID;
+//   - otherwise the DID document is returned.
+//
+// Paths are method specific and not supported. Errors are reported as *ResolutionError.
+func Dereference(resolver Resolver, didURL string, opts ...DIDMethodOption) (*DereferencingResult, error) {
+	parsed, err := did.ParseDIDURL(didURL)
+	if err != nil {
+		return nil, &ResolutionError{Code: InvalidDIDURLError, DID: didURL,
+			Err: fmt.Errorf("%w: %v", ErrInvalidDID, err)}
+	}
+
+	if parsed.Path != "" && parsed.Path != "/" {
+		return nil, &ResolutionError{Code: NotFoundError, DID: didURL,
+			Err: fmt.Errorf("%w: dereferencing path %s is not supported", ErrNotFound, parsed.Path)}
+	}
+
+	docResolution, err := resolver.Resolve(resolutionInput(parsed), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkDIDParameters(parsed, docResolution); err != nil {
+		return nil, &ResolutionError{Code: NotFoundError, DID: didURL, Err: err}
+	}
+
+	result := &DereferencingResult{
+		Context:               docResolution.Context,
+		ContentMetadata:       docResolution.DocumentMetadata,
+		DereferencingMetadata: &DereferencingMetadata{ContentType: ContentTypeDIDLDJSON},
+	}
+
+	doc := docResolution.DIDDocument
+
+	switch {
+	case len(parsed.Queries[ServiceParam]) > 0:
+		endpoint, err := serviceEndpointURL(doc, parsed)
+		if err != nil {
+			return nil, &ResolutionError{Code: NotFoundError, DID: didURL, Err: err}
+		}
+
+		result.Content = endpoint
+		result.DereferencingMetadata.ContentType = ContentTypeURIList
+	case parsed.Fragment != "":
+		resource, ok := lookupFragment(doc, parsed.Fragment)
+		if !ok {
+			return nil, &ResolutionError{Code: NotFoundError, DID: didURL,
+				Err: fmt.Errorf("%w: no verification method or service #%s", ErrNotFound, parsed.Fragment)}
+		}
+
+		result.Content = resource
+	default:
+		result.Content = doc
+	}
+
+	return result, nil
+}
+
+// resolutionInput returns the DID of didURL with the DID parameters which select the resolved document.
+func resolutionInput(didURL *did.DIDURL) string {
+	query := url.Values{}
+
+	for _, param := range []string{VersionIDParam, VersionTimeParam} {
+		if values, ok := didURL.Queries[param]; ok {
+			query[param] = values
+		}
+	}
+
+	if len(query) == 0 {
+		return didURL.DID.String()
+	}
+
+	return didURL.DID.String() + "?" + query.Encode()
+}
+
+// checkDIDParameters checks that the resolved document is the version selected by the DID parameters.
+func checkDIDParameters(didURL *did.DIDURL, docResolution *did.DocResolution) error {
+	if versionID, ok := didURL.Queries[VersionIDParam]; ok &&
+		(docResolution.DocumentMetadata == nil || docResolution.DocumentMetadata.VersionID != versionID[0]) {
+		return fmt.Errorf("%w: version %s", ErrNotFound, versionID[0])
+	}
+
+	if _, ok := didURL.Queries[VersionTimeParam]; ok &&
+		(docResolution.DocumentMetadata == nil || docResolution.DocumentMetadata.VersionID == "") {
+		return fmt.Errorf("%w: the DID method does not support %s", ErrNotFound, VersionTimeParam)
+	}
+
+	if hl, ok := didURL.Queries[HashLinkParam]; ok {
+		expected, err := HashLink(docResolution.DIDDocument)
+		if err != nil {
+			return err
+		}
+
+		if hl[0] != expected {
+			return fmt.Errorf("%w: %s", ErrHashLinkMismatch, hl[0])
+		}
+	}
+
+	return nil
+}
+
+// HashLink returns the value of the hl DID parameter identifying doc: the base58btc multibase encoding of the
+// sha2-256 multihash of its JSON serialization.
+func HashLink(doc *did.Document) (string, error) {
+	data, err := doc.JSONBytes()
+	if err != nil {
+		return "", fmt.Errorf("marshal DID document: %w", err)
+	}
+
+	digest := sha256.Sum256(data)
+
+	// multihash header: sha2-256 code, digest length.
+	multihash := append([]byte{0x12, sha256.Size}, digest[:]...)
+
+	return multibase.Encode(multibase.Base58BTC, multihash)
+}
+
+// serviceEndpointURL returns the endpoint URL of the service selected by the service parameter of didURL, with
+// its relativeRef parameter and fragment applied, see
+// https://w3c-ccg.github.io/did-resolution/#service-endpoint-construction.
+func serviceEndpointURL(doc *did.Document, didURL *did.DIDURL) (string, error) {
+	name := didURL.Queries[ServiceParam][0]
+
+	service, ok := did.LookupServiceByID("#"+name, doc)
+	if !ok {
+		return "", fmt.Errorf("%w: no service %s", ErrNotFound, name)
+	}
+
+	uri, err := service.ServiceEndpoint.URI()
+	if err != nil {
+		return "", fmt.Errorf("service %s: %w", name, err)
+	}
+
+	endpoint, err := url.Parse(uri)
+	if err != nil {
+		return "", fmt.Errorf("service %s: invalid endpoint: %w", name, err)
+	}
+
+	if relativeRef, ok := didURL.Queries[RelativeRefParam]; ok {
+		ref, err := url.Parse(relativeRef[0])
+		if err != nil || ref.IsAbs() {
+			return "", fmt.Errorf("invalid %s %s", RelativeRefParam, relativeRef[0])
+		}
+
+		endpoint = endpoint.ResolveReference(ref)
+	}
+
+	if didURL.Fragment != "" {
+		endpoint.Fragment = didURL.Fragment
+	}
+
+	return endpoint.String(), nil
+}
+
+// lookupFragment returns the verification method, possibly embedded in a verification relationship, or the
+// service of doc identified by fragment.
+func lookupFragment(doc *did.Document, fragment string) (interface{}, bool) {
+	if vm, ok := did.LookupPublicKey("#"+fragment, doc); ok {
+		return vm, true
+	}
+
+	return did.LookupServiceByID("#"+fragment, doc)
+}
@@ -0,0 +1,151 @@
+package vdr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zRich/zFusion/did"
+)
+
+const dereferencedDoc = `{
+  "@context": ["https://www.w3.org/ns/did/v1"],
+  "id": "did:example:123",
+  "verificationMethod": [{
+    "id": "did:example:123#key-1",
+    "type": "Ed25519VerificationKey2018",
+    "controller": "did:example:123",
+    "publicKeyBase58": "H3C2AVvLMv6gmMNam3uVAjZpfkcJCwDwnZn6z3wXmqPV"
+  }],
+  "authentication": [{
+    "id": "did:example:123#auth",
+    "type": "Ed25519VerificationKey2018",
+    "controller": "did:example:123",
+    "publicKeyBase58": "4zvwRjXUKGfvwnParsHAS3HuSVzV5cA4McphgmoCtajS"
+  }],
+  "service": [{
+    "id": "did:example:123#files",
+    "type": "LinkedDomains",
+    "serviceEndpoint": "https://files.example.com/base/"
+  }, {
+    "id": "#hub",
+    "type": "IdentityHub",
+    "serviceEndpoint": "https://hub.example.com"
+  }]
+}`
+
+func TestDereference(t *testing.T) {
+	doc, err := did.ParseDocument([]byte(dereferencedDoc))
+	require.NoError(t, err)
+
+	registry := New(WithVDR(&mockVDR{
+		method: "example",
+		readFunc: func(didID string, opts ...DIDMethodOption) (*did.DocResolution, error) {
+			didURL, err := did.ParseDIDURL(didID)
+			if err != nil {
+				return nil, err
+			}
+
+			versionID := "2"
+			if v, ok := didURL.Queries[VersionIDParam]; ok {
+				if v[0] != "1" && v[0] != "2" {
+					return nil, ErrNotFound
+				}
+
+				versionID = v[0]
+			}
+
+			if didURL.Fragment != "" || len(didURL.Queries[ServiceParam]) > 0 {
+				return nil, ErrInvalidDID
+			}
+
+			return &did.DocResolution{DIDDocument: doc, DocumentMetadata: &did.DocumentMetadata{VersionID: versionID}}, nil
+		},
+	}))
+
+	t.Run("DID document", func(t *testing.T) {
+		result, err := registry.Dereference("did:example:123")
+		require.NoError(t, err)
+		require.Equal(t, doc, result.Content)
+		require.Equal(t, ContextDIDResolution, result.Context)
+		require.Equal(t, ContentTypeDIDLDJSON, result.DereferencingMetadata.ContentType)
+		require.Equal(t, "2", result.ContentMetadata.VersionID)
+
+		result, err = registry.Dereference("did:example:123?versionId=1")
+		require.NoError(t, err)
+		require.Equal(t, "1", result.ContentMetadata.VersionID)
+
+		hl, err := HashLink(doc)
+		require.NoError(t, err)
+		require.Equal(t, byte('z'), hl[0])
+
+		result, err = registry.Dereference("did:example:123?hl=" + hl)
+		require.NoError(t, err)
+		require.Equal(t, doc, result.Content)
+	})
+
+	t.Run("verification methods and services", func(t *testing.T) {
+		for didURL, expected := range map[string]string{
+			"did:example:123#key-1":             "did:example:123#key-1",
+			"did:example:123#auth":              "did:example:123#auth",
+			"did:example:123?versionId=1#key-1": "did:example:123#key-1",
+		} {
+			result, err := registry.Dereference(didURL)
+			require.NoError(t, err, didURL)
+			require.Equal(t, expected, result.Content.(*did.VerificationMethod).ID, didURL)
+			require.Equal(t, ContentTypeDIDLDJSON, result.DereferencingMetadata.ContentType)
+		}
+
+		result, err := registry.Dereference("did:example:123#hub")
+		require.NoError(t, err)
+		require.Equal(t, "did:example:123#hub", result.Content.(*did.Service).ID)
+	})
+
+	t.Run("service endpoints", func(t *testing.T) {
+		for didURL, expected := range map[string]string{
+			"did:example:123?service=files":                                 "https://files.example.com/base/",
+			"did:example:123?service=files&relativeRef=%2Fresume.pdf":       "https://files.example.com/resume.pdf",
+			"did:example:123?service=files&relativeRef=cv%2Fresume.pdf":     "https://files.example.com/base/cv/resume.pdf",
+			"did:example:123?service=files&relativeRef=a%3Fb%3Dc#section-2": "https://files.example.com/base/a?b=c#section-2",
+			"did:example:123?service=hub&versionId=1":                       "https://hub.example.com",
+		} {
+			result, err := registry.Dereference(didURL)
+			require.NoError(t, err, didURL)
+			require.Equal(t, expected, result.Content, didURL)
+			require.Equal(t, ContentTypeURIList, result.DereferencingMetadata.ContentType)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		for didURL, code := range map[string]string{
+			"did:example":                        InvalidDIDURLError,
+			"did:example:123#key-2":              NotFoundError,
+			"did:example:123?service=missing":    NotFoundError,
+			"did:example:123?versionId=3":        NotFoundError,
+			"did:example:123/path":               NotFoundError,
+			"did:example:123?hl=zQmWvQxTqbG2Z9H": NotFoundError,
+			"did:other:123#key-1":                MethodNotSupportedError,
+			"did:example:123?service=files&relativeRef=https%3A%2F%2Fevil.example.com": NotFoundError,
+		} {
+			_, err := registry.Dereference(didURL)
+			require.Error(t, err, didURL)
+			require.Equal(t, code, ErrorCode(err), didURL)
+		}
+
+		_, err := registry.Dereference("did:example:123?hl=zQmWvQxTqbG2Z9H")
+		require.ErrorIs(t, err, ErrHashLinkMismatch)
+	})
+
+	t.Run("DID methods without versions", func(t *testing.T) {
+		registry := New(WithVDR(&mockVDR{
+			method: "example",
+			readFunc: func(string, ...DIDMethodOption) (*did.DocResolution, error) {
+				return &did.DocResolution{DIDDocument: doc}, nil
+			},
+		}))
+
+		for _, didURL := range []string{"did:example:123?versionId=1", "did:example:123?versionTime=2022-01-01T00:00:00Z"} {
+			_, err := registry.Dereference(didURL)
+			require.ErrorIs(t, err, ErrNotFound, didURL)
+		}
+	})
+}
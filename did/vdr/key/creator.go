@@ -0,0 +1,129 @@
+package key
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zRich/zFusion/did"
+	"github.com/zRich/zFusion/did/vdr"
+)
+
+// Create creates a did:key document from the first verification method of the given document. Its type
+// selects the key multicodec, see keyTypeCodes.
+func (v *VDR) Create(didDoc *did.Document, _ ...vdr.DIDMethodOption) (*did.DocResolution, error) {
+	if len(didDoc.VerificationMethod) == 0 {
+		return nil, errors.New("missing verification method")
+	}
+
+	fingerprint, err := VerificationMethodFingerprint(&didDoc.VerificationMethod[0])
+	if err != nil {
+		return nil, err
+	}
+
+	didKey := fmt.Sprintf("did:key:%s", fingerprint)
+
+	doc, err := CreateDocument(didKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &did.DocResolution{Context: vdr.ContextDIDResolution, DIDDocument: doc}, nil
+}
+
+// CreateDocument deterministically expands a did:key into its DID document.
+func CreateDocument(didKey string) (*did.Document, error) {
+	parsed, err := did.Parse(didKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", vdr.ErrInvalidDID, err)
+	}
+
+	if parsed.Method != DIDMethod {
+		return nil, fmt.Errorf("%w: not a did:key: %s", vdr.ErrInvalidDID, didKey)
+	}
+
+	fingerprint := parsed.MethodSpecificID
+
+	pubKey, code, err := PubKeyFromFingerprint(fingerprint)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", vdr.ErrInvalidDID, err)
+	}
+
+	if err = validateKey(code, pubKey); err != nil {
+		return nil, fmt.Errorf("%w: %v", vdr.ErrInvalidDID, err)
+	}
+
+	keyID := fmt.Sprintf("%s#%s", didKey, fingerprint)
+
+	switch code {
+	case ED25519PubKeyMultiCodec:
+		return createEd25519Document(didKey, keyID, pubKey)
+	case X25519PubKeyMultiCodec:
+		vm := did.NewVerificationMethodFromBytes(keyID, X25519KeyAgreementKey2019, didKey, pubKey)
+
+		return createDoc(didKey, []string{did.ContextV1, x25519Context}, nil, vm), nil
+	case Secp256k1PubKeyMultiCodec:
+		vm := did.NewVerificationMethodFromBytes(keyID, EcdsaSecp256k1VerificationKey2019, didKey, pubKey)
+
+		return createDoc(didKey, []string{did.ContextV1, secp256k1Context}, vm, vm), nil
+	default: // P256PubKeyMultiCodec, as validated above
+		vm := did.NewVerificationMethodFromBytes(keyID, EcdsaSecp256r1VerificationKey2019, didKey, pubKey)
+
+		return createDoc(didKey, []string{did.ContextV1}, vm, vm), nil
+	}
+}
+
+func createEd25519Document(didKey, keyID string, pubKey []byte) (*did.Document, error) {
+	x25519Key, err := ed25519PublicKeyToX25519(pubKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", vdr.ErrInvalidDID, err)
+	}
+
+	keyAgreementID := fmt.Sprintf("%s#%s", didKey, KeyFingerprint(X25519PubKeyMultiCodec, x25519Key))
+
+	vm := did.NewVerificationMethodFromBytes(keyID, Ed25519VerificationKey2018, didKey, pubKey)
+	keyAgreement := did.NewVerificationMethodFromBytes(keyAgreementID, X25519KeyAgreementKey2019, didKey,
+		x25519Key)
+
+	return createDoc(didKey, []string{did.ContextV1, ed25519Context, x25519Context}, vm, keyAgreement), nil
+}
+
+// createDoc builds a did:key document. signingKey is referenced by all verification relationships
+// except keyAgreement; keyAgreement is embedded when it is a different key than signingKey.
+func createDoc(didKey string, context []string, signingKey,
+	keyAgreement *did.VerificationMethod) *did.Document {
+	var (
+		vms                                     []did.VerificationMethod
+		auth, assertion, capDel, capInv, keyAgr []did.Verification
+	)
+
+	if signingKey != nil {
+		vms = append(vms, *signingKey)
+		auth = []did.Verification{*did.NewReferencedVerification(signingKey, did.Authentication)}
+		assertion = []did.Verification{*did.NewReferencedVerification(signingKey, did.AssertionMethod)}
+		capDel = []did.Verification{*did.NewReferencedVerification(signingKey, did.CapabilityDelegation)}
+		capInv = []did.Verification{*did.NewReferencedVerification(signingKey, did.CapabilityInvocation)}
+	}
+
+	switch {
+	case signingKey == nil:
+		vms = append(vms, *keyAgreement)
+		keyAgr = []did.Verification{*did.NewReferencedVerification(keyAgreement, did.KeyAgreement)}
+	case keyAgreement.ID == signingKey.ID:
+		keyAgr = []did.Verification{*did.NewReferencedVerification(keyAgreement, did.KeyAgreement)}
+	default:
+		keyAgr = []did.Verification{*did.NewEmbeddedVerification(keyAgreement, did.KeyAgreement)}
+	}
+
+	doc := did.BuildDoc(
+		did.WithVerificationMethod(vms),
+		did.WithAuthentication(auth),
+		did.WithAssertion(assertion),
+		did.WithCapabilityDelegation(capDel),
+		did.WithCapabilityInvocation(capInv),
+		did.WithKeyAgreement(keyAgr),
+	)
+	doc.Context = context
+	doc.ID = didKey
+
+	return doc
+}
@@ -0,0 +1,61 @@
+package key
+
+import (
+	"crypto/elliptic"
+	"errors"
+	"math/big"
+)
+
+func unmarshalP256(pubKey []byte) (*big.Int, *big.Int) {
+	if len(pubKey) > 0 && pubKey[0] == 4 {
+		return elliptic.Unmarshal(elliptic.P256(), pubKey) //nolint:staticcheck
+	}
+
+	return elliptic.UnmarshalCompressed(elliptic.P256(), pubKey)
+}
+
+// ed25519PublicKeyToX25519 converts an Ed25519 public key to the X25519 public key of the same secret,
+// using the birational map u = (1 + y) / (1 - y) from the twisted Edwards curve to Curve25519.
+func ed25519PublicKeyToX25519(pubKey []byte) ([]byte, error) {
+	const keySize = 32
+
+	if len(pubKey) != keySize {
+		return nil, errors.New("invalid Ed25519 public key size")
+	}
+
+	p := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+
+	le := make([]byte, keySize)
+	copy(le, pubKey)
+	le[keySize-1] &= 0x7f // clear the sign bit of x
+
+	y := new(big.Int).SetBytes(reverse(le))
+	if y.Cmp(p) >= 0 {
+		return nil, errors.New("invalid Ed25519 public key")
+	}
+
+	one := big.NewInt(1)
+	num := new(big.Int).Add(one, y)
+	den := new(big.Int).Sub(one, y)
+	den.Mod(den, p)
+
+	if den.Sign() == 0 {
+		return nil, errors.New("invalid Ed25519 public key")
+	}
+
+	u := num.Mul(num, den.ModInverse(den, p))
+	u.Mod(u, p)
+
+	out := make([]byte, keySize)
+	u.FillBytes(out)
+
+	return reverse(out), nil
+}
+
+func reverse(b []byte) []byte {
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+
+	return b
+}
@@ -0,0 +1,184 @@
+package key
+
+import (
+	"crypto/elliptic"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/zRich/zFusion/common/crypto/secp256k1"
+	"github.com/zRich/zFusion/common/multicodec"
+	"github.com/zRich/zFusion/did"
+)
+
+// Multicodec codes of the public key types supported by did:key.
+// See https://github.com/multiformats/multicodec/blob/master/table.csv.
+const (
+	X25519PubKeyMultiCodec    = uint64(multicodec.X25519Pub)
+	ED25519PubKeyMultiCodec   = uint64(multicodec.Ed25519Pub)
+	Secp256k1PubKeyMultiCodec = uint64(multicodec.Secp256k1Pub)
+	P256PubKeyMultiCodec      = uint64(multicodec.P256Pub)
+
+	maxMulticodecBytes = 9
+)
+
+// CreateDIDKey creates a did:key from an Ed25519 public key.
+func CreateDIDKey(pubKey []byte) (string, string) {
+	return CreateDIDKeyByCode(ED25519PubKeyMultiCodec, pubKey)
+}
+
+// CreateDIDKeyByCode creates a did:key and the ID of its key from the given multicodec code and public key.
+func CreateDIDKeyByCode(code uint64, pubKey []byte) (string, string) {
+	methodID := KeyFingerprint(code, pubKey)
+	didKey := fmt.Sprintf("did:key:%s", methodID)
+	keyID := fmt.Sprintf("%s#%s", didKey, methodID)
+
+	return didKey, keyID
+}
+
+// KeyFingerprint generates a multicodec, base58btc multibase encoded fingerprint of the public key.
+func KeyFingerprint(code uint64, pubKeyValue []byte) string {
+	multicodecValue := multicodecPrefix(code)
+	mcLength := len(multicodecValue)
+	buf := make([]uint8, mcLength+len(pubKeyValue))
+	copy(buf, multicodecValue)
+	copy(buf[mcLength:], pubKeyValue)
+
+	return fmt.Sprintf("z%s", base58.Encode(buf))
+}
+
+func multicodecPrefix(code uint64) []byte {
+	buf := make([]byte, maxMulticodecBytes)
+	bw := binary.PutUvarint(buf, code)
+
+	return buf[:bw]
+}
+
+// PubKeyFromFingerprint extracts the raw public key and its multicodec code from a did:key fingerprint.
+func PubKeyFromFingerprint(fingerprint string) ([]byte, uint64, error) {
+	if len(fingerprint) < 2 || fingerprint[0] != 'z' {
+		return nil, 0, errors.New("unknown key encoding")
+	}
+
+	mc := base58.Decode(fingerprint[1:]) // skip leading "z"
+
+	code, br := binary.Uvarint(mc)
+	if br <= 0 {
+		return nil, 0, errors.New("unknown key encoding")
+	}
+
+	if br > maxMulticodecBytes {
+		return nil, 0, errors.New("code exceeds maximum size")
+	}
+
+	return mc[br:], code, nil
+}
+
+// PubKeyFromDIDKey parses a did:key string and returns its raw public key.
+func PubKeyFromDIDKey(didKey string) ([]byte, error) {
+	id, err := did.Parse(didKey)
+	if err != nil {
+		return nil, fmt.Errorf("pubKeyFromDIDKey: %w", err)
+	}
+
+	pubKey, code, err := PubKeyFromFingerprint(id.MethodSpecificID)
+	if err != nil {
+		return nil, fmt.Errorf("pubKeyFromDIDKey: %w", err)
+	}
+
+	if err = validateKey(code, pubKey); err != nil {
+		return nil, fmt.Errorf("pubKeyFromDIDKey: %w", err)
+	}
+
+	return pubKey, nil
+}
+
+// normalizeKey brings EC public keys into their compressed form as required by the did:key spec.
+func normalizeKey(code uint64, pubKey []byte) ([]byte, error) {
+	switch code {
+	case Secp256k1PubKeyMultiCodec:
+		x, y, err := secp256k1.Unmarshal(pubKey)
+		if err != nil {
+			return nil, err
+		}
+
+		return secp256k1.MarshalCompressed(x, y), nil
+	case P256PubKeyMultiCodec:
+		x, y := unmarshalP256(pubKey)
+		if x == nil {
+			return nil, errors.New("invalid P-256 public key")
+		}
+
+		return elliptic.MarshalCompressed(elliptic.P256(), x, y), nil
+	}
+
+	return pubKey, validateKey(code, pubKey)
+}
+
+func validateKey(code uint64, pubKey []byte) error {
+	const curve25519KeySize = 32
+
+	switch code {
+	case ED25519PubKeyMultiCodec, X25519PubKeyMultiCodec:
+		if len(pubKey) != curve25519KeySize {
+			return fmt.Errorf("invalid key size %d for multicodec 0x%x", len(pubKey), code)
+		}
+	case Secp256k1PubKeyMultiCodec:
+		if _, _, err := secp256k1.Unmarshal(pubKey); err != nil {
+			return err
+		}
+	case P256PubKeyMultiCodec:
+		if x, _ := unmarshalP256(pubKey); x == nil {
+			return errors.New("invalid P-256 public key")
+		}
+	default:
+		return fmt.Errorf("unsupported key multicodec code [0x%x]", code)
+	}
+
+	return nil
+}
+
+// VerificationMethodFingerprint returns the fingerprint of the public key of a verification method. Its type
+// selects the key multicodec, see keyTypeCodes.
+func VerificationMethodFingerprint(vm *did.VerificationMethod) (string, error) {
+	code, ok := keyTypeCodes[vm.Type]
+	if !ok {
+		return "", fmt.Errorf("not supported public key type: %s", vm.Type)
+	}
+
+	pubKey, err := normalizeKey(code, vm.Value)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s public key: %w", vm.Type, err)
+	}
+
+	return KeyFingerprint(code, pubKey), nil
+}
+
+// VerificationMethodFromFingerprint decodes a fingerprint into a verification method with the given ID and
+// controller, typed after the key multicodec.
+func VerificationMethodFromFingerprint(id, controller, fingerprint string) (*did.VerificationMethod, error) {
+	pubKey, code, err := PubKeyFromFingerprint(fingerprint)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = validateKey(code, pubKey); err != nil {
+		return nil, err
+	}
+
+	var vmType string
+
+	switch code {
+	case ED25519PubKeyMultiCodec:
+		vmType = Ed25519VerificationKey2018
+	case X25519PubKeyMultiCodec:
+		vmType = X25519KeyAgreementKey2019
+	case Secp256k1PubKeyMultiCodec:
+		vmType = EcdsaSecp256k1VerificationKey2019
+	default: // P256PubKeyMultiCodec, as validated above
+		vmType = EcdsaSecp256r1VerificationKey2019
+	}
+
+	return did.NewVerificationMethodFromBytes(id, vmType, controller, pubKey), nil
+}
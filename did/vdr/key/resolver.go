@@ -0,0 +1,27 @@
+package key
+
+import (
+	"fmt"
+
+	"github.com/zRich/zFusion/did"
+	"github.com/zRich/zFusion/did/vdr"
+)
+
+// Read expands did:key into a DID document.
+func (v *VDR) Read(didKey string, _ ...vdr.DIDMethodOption) (*did.DocResolution, error) {
+	didURL, err := did.ParseDIDURL(didKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", vdr.ErrInvalidDID, err)
+	}
+
+	doc, err := CreateDocument(didURL.DID.String())
+	if err != nil {
+		return nil, fmt.Errorf("resolve did:key: %w", err)
+	}
+
+	return &did.DocResolution{
+		Context:          vdr.ContextDIDResolution,
+		DIDDocument:      doc,
+		DocumentMetadata: &did.DocumentMetadata{},
+	}, nil
+}
@@ -0,0 +1,65 @@
+// Package key implements the did:key method, see https://w3c-ccg.github.io/did-method-key/.
+package key
+
+import (
+	"errors"
+
+	"github.com/zRich/zFusion/did"
+	"github.com/zRich/zFusion/did/vdr"
+)
+
+const (
+	// DIDMethod did method.
+	DIDMethod = "key"
+
+	// Ed25519VerificationKey2018 verification method type of Ed25519 keys.
+	Ed25519VerificationKey2018 = "Ed25519VerificationKey2018"
+	// X25519KeyAgreementKey2019 verification method type of X25519 keys.
+	X25519KeyAgreementKey2019 = "X25519KeyAgreementKey2019"
+	// EcdsaSecp256k1VerificationKey2019 verification method type of secp256k1 keys.
+	EcdsaSecp256k1VerificationKey2019 = "EcdsaSecp256k1VerificationKey2019"
+	// EcdsaSecp256r1VerificationKey2019 verification method type of P-256 keys.
+	EcdsaSecp256r1VerificationKey2019 = "EcdsaSecp256r1VerificationKey2019"
+
+	ed25519Context   = "https://w3id.org/security/suites/ed25519-2018/v1"
+	x25519Context    = "https://w3id.org/security/suites/x25519-2019/v1"
+	secp256k1Context = "https://w3id.org/security/suites/secp256k1-2019/v1"
+)
+
+// VDR implements the did:key method. did:key documents are derived from the DID itself, so the VDR
+// does not need any storage.
+type VDR struct{}
+
+// New returns a new did:key VDR.
+func New() *VDR {
+	return &VDR{}
+}
+
+// Accept accepts the did:key method.
+func (v *VDR) Accept(method string, _ ...vdr.DIDMethodOption) bool {
+	return method == DIDMethod
+}
+
+// Update is not supported by did:key.
+func (v *VDR) Update(*did.Document, ...vdr.DIDMethodOption) error {
+	return errors.New("not supported")
+}
+
+// Deactivate is not supported by did:key.
+func (v *VDR) Deactivate(string, ...vdr.DIDMethodOption) error {
+	return errors.New("not supported")
+}
+
+// Close frees resources being maintained by VDR.
+func (v *VDR) Close() error {
+	return nil
+}
+
+// keyTypeCodes maps verification method types to multicodec codes.
+var keyTypeCodes = map[string]uint64{ //nolint:gochecknoglobals
+	Ed25519VerificationKey2018:        ED25519PubKeyMultiCodec,
+	"Ed25519VerificationKey2020":      ED25519PubKeyMultiCodec,
+	X25519KeyAgreementKey2019:         X25519PubKeyMultiCodec,
+	EcdsaSecp256k1VerificationKey2019: Secp256k1PubKeyMultiCodec,
+	EcdsaSecp256r1VerificationKey2019: P256PubKeyMultiCodec,
+}
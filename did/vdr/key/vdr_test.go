@@ -0,0 +1,195 @@
+package key
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zRich/zFusion/common/crypto/secp256k1"
+	"github.com/zRich/zFusion/did"
+	"github.com/zRich/zFusion/did/vdr"
+)
+
+const (
+	didKeyEd25519   = "did:key:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK"
+	didKeyX25519    = "did:key:z6LSeu9HkTHSfLLeUs2nnzUSNedgDUevfNQgQjQC23ZCit6F"
+	didKeySecp256k1 = "did:key:zQ3shokFTS3brHcDQrn82RUDfCZESWL1ZdCEJwekUDPQiYBme"
+	didKeyP256      = "did:key:zDnaerDaTF5BXEavCrfRZEk316dpbLsfPDZ3WJ5hRTPFU2169"
+
+	ed25519KeyAgreementID = didKeyEd25519 + "#z6LSj72tK8brWgZja8NLRwPigth2T9QRiG1uH9oKZuKjdh9p"
+)
+
+func TestRead(t *testing.T) {
+	v := New()
+
+	t.Run("Ed25519", func(t *testing.T) {
+		docResolution, err := v.Read(didKeyEd25519)
+		require.NoError(t, err)
+
+		doc := docResolution.DIDDocument
+		require.Equal(t, didKeyEd25519, doc.ID)
+		require.Len(t, doc.VerificationMethod, 1)
+
+		keyID := didKeyEd25519 + "#z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK"
+		require.Equal(t, keyID, doc.VerificationMethod[0].ID)
+		require.Equal(t, Ed25519VerificationKey2018, doc.VerificationMethod[0].Type)
+		require.Len(t, doc.VerificationMethod[0].Value, ed25519.PublicKeySize)
+
+		for _, relationship := range [][]did.Verification{
+			doc.Authentication, doc.AssertionMethod, doc.CapabilityDelegation, doc.CapabilityInvocation,
+		} {
+			require.Len(t, relationship, 1)
+			require.Equal(t, keyID, relationship[0].VerificationMethod.ID)
+			require.False(t, relationship[0].Embedded)
+		}
+
+		require.Len(t, doc.KeyAgreement, 1)
+		require.True(t, doc.KeyAgreement[0].Embedded)
+		require.Equal(t, ed25519KeyAgreementID, doc.KeyAgreement[0].VerificationMethod.ID)
+		require.Equal(t, X25519KeyAgreementKey2019, doc.KeyAgreement[0].VerificationMethod.Type)
+
+		// the expanded document is a valid DID document
+		docBytes, err := doc.JSONBytes()
+		require.NoError(t, err)
+
+		parsed, err := did.ParseDocument(docBytes)
+		require.NoError(t, err)
+		require.Equal(t, doc.VerificationMethod[0].Value, parsed.VerificationMethod[0].Value)
+		require.Equal(t, ed25519KeyAgreementID, parsed.KeyAgreement[0].VerificationMethod.ID)
+	})
+
+	t.Run("X25519", func(t *testing.T) {
+		docResolution, err := v.Read(didKeyX25519)
+		require.NoError(t, err)
+
+		doc := docResolution.DIDDocument
+		require.Len(t, doc.VerificationMethod, 1)
+		require.Equal(t, X25519KeyAgreementKey2019, doc.VerificationMethod[0].Type)
+		require.Len(t, doc.KeyAgreement, 1)
+		require.Empty(t, doc.Authentication)
+		require.Empty(t, doc.AssertionMethod)
+	})
+
+	t.Run("secp256k1", func(t *testing.T) {
+		docResolution, err := v.Read(didKeySecp256k1)
+		require.NoError(t, err)
+
+		doc := docResolution.DIDDocument
+		require.Equal(t, EcdsaSecp256k1VerificationKey2019, doc.VerificationMethod[0].Type)
+		require.Len(t, doc.VerificationMethod[0].Value, secp256k1.PublicKeySizeCompressed)
+		require.Len(t, doc.Authentication, 1)
+		require.Len(t, doc.KeyAgreement, 1)
+	})
+
+	t.Run("P-256", func(t *testing.T) {
+		docResolution, err := v.Read(didKeyP256)
+		require.NoError(t, err)
+
+		doc := docResolution.DIDDocument
+		require.Equal(t, EcdsaSecp256r1VerificationKey2019, doc.VerificationMethod[0].Type)
+		require.Len(t, doc.VerificationMethod[0].Value, 33)
+		require.Len(t, doc.AssertionMethod, 1)
+	})
+
+	t.Run("DID URL", func(t *testing.T) {
+		docResolution, err := v.Read(didKeyEd25519 + "#z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK")
+		require.NoError(t, err)
+		require.Equal(t, didKeyEd25519, docResolution.DIDDocument.ID)
+	})
+
+	t.Run("invalid DIDs", func(t *testing.T) {
+		_, err := v.Read("did:key:abc")
+		require.ErrorIs(t, err, vdr.ErrInvalidDID)
+
+		_, err = v.Read("did:example:z6MkhaXgBZDvotDkL5257faiztiGiC2QtKLGpbnnEGta2doK")
+		require.ErrorIs(t, err, vdr.ErrInvalidDID)
+
+		// unsupported multicodec (bls12_381-g2)
+		_, err = v.Read("did:key:" + KeyFingerprint(0xeb, make([]byte, 96)))
+		require.ErrorIs(t, err, vdr.ErrInvalidDID)
+		require.Contains(t, err.Error(), "unsupported key multicodec code")
+
+		// wrong key length
+		_, err = v.Read("did:key:" + KeyFingerprint(ED25519PubKeyMultiCodec, make([]byte, 31)))
+		require.ErrorIs(t, err, vdr.ErrInvalidDID)
+	})
+}
+
+func TestCreate(t *testing.T) {
+	v := New()
+
+	t.Run("Ed25519", func(t *testing.T) {
+		pubKey, _, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		docResolution, err := v.Create(&did.Document{VerificationMethod: []did.VerificationMethod{
+			*did.NewVerificationMethodFromBytes("#key-1", Ed25519VerificationKey2018, "", pubKey),
+		}})
+		require.NoError(t, err)
+
+		didKey, keyID := CreateDIDKey(pubKey)
+		require.Equal(t, didKey, docResolution.DIDDocument.ID)
+		require.Equal(t, keyID, docResolution.DIDDocument.VerificationMethod[0].ID)
+
+		resolvedKey, err := PubKeyFromDIDKey(didKey)
+		require.NoError(t, err)
+		require.Equal(t, []byte(pubKey), resolvedKey)
+	})
+
+	t.Run("uncompressed EC keys are compressed", func(t *testing.T) {
+		p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		docResolution, err := v.Create(&did.Document{VerificationMethod: []did.VerificationMethod{
+			*did.NewVerificationMethodFromBytes("#key-1", EcdsaSecp256r1VerificationKey2019, "",
+				elliptic.Marshal(elliptic.P256(), p256Key.X, p256Key.Y)), //nolint:staticcheck
+		}})
+		require.NoError(t, err)
+		require.Equal(t, elliptic.MarshalCompressed(elliptic.P256(), p256Key.X, p256Key.Y),
+			docResolution.DIDDocument.VerificationMethod[0].Value)
+
+		k1Key, err := ecdsa.GenerateKey(secp256k1.S256(), rand.Reader)
+		require.NoError(t, err)
+
+		docResolution, err = v.Create(&did.Document{VerificationMethod: []did.VerificationMethod{
+			*did.NewVerificationMethodFromBytes("#key-1", EcdsaSecp256k1VerificationKey2019, "",
+				secp256k1.Marshal(k1Key.X, k1Key.Y)),
+		}})
+		require.NoError(t, err)
+
+		didKey, _ := CreateDIDKeyByCode(Secp256k1PubKeyMultiCodec, secp256k1.MarshalCompressed(k1Key.X, k1Key.Y))
+		require.Equal(t, didKey, docResolution.DIDDocument.ID)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := v.Create(&did.Document{})
+		require.EqualError(t, err, "missing verification method")
+
+		_, err = v.Create(&did.Document{VerificationMethod: []did.VerificationMethod{
+			*did.NewVerificationMethodFromBytes("#key-1", "RsaVerificationKey2018", "", []byte{1}),
+		}})
+		require.EqualError(t, err, "not supported public key type: RsaVerificationKey2018")
+
+		_, err = v.Create(&did.Document{VerificationMethod: []did.VerificationMethod{
+			*did.NewVerificationMethodFromBytes("#key-1", EcdsaSecp256r1VerificationKey2019, "", []byte{1}),
+		}})
+		require.Error(t, err)
+	})
+}
+
+func TestVDR(t *testing.T) {
+	v := New()
+	require.True(t, v.Accept(DIDMethod))
+	require.False(t, v.Accept("web"))
+	require.Error(t, v.Update(&did.Document{}))
+	require.Error(t, v.Deactivate(didKeyEd25519))
+	require.NoError(t, v.Close())
+
+	registry := vdr.New(vdr.WithVDR(v))
+	docResolution, err := registry.Resolve(didKeyEd25519)
+	require.NoError(t, err)
+	require.Equal(t, didKeyEd25519, docResolution.DIDDocument.ID)
+}
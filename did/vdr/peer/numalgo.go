@@ -0,0 +1,326 @@
+package peer
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/zRich/zFusion/common/model"
+	"github.com/zRich/zFusion/did"
+	"github.com/zRich/zFusion/did/vdr"
+	"github.com/zRich/zFusion/did/vdr/key"
+)
+
+// Purpose codes prefixing the elements of a numalgo 2 DID.
+const (
+	purposeKeyAgreement         = 'E'
+	purposeAuthentication       = 'V'
+	purposeAssertion            = 'A'
+	purposeCapabilityInvocation = 'I'
+	purposeCapabilityDelegation = 'D'
+	purposeService              = 'S'
+)
+
+// DIDCommMessaging is the type of DIDComm V2 services.
+const DIDCommMessaging = "DIDCommMessaging"
+
+// serviceTypeAbbreviations abbreviates service types encoded in numalgo 2 DIDs.
+var serviceTypeAbbreviations = map[string]string{ //nolint:gochecknoglobals
+	DIDCommMessaging: "dm",
+}
+
+// relationships maps verification relationships to their purpose codes, in encoding order.
+var relationships = []struct { //nolint:gochecknoglobals
+	purpose      byte
+	relationship did.VerificationRelationship
+}{
+	{purposeKeyAgreement, did.KeyAgreement},
+	{purposeAuthentication, did.Authentication},
+	{purposeAssertion, did.AssertionMethod},
+	{purposeCapabilityInvocation, did.CapabilityInvocation},
+	{purposeCapabilityDelegation, did.CapabilityDelegation},
+}
+
+// service is the abbreviated JSON form of a service encoded in a numalgo 2 DID.
+type service struct {
+	Type        string          `json:"t"`
+	Endpoint    json.RawMessage `json:"s"`
+	RoutingKeys []string        `json:"r,omitempty"`
+	Accept      []string        `json:"a,omitempty"`
+}
+
+// serviceEndpoint is the abbreviated object form of a service endpoint.
+type serviceEndpoint struct {
+	URI         string   `json:"uri"`
+	RoutingKeys []string `json:"r,omitempty"`
+	Accept      []string `json:"a,omitempty"`
+}
+
+// CreateNumalgo0 creates a numalgo 0 did:peer from a single inception key.
+func CreateNumalgo0(vm *did.VerificationMethod) (string, error) {
+	fingerprint, err := key.VerificationMethodFingerprint(vm)
+	if err != nil {
+		return "", err
+	}
+
+	return didPrefix + "0" + fingerprint, nil
+}
+
+// CreateNumalgo2 creates a numalgo 2 did:peer from the verification relationships and services of doc.
+// Verification methods which are not part of a verification relationship are not encoded.
+func CreateNumalgo2(doc *did.Document) (string, error) {
+	var sb strings.Builder
+
+	sb.WriteString(didPrefix + "2")
+
+	keys := 0
+
+	for _, r := range relationships {
+		for _, v := range verifications(doc, r.relationship) {
+			fingerprint, err := key.VerificationMethodFingerprint(&v.VerificationMethod)
+			if err != nil {
+				return "", fmt.Errorf("verification method %s: %w", v.VerificationMethod.ID, err)
+			}
+
+			sb.WriteByte('.')
+			sb.WriteByte(r.purpose)
+			sb.WriteString(fingerprint)
+
+			keys++
+		}
+	}
+
+	if keys == 0 {
+		return "", errors.New("numalgo 2 requires at least one key")
+	}
+
+	for i := range doc.Service {
+		encoded, err := encodeService(&doc.Service[i])
+		if err != nil {
+			return "", fmt.Errorf("service %s: %w", doc.Service[i].ID, err)
+		}
+
+		sb.WriteByte('.')
+		sb.WriteByte(purposeService)
+		sb.WriteString(encoded)
+	}
+
+	return sb.String(), nil
+}
+
+// CreateDocument deterministically expands a did:peer into its DID document.
+func CreateDocument(didPeer string) (*did.Document, error) {
+	parsed, err := did.Parse(didPeer)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", vdr.ErrInvalidDID, err)
+	}
+
+	if parsed.Method != DIDMethod || parsed.MethodSpecificID == "" {
+		return nil, fmt.Errorf("%w: not a did:peer: %s", vdr.ErrInvalidDID, didPeer)
+	}
+
+	var doc *did.Document
+
+	switch numalgo, encoded := parsed.MethodSpecificID[0], parsed.MethodSpecificID[1:]; numalgo {
+	case '0':
+		doc, err = numalgo0Document(didPeer, encoded)
+	case '2':
+		doc, err = numalgo2Document(didPeer, encoded)
+	default:
+		return nil, fmt.Errorf("%w: unsupported did:peer numalgo %c", vdr.ErrInvalidDID, numalgo)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", vdr.ErrInvalidDID, err)
+	}
+
+	return doc, nil
+}
+
+// numalgo0Document expands the inception key the same way did:key does.
+func numalgo0Document(didPeer, fingerprint string) (*did.Document, error) {
+	didKey := "did:key:" + fingerprint
+
+	keyDoc, err := key.CreateDocument(didKey)
+	if err != nil {
+		return nil, err
+	}
+
+	docBytes, err := keyDoc.JSONBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	return did.ParseDocument([]byte(strings.ReplaceAll(string(docBytes), didKey, didPeer)))
+}
+
+func numalgo2Document(didPeer, encoded string) (*did.Document, error) {
+	if !strings.HasPrefix(encoded, ".") {
+		return nil, errors.New("invalid numalgo 2 encoding")
+	}
+
+	var (
+		vms      []did.VerificationMethod
+		services []did.Service
+	)
+
+	verifications := make(map[did.VerificationRelationship][]did.Verification)
+
+	for _, element := range strings.Split(encoded[1:], ".") {
+		if len(element) < 2 { //nolint:gomnd
+			return nil, fmt.Errorf("invalid numalgo 2 element %q", element)
+		}
+
+		purpose, value := element[0], element[1:]
+
+		if purpose == purposeService {
+			svc, err := decodeService(didPeer, len(services), value)
+			if err != nil {
+				return nil, err
+			}
+
+			services = append(services, *svc)
+
+			continue
+		}
+
+		relationship, ok := relationshipOf(purpose)
+		if !ok {
+			return nil, fmt.Errorf("unsupported numalgo 2 purpose %c", purpose)
+		}
+
+		keyID := fmt.Sprintf("%s#key-%d", didPeer, len(vms)+1)
+
+		vm, err := key.VerificationMethodFromFingerprint(keyID, didPeer, value)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", keyID, err)
+		}
+
+		vms = append(vms, *vm)
+		verifications[relationship] = append(verifications[relationship],
+			*did.NewReferencedVerification(vm, relationship))
+	}
+
+	if len(vms) == 0 {
+		return nil, errors.New("numalgo 2 requires at least one key")
+	}
+
+	doc := did.BuildDoc(
+		did.WithVerificationMethod(vms),
+		did.WithAuthentication(verifications[did.Authentication]),
+		did.WithAssertion(verifications[did.AssertionMethod]),
+		did.WithCapabilityDelegation(verifications[did.CapabilityDelegation]),
+		did.WithCapabilityInvocation(verifications[did.CapabilityInvocation]),
+		did.WithKeyAgreement(verifications[did.KeyAgreement]),
+		did.WithService(services),
+	)
+	doc.Context = []string{did.ContextV1}
+	doc.ID = didPeer
+
+	return doc, nil
+}
+
+func relationshipOf(purpose byte) (did.VerificationRelationship, bool) {
+	for _, r := range relationships {
+		if r.purpose == purpose {
+			return r.relationship, true
+		}
+	}
+
+	return did.VerificationRelationshipGeneral, false
+}
+
+func verifications(doc *did.Document, relationship did.VerificationRelationship) []did.Verification {
+	switch relationship {
+	case did.KeyAgreement:
+		return doc.KeyAgreement
+	case did.Authentication:
+		return doc.Authentication
+	case did.AssertionMethod:
+		return doc.AssertionMethod
+	case did.CapabilityInvocation:
+		return doc.CapabilityInvocation
+	default: // did.CapabilityDelegation
+		return doc.CapabilityDelegation
+	}
+}
+
+func encodeService(svc *did.Service) (string, error) {
+	uri, err := svc.ServiceEndpoint.URI()
+	if err != nil {
+		return "", err
+	}
+
+	accept, routingKeys := svc.Accept, svc.RoutingKeys
+
+	if svc.ServiceEndpoint.Type() == model.DIDCommV2 {
+		accept, _ = svc.ServiceEndpoint.Accept()           //nolint:errcheck
+		routingKeys, _ = svc.ServiceEndpoint.RoutingKeys() //nolint:errcheck
+	}
+
+	endpoint, err := json.Marshal(uri)
+	if err != nil {
+		return "", err
+	}
+
+	svcType := svc.Type
+	if abbreviation, ok := serviceTypeAbbreviations[svcType]; ok {
+		svcType = abbreviation
+	}
+
+	svcBytes, err := json.Marshal(service{Type: svcType, Endpoint: endpoint, RoutingKeys: routingKeys, Accept: accept})
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(svcBytes), nil
+}
+
+func decodeService(didPeer string, index int, encoded string) (*did.Service, error) {
+	svcBytes, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("decode service: %w", err)
+	}
+
+	var svc service
+
+	if err = json.Unmarshal(svcBytes, &svc); err != nil {
+		return nil, fmt.Errorf("unmarshal service: %w", err)
+	}
+
+	endpoint := serviceEndpoint{RoutingKeys: svc.RoutingKeys, Accept: svc.Accept}
+
+	// the endpoint is either an URI or, in later revisions of the spec, an abbreviated endpoint object.
+	if err = json.Unmarshal(svc.Endpoint, &endpoint.URI); err != nil {
+		if err = json.Unmarshal(svc.Endpoint, &endpoint); err != nil {
+			return nil, fmt.Errorf("unmarshal service endpoint: %w", err)
+		}
+	}
+
+	if endpoint.URI == "" {
+		return nil, errors.New("service endpoint URI is missing")
+	}
+
+	svcType := svc.Type
+
+	for t, abbreviation := range serviceTypeAbbreviations {
+		if svcType == abbreviation {
+			svcType = t
+		}
+	}
+
+	id := didPeer + "#service"
+	if index > 0 {
+		id = fmt.Sprintf("%s-%d", id, index)
+	}
+
+	return &did.Service{
+		ID:   id,
+		Type: svcType,
+		ServiceEndpoint: model.NewDIDCommV2Endpoint([]model.DIDCommV2Endpoint{
+			{URI: endpoint.URI, Accept: endpoint.Accept, RoutingKeys: endpoint.RoutingKeys},
+		}),
+	}, nil
+}
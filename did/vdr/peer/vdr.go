@@ -0,0 +1,106 @@
+// Package peer implements numalgo 0 and 2 of the did:peer method, see https://identity.foundation/peer-did-method-spec/.
+// did:peer documents are derived from the DID itself, so pairwise DIDs never have to be written to the ledger.
+package peer
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zRich/zFusion/did"
+	"github.com/zRich/zFusion/did/vdr"
+)
+
+const (
+	// DIDMethod did method.
+	DIDMethod = "peer"
+
+	// NumalgoOpt is the DID method option selecting the numalgo used by Create, either 0 or 2 (default).
+	NumalgoOpt = "numalgo"
+
+	didPrefix = "did:" + DIDMethod + ":"
+)
+
+// VDR implements the did:peer method.
+type VDR struct{}
+
+// New returns a new did:peer VDR.
+func New() *VDR {
+	return &VDR{}
+}
+
+// Accept accepts the did:peer method.
+func (v *VDR) Accept(method string, _ ...vdr.DIDMethodOption) bool {
+	return method == DIDMethod
+}
+
+// Create creates a did:peer document. Numalgo 0 encodes the first verification method of didDoc as inception
+// key, numalgo 2 encodes all keys of its verification relationships together with its services.
+func (v *VDR) Create(didDoc *did.Document, opts ...vdr.DIDMethodOption) (*did.DocResolution, error) {
+	var (
+		didPeer string
+		err     error
+	)
+
+	numalgo, ok := vdr.GetDIDMethodOpts(opts...).Values[NumalgoOpt]
+	if !ok {
+		numalgo = 2
+	}
+
+	switch numalgo {
+	case 0:
+		if len(didDoc.VerificationMethod) == 0 {
+			return nil, errors.New("missing verification method")
+		}
+
+		didPeer, err = CreateNumalgo0(&didDoc.VerificationMethod[0])
+	case 2: //nolint:gomnd
+		didPeer, err = CreateNumalgo2(didDoc)
+	default:
+		return nil, fmt.Errorf("unsupported did:peer numalgo %v", numalgo)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("create did:peer: %w", err)
+	}
+
+	doc, err := CreateDocument(didPeer)
+	if err != nil {
+		return nil, err
+	}
+
+	return &did.DocResolution{Context: vdr.ContextDIDResolution, DIDDocument: doc}, nil
+}
+
+// Read expands did:peer into a DID document.
+func (v *VDR) Read(didPeer string, _ ...vdr.DIDMethodOption) (*did.DocResolution, error) {
+	didURL, err := did.ParseDIDURL(didPeer)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", vdr.ErrInvalidDID, err)
+	}
+
+	doc, err := CreateDocument(didURL.DID.String())
+	if err != nil {
+		return nil, fmt.Errorf("resolve did:peer: %w", err)
+	}
+
+	return &did.DocResolution{
+		Context:          vdr.ContextDIDResolution,
+		DIDDocument:      doc,
+		DocumentMetadata: &did.DocumentMetadata{},
+	}, nil
+}
+
+// Update is not supported by did:peer.
+func (v *VDR) Update(*did.Document, ...vdr.DIDMethodOption) error {
+	return errors.New("not supported")
+}
+
+// Deactivate is not supported by did:peer.
+func (v *VDR) Deactivate(string, ...vdr.DIDMethodOption) error {
+	return errors.New("not supported")
+}
+
+// Close frees resources being maintained by VDR.
+func (v *VDR) Close() error {
+	return nil
+}
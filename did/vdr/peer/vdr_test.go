@@ -0,0 +1,195 @@
+package peer
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zRich/zFusion/common/model"
+	"github.com/zRich/zFusion/did"
+	"github.com/zRich/zFusion/did/vdr"
+	"github.com/zRich/zFusion/did/vdr/key"
+)
+
+const (
+	numalgo0DID = "did:peer:0z6MkqRYqQiSgvZQdnBytw86Qbs2ZWUkGv22od935YF4s8M7V"
+
+	// from https://identity.foundation/peer-did-method-spec/#method-2-multiple-inception-key-without-doc.
+	numalgo2DID = "did:peer:2.Ez6LSbysY2xFMRpGMhb7tFTLMpeuPRaqaWM1yECx2AtzE3KCc" +
+		".Vz6MkqRYqQiSgvZQdnBytw86Qbs2ZWUkGv22od935YF4s8M7V" +
+		".Vz6MkgoLTnTypo3tDRwCkZXSccTPHRLhF4ZnjhueYAFpEX6vg" +
+		".SeyJ0IjoiZG0iLCJzIjoiaHR0cHM6Ly9leGFtcGxlLmNvbS9lbmRwb2ludCIsInIiOlsiZGlkOmV4YW1wbGU6c29tZW1lZGlh" +
+		"dG9yI3NvbWVrZXkiXSwiYSI6WyJkaWRjb21tL3YyIiwiZGlkY29tbS9haXAyO2Vudj1yZmM1ODciXX0"
+)
+
+func TestRead(t *testing.T) {
+	v := New()
+
+	t.Run("numalgo 0", func(t *testing.T) {
+		docResolution, err := v.Read(numalgo0DID)
+		require.NoError(t, err)
+
+		doc := docResolution.DIDDocument
+		keyID := numalgo0DID + "#z6MkqRYqQiSgvZQdnBytw86Qbs2ZWUkGv22od935YF4s8M7V"
+
+		require.Equal(t, numalgo0DID, doc.ID)
+		require.Len(t, doc.VerificationMethod, 1)
+		require.Equal(t, keyID, doc.VerificationMethod[0].ID)
+		require.Equal(t, numalgo0DID, doc.VerificationMethod[0].Controller)
+		require.Equal(t, keyID, doc.Authentication[0].VerificationMethod.ID)
+		require.Len(t, doc.KeyAgreement, 1)
+		require.Equal(t, key.X25519KeyAgreementKey2019, doc.KeyAgreement[0].VerificationMethod.Type)
+	})
+
+	t.Run("numalgo 2", func(t *testing.T) {
+		docResolution, err := v.Read(numalgo2DID + "#key-2")
+		require.NoError(t, err)
+
+		doc := docResolution.DIDDocument
+		require.Equal(t, numalgo2DID, doc.ID)
+		require.Len(t, doc.VerificationMethod, 3)
+
+		require.Len(t, doc.KeyAgreement, 1)
+		require.Equal(t, numalgo2DID+"#key-1", doc.KeyAgreement[0].VerificationMethod.ID)
+		require.Equal(t, key.X25519KeyAgreementKey2019, doc.KeyAgreement[0].VerificationMethod.Type)
+
+		require.Len(t, doc.Authentication, 2)
+		require.Equal(t, numalgo2DID+"#key-2", doc.Authentication[0].VerificationMethod.ID)
+		require.Equal(t, numalgo2DID+"#key-3", doc.Authentication[1].VerificationMethod.ID)
+		require.Equal(t, key.Ed25519VerificationKey2018, doc.Authentication[1].VerificationMethod.Type)
+
+		require.Len(t, doc.Service, 1)
+		svc := doc.Service[0]
+		require.Equal(t, numalgo2DID+"#service", svc.ID)
+		require.Equal(t, DIDCommMessaging, svc.Type)
+		require.Equal(t, model.DIDCommV2, svc.ServiceEndpoint.Type())
+
+		uri, err := svc.ServiceEndpoint.URI()
+		require.NoError(t, err)
+		require.Equal(t, "https://example.com/endpoint", uri)
+
+		routingKeys, err := svc.ServiceEndpoint.RoutingKeys()
+		require.NoError(t, err)
+		require.Equal(t, []string{"did:example:somemediator#somekey"}, routingKeys)
+
+		accept, err := svc.ServiceEndpoint.Accept()
+		require.NoError(t, err)
+		require.Equal(t, []string{"didcomm/v2", "didcomm/aip2;env=rfc587"}, accept)
+	})
+
+	t.Run("round trip through JSON", func(t *testing.T) {
+		docResolution, err := v.Read(numalgo2DID)
+		require.NoError(t, err)
+
+		docBytes, err := docResolution.DIDDocument.JSONBytes()
+		require.NoError(t, err)
+
+		doc, err := did.ParseDocument(docBytes)
+		require.NoError(t, err)
+
+		didPeer, err := CreateNumalgo2(doc)
+		require.NoError(t, err)
+		require.Equal(t, numalgo2DID, didPeer)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, didPeer := range []string{
+			"did:key:z6MkqRYqQiSgvZQdnBytw86Qbs2ZWUkGv22od935YF4s8M7V",
+			"did:peer:1zQmZMygzYqNwU6Uhmewx5Xepf2VLp5S4HLSwwgf2aiKZuwa",
+			"did:peer:0z6MkqRYqQiSgvZQdnBytw86Qbs2ZWUkGv22od935YF4s8M7",
+			"did:peer:2Ez6LSbysY2xFMRpGMhb7tFTLMpeuPRaqaWM1yECx2AtzE3KCc",
+			"did:peer:2.Xz6LSbysY2xFMRpGMhb7tFTLMpeuPRaqaWM1yECx2AtzE3KCc",
+			"did:peer:2.SeyJ0IjoiZG0iLCJzIjoiaHR0cHM6Ly9leGFtcGxlLmNvbS9lbmRwb2ludCJ9",
+			"did:peer:2.Vz6MkqRYqQiSgvZQdnBytw86Qbs2ZWUkGv22od935YF4s8M7V.Sinvalid",
+		} {
+			_, err := v.Read(didPeer)
+			require.ErrorIs(t, err, vdr.ErrInvalidDID, didPeer)
+		}
+	})
+}
+
+func TestCreate(t *testing.T) {
+	v := New()
+
+	authKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	agreementKey := make([]byte, 32)
+	_, err = rand.Read(agreementKey)
+	require.NoError(t, err)
+
+	auth := did.NewVerificationMethodFromBytes("#auth", key.Ed25519VerificationKey2018, "", authKey)
+	agreement := did.NewVerificationMethodFromBytes("#agreement", key.X25519KeyAgreementKey2019, "", agreementKey)
+
+	doc := did.BuildDoc(
+		did.WithVerificationMethod([]did.VerificationMethod{*auth}),
+		did.WithAuthentication([]did.Verification{*did.NewReferencedVerification(auth, did.Authentication)}),
+		did.WithKeyAgreement([]did.Verification{*did.NewEmbeddedVerification(agreement, did.KeyAgreement)}),
+		did.WithService([]did.Service{{
+			ID:   "#didcomm",
+			Type: DIDCommMessaging,
+			ServiceEndpoint: model.NewDIDCommV2Endpoint([]model.DIDCommV2Endpoint{
+				{URI: "https://peer.example.com/didcomm", Accept: []string{"didcomm/v2"}},
+			}),
+		}}),
+	)
+
+	t.Run("numalgo 2", func(t *testing.T) {
+		docResolution, err := v.Create(doc)
+		require.NoError(t, err)
+
+		created := docResolution.DIDDocument
+		require.Regexp(t, `^did:peer:2\.Ez6LS[^.]+\.Vz6Mk[^.]+\.S[^.]+$`, created.ID)
+		require.Equal(t, []byte(agreementKey), created.KeyAgreement[0].VerificationMethod.Value)
+		require.Equal(t, []byte(authKey), created.Authentication[0].VerificationMethod.Value)
+
+		uri, err := created.Service[0].ServiceEndpoint.URI()
+		require.NoError(t, err)
+		require.Equal(t, "https://peer.example.com/didcomm", uri)
+
+		resolved, err := v.Read(created.ID)
+		require.NoError(t, err)
+		require.Equal(t, created, resolved.DIDDocument)
+	})
+
+	t.Run("numalgo 0", func(t *testing.T) {
+		docResolution, err := v.Create(doc, vdr.WithOption(NumalgoOpt, 0))
+		require.NoError(t, err)
+
+		fingerprint := key.KeyFingerprint(key.ED25519PubKeyMultiCodec, authKey)
+		require.Equal(t, "did:peer:0"+fingerprint, docResolution.DIDDocument.ID)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := v.Create(doc, vdr.WithOption(NumalgoOpt, 1))
+		require.Error(t, err)
+
+		_, err = v.Create(&did.Document{}, vdr.WithOption(NumalgoOpt, 0))
+		require.Error(t, err)
+
+		_, err = v.Create(&did.Document{})
+		require.Error(t, err)
+
+		unsupported := did.NewVerificationMethodFromBytes("#key", "UnknownKeyType", "", authKey)
+		_, err = v.Create(did.BuildDoc(did.WithAuthentication([]did.Verification{
+			*did.NewEmbeddedVerification(unsupported, did.Authentication),
+		})))
+		require.Error(t, err)
+	})
+}
+
+func TestVDR(t *testing.T) {
+	v := New()
+	require.True(t, v.Accept(DIDMethod))
+	require.False(t, v.Accept("key"))
+	require.Error(t, v.Update(&did.Document{}))
+	require.Error(t, v.Deactivate(numalgo0DID))
+	require.NoError(t, v.Close())
+
+	registry := vdr.New(vdr.WithVDR(v))
+
+	docResolution, err := registry.Resolve(numalgo2DID)
+	require.NoError(t, err)
+	require.Equal(t, numalgo2DID, docResolution.DIDDocument.ID)
+}
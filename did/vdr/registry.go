@@ -0,0 +1,160 @@
+package vdr
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zRich/zFusion/common/logging"
+	"github.com/zRich/zFusion/did"
+)
+
+var logger = logging.GetLogger("vdr") //nolint:gochecknoglobals
+
+// Option is a registry instance option.
+type Option func(opts *Registry)
+
+// WithVDR adds a DID method implementation to the registry.
+func WithVDR(v VDR) Option {
+	return func(opts *Registry) {
+		opts.vdrs = append(opts.vdrs, v)
+	}
+}
+
+// Registry dispatches DID operations to the VDR accepting the DID method.
+type Registry struct {
+	vdrs []VDR
+}
+
+// New returns a new DID method registry.
+func New(opts ...Option) *Registry {
+	baseVDR := &Registry{}
+
+	for _, opt := range opts {
+		opt(baseVDR)
+	}
+
+	return baseVDR
+}
+
+// Resolve resolves a DID (or a DID URL carrying DID parameters such as versionId) to a DID resolution
+// result. Errors are reported as *ResolutionError carrying the W3C resolution error code.
+func (r *Registry) Resolve(didID string, opts ...DIDMethodOption) (*did.DocResolution, error) {
+	didURL, err := did.ParseDIDURL(didID)
+	if err != nil {
+		return nil, &ResolutionError{Code: InvalidDIDError, DID: didID, Err: fmt.Errorf("%w: %v", ErrInvalidDID, err)}
+	}
+
+	method, err := r.resolveVDR(didURL.Method)
+	if err != nil {
+		return nil, &ResolutionError{Code: MethodNotSupportedError, DID: didID, Err: err}
+	}
+
+	docResolution, err := method.Read(didID, opts...)
+	if err != nil {
+		logger.Debugf("failed to resolve %s: %s", didID, err)
+
+		var resErr *ResolutionError
+		if errors.As(err, &resErr) {
+			return nil, err
+		}
+
+		code := InternalError
+		if errors.Is(err, ErrNotFound) {
+			code = NotFoundError
+		}
+
+		return nil, &ResolutionError{Code: code, DID: didID, Err: err}
+	}
+
+	if docResolution == nil || docResolution.DIDDocument == nil {
+		return nil, &ResolutionError{Code: NotFoundError, DID: didID, Err: ErrNotFound}
+	}
+
+	if docResolution.Context == nil {
+		docResolution.Context = ContextDIDResolution
+	}
+
+	if docResolution.DocumentMetadata == nil {
+		docResolution.DocumentMetadata = &did.DocumentMetadata{}
+	}
+
+	return docResolution, nil
+}
+
+// ResolveDocument resolves a DID to its DID document, which lets the registry resolve the creators of
+// DID document proofs, see did.DocumentResolver.
+func (r *Registry) ResolveDocument(didID string) (*did.Document, error) {
+	docResolution, err := r.Resolve(didID)
+	if err != nil {
+		return nil, err
+	}
+
+	return docResolution.DIDDocument, nil
+}
+
+// Create creates a new DID document with the VDR of the given method.
+func (r *Registry) Create(didMethod string, doc *did.Document,
+	opts ...DIDMethodOption) (*did.DocResolution, error) {
+	method, err := r.resolveVDR(didMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	docResolution, err := method.Create(doc, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("create DID with %s method: %w", didMethod, err)
+	}
+
+	return docResolution, nil
+}
+
+// Update updates the DID document with the VDR of the document's DID method.
+func (r *Registry) Update(doc *did.Document, opts ...DIDMethodOption) error {
+	didID, err := did.Parse(doc.ID)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidDID, err)
+	}
+
+	method, err := r.resolveVDR(didID.Method)
+	if err != nil {
+		return err
+	}
+
+	return method.Update(doc, opts...)
+}
+
+// Deactivate deactivates the DID with the VDR of its DID method.
+func (r *Registry) Deactivate(didID string, opts ...DIDMethodOption) error {
+	parsed, err := did.Parse(didID)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidDID, err)
+	}
+
+	method, err := r.resolveVDR(parsed.Method)
+	if err != nil {
+		return err
+	}
+
+	return method.Deactivate(didID, opts...)
+}
+
+// Close closes all registered VDRs.
+func (r *Registry) Close() error {
+	for _, v := range r.vdrs {
+		if err := v.Close(); err != nil {
+			return fmt.Errorf("close vdr: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *Registry) resolveVDR(method string) (VDR, error) {
+	for _, v := range r.vdrs {
+		if v.Accept(method) {
+			return v, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrMethodNotSupported, method)
+}
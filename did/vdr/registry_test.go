@@ -0,0 +1,176 @@
+package vdr
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zRich/zFusion/did"
+)
+
+type mockVDR struct {
+	method       string
+	readFunc     func(did string, opts ...DIDMethodOption) (*did.DocResolution, error)
+	createFunc   func(doc *did.Document, opts ...DIDMethodOption) (*did.DocResolution, error)
+	updateErr    error
+	deactivated  string
+	closeErr     error
+	acceptedOpts *DIDMethodOpts
+}
+
+func (m *mockVDR) Read(didID string, opts ...DIDMethodOption) (*did.DocResolution, error) {
+	m.acceptedOpts = GetDIDMethodOpts(opts...)
+
+	return m.readFunc(didID, opts...)
+}
+
+func (m *mockVDR) Create(doc *did.Document, opts ...DIDMethodOption) (*did.DocResolution, error) {
+	return m.createFunc(doc, opts...)
+}
+
+func (m *mockVDR) Accept(method string, _ ...DIDMethodOption) bool {
+	return method == m.method
+}
+
+func (m *mockVDR) Update(*did.Document, ...DIDMethodOption) error {
+	return m.updateErr
+}
+
+func (m *mockVDR) Deactivate(didID string, _ ...DIDMethodOption) error {
+	m.deactivated = didID
+
+	return nil
+}
+
+func (m *mockVDR) Close() error {
+	return m.closeErr
+}
+
+func TestRegistry_Resolve(t *testing.T) {
+	doc := &did.Document{ID: "did:example:123"}
+
+	v := &mockVDR{
+		method: "example",
+		readFunc: func(didID string, opts ...DIDMethodOption) (*did.DocResolution, error) {
+			if didID == "did:example:missing" {
+				return nil, ErrNotFound
+			}
+
+			if didID == "did:example:broken" {
+				return nil, errors.New("storage failure")
+			}
+
+			return &did.DocResolution{DIDDocument: doc}, nil
+		},
+	}
+
+	registry := New(WithVDR(v))
+
+	t.Run("success", func(t *testing.T) {
+		result, err := registry.Resolve("did:example:123", WithOption("k", "v"))
+		require.NoError(t, err)
+		require.Equal(t, doc, result.DIDDocument)
+		require.Equal(t, ContextDIDResolution, result.Context)
+		require.NotNil(t, result.DocumentMetadata)
+		require.Equal(t, "v", v.acceptedOpts.Values["k"])
+	})
+
+	t.Run("success with DID parameters", func(t *testing.T) {
+		result, err := registry.Resolve("did:example:123?versionId=1")
+		require.NoError(t, err)
+		require.Equal(t, doc, result.DIDDocument)
+	})
+
+	t.Run("invalid DID", func(t *testing.T) {
+		_, err := registry.Resolve("not-a-did")
+		require.Error(t, err)
+		require.ErrorIs(t, err, ErrInvalidDID)
+		require.Equal(t, InvalidDIDError, ErrorCode(err))
+	})
+
+	t.Run("method not supported", func(t *testing.T) {
+		_, err := registry.Resolve("did:other:123")
+		require.Error(t, err)
+		require.ErrorIs(t, err, ErrMethodNotSupported)
+		require.Equal(t, MethodNotSupportedError, ErrorCode(err))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := registry.Resolve("did:example:missing")
+		require.Error(t, err)
+		require.ErrorIs(t, err, ErrNotFound)
+		require.Equal(t, NotFoundError, ErrorCode(err))
+	})
+
+	t.Run("internal error", func(t *testing.T) {
+		_, err := registry.Resolve("did:example:broken")
+		require.Error(t, err)
+		require.Equal(t, InternalError, ErrorCode(err))
+		require.Contains(t, err.Error(), "storage failure")
+	})
+
+	t.Run("not a resolution error", func(t *testing.T) {
+		require.Empty(t, ErrorCode(errors.New("other")))
+	})
+
+	t.Run("resolve document", func(t *testing.T) {
+		var resolver did.DocumentResolver = registry
+
+		result, err := resolver.ResolveDocument("did:example:123")
+		require.NoError(t, err)
+		require.Equal(t, doc, result)
+
+		_, err = resolver.ResolveDocument("did:example:missing")
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestRegistry_Create(t *testing.T) {
+	v := &mockVDR{
+		method: "example",
+		createFunc: func(doc *did.Document, opts ...DIDMethodOption) (*did.DocResolution, error) {
+			if doc.ID == "fail" {
+				return nil, errors.New("create failed")
+			}
+
+			return &did.DocResolution{DIDDocument: doc}, nil
+		},
+	}
+
+	registry := New(WithVDR(v))
+
+	result, err := registry.Create("example", &did.Document{ID: "did:example:123"})
+	require.NoError(t, err)
+	require.Equal(t, "did:example:123", result.DIDDocument.ID)
+
+	_, err = registry.Create("example", &did.Document{ID: "fail"})
+	require.EqualError(t, err, "create DID with example method: create failed")
+
+	_, err = registry.Create("other", &did.Document{})
+	require.ErrorIs(t, err, ErrMethodNotSupported)
+}
+
+func TestRegistry_UpdateDeactivateClose(t *testing.T) {
+	v := &mockVDR{method: "example", updateErr: errors.New("update failed")}
+	registry := New(WithVDR(v))
+
+	err := registry.Update(&did.Document{ID: "did:example:123"})
+	require.EqualError(t, err, "update failed")
+
+	err = registry.Update(&did.Document{ID: "invalid"})
+	require.ErrorIs(t, err, ErrInvalidDID)
+
+	err = registry.Update(&did.Document{ID: "did:other:123"})
+	require.ErrorIs(t, err, ErrMethodNotSupported)
+
+	require.NoError(t, registry.Deactivate("did:example:123"))
+	require.Equal(t, "did:example:123", v.deactivated)
+
+	require.ErrorIs(t, registry.Deactivate("invalid"), ErrInvalidDID)
+	require.ErrorIs(t, registry.Deactivate("did:other:123"), ErrMethodNotSupported)
+
+	require.NoError(t, registry.Close())
+
+	v.closeErr = errors.New("close failed")
+	require.EqualError(t, registry.Close(), "close vdr: close failed")
+}
@@ -0,0 +1,101 @@
+package vdr
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zRich/zFusion/did"
+)
+
+// ContextDIDResolution is the JSON-LD context of a DID resolution result.
+const ContextDIDResolution = "https://w3id.org/did-resolution/v1"
+
+// DID resolution error codes, see https://www.w3.org/TR/did-core/#did-resolution-metadata.
+const (
+	// InvalidDIDError is returned when the requested DID is not a valid DID.
+	InvalidDIDError = "invalidDid"
+	// NotFoundError is returned when the DID resolver was unable to find the DID document.
+	NotFoundError = "notFound"
+	// MethodNotSupportedError is returned when the DID method is not supported by any registered VDR.
+	MethodNotSupportedError = "methodNotSupported"
+	// InternalError is returned when an unexpected error occurred during resolution.
+	InternalError = "internalError"
+)
+
+var (
+	// ErrNotFound is returned by a VDR when the DID does not exist.
+	ErrNotFound = errors.New("DID does not exist")
+	// ErrInvalidDID is returned when the DID does not conform to the DID syntax.
+	ErrInvalidDID = errors.New("invalid DID")
+	// ErrMethodNotSupported is returned when no VDR accepts the DID method.
+	ErrMethodNotSupported = errors.New("DID method not supported")
+)
+
+// ResolutionError is a DID resolution error carrying one of the W3C resolution error codes.
+type ResolutionError struct {
+	Code string
+	DID  string
+	Err  error
+}
+
+// Error returns the error message.
+func (e *ResolutionError) Error() string {
+	return fmt.Sprintf("resolve %s: %s: %v", e.DID, e.Code, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ResolutionError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorCode returns the W3C resolution error code of err, or an empty string when err is not
+// a ResolutionError.
+func ErrorCode(err error) string {
+	var resErr *ResolutionError
+	if errors.As(err, &resErr) {
+		return resErr.Code
+	}
+
+	return ""
+}
+
+// VDR verifiable data registry interface, implemented once per DID method.
+type VDR interface {
+	Read(did string, opts ...DIDMethodOption) (*did.DocResolution, error)
+	Create(doc *did.Document, opts ...DIDMethodOption) (*did.DocResolution, error)
+	Accept(method string, opts ...DIDMethodOption) bool
+	Update(doc *did.Document, opts ...DIDMethodOption) error
+	Deactivate(did string, opts ...DIDMethodOption) error
+	Close() error
+}
+
+// Resolver resolves a DID (or a DID URL carrying DID parameters) to its DID document.
+type Resolver interface {
+	Resolve(did string, opts ...DIDMethodOption) (*did.DocResolution, error)
+}
+
+// DIDMethodOpts did method opts.
+type DIDMethodOpts struct {
+	Values map[string]interface{}
+}
+
+// DIDMethodOption is a did method option.
+type DIDMethodOption func(opts *DIDMethodOpts)
+
+// WithOption add option for did method.
+func WithOption(name string, value interface{}) DIDMethodOption {
+	return func(didMethodOpts *DIDMethodOpts) {
+		didMethodOpts.Values[name] = value
+	}
+}
+
+// GetDIDMethodOpts applies the given options and returns the resulting option values.
+func GetDIDMethodOpts(opts ...DIDMethodOption) *DIDMethodOpts {
+	didMethodOpts := &DIDMethodOpts{Values: make(map[string]interface{})}
+
+	for _, opt := range opts {
+		opt(didMethodOpts)
+	}
+
+	return didMethodOpts
+}
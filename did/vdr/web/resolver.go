@@ -0,0 +1,95 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/zRich/zFusion/common/logging"
+	"github.com/zRich/zFusion/did"
+	"github.com/zRich/zFusion/did/vdr"
+)
+
+var logger = logging.GetLogger("vdr.web") //nolint:gochecknoglobals
+
+// Read fetches the did:web document from its web server, validates it and checks that its ID matches
+// the requested DID.
+func (v *VDR) Read(didWeb string, opts ...vdr.DIDMethodOption) (*did.DocResolution, error) {
+	didMethodOpts := vdr.GetDIDMethodOpts(opts...)
+	useHTTP, _ := didMethodOpts.Values[UseHTTPOpt].(bool) //nolint:errcheck
+
+	didURL, err := did.ParseDIDURL(didWeb)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", vdr.ErrInvalidDID, err)
+	}
+
+	didID := didURL.DID.String()
+
+	address, err := URL(didID, useHTTP)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := v.fetch(address)
+	if err != nil {
+		return nil, fmt.Errorf("resolve %s: %w", didID, err)
+	}
+
+	doc, err := did.ParseDocument(data)
+	if err != nil {
+		return nil, fmt.Errorf("resolve %s: invalid DID document: %w", didID, err)
+	}
+
+	if doc.ID != didID {
+		return nil, fmt.Errorf("resolve %s: document ID %s does not match the DID", didID, doc.ID)
+	}
+
+	return &did.DocResolution{
+		Context:          vdr.ContextDIDResolution,
+		DIDDocument:      doc,
+		DocumentMetadata: &did.DocumentMetadata{},
+	}, nil
+}
+
+func (v *VDR) fetch(address string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), v.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, address, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/did+json, application/json")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch %s: %w", address, err)
+	}
+
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			logger.Warnf("failed to close response body: %s", closeErr)
+		}
+	}()
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+		return nil, fmt.Errorf("%w: %s returned status %d", vdr.ErrNotFound, address, resp.StatusCode)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch %s: unexpected status %d", address, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxDocumentSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", address, err)
+	}
+
+	if len(data) > maxDocumentSize {
+		return nil, fmt.Errorf("fetch %s: document exceeds %d bytes", address, maxDocumentSize)
+	}
+
+	return data, nil
+}
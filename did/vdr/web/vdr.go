@@ -0,0 +1,160 @@
+// Package web implements the did:web method, see https://w3c-ccg.github.io/did-method-web/.
+package web
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/zRich/zFusion/did"
+	"github.com/zRich/zFusion/did/vdr"
+)
+
+const (
+	// DIDMethod did method.
+	DIDMethod = "web"
+
+	// UseHTTPOpt is the DID method option to fetch documents over plain HTTP instead of HTTPS. It is meant for
+	// tests only.
+	UseHTTPOpt = "useHTTP"
+
+	defaultPath     = "/.well-known/did.json"
+	documentPath    = "/did.json"
+	defaultTimeout  = 10 * time.Second
+	maxDocumentSize = 1 << 20
+)
+
+// HTTPClient sends the HTTP requests fetching DID documents.
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Option configures the VDR.
+type Option func(opts *VDR)
+
+// WithHTTPClient sets the HTTP client used to fetch DID documents. It takes precedence over WithTimeout and
+// WithRootCAs.
+func WithHTTPClient(client HTTPClient) Option {
+	return func(opts *VDR) {
+		opts.client = client
+	}
+}
+
+// WithTimeout sets the timeout of the HTTP requests fetching DID documents.
+func WithTimeout(timeout time.Duration) Option {
+	return func(opts *VDR) {
+		opts.timeout = timeout
+	}
+}
+
+// WithRootCAs sets the root certificate authorities trusted when fetching DID documents.
+func WithRootCAs(rootCAs *x509.CertPool) Option {
+	return func(opts *VDR) {
+		opts.rootCAs = rootCAs
+	}
+}
+
+// VDR implements the did:web method.
+type VDR struct {
+	client  HTTPClient
+	timeout time.Duration
+	rootCAs *x509.CertPool
+}
+
+// New returns a new did:web VDR.
+func New(opts ...Option) *VDR {
+	v := &VDR{timeout: defaultTimeout}
+
+	for _, opt := range opts {
+		opt(v)
+	}
+
+	if v.client == nil {
+		v.client = &http.Client{
+			Timeout: v.timeout,
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: &tls.Config{RootCAs: v.rootCAs, MinVersion: tls.VersionTLS12},
+			},
+		}
+	}
+
+	return v
+}
+
+// Accept accepts the did:web method.
+func (v *VDR) Accept(method string, _ ...vdr.DIDMethodOption) bool {
+	return method == DIDMethod
+}
+
+// Create is not supported: did:web documents are published by hosting them on a web server.
+func (v *VDR) Create(*did.Document, ...vdr.DIDMethodOption) (*did.DocResolution, error) {
+	return nil, errors.New("create is not supported by did:web, publish the document on the web server instead")
+}
+
+// Update is not supported: did:web documents are updated on the web server.
+func (v *VDR) Update(*did.Document, ...vdr.DIDMethodOption) error {
+	return errors.New("update is not supported by did:web")
+}
+
+// Deactivate is not supported: did:web documents are deactivated by removing them from the web server.
+func (v *VDR) Deactivate(string, ...vdr.DIDMethodOption) error {
+	return errors.New("deactivate is not supported by did:web")
+}
+
+// Close frees resources being maintained by VDR.
+func (v *VDR) Close() error {
+	return nil
+}
+
+// URL maps a did:web DID to the URL of its DID document, e.g. did:web:example.com:user:alice is
+// mapped to https://example.com/user/alice/did.json and did:web:example.com to
+// https://example.com/.well-known/did.json.
+func URL(didWeb string, useHTTP bool) (string, error) {
+	parsed, err := did.Parse(didWeb)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", vdr.ErrInvalidDID, err)
+	}
+
+	if parsed.Method != DIDMethod {
+		return "", fmt.Errorf("%w: not a did:web: %s", vdr.ErrInvalidDID, didWeb)
+	}
+
+	segments := strings.Split(parsed.MethodSpecificID, ":")
+
+	host, err := url.PathUnescape(segments[0])
+	if err != nil || host == "" || strings.ContainsAny(host, "/?#@") {
+		return "", fmt.Errorf("%w: invalid did:web domain %q", vdr.ErrInvalidDID, segments[0])
+	}
+
+	path := defaultPath
+
+	if len(segments) > 1 {
+		for i, segment := range segments[1:] {
+			if segment == "" {
+				return "", fmt.Errorf("%w: empty did:web path segment", vdr.ErrInvalidDID)
+			}
+
+			segments[i+1], err = url.PathUnescape(segment)
+			if err != nil {
+				return "", fmt.Errorf("%w: invalid did:web path segment %q", vdr.ErrInvalidDID, segment)
+			}
+
+			segments[i+1] = url.PathEscape(segments[i+1])
+		}
+
+		path = "/" + strings.Join(segments[1:], "/") + documentPath
+	}
+
+	scheme := "https"
+	if useHTTP {
+		scheme = "http"
+	}
+
+	return fmt.Sprintf("%s://%s%s", scheme, host, path), nil
+}
@@ -0,0 +1,185 @@
+package web
+
+import (
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zRich/zFusion/did"
+	"github.com/zRich/zFusion/did/vdr"
+)
+
+const docTemplate = `{
+  "@context": ["https://www.w3.org/ns/did/v1"],
+  "id": "%s",
+  "verificationMethod": [{
+    "id": "%s#key-1",
+    "type": "Ed25519VerificationKey2018",
+    "controller": "%s",
+    "publicKeyBase58": "H3C2AVvLMv6gmMNam3uVAjZpfkcJCwDwnZn6z3wXmqPV"
+  }],
+  "authentication": ["%s#key-1"]
+}`
+
+func newDoc(didID string) string {
+	return fmt.Sprintf(docTemplate, didID, didID, didID, didID)
+}
+
+func newServer(t *testing.T, docs map[string]string) (*httptest.Server, string) {
+	t.Helper()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		doc, ok := docs[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/did+json")
+		_, err := w.Write([]byte(doc))
+		require.NoError(t, err)
+	}))
+	t.Cleanup(server.Close)
+
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	return server, "did:web:" + strings.ReplaceAll(u.Host, ":", "%3A")
+}
+
+func TestURL(t *testing.T) {
+	tests := []struct {
+		did string
+		url string
+	}{
+		{"did:web:w3c-ccg.github.io", "https://w3c-ccg.github.io/.well-known/did.json"},
+		{"did:web:w3c-ccg.github.io:user:alice", "https://w3c-ccg.github.io/user/alice/did.json"},
+		{"did:web:example.com%3A3000:user:alice", "https://example.com:3000/user/alice/did.json"},
+	}
+
+	for _, tc := range tests {
+		address, err := URL(tc.did, false)
+		require.NoError(t, err)
+		require.Equal(t, tc.url, address)
+	}
+
+	address, err := URL("did:web:example.com", true)
+	require.NoError(t, err)
+	require.Equal(t, "http://example.com/.well-known/did.json", address)
+
+	_, err = URL("did:key:example.com", false)
+	require.ErrorIs(t, err, vdr.ErrInvalidDID)
+
+	_, err = URL("did:web:example.com%2Fpath", false)
+	require.ErrorIs(t, err, vdr.ErrInvalidDID)
+
+	_, err = URL("invalid", false)
+	require.ErrorIs(t, err, vdr.ErrInvalidDID)
+}
+
+func TestRead(t *testing.T) {
+	docs := map[string]string{}
+	server, host := newServer(t, docs)
+
+	rootDID := host
+	userDID := host + ":user:alice"
+	mismatchDID := host + ":user:mallory"
+	invalidDID := host + ":user:invalid"
+
+	docs["/.well-known/did.json"] = newDoc(rootDID)
+	docs["/user/alice/did.json"] = newDoc(userDID)
+	docs["/user/mallory/did.json"] = newDoc(userDID)
+	docs["/user/invalid/did.json"] = `{"id": 1}`
+
+	v := New(WithHTTPClient(server.Client()))
+
+	t.Run("success", func(t *testing.T) {
+		for _, didID := range []string{rootDID, userDID} {
+			docResolution, err := v.Read(didID)
+			require.NoError(t, err)
+			require.Equal(t, didID, docResolution.DIDDocument.ID)
+			require.Equal(t, didID+"#key-1", docResolution.DIDDocument.Authentication[0].VerificationMethod.ID)
+		}
+	})
+
+	t.Run("DID URL", func(t *testing.T) {
+		docResolution, err := v.Read(userDID + "#key-1")
+		require.NoError(t, err)
+		require.Equal(t, userDID, docResolution.DIDDocument.ID)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := v.Read(host + ":user:bob")
+		require.ErrorIs(t, err, vdr.ErrNotFound)
+	})
+
+	t.Run("document ID mismatch", func(t *testing.T) {
+		_, err := v.Read(mismatchDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "does not match the DID")
+	})
+
+	t.Run("invalid document", func(t *testing.T) {
+		_, err := v.Read(invalidDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid DID document")
+	})
+
+	t.Run("untrusted certificate", func(t *testing.T) {
+		_, err := New(WithTimeout(time.Second)).Read(rootDID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "certificate")
+	})
+
+	t.Run("trusted root CA", func(t *testing.T) {
+		pool := x509.NewCertPool()
+		pool.AddCert(server.Certificate())
+
+		docResolution, err := New(WithRootCAs(pool), WithTimeout(time.Second)).Read(rootDID)
+		require.NoError(t, err)
+		require.Equal(t, rootDID, docResolution.DIDDocument.ID)
+	})
+
+	t.Run("plain HTTP", func(t *testing.T) {
+		httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, err := w.Write([]byte(newDoc("did:web:" + strings.ReplaceAll(r.Host, ":", "%3A"))))
+			require.NoError(t, err)
+		}))
+		defer httpServer.Close()
+
+		u, err := url.Parse(httpServer.URL)
+		require.NoError(t, err)
+
+		didID := "did:web:" + strings.ReplaceAll(u.Host, ":", "%3A")
+
+		docResolution, err := New().Read(didID, vdr.WithOption(UseHTTPOpt, true))
+		require.NoError(t, err)
+		require.Equal(t, didID, docResolution.DIDDocument.ID)
+	})
+
+	t.Run("registry", func(t *testing.T) {
+		registry := vdr.New(vdr.WithVDR(v))
+
+		_, err := registry.Resolve(host + ":user:bob")
+		require.Equal(t, vdr.NotFoundError, vdr.ErrorCode(err))
+	})
+}
+
+func TestVDR(t *testing.T) {
+	v := New()
+	require.True(t, v.Accept(DIDMethod))
+	require.False(t, v.Accept("key"))
+
+	_, err := v.Create(&did.Document{})
+	require.Error(t, err)
+	require.Error(t, v.Update(&did.Document{}))
+	require.Error(t, v.Deactivate("did:web:example.com"))
+	require.NoError(t, v.Close())
+}
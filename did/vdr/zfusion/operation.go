@@ -0,0 +1,365 @@
+package zfusion
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/zRich/zFusion/common/crypto"
+	"github.com/zRich/zFusion/common/crypto/jwk"
+	"github.com/zRich/zFusion/did"
+	"github.com/zRich/zFusion/did/patch"
+)
+
+// OperationType is the type of a did:zfusion operation.
+type OperationType string
+
+const (
+	// OperationCreate creates a new DID.
+	OperationCreate OperationType = "create"
+	// OperationUpdate replaces or patches the DID document of an existing DID.
+	OperationUpdate OperationType = "update"
+	// OperationRecover replaces the DID document of a DID with the key of its recovery commitment.
+	OperationRecover OperationType = "recover"
+	// OperationDeactivate permanently deactivates a DID.
+	OperationDeactivate OperationType = "deactivate"
+)
+
+// Operation is a signed did:zfusion lifecycle operation. Create operations are signed with a
+// capabilityInvocation key of the new document.
+//
+// A create operation may commit to the keys of the next operations with an update and a recovery commitment,
+// see patch.Commitment. Later operations of such a DID are signed with the committed key, which they reveal:
+// update operations with the key of the update commitment, recover and deactivate operations with the key of
+// the recovery commitment. Update operations commit to the next update key, and recover operations to both
+// the next update and recovery keys. Operations of a DID without commitments are signed with a
+// capabilityInvocation key of the current document.
+type Operation struct {
+	Type OperationType `json:"type"`
+	DID  string        `json:"did"`
+	// Document is the new DID document for create, recover and update operations.
+	Document json.RawMessage `json:"document,omitempty"`
+	// Patches are applied to the current DID document by update operations without Document.
+	Patches []patch.Patch `json:"patches,omitempty"`
+	// Previous is the update commitment of the current document version, or its recovery commitment for
+	// recover and deactivate operations, of DIDs with commitments, and the hash of the latest operation of DIDs
+	// without commitments. It binds operations to the version they apply to and prevents replays.
+	Previous string `json:"previous,omitempty"`
+	// UpdateCommitment commits to the key of the next update operation.
+	UpdateCommitment string `json:"updateCommitment,omitempty"`
+	// RecoveryCommitment commits to the key of the next recover or deactivate operation.
+	RecoveryCommitment string `json:"recoveryCommitment,omitempty"`
+	// RevealValue reveals SigningKey, the committed key which signed the operation.
+	RevealValue string   `json:"revealValue,omitempty"`
+	SigningKey  *jwk.JWK `json:"signingKey,omitempty"`
+	// KeyID is the ID of the verification method that signed the operation.
+	KeyID     string `json:"keyId"`
+	Signature []byte `json:"signature,omitempty"`
+}
+
+// NewCreateOperation creates an unsigned create operation for the given document template. The DID is
+// derived from the template, which must therefore not have an ID and must use relative verification method
+// and service IDs such as "#key-1".
+func NewCreateOperation(doc *did.Document) (*Operation, error) {
+	if doc.ID != "" {
+		return nil, errors.New("document of a create operation must not have an ID")
+	}
+
+	template, err := doc.JSONBytes()
+	if err != nil {
+		return nil, fmt.Errorf("create operation: %w", err)
+	}
+
+	op := &Operation{Type: OperationCreate, DID: deriveDID(template), Document: template}
+
+	if _, err = op.document(); err != nil {
+		return nil, fmt.Errorf("create operation: %w", err)
+	}
+
+	return op, nil
+}
+
+// NewUpdateOperation creates an unsigned update operation replacing the document of doc.ID. previous is the
+// update commitment of the current version, as returned in the document method metadata.
+func NewUpdateOperation(doc *did.Document, previous string) (*Operation, error) {
+	docBytes, err := doc.JSONBytes()
+	if err != nil {
+		return nil, fmt.Errorf("update operation: %w", err)
+	}
+
+	op := &Operation{Type: OperationUpdate, DID: doc.ID, Document: docBytes, Previous: previous}
+
+	if _, err = op.document(); err != nil {
+		return nil, fmt.Errorf("update operation: %w", err)
+	}
+
+	return op, nil
+}
+
+// NewPatchOperation creates an unsigned update operation applying patches to the current document of didID.
+func NewPatchOperation(didID, previous string, patches ...patch.Patch) *Operation {
+	return &Operation{Type: OperationUpdate, DID: didID, Patches: patches, Previous: previous}
+}
+
+// NewRecoverOperation creates an unsigned recover operation replacing the document of doc.ID. previous is the
+// recovery commitment of the current version, as returned in the document method metadata. The operation
+// must commit to the next update and recovery keys.
+func NewRecoverOperation(doc *did.Document, previous string) (*Operation, error) {
+	docBytes, err := doc.JSONBytes()
+	if err != nil {
+		return nil, fmt.Errorf("recover operation: %w", err)
+	}
+
+	op := &Operation{Type: OperationRecover, DID: doc.ID, Document: docBytes, Previous: previous}
+
+	if _, err = op.document(); err != nil {
+		return nil, fmt.Errorf("recover operation: %w", err)
+	}
+
+	return op, nil
+}
+
+// NewDeactivateOperation creates an unsigned deactivate operation.
+func NewDeactivateOperation(didID, previous string) *Operation {
+	return &Operation{Type: OperationDeactivate, DID: didID, Previous: previous}
+}
+
+// Sign signs the operation with the given signer, keyID being the ID of the signer's verification method.
+// Relative key IDs are resolved against the operation DID.
+func (op *Operation) Sign(keyID string, signer crypto.Signer) error {
+	op.KeyID = did.AbsoluteID(op.DID, keyID)
+	op.RevealValue = ""
+	op.SigningKey = nil
+
+	return op.sign(signer)
+}
+
+// SignWithCommittedKey signs the operation with the committed key, which is revealed in the operation. key is
+// the public key of signer.
+func (op *Operation) SignWithCommittedKey(key *jwk.JWK, signer crypto.Signer) error {
+	revealValue, err := patch.RevealValue(key)
+	if err != nil {
+		return fmt.Errorf("sign %s operation: %w", op.Type, err)
+	}
+
+	op.KeyID = ""
+	op.RevealValue = revealValue
+	op.SigningKey = key
+
+	return op.sign(signer)
+}
+
+func (op *Operation) sign(signer crypto.Signer) error {
+	payload, err := op.signingPayload()
+	if err != nil {
+		return err
+	}
+
+	op.Signature, err = signer.Sign(payload)
+	if err != nil {
+		return fmt.Errorf("sign %s operation: %w", op.Type, err)
+	}
+
+	return nil
+}
+
+// document returns the DID document carried by a create, recover or update operation. The document of a
+// create operation is a template without ID, which is materialized with the operation DID.
+func (op *Operation) document() (*did.Document, error) {
+	if len(op.Document) == 0 {
+		return nil, fmt.Errorf("%s operation has no document", op.Type)
+	}
+
+	docBytes := []byte(op.Document)
+
+	if op.Type == OperationCreate {
+		if op.DID != deriveDID(op.Document) {
+			return nil, fmt.Errorf("DID %s does not match the create operation document", op.DID)
+		}
+
+		template := map[string]interface{}{}
+		if err := json.Unmarshal(op.Document, &template); err != nil {
+			return nil, fmt.Errorf("unmarshal document template: %w", err)
+		}
+
+		if _, ok := template["id"]; ok {
+			return nil, errors.New("document template must not have an ID")
+		}
+
+		template["id"] = op.DID
+
+		var err error
+
+		docBytes, err = json.Marshal(template)
+		if err != nil {
+			return nil, fmt.Errorf("marshal document: %w", err)
+		}
+	}
+
+	doc, err := did.ParseDocument(docBytes, did.WithStrictValidation())
+	if err != nil {
+		return nil, err
+	}
+
+	if err = op.checkDocument(doc); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// newDocument returns the document of the version created by a create, recover or update operation applying
+// to the current document.
+func (op *Operation) newDocument(current *did.Document) (*did.Document, error) {
+	if op.Type != OperationUpdate || len(op.Patches) == 0 {
+		return op.document()
+	}
+
+	if len(op.Document) != 0 {
+		return nil, errors.New("update operation cannot have both a document and patches")
+	}
+
+	doc, err := patch.Apply(current, op.Patches...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = op.checkDocument(doc); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+func (op *Operation) checkDocument(doc *did.Document) error {
+	if doc.ID != op.DID {
+		return fmt.Errorf("document ID %s does not match operation DID %s", doc.ID, op.DID)
+	}
+
+	if len(doc.CapabilityInvocation) == 0 {
+		return errors.New("document must have at least one capabilityInvocation key")
+	}
+
+	return nil
+}
+
+// checkCommitments checks the commitments and reveal value carried by the operation, which depend on its type.
+func (op *Operation) checkCommitments() error {
+	switch op.Type {
+	case OperationCreate:
+		if (op.UpdateCommitment == "") != (op.RecoveryCommitment == "") {
+			return errors.New("create operation must have both an update and a recovery commitment, or none")
+		}
+	case OperationUpdate:
+		if op.RecoveryCommitment != "" {
+			return errors.New("update operation cannot have a recovery commitment")
+		}
+	case OperationRecover:
+		if op.UpdateCommitment == "" || op.RecoveryCommitment == "" {
+			return errors.New("recover operation must have an update and a recovery commitment")
+		}
+	case OperationDeactivate:
+		if op.UpdateCommitment != "" || op.RecoveryCommitment != "" {
+			return errors.New("deactivate operation cannot have commitments")
+		}
+	}
+
+	if op.Type == OperationCreate && (op.RevealValue != "" || op.SigningKey != nil) {
+		return errors.New("create operation cannot reveal a key")
+	}
+
+	return nil
+}
+
+// verify verifies the operation signature against the capabilityInvocation keys of doc.
+func (op *Operation) verify(doc *did.Document) error {
+	var key *did.VerificationMethod
+
+	for i := range doc.CapabilityInvocation {
+		if doc.CapabilityInvocation[i].VerificationMethod.ID == op.KeyID {
+			key = &doc.CapabilityInvocation[i].VerificationMethod
+
+			break
+		}
+	}
+
+	if key == nil {
+		return fmt.Errorf("%w: %s is not a capabilityInvocation key of %s", ErrUnauthorized, op.KeyID, doc.ID)
+	}
+
+	payload, err := op.signingPayload()
+	if err != nil {
+		return err
+	}
+
+	if err = key.Verify(payload, op.Signature); err != nil {
+		return fmt.Errorf("%w: %v", ErrUnauthorized, err)
+	}
+
+	return nil
+}
+
+// verifyCommitted verifies that the operation reveals the key committed to by commitment, and is signed with it.
+func (op *Operation) verifyCommitted(commitment string) error {
+	if op.SigningKey == nil || op.RevealValue == "" {
+		return fmt.Errorf("%w: %s operation must reveal the committed key", ErrUnauthorized, op.Type)
+	}
+
+	if err := patch.CheckReveal(op.SigningKey, op.RevealValue, commitment); err != nil {
+		return fmt.Errorf("%w: %v", ErrUnauthorized, err)
+	}
+
+	keyType, err := op.SigningKey.KeyType()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnauthorized, err)
+	}
+
+	pubKey, err := op.SigningKey.PublicKeyBytes()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnauthorized, err)
+	}
+
+	payload, err := op.signingPayload()
+	if err != nil {
+		return err
+	}
+
+	if err = crypto.Verify(keyType, pubKey, payload, op.Signature); err != nil {
+		return fmt.Errorf("%w: %v", ErrUnauthorized, err)
+	}
+
+	return nil
+}
+
+func (op *Operation) signingPayload() ([]byte, error) {
+	unsigned := *op
+	unsigned.Signature = nil
+
+	payload, err := json.Marshal(&unsigned)
+	if err != nil {
+		return nil, fmt.Errorf("marshal %s operation: %w", op.Type, err)
+	}
+
+	return payload, nil
+}
+
+// hash returns the base64url encoded SHA-256 hash of the signed operation.
+func (op *Operation) hash() (string, []byte, error) {
+	opBytes, err := json.Marshal(op)
+	if err != nil {
+		return "", nil, fmt.Errorf("marshal %s operation: %w", op.Type, err)
+	}
+
+	digest := sha256.Sum256(opBytes)
+
+	return base64.RawURLEncoding.EncodeToString(digest[:]), opBytes, nil
+}
+
+func deriveDID(template []byte) string {
+	digest := sha256.Sum256(template)
+
+	return fmt.Sprintf("did:%s:%s", DIDMethod, base58.Encode(digest[:]))
+}
@@ -0,0 +1,584 @@
+// Package zfusion implements the did:zfusion method, whose DID documents are hosted by zFusion peers.
+// DIDs are managed through signed create, update, recover and deactivate operations which are persisted,
+// together with every document version, in a storage/spi.Store. Update operations either replace the document
+// or patch it, see did/patch.
+package zfusion
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/zRich/zFusion/common/crypto"
+	"github.com/zRich/zFusion/common/crypto/jwk"
+	"github.com/zRich/zFusion/did"
+	"github.com/zRich/zFusion/did/patch"
+	"github.com/zRich/zFusion/did/vdr"
+	"github.com/zRich/zFusion/storage/spi"
+)
+
+const (
+	// DIDMethod did method.
+	DIDMethod = "zfusion"
+	// StoreName is the recommended name of the store holding did:zfusion operations.
+	StoreName = "zfusion"
+
+	// SignerOpt is the DID method option carrying the crypto.Signer which signs operations.
+	SignerOpt = "signer"
+	// KeyIDOpt is the DID method option carrying the ID of the signer's verification method.
+	KeyIDOpt = "keyID"
+	// CommittedKeyOpt is the DID method option carrying the *jwk.JWK of the signer, when it is the committed
+	// key of a DID with commitments rather than a verification method.
+	CommittedKeyOpt = "committedKey"
+	// UpdateCommitmentOpt is the DID method option carrying the commitment to the next update key.
+	UpdateCommitmentOpt = "updateCommitment"
+	// RecoveryCommitmentOpt is the DID method option carrying the commitment to the next recovery key.
+	RecoveryCommitmentOpt = "recoveryCommitment"
+
+	// VersionIDParam is the DID parameter selecting a document version by ID.
+	VersionIDParam = vdr.VersionIDParam
+	// VersionTimeParam is the DID parameter selecting the document version valid at the given time.
+	VersionTimeParam = vdr.VersionTimeParam
+
+	protocolVersion = 1
+)
+
+var (
+	// ErrUnauthorized is returned when an operation is not signed by a capabilityInvocation key, or by the
+	// committed key.
+	ErrUnauthorized = errors.New("operation not authorized")
+	// ErrDeactivated is returned when an operation targets a deactivated DID.
+	ErrDeactivated = errors.New("DID is deactivated")
+	// ErrConflict is returned when an operation does not apply to the current document version.
+	ErrConflict = errors.New("operation conflicts with the current document version")
+)
+
+// version is a document version created by an operation.
+type version struct {
+	VersionID string    `json:"versionId"`
+	Operation Operation `json:"operation"`
+	// Document is the document produced by an update operation carrying patches.
+	Document json.RawMessage `json:"document,omitempty"`
+	Hash     string          `json:"hash"`
+	Time     time.Time       `json:"time"`
+}
+
+func (v *version) document() (*did.Document, error) {
+	if len(v.Document) == 0 {
+		return v.Operation.document()
+	}
+
+	return did.ParseDocument(v.Document, did.WithStrictValidation())
+}
+
+// record is the stored history of a DID.
+type record struct {
+	Versions []version `json:"versions"`
+}
+
+func (r *record) deactivated() bool {
+	return len(r.Versions) > 0 && r.Versions[len(r.Versions)-1].Operation.Type == OperationDeactivate
+}
+
+// commitments returns the current update and recovery commitments of the DID, which are empty for DIDs
+// created without commitments.
+func (r *record) commitments() (update, recovery string) {
+	for i := range r.Versions {
+		op := &r.Versions[i].Operation
+
+		if op.UpdateCommitment != "" {
+			update = op.UpdateCommitment
+		}
+
+		if op.RecoveryCommitment != "" {
+			recovery = op.RecoveryCommitment
+		}
+	}
+
+	return update, recovery
+}
+
+// Option configures the VDR.
+type Option func(v *VDR)
+
+// WithClock sets the time source used to timestamp operations.
+func WithClock(now func() time.Time) Option {
+	return func(v *VDR) {
+		v.now = now
+	}
+}
+
+// VDR implements the did:zfusion method.
+type VDR struct {
+	store spi.Store
+	now   func() time.Time
+	lock  sync.Mutex
+}
+
+// New returns a did:zfusion VDR persisting operations in the given store.
+func New(store spi.Store, opts ...Option) *VDR {
+	v := &VDR{store: store, now: time.Now}
+
+	for _, opt := range opts {
+		opt(v)
+	}
+
+	return v
+}
+
+// Accept accepts the did:zfusion method.
+func (v *VDR) Accept(method string, _ ...vdr.DIDMethodOption) bool {
+	return method == DIDMethod
+}
+
+// Close frees resources being maintained by VDR.
+func (v *VDR) Close() error {
+	return nil
+}
+
+// Create creates a DID from a document template, see NewCreateOperation. The operation is signed with
+// the signer passed in the SignerOpt option, keyID (KeyIDOpt) being a capabilityInvocation key of doc. The
+// UpdateCommitmentOpt and RecoveryCommitmentOpt options create a DID with commitments.
+func (v *VDR) Create(doc *did.Document, opts ...vdr.DIDMethodOption) (*did.DocResolution, error) {
+	op, err := NewCreateOperation(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = signOperation(op, opts...); err != nil {
+		return nil, err
+	}
+
+	return v.Apply(op)
+}
+
+// Update replaces the document of doc.ID. The operation is signed with the signer passed in the SignerOpt
+// option, keyID (KeyIDOpt) being a capabilityInvocation key of the current document. For DIDs with
+// commitments, the signer is the key of the update commitment (CommittedKeyOpt) and UpdateCommitmentOpt
+// commits to the next update key.
+func (v *VDR) Update(doc *did.Document, opts ...vdr.DIDMethodOption) error {
+	current, err := v.Read(doc.ID)
+	if err != nil {
+		return err
+	}
+
+	op, err := NewUpdateOperation(doc, previous(current, false))
+	if err != nil {
+		return err
+	}
+
+	if err = signOperation(op, opts...); err != nil {
+		return err
+	}
+
+	_, err = v.Apply(op)
+
+	return err
+}
+
+// Patch applies patches to the document of didID. The operation is signed as by Update.
+func (v *VDR) Patch(didID string, patches []patch.Patch, opts ...vdr.DIDMethodOption) error {
+	current, err := v.Read(didID)
+	if err != nil {
+		return err
+	}
+
+	op := NewPatchOperation(didID, previous(current, false), patches...)
+
+	if err = signOperation(op, opts...); err != nil {
+		return err
+	}
+
+	_, err = v.Apply(op)
+
+	return err
+}
+
+// Recover replaces the document of doc.ID of a DID with commitments. The operation is signed with the key of
+// the recovery commitment (CommittedKeyOpt), the signer being passed in the SignerOpt option, and commits to
+// the next update and recovery keys (UpdateCommitmentOpt and RecoveryCommitmentOpt).
+func (v *VDR) Recover(doc *did.Document, opts ...vdr.DIDMethodOption) error {
+	current, err := v.Read(doc.ID)
+	if err != nil {
+		return err
+	}
+
+	op, err := NewRecoverOperation(doc, current.DocumentMetadata.Method.RecoveryCommitment)
+	if err != nil {
+		return err
+	}
+
+	if err = signOperation(op, opts...); err != nil {
+		return err
+	}
+
+	_, err = v.Apply(op)
+
+	return err
+}
+
+// Deactivate deactivates the DID. The operation is signed with the signer passed in the SignerOpt option,
+// keyID (KeyIDOpt) being a capabilityInvocation key of the current document. For DIDs with commitments, the
+// signer is the key of the recovery commitment (CommittedKeyOpt).
+func (v *VDR) Deactivate(didID string, opts ...vdr.DIDMethodOption) error {
+	current, err := v.Read(didID)
+	if err != nil {
+		return err
+	}
+
+	if current.DocumentMetadata.Deactivated {
+		return ErrDeactivated
+	}
+
+	op := NewDeactivateOperation(didID, previous(current, true))
+
+	if err = signOperation(op, opts...); err != nil {
+		return err
+	}
+
+	_, err = v.Apply(op)
+
+	return err
+}
+
+// previous returns the previous value of the next operation on the current version of a DID: the update
+// commitment, or the recovery commitment if recovery is set, of DIDs with commitments, and the hash of the
+// latest operation of other DIDs.
+func previous(current *did.DocResolution, recovery bool) string {
+	method := current.DocumentMetadata.Method
+
+	switch {
+	case method.RecoveryCommitment == "":
+		return method.PreviousOperation
+	case recovery:
+		return method.RecoveryCommitment
+	default:
+		return method.UpdateCommitment
+	}
+}
+
+func signOperation(op *Operation, opts ...vdr.DIDMethodOption) error {
+	didMethodOpts := vdr.GetDIDMethodOpts(opts...)
+
+	if commitment, ok := didMethodOpts.Values[UpdateCommitmentOpt].(string); ok {
+		op.UpdateCommitment = commitment
+	}
+
+	if commitment, ok := didMethodOpts.Values[RecoveryCommitmentOpt].(string); ok {
+		op.RecoveryCommitment = commitment
+	}
+
+	signer, ok := didMethodOpts.Values[SignerOpt].(crypto.Signer)
+	if !ok {
+		return fmt.Errorf("%s option is required to sign the %s operation", SignerOpt, op.Type)
+	}
+
+	if key, ok := didMethodOpts.Values[CommittedKeyOpt].(*jwk.JWK); ok {
+		return op.SignWithCommittedKey(key, signer)
+	}
+
+	keyID, ok := didMethodOpts.Values[KeyIDOpt].(string)
+	if !ok {
+		return fmt.Errorf("%s option is required to sign the %s operation", KeyIDOpt, op.Type)
+	}
+
+	return op.Sign(keyID, signer)
+}
+
+// Apply validates a signed operation against the current state of the DID and persists it. Operations
+// created by other peers can be applied directly.
+func (v *VDR) Apply(op *Operation) (*did.DocResolution, error) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	rec, err := v.getRecord(op.DID)
+	if err != nil && !errors.Is(err, vdr.ErrNotFound) {
+		return nil, err
+	}
+
+	doc, err := validateOperation(rec, op)
+	if err != nil {
+		return nil, fmt.Errorf("apply %s operation for %s: %w", op.Type, op.DID, err)
+	}
+
+	hash, _, err := op.hash()
+	if err != nil {
+		return nil, err
+	}
+
+	if rec == nil {
+		rec = &record{}
+	}
+
+	ver := version{
+		VersionID: strconv.Itoa(len(rec.Versions) + 1),
+		Operation: *op,
+		Hash:      hash,
+		Time:      v.now().UTC(),
+	}
+
+	if len(op.Patches) > 0 {
+		if ver.Document, err = doc.JSONBytes(); err != nil {
+			return nil, fmt.Errorf("marshal patched document: %w", err)
+		}
+	}
+
+	rec.Versions = append(rec.Versions, ver)
+
+	recBytes, err := json.Marshal(rec)
+	if err != nil {
+		return nil, fmt.Errorf("marshal DID record: %w", err)
+	}
+
+	if err = v.store.Put(op.DID, recBytes); err != nil {
+		return nil, fmt.Errorf("store DID record: %w", err)
+	}
+
+	return resolveVersion(rec, len(rec.Versions)-1)
+}
+
+// validateOperation validates op against the DID record, and returns the document of the version it creates,
+// which is nil for deactivate operations.
+func validateOperation(rec *record, op *Operation) (*did.Document, error) {
+	if err := op.checkCommitments(); err != nil {
+		return nil, err
+	}
+
+	if op.Type == OperationCreate {
+		if rec != nil {
+			return nil, fmt.Errorf("%w: DID already exists", ErrConflict)
+		}
+
+		doc, err := op.document()
+		if err != nil {
+			return nil, err
+		}
+
+		return doc, op.verify(doc)
+	}
+
+	if op.Type != OperationUpdate && op.Type != OperationRecover && op.Type != OperationDeactivate {
+		return nil, fmt.Errorf("unsupported operation type: %s", op.Type)
+	}
+
+	if rec == nil {
+		return nil, vdr.ErrNotFound
+	}
+
+	if rec.deactivated() {
+		return nil, ErrDeactivated
+	}
+
+	latest := rec.Versions[len(rec.Versions)-1]
+
+	current, err := latest.document()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = authorizeOperation(rec, op, current); err != nil {
+		return nil, err
+	}
+
+	if op.Type == OperationDeactivate {
+		return nil, nil
+	}
+
+	return op.newDocument(current)
+}
+
+// authorizeOperation checks that op applies to the latest version and is signed by a capabilityInvocation key
+// of the current document or, for DIDs with commitments, by the committed key. Updates and recovers of DIDs with
+// commitments must rotate the committed keys.
+func authorizeOperation(rec *record, op *Operation, current *did.Document) error {
+	updateCommitment, recoveryCommitment := rec.commitments()
+
+	if recoveryCommitment == "" {
+		if op.Type == OperationRecover {
+			return fmt.Errorf("%w: %s has no recovery commitment", ErrUnauthorized, op.DID)
+		}
+
+		if op.UpdateCommitment != "" || op.RevealValue != "" {
+			return fmt.Errorf("%s has no commitments", op.DID)
+		}
+
+		latest := rec.Versions[len(rec.Versions)-1]
+
+		if op.Previous != latest.Hash {
+			return fmt.Errorf("%w: expected previous %s", ErrConflict, latest.Hash)
+		}
+
+		return op.verify(current)
+	}
+
+	commitment := recoveryCommitment
+	if op.Type == OperationUpdate {
+		commitment = updateCommitment
+	}
+
+	if op.Previous != commitment {
+		return fmt.Errorf("%w: expected previous %s", ErrConflict, commitment)
+	}
+
+	if err := op.verifyCommitted(commitment); err != nil {
+		return err
+	}
+
+	if op.Type == OperationUpdate && (op.UpdateCommitment == "" || op.UpdateCommitment == updateCommitment) {
+		return errors.New("update operation must commit to a new update key")
+	}
+
+	// a recover that keeps a commitment could be replayed to roll the document back.
+	if op.Type == OperationRecover && (op.UpdateCommitment == updateCommitment ||
+		op.RecoveryCommitment == recoveryCommitment) {
+		return errors.New("recover operation must commit to a new update and a new recovery key")
+	}
+
+	return nil
+}
+
+// Read resolves a did:zfusion DID. The versionId and versionTime DID parameters select a previous version
+// of the document.
+func (v *VDR) Read(didID string, _ ...vdr.DIDMethodOption) (*did.DocResolution, error) {
+	didURL, err := did.ParseDIDURL(didID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", vdr.ErrInvalidDID, err)
+	}
+
+	if didURL.Method != DIDMethod {
+		return nil, fmt.Errorf("%w: not a did:%s: %s", vdr.ErrInvalidDID, DIDMethod, didID)
+	}
+
+	rec, err := v.getRecord(didURL.DID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := selectVersion(rec, didURL.Queries)
+	if err != nil {
+		return nil, err
+	}
+
+	return resolveVersion(rec, index)
+}
+
+func (v *VDR) getRecord(didID string) (*record, error) {
+	recBytes, err := v.store.Get(didID)
+	if err != nil {
+		if errors.Is(err, spi.ErrDataNotFound) {
+			return nil, fmt.Errorf("%w: %s", vdr.ErrNotFound, didID)
+		}
+
+		return nil, fmt.Errorf("get DID record: %w", err)
+	}
+
+	rec := &record{}
+	if err = json.Unmarshal(recBytes, rec); err != nil {
+		return nil, fmt.Errorf("unmarshal DID record: %w", err)
+	}
+
+	return rec, nil
+}
+
+func selectVersion(rec *record, queries map[string][]string) (int, error) {
+	versionID, hasVersionID := queries[VersionIDParam]
+	versionTime, hasVersionTime := queries[VersionTimeParam]
+
+	switch {
+	case hasVersionID && hasVersionTime:
+		return 0, fmt.Errorf("%w: %s and %s are mutually exclusive", vdr.ErrInvalidDID, VersionIDParam,
+			VersionTimeParam)
+	case hasVersionID:
+		for i := range rec.Versions {
+			if rec.Versions[i].VersionID == versionID[0] {
+				return i, nil
+			}
+		}
+
+		return 0, fmt.Errorf("%w: version %s", vdr.ErrNotFound, versionID[0])
+	case hasVersionTime:
+		t, err := time.Parse(time.RFC3339, versionTime[0])
+		if err != nil {
+			return 0, fmt.Errorf("%w: invalid %s: %v", vdr.ErrInvalidDID, VersionTimeParam, err)
+		}
+
+		index := -1
+
+		for i := range rec.Versions {
+			if !rec.Versions[i].Time.After(t) {
+				index = i
+			}
+		}
+
+		if index == -1 {
+			return 0, fmt.Errorf("%w: no version at %s", vdr.ErrNotFound, versionTime[0])
+		}
+
+		return index, nil
+	default:
+		return len(rec.Versions) - 1, nil
+	}
+}
+
+// resolveVersion builds the resolution result of the given version. The document of a deactivate version
+// is the document of the version it deactivated.
+func resolveVersion(rec *record, index int) (*did.DocResolution, error) {
+	docIndex := index
+	for docIndex > 0 && rec.Versions[docIndex].Operation.Type == OperationDeactivate {
+		docIndex--
+	}
+
+	doc, err := rec.Versions[docIndex].document()
+	if err != nil {
+		return nil, fmt.Errorf("invalid stored document: %w", err)
+	}
+
+	selected := rec.Versions[index]
+	created := rec.Versions[0].Time
+
+	metadata := &did.DocumentMetadata{
+		VersionID:   selected.VersionID,
+		Deactivated: rec.deactivated(),
+		Created:     &created,
+		Method:      &did.MethodMetadata{Published: true},
+	}
+
+	doc.Created = &created
+
+	if index > 0 {
+		updated := selected.Time
+		metadata.Updated = &updated
+		doc.Updated = &updated
+	}
+
+	if index < len(rec.Versions)-1 {
+		metadata.NextVersionID = rec.Versions[index+1].VersionID
+	} else if !metadata.Deactivated {
+		metadata.Method.UpdateCommitment, metadata.Method.RecoveryCommitment = rec.commitments()
+		if metadata.Method.RecoveryCommitment == "" {
+			metadata.Method.PreviousOperation = selected.Hash
+		}
+	}
+
+	for i := 0; i <= index; i++ {
+		_, opBytes, err := rec.Versions[i].Operation.hash()
+		if err != nil {
+			return nil, err
+		}
+
+		metadata.Method.PublishedOperations = append(metadata.Method.PublishedOperations, &did.ProtocolOperation{
+			Operation:          base64.RawURLEncoding.EncodeToString(opBytes),
+			ProtocolVersion:    protocolVersion,
+			TransactionNumber:  i + 1,
+			TransactionTime:    rec.Versions[i].Time.Unix(),
+			Type:               string(rec.Versions[i].Operation.Type),
+			CanonicalReference: rec.Versions[i].Hash,
+		})
+	}
+
+	return &did.DocResolution{Context: vdr.ContextDIDResolution, DIDDocument: doc, DocumentMetadata: metadata}, nil
+}
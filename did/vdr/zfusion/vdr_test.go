@@ -0,0 +1,510 @@
+package zfusion
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zRich/zFusion/common/crypto"
+	"github.com/zRich/zFusion/common/crypto/jwk"
+	"github.com/zRich/zFusion/did"
+	"github.com/zRich/zFusion/did/patch"
+	"github.com/zRich/zFusion/did/vdr"
+	"github.com/zRich/zFusion/storage/leveldb"
+)
+
+const ed25519KeyType = "Ed25519VerificationKey2018"
+
+type clock struct {
+	t time.Time
+}
+
+func (c *clock) now() time.Time {
+	c.t = c.t.Add(time.Hour)
+
+	return c.t
+}
+
+func newVDR(t *testing.T) *VDR {
+	t.Helper()
+
+	c := &clock{t: time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC)}
+
+	p := leveldb.NewProvider(filepath.Join(t.TempDir(), "db"))
+	t.Cleanup(func() { require.NoError(t, p.Close()) })
+
+	store, err := p.OpenStore(StoreName)
+	require.NoError(t, err)
+
+	return New(store, WithClock(c.now))
+}
+
+func newSigner(t *testing.T) crypto.Signer {
+	t.Helper()
+
+	_, privKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	signer, err := crypto.NewSigner(privKey)
+	require.NoError(t, err)
+
+	return signer
+}
+
+func newTemplate(signer crypto.Signer) *did.Document {
+	vm := did.NewVerificationMethodFromBytes("#key-1", ed25519KeyType, "", signer.PublicKeyBytes())
+
+	return did.BuildDoc(
+		did.WithVerificationMethod([]did.VerificationMethod{*vm}),
+		did.WithAuthentication([]did.Verification{*did.NewReferencedVerification(vm, did.Authentication)}),
+		did.WithCapabilityInvocation([]did.Verification{
+			*did.NewReferencedVerification(vm, did.CapabilityInvocation),
+		}),
+	)
+}
+
+func signerOpts(signer crypto.Signer, keyID string) []vdr.DIDMethodOption {
+	return []vdr.DIDMethodOption{vdr.WithOption(SignerOpt, signer), vdr.WithOption(KeyIDOpt, keyID)}
+}
+
+func TestLifecycle(t *testing.T) {
+	v := newVDR(t)
+	signer := newSigner(t)
+
+	created, err := v.Create(newTemplate(signer), signerOpts(signer, "#key-1")...)
+	require.NoError(t, err)
+
+	didID := created.DIDDocument.ID
+	require.Contains(t, didID, "did:zfusion:")
+	require.Equal(t, didID+"#key-1", created.DIDDocument.VerificationMethod[0].ID)
+	require.Equal(t, "1", created.DocumentMetadata.VersionID)
+	require.Equal(t, created.DocumentMetadata.Method.PublishedOperations[0].CanonicalReference,
+		created.DocumentMetadata.Method.PreviousOperation)
+	require.Empty(t, created.DocumentMetadata.Method.UpdateCommitment)
+	require.Len(t, created.DocumentMetadata.Method.PublishedOperations, 1)
+	require.Nil(t, created.DocumentMetadata.Updated)
+
+	// rotate: add key-2 and make it the only capabilityInvocation key
+	signer2 := newSigner(t)
+	doc := created.DIDDocument
+	key2 := did.NewVerificationMethodFromBytes(didID+"#key-2", ed25519KeyType, didID, signer2.PublicKeyBytes())
+	doc.VerificationMethod = append(doc.VerificationMethod, *key2)
+	doc.CapabilityInvocation = []did.Verification{*did.NewReferencedVerification(key2, did.CapabilityInvocation)}
+
+	require.NoError(t, v.Update(doc, signerOpts(signer, "#key-1")...))
+
+	resolved, err := v.Read(didID)
+	require.NoError(t, err)
+	require.Equal(t, "2", resolved.DocumentMetadata.VersionID)
+	require.Len(t, resolved.DIDDocument.VerificationMethod, 2)
+	require.NotNil(t, resolved.DocumentMetadata.Updated)
+	require.Equal(t, resolved.DocumentMetadata.Updated, resolved.DIDDocument.Updated)
+	require.Len(t, resolved.DocumentMetadata.Method.PublishedOperations, 2)
+	require.Equal(t, "update", resolved.DocumentMetadata.Method.PublishedOperations[1].Type)
+
+	// key-1 can no longer update the document
+	err = v.Update(resolved.DIDDocument, signerOpts(signer, "#key-1")...)
+	require.ErrorIs(t, err, ErrUnauthorized)
+
+	// previous versions remain resolvable
+	first, err := v.Read(didID + "?versionId=1")
+	require.NoError(t, err)
+	require.Len(t, first.DIDDocument.VerificationMethod, 1)
+	require.Equal(t, "2", first.DocumentMetadata.NextVersionID)
+	require.Empty(t, first.DocumentMetadata.Method.PreviousOperation)
+
+	versionTime := created.DocumentMetadata.Created.Add(time.Minute).Format(time.RFC3339)
+	atTime, err := v.Read(didID + "?versionTime=" + versionTime)
+	require.NoError(t, err)
+	require.Equal(t, "1", atTime.DocumentMetadata.VersionID)
+
+	_, err = v.Read(didID + "?versionTime=2000-01-01T00:00:00Z")
+	require.ErrorIs(t, err, vdr.ErrNotFound)
+
+	_, err = v.Read(didID + "?versionId=7")
+	require.ErrorIs(t, err, vdr.ErrNotFound)
+
+	_, err = v.Read(didID + "?versionTime=yesterday")
+	require.ErrorIs(t, err, vdr.ErrInvalidDID)
+
+	_, err = v.Read(didID + "?versionId=1&versionTime=2000-01-01T00:00:00Z")
+	require.ErrorIs(t, err, vdr.ErrInvalidDID)
+
+	// deactivate with the rotated key
+	require.NoError(t, v.Deactivate(didID, signerOpts(signer2, "#key-2")...))
+
+	deactivated, err := v.Read(didID)
+	require.NoError(t, err)
+	require.True(t, deactivated.DocumentMetadata.Deactivated)
+	require.Equal(t, "3", deactivated.DocumentMetadata.VersionID)
+	require.Len(t, deactivated.DIDDocument.VerificationMethod, 2)
+	require.Empty(t, deactivated.DocumentMetadata.Method.PreviousOperation)
+
+	require.ErrorIs(t, v.Deactivate(didID, signerOpts(signer2, "#key-2")...), ErrDeactivated)
+
+	op, err := NewUpdateOperation(deactivated.DIDDocument, "")
+	require.NoError(t, err)
+	require.NoError(t, op.Sign("#key-2", signer2))
+
+	_, err = v.Apply(op)
+	require.ErrorIs(t, err, ErrDeactivated)
+}
+
+func TestApply(t *testing.T) {
+	v := newVDR(t)
+	signer := newSigner(t)
+
+	t.Run("create must be signed by a key of the new document", func(t *testing.T) {
+		op, err := NewCreateOperation(newTemplate(signer))
+		require.NoError(t, err)
+		require.NoError(t, op.Sign("#key-1", newSigner(t)))
+
+		_, err = v.Apply(op)
+		require.ErrorIs(t, err, ErrUnauthorized)
+
+		require.NoError(t, op.Sign("#key-2", signer))
+
+		_, err = v.Apply(op)
+		require.ErrorIs(t, err, ErrUnauthorized)
+	})
+
+	t.Run("DID must be derived from the document", func(t *testing.T) {
+		op, err := NewCreateOperation(newTemplate(signer))
+		require.NoError(t, err)
+
+		op.DID = "did:zfusion:123"
+		require.NoError(t, op.Sign("#key-1", signer))
+
+		_, err = v.Apply(op)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "does not match the create operation document")
+	})
+
+	t.Run("operations cannot be replayed", func(t *testing.T) {
+		op, err := NewCreateOperation(newTemplate(signer))
+		require.NoError(t, err)
+		require.NoError(t, op.Sign("#key-1", signer))
+
+		created, err := v.Apply(op)
+		require.NoError(t, err)
+
+		_, err = v.Apply(op)
+		require.ErrorIs(t, err, ErrConflict)
+
+		update, err := NewUpdateOperation(created.DIDDocument, created.DocumentMetadata.Method.PreviousOperation)
+		require.NoError(t, err)
+		require.NoError(t, update.Sign("#key-1", signer))
+
+		_, err = v.Apply(update)
+		require.NoError(t, err)
+
+		_, err = v.Apply(update)
+		require.ErrorIs(t, err, ErrConflict)
+	})
+
+	t.Run("update of unknown DID", func(t *testing.T) {
+		op := NewDeactivateOperation("did:zfusion:unknown", "")
+		require.NoError(t, op.Sign("#key-1", signer))
+
+		_, err := v.Apply(op)
+		require.ErrorIs(t, err, vdr.ErrNotFound)
+	})
+
+	t.Run("invalid operations", func(t *testing.T) {
+		_, err := NewCreateOperation(&did.Document{ID: "did:zfusion:123"})
+		require.EqualError(t, err, "document of a create operation must not have an ID")
+
+		_, err = NewCreateOperation(did.BuildDoc())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "at least one capabilityInvocation key")
+
+		_, err = v.Apply(&Operation{Type: "recover", DID: "did:zfusion:123"})
+		require.Error(t, err)
+	})
+
+	t.Run("missing signer", func(t *testing.T) {
+		_, err := v.Create(newTemplate(signer))
+		require.EqualError(t, err, "signer option is required to sign the create operation")
+
+		_, err = v.Create(newTemplate(signer), vdr.WithOption(SignerOpt, signer))
+		require.EqualError(t, err, "keyID option is required to sign the create operation")
+	})
+}
+
+func TestRegistry(t *testing.T) {
+	v := newVDR(t)
+	signer := newSigner(t)
+
+	registry := vdr.New(vdr.WithVDR(v))
+
+	created, err := registry.Create(DIDMethod, newTemplate(signer), signerOpts(signer, "#key-1")...)
+	require.NoError(t, err)
+
+	resolved, err := registry.Resolve(created.DIDDocument.ID)
+	require.NoError(t, err)
+	require.Equal(t, created.DIDDocument.ID, resolved.DIDDocument.ID)
+
+	_, err = registry.Resolve("did:zfusion:unknown")
+	require.Equal(t, vdr.NotFoundError, vdr.ErrorCode(err))
+
+	key, err := registry.Dereference(created.DIDDocument.ID + "?versionId=1#key-1")
+	require.NoError(t, err)
+	require.Equal(t, created.DIDDocument.ID+"#key-1", key.Content.(*did.VerificationMethod).ID)
+	require.Equal(t, "1", key.ContentMetadata.VersionID)
+
+	_, err = v.Read("did:key:123")
+	require.ErrorIs(t, err, vdr.ErrInvalidDID)
+}
+
+func TestPatch(t *testing.T) {
+	v := newVDR(t)
+	signer := newSigner(t)
+
+	created, err := v.Create(newTemplate(signer), signerOpts(signer, "#key-1")...)
+	require.NoError(t, err)
+
+	didID := created.DIDDocument.ID
+
+	// rotate key-1 to key-2 with patches rather than a whole document
+	signer2 := newSigner(t)
+	key2 := did.NewVerificationMethodFromBytes("#key-2", ed25519KeyType, "", signer2.PublicKeyBytes())
+
+	addKey, err := patch.AddVerificationMethods(*key2)
+	require.NoError(t, err)
+
+	addInvocation, err := patch.AddRelationships(did.CapabilityInvocation, "#key-2")
+	require.NoError(t, err)
+
+	require.NoError(t, v.Patch(didID, []patch.Patch{addKey, addInvocation, patch.RemoveVerificationMethods("#key-1")},
+		signerOpts(signer, "#key-1")...))
+
+	resolved, err := v.Read(didID)
+	require.NoError(t, err)
+	require.Equal(t, "2", resolved.DocumentMetadata.VersionID)
+	require.Len(t, resolved.DIDDocument.VerificationMethod, 1)
+	require.Equal(t, didID+"#key-2", resolved.DIDDocument.VerificationMethod[0].ID)
+	require.Empty(t, resolved.DIDDocument.Authentication)
+	require.Equal(t, "update", resolved.DocumentMetadata.Method.PublishedOperations[1].Type)
+
+	// the removed key can no longer patch the document
+	err = v.Patch(didID, []patch.Patch{patch.RemoveVerificationMethods("#key-2")}, signerOpts(signer, "#key-1")...)
+	require.ErrorIs(t, err, ErrUnauthorized)
+
+	// patches producing an invalid document are rejected
+	err = v.Patch(didID, []patch.Patch{patch.RemoveVerificationMethods("#key-2")}, signerOpts(signer2, "#key-2")...)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "at least one capabilityInvocation key")
+
+	first, err := v.Read(didID + "?versionId=1")
+	require.NoError(t, err)
+	require.Equal(t, didID+"#key-1", first.DIDDocument.VerificationMethod[0].ID)
+
+	op := NewPatchOperation(didID, resolved.DocumentMetadata.Method.PreviousOperation, addKey)
+	op.Document = []byte(`{}`)
+	require.NoError(t, op.Sign("#key-2", signer2))
+
+	_, err = v.Apply(op)
+	require.EqualError(t, err, "apply update operation for "+didID+
+		": update operation cannot have both a document and patches")
+}
+
+func newCommittedKey(t *testing.T) (crypto.Signer, *jwk.JWK, string) {
+	t.Helper()
+
+	signer := newSigner(t)
+
+	key, err := jwk.NewFromBytes(signer.KeyType(), signer.PublicKeyBytes())
+	require.NoError(t, err)
+
+	commitment, err := patch.Commitment(key)
+	require.NoError(t, err)
+
+	return signer, key, commitment
+}
+
+func committedOpts(signer crypto.Signer, key *jwk.JWK, updateCommitment string) []vdr.DIDMethodOption {
+	return []vdr.DIDMethodOption{
+		vdr.WithOption(SignerOpt, signer), vdr.WithOption(CommittedKeyOpt, key),
+		vdr.WithOption(UpdateCommitmentOpt, updateCommitment),
+	}
+}
+
+func TestCommitments(t *testing.T) {
+	v := newVDR(t)
+	signer := newSigner(t)
+	updateSigner, updateKey, updateCommitment := newCommittedKey(t)
+	recoverySigner, recoveryKey, recoveryCommitment := newCommittedKey(t)
+
+	created, err := v.Create(newTemplate(signer), append(signerOpts(signer, "#key-1"),
+		vdr.WithOption(UpdateCommitmentOpt, updateCommitment),
+		vdr.WithOption(RecoveryCommitmentOpt, recoveryCommitment))...)
+	require.NoError(t, err)
+
+	didID := created.DIDDocument.ID
+	require.Equal(t, updateCommitment, created.DocumentMetadata.Method.UpdateCommitment)
+	require.Equal(t, recoveryCommitment, created.DocumentMetadata.Method.RecoveryCommitment)
+	require.Empty(t, created.DocumentMetadata.Method.PreviousOperation)
+
+	// capabilityInvocation keys cannot update a DID with commitments
+	err = v.Update(created.DIDDocument, signerOpts(signer, "#key-1")...)
+	require.ErrorIs(t, err, ErrUnauthorized)
+
+	// the update key must commit to the next update key
+	nextSigner, nextKey, nextCommitment := newCommittedKey(t)
+
+	err = v.Update(created.DIDDocument, committedOpts(updateSigner, updateKey, updateCommitment)...)
+	require.EqualError(t, err, "apply update operation for "+didID+
+		": update operation must commit to a new update key")
+
+	err = v.Update(created.DIDDocument, committedOpts(updateSigner, nextKey, nextCommitment)...)
+	require.ErrorIs(t, err, ErrUnauthorized)
+
+	alsoKnownAs := patch.JSONPatch(patch.Operation{
+		Op: patch.OpAdd, Path: "/alsoKnownAs", Value: []byte(`["https://example.com/me"]`),
+	})
+
+	require.NoError(t, v.Patch(didID, []patch.Patch{alsoKnownAs},
+		committedOpts(updateSigner, updateKey, nextCommitment)...))
+
+	updated, err := v.Read(didID)
+	require.NoError(t, err)
+	require.Equal(t, nextCommitment, updated.DocumentMetadata.Method.UpdateCommitment)
+	require.Equal(t, recoveryCommitment, updated.DocumentMetadata.Method.RecoveryCommitment)
+	require.Equal(t, []string{"https://example.com/me"}, updated.DIDDocument.AlsoKnownAs)
+
+	// the revealed update key cannot be used again
+	_, _, otherCommitment := newCommittedKey(t)
+
+	err = v.Update(updated.DIDDocument, committedOpts(updateSigner, updateKey, otherCommitment)...)
+	require.ErrorIs(t, err, ErrUnauthorized)
+
+	// recover with a new document and new keys
+	signer2 := newSigner(t)
+	doc := newTemplate(signer2)
+	doc.ID = didID
+
+	_, _, newUpdateCommitment := newCommittedKey(t)
+	newRecoverySigner, newRecoveryKey, newRecoveryCommitment := newCommittedKey(t)
+
+	recoverOpts := func(signer crypto.Signer, key *jwk.JWK) []vdr.DIDMethodOption {
+		return append(committedOpts(signer, key, newUpdateCommitment),
+			vdr.WithOption(RecoveryCommitmentOpt, newRecoveryCommitment))
+	}
+
+	// the update key cannot recover the DID
+	require.ErrorIs(t, v.Recover(doc, recoverOpts(nextSigner, nextKey)...), ErrUnauthorized)
+
+	err = v.Recover(doc, committedOpts(recoverySigner, recoveryKey, newUpdateCommitment)...)
+	require.EqualError(t, err, "apply recover operation for "+didID+
+		": recover operation must have an update and a recovery commitment")
+
+	require.NoError(t, v.Recover(doc, recoverOpts(recoverySigner, recoveryKey)...))
+
+	recovered, err := v.Read(didID)
+	require.NoError(t, err)
+	require.Equal(t, "3", recovered.DocumentMetadata.VersionID)
+	require.Equal(t, signer2.PublicKeyBytes(), recovered.DIDDocument.VerificationMethod[0].Value)
+	require.Equal(t, newUpdateCommitment, recovered.DocumentMetadata.Method.UpdateCommitment)
+	require.Equal(t, newRecoveryCommitment, recovered.DocumentMetadata.Method.RecoveryCommitment)
+	require.Equal(t, "recover", recovered.DocumentMetadata.Method.PublishedOperations[2].Type)
+
+	// deactivate with the recovery key
+	require.ErrorIs(t, v.Deactivate(didID, committedOpts(recoverySigner, recoveryKey, "")...), ErrUnauthorized)
+	require.NoError(t, v.Deactivate(didID, committedOpts(newRecoverySigner, newRecoveryKey, "")...))
+
+	deactivated, err := v.Read(didID)
+	require.NoError(t, err)
+	require.True(t, deactivated.DocumentMetadata.Deactivated)
+	require.Empty(t, deactivated.DocumentMetadata.Method.UpdateCommitment)
+	require.Empty(t, deactivated.DocumentMetadata.Method.RecoveryCommitment)
+
+	t.Run("invalid commitments", func(t *testing.T) {
+		op, err := NewCreateOperation(newTemplate(signer))
+		require.NoError(t, err)
+
+		op.UpdateCommitment = updateCommitment
+		require.NoError(t, op.Sign("#key-1", signer))
+
+		_, err = v.Apply(op)
+		require.EqualError(t, err, "apply create operation for "+op.DID+
+			": create operation must have both an update and a recovery commitment, or none")
+
+		legacySigner := newSigner(t)
+
+		legacy, err := v.Create(newTemplate(legacySigner), signerOpts(legacySigner, "#key-1")...)
+		require.NoError(t, err)
+
+		err = v.Recover(legacy.DIDDocument, append(committedOpts(recoverySigner, recoveryKey, updateCommitment),
+			vdr.WithOption(RecoveryCommitmentOpt, recoveryCommitment))...)
+		require.ErrorIs(t, err, ErrUnauthorized)
+	})
+}
+
+func TestRecoverReplay(t *testing.T) {
+	v := newVDR(t)
+	signer := newSigner(t)
+	_, _, updateCommitment := newCommittedKey(t)
+	recoverySigner, recoveryKey, recoveryCommitment := newCommittedKey(t)
+
+	created, err := v.Create(newTemplate(signer), append(signerOpts(signer, "#key-1"),
+		vdr.WithOption(UpdateCommitmentOpt, updateCommitment),
+		vdr.WithOption(RecoveryCommitmentOpt, recoveryCommitment))...)
+	require.NoError(t, err)
+
+	didID := created.DIDDocument.ID
+
+	recoverTo := func(signer crypto.Signer, key *jwk.JWK, update, recovery string) (*Operation, error) {
+		doc := newTemplate(newSigner(t))
+		doc.ID = didID
+
+		current, err := v.Read(didID)
+		require.NoError(t, err)
+
+		op, err := NewRecoverOperation(doc, current.DocumentMetadata.Method.RecoveryCommitment)
+		require.NoError(t, err)
+
+		require.NoError(t, signOperation(op, append(committedOpts(signer, key, update),
+			vdr.WithOption(RecoveryCommitmentOpt, recovery))...))
+
+		_, err = v.Apply(op)
+
+		return op, err
+	}
+
+	_, _, updateCommitment2 := newCommittedKey(t)
+	recoverySigner2, recoveryKey2, recoveryCommitment2 := newCommittedKey(t)
+
+	// a recover must commit to a new update and a new recovery key
+	_, err = recoverTo(recoverySigner, recoveryKey, updateCommitment2, recoveryCommitment)
+	require.EqualError(t, err, "apply recover operation for "+didID+
+		": recover operation must commit to a new update and a new recovery key")
+
+	_, err = recoverTo(recoverySigner, recoveryKey, updateCommitment, recoveryCommitment2)
+	require.EqualError(t, err, "apply recover operation for "+didID+
+		": recover operation must commit to a new update and a new recovery key")
+
+	first, err := recoverTo(recoverySigner, recoveryKey, updateCommitment2, recoveryCommitment2)
+	require.NoError(t, err)
+
+	_, _, updateCommitment3 := newCommittedKey(t)
+	_, _, recoveryCommitment3 := newCommittedKey(t)
+
+	second, err := recoverTo(recoverySigner2, recoveryKey2, updateCommitment3, recoveryCommitment3)
+	require.NoError(t, err)
+
+	// replaying the first recover cannot roll the document back
+	_, err = v.Apply(first)
+	require.ErrorIs(t, err, ErrConflict)
+
+	resolved, err := v.Read(didID)
+	require.NoError(t, err)
+	require.Equal(t, "3", resolved.DocumentMetadata.VersionID)
+	require.Equal(t, recoveryCommitment3, resolved.DocumentMetadata.Method.RecoveryCommitment)
+
+	secondDoc, err := second.document()
+	require.NoError(t, err)
+	require.Equal(t, secondDoc.VerificationMethod[0].Value, resolved.DIDDocument.VerificationMethod[0].Value)
+}
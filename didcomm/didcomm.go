@@ -0,0 +1,53 @@
+/*
+Package didcomm packs, unpacks and delivers DIDComm Messaging v2 messages between DIDs.
+
+Messages are packed as plaintext, signed (JWS) or encrypted (JWE) envelopes. Encrypted envelopes are either
+anoncrypt, ECDH-ES+A256KW with A256GCM, or authcrypt, ECDH-1PU+A256KW with A256CBC-HS512, to the X25519 or
+P-256 keyAgreement keys of the resolved DID documents of the recipients. A Messenger delivers packed messages to
+the DIDCommMessaging service endpoints of the recipients, wrapped in forward messages for the mediators of their
+routingKeys, over a Transport.
+
+See https://identity.foundation/didcomm-messaging/spec/v2.0/.
+*/
+package didcomm
+
+import (
+	"errors"
+
+	"github.com/zRich/zFusion/common/crypto"
+)
+
+// Media types of DIDComm envelopes.
+const (
+	MediaTypePlaintext = "application/didcomm-plain+json"
+	MediaTypeSigned    = "application/didcomm-signed+json"
+	MediaTypeEncrypted = "application/didcomm-encrypted+json"
+)
+
+// ServiceType is the type of the DID document services of DIDComm v2 endpoints.
+const ServiceType = "DIDCommMessaging"
+
+// ProfileV2 is the accept profile of DIDComm v2 endpoints.
+const ProfileV2 = "didcomm/v2"
+
+var (
+	// ErrInvalidMessage is returned when a message or one of its envelopes is malformed or fails verification.
+	ErrInvalidMessage = errors.New("invalid DIDComm message")
+	// ErrNoKey is returned when no key agreement key is available to encrypt or decrypt a message.
+	ErrNoKey = errors.New("no DIDComm key")
+	// ErrMessageExpired is returned when a message is unpacked after its expires_time.
+	ErrMessageExpired = errors.New("DIDComm message expired")
+	// ErrUnsupportedEndpoint is returned by transports when they cannot deliver to the URI of an endpoint.
+	ErrUnsupportedEndpoint = errors.New("unsupported DIDComm endpoint")
+)
+
+// KeyManager holds the private keys of a Packer, see kms.LocalKMS. Keys are identified by the IDs of the
+// verification methods publishing them.
+type KeyManager interface {
+	// PublicKey returns the type and public key of the key keyID.
+	PublicKey(keyID string) (crypto.KeyType, []byte, error)
+	// Signer returns a signer with the key keyID.
+	Signer(keyID string) (crypto.Signer, error)
+	// DeriveSharedSecret returns the Diffie-Hellman shared secret of the key keyID and a peer public key.
+	DeriveSharedSecret(keyID string, peerPublicKey []byte) ([]byte, error)
+}
@@ -0,0 +1,391 @@
+package didcomm
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/zRich/zFusion/common/crypto"
+	"github.com/zRich/zFusion/common/crypto/jwk"
+	"github.com/zRich/zFusion/common/crypto/x25519"
+)
+
+// Key management and content encryption algorithms of DIDComm encrypted envelopes.
+const (
+	algECDHES       = "ECDH-ES+A256KW"
+	algECDH1PU      = "ECDH-1PU+A256KW"
+	encA256GCM      = "A256GCM"
+	encA256CBCHS512 = "A256CBC-HS512"
+)
+
+// kekSize is the size of A256KW key encryption keys.
+const kekSize = 32
+
+// jwe is the general JSON serialization of a JWE.
+type jwe struct {
+	Protected  string         `json:"protected"`
+	Recipients []jweRecipient `json:"recipients"`
+	IV         string         `json:"iv"`
+	Ciphertext string         `json:"ciphertext"`
+	Tag        string         `json:"tag"`
+}
+
+type jweRecipient struct {
+	Header       jweRecipientHeader `json:"header"`
+	EncryptedKey string             `json:"encrypted_key"`
+}
+
+type jweRecipientHeader struct {
+	Kid string `json:"kid"`
+}
+
+type jweHeader struct {
+	Typ  string   `json:"typ,omitempty"`
+	Alg  string   `json:"alg"`
+	Enc  string   `json:"enc"`
+	Skid string   `json:"skid,omitempty"`
+	Apu  string   `json:"apu,omitempty"`
+	Apv  string   `json:"apv"`
+	Epk  *jwk.JWK `json:"epk"`
+}
+
+// agreementKey is a public key agreement key.
+type agreementKey struct {
+	ID        string
+	KeyType   crypto.KeyType
+	PublicKey []byte
+}
+
+// encrypt encrypts the plaintext for the recipients, which must have keys of the same type. The envelope is
+// authcrypt when sender, a key of the KeyManager, is set, and anoncrypt otherwise.
+func encrypt(plaintext []byte, recipients []agreementKey, sender *agreementKey, km KeyManager) ([]byte, error) {
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("%w: no recipient key", ErrNoKey)
+	}
+
+	keyType := recipients[0].KeyType
+	kids := make([]string, len(recipients))
+
+	for i, recipient := range recipients {
+		if recipient.KeyType != keyType {
+			return nil, fmt.Errorf("%w: recipient keys of different types %s and %s", ErrNoKey, keyType,
+				recipient.KeyType)
+		}
+
+		kids[i] = recipient.ID
+	}
+
+	header := &jweHeader{Typ: MediaTypeEncrypted, Alg: algECDHES, Enc: encA256GCM, Apv: encode(apv(kids))}
+
+	var apu []byte
+
+	if sender != nil {
+		if sender.KeyType != keyType {
+			return nil, fmt.Errorf("%w: sender key %s is not a %s key", ErrNoKey, sender.ID, keyType)
+		}
+
+		apu = []byte(sender.ID)
+		header.Alg, header.Enc, header.Skid, header.Apu = algECDH1PU, encA256CBCHS512, sender.ID, encode(apu)
+	}
+
+	ephemeral, epk, err := generateEphemeralKey(keyType)
+	if err != nil {
+		return nil, err
+	}
+
+	if header.Epk, err = jwk.NewFromBytes(keyType, epk); err != nil {
+		return nil, err
+	}
+
+	protected, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+
+	cek, aead, err := newContentEncryption(header.Enc, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	envelope := &jwe{Protected: encode(protected)}
+
+	iv := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(iv); err != nil {
+		return nil, err
+	}
+
+	sealed := aead.Seal(nil, iv, plaintext, []byte(envelope.Protected))
+	tagSize := contentTagSize(header.Enc)
+	ciphertext, tag := sealed[:len(sealed)-tagSize], sealed[len(sealed)-tagSize:]
+
+	envelope.IV, envelope.Ciphertext, envelope.Tag = encode(iv), encode(ciphertext), encode(tag)
+
+	for _, recipient := range recipients {
+		z, err := ecdh(keyType, ephemeral, recipient.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("key agreement with %s: %w", recipient.ID, err)
+		}
+
+		kdfTag := []byte(nil)
+
+		if sender != nil {
+			zs, err := km.DeriveSharedSecret(sender.ID, recipient.PublicKey)
+			if err != nil {
+				return nil, fmt.Errorf("key agreement of %s with %s: %w", sender.ID, recipient.ID, err)
+			}
+
+			z, kdfTag = append(z, zs...), tag
+		}
+
+		wrapped, err := wrapKey(concatKDF(z, header.Alg, apu, apv(kids), kekSize, kdfTag), cek)
+		if err != nil {
+			return nil, err
+		}
+
+		envelope.Recipients = append(envelope.Recipients, jweRecipient{
+			Header:       jweRecipientHeader{Kid: recipient.ID},
+			EncryptedKey: encode(wrapped),
+		})
+	}
+
+	return json.Marshal(envelope)
+}
+
+// decrypted is the result of decrypt.
+type decrypted struct {
+	plaintext []byte
+	// recipient is the ID of the key which decrypted the envelope.
+	recipient string
+	// sender is the skid of authcrypt envelopes.
+	sender string
+}
+
+// decrypt decrypts the envelope with the first key of its recipients held by the KeyManager. senderKey resolves
+// the sender key of authcrypt envelopes.
+func decrypt(data []byte, km KeyManager, senderKey func(skid string) (*agreementKey, error)) (*decrypted, error) {
+	var envelope jwe
+
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMessage, err)
+	}
+
+	var header jweHeader
+	if err := decodeJSON(envelope.Protected, &header); err != nil {
+		return nil, fmt.Errorf("%w: JWE header: %v", ErrInvalidMessage, err)
+	}
+
+	if header.Epk == nil || !(header.Alg == algECDHES && (header.Enc == encA256GCM || header.Enc == encA256CBCHS512) ||
+		header.Alg == algECDH1PU && header.Enc == encA256CBCHS512) {
+		return nil, fmt.Errorf("%w: unsupported JWE alg %q and enc %q", ErrInvalidMessage, header.Alg, header.Enc)
+	}
+
+	keyType, err := header.Epk.KeyType()
+	if err != nil {
+		return nil, fmt.Errorf("%w: epk: %v", ErrInvalidMessage, err)
+	}
+
+	epk, err := header.Epk.PublicKeyBytes()
+	if err != nil {
+		return nil, fmt.Errorf("%w: epk: %v", ErrInvalidMessage, err)
+	}
+
+	parts, err := decodeParts(envelope.IV, envelope.Ciphertext, envelope.Tag, header.Apu, header.Apv)
+	if err != nil {
+		return nil, err
+	}
+
+	iv, ciphertext, tag, apu, headerApv := parts[0], parts[1], parts[2], parts[3], parts[4]
+	kids := make([]string, len(envelope.Recipients))
+
+	for i, recipient := range envelope.Recipients {
+		kids[i] = recipient.Header.Kid
+	}
+
+	if !bytes.Equal(headerApv, apv(kids)) {
+		return nil, fmt.Errorf("%w: apv does not match the recipients", ErrInvalidMessage)
+	}
+
+	result := &decrypted{}
+
+	var sender *agreementKey
+
+	if header.Alg == algECDH1PU {
+		if header.Skid == "" || string(apu) != header.Skid {
+			return nil, fmt.Errorf("%w: apu does not match skid %q", ErrInvalidMessage, header.Skid)
+		}
+
+		if sender, err = senderKey(header.Skid); err != nil {
+			return nil, err
+		}
+
+		if sender.KeyType != keyType {
+			return nil, fmt.Errorf("%w: sender key %s is not a %s key", ErrInvalidMessage, header.Skid, keyType)
+		}
+
+		result.sender = header.Skid
+	}
+
+	for _, recipient := range envelope.Recipients {
+		if recipientType, _, err := km.PublicKey(recipient.Header.Kid); err != nil || recipientType != keyType {
+			continue
+		}
+
+		z, err := km.DeriveSharedSecret(recipient.Header.Kid, epk)
+		if err != nil {
+			return nil, fmt.Errorf("key agreement of %s: %w", recipient.Header.Kid, err)
+		}
+
+		kdfTag := []byte(nil)
+
+		if sender != nil {
+			zs, err := km.DeriveSharedSecret(recipient.Header.Kid, sender.PublicKey)
+			if err != nil {
+				return nil, fmt.Errorf("key agreement of %s with %s: %w", recipient.Header.Kid, sender.ID, err)
+			}
+
+			z, kdfTag = append(z, zs...), tag
+		}
+
+		wrapped, err := decode(recipient.EncryptedKey)
+		if err != nil {
+			return nil, fmt.Errorf("%w: encrypted_key: %v", ErrInvalidMessage, err)
+		}
+
+		cek, err := unwrapKey(concatKDF(z, header.Alg, apu, headerApv, kekSize, kdfTag), wrapped)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidMessage, err)
+		}
+
+		_, aead, err := newContentEncryption(header.Enc, cek)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidMessage, err)
+		}
+
+		if len(iv) != aead.NonceSize() {
+			return nil, fmt.Errorf("%w: invalid %s IV size %d", ErrInvalidMessage, header.Enc, len(iv))
+		}
+
+		plaintext, err := aead.Open(nil, iv, append(ciphertext, tag...), []byte(envelope.Protected))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidMessage, errAuthentication)
+		}
+
+		result.plaintext, result.recipient = plaintext, recipient.Header.Kid
+
+		return result, nil
+	}
+
+	return nil, fmt.Errorf("%w: no %s key of the recipients %s", ErrNoKey, keyType, strings.Join(kids, ", "))
+}
+
+func decodeParts(parts ...string) ([][]byte, error) {
+	decoded := make([][]byte, len(parts))
+
+	for i, part := range parts {
+		var err error
+
+		if decoded[i], err = decode(part); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidMessage, err)
+		}
+	}
+
+	return decoded, nil
+}
+
+// apv returns the SHA-256 hash of the sorted recipient key IDs joined with '.'.
+func apv(kids []string) []byte {
+	sorted := append([]string(nil), kids...)
+	sort.Strings(sorted)
+
+	hash := sha256.Sum256([]byte(strings.Join(sorted, ".")))
+
+	return hash[:]
+}
+
+// newContentEncryption returns the AEAD of the content encryption algorithm enc with the key cek, or with a
+// random key when cek is nil.
+func newContentEncryption(enc string, cek []byte) ([]byte, cipher.AEAD, error) {
+	size := kekSize
+	if enc == encA256CBCHS512 {
+		size = cbcHMACKeySize
+	}
+
+	if cek == nil {
+		cek = make([]byte, size)
+		if _, err := rand.Read(cek); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if len(cek) != size {
+		return nil, nil, fmt.Errorf("invalid %s key size %d", enc, len(cek))
+	}
+
+	if enc == encA256CBCHS512 {
+		aead, err := newCBCHMAC(cek)
+
+		return cek, aead, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+
+	return cek, aead, err
+}
+
+func contentTagSize(enc string) int {
+	if enc == encA256CBCHS512 {
+		return cbcHMACTagSize
+	}
+
+	return 16 //nolint:gomnd
+}
+
+// generateEphemeralKey generates an ephemeral key agreement key and returns its private and public keys.
+func generateEphemeralKey(keyType crypto.KeyType) ([]byte, []byte, error) {
+	switch keyType {
+	case crypto.X25519:
+		return x25519.GenerateKey()
+	case crypto.ECDSAP256:
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return key.D.FillBytes(make([]byte, 32)), elliptic.Marshal(key.Curve, key.X, key.Y), nil //nolint:staticcheck
+	default:
+		return nil, nil, fmt.Errorf("%w: unsupported key agreement key type %s", ErrNoKey, keyType)
+	}
+}
+
+// ecdh returns the shared secret of an ephemeral private key and a peer public key, as kms.DeriveSharedSecret.
+func ecdh(keyType crypto.KeyType, privateKey, peerPublicKey []byte) ([]byte, error) {
+	if keyType == crypto.X25519 {
+		return x25519.X25519(privateKey, peerPublicKey)
+	}
+
+	peer, err := crypto.ParseECDSAPublicKey(keyType, peerPublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if !peer.Curve.IsOnCurve(peer.X, peer.Y) {
+		return nil, fmt.Errorf("invalid %s peer public key", keyType)
+	}
+
+	x, _ := peer.Curve.ScalarMult(peer.X, peer.Y, privateKey) //nolint:staticcheck
+
+	return x.FillBytes(make([]byte, len(privateKey))), nil
+}
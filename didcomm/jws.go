@@ -0,0 +1,139 @@
+package didcomm
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/zRich/zFusion/common/crypto"
+	"github.com/zRich/zFusion/common/crypto/jwk"
+	"github.com/zRich/zFusion/did"
+)
+
+// jws is the general JSON serialization of a JWS.
+type jws struct {
+	Payload    string         `json:"payload"`
+	Signatures []jwsSignature `json:"signatures"`
+}
+
+type jwsSignature struct {
+	Protected string    `json:"protected"`
+	Header    jwsHeader `json:"header"`
+	Signature string    `json:"signature"`
+}
+
+type jwsHeader struct {
+	Typ string `json:"typ,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Kid string `json:"kid,omitempty"`
+}
+
+// sign signs the plaintext with the signer of the authentication method vm, whose ID is the kid of the
+// signature.
+func sign(plaintext []byte, vm *did.VerificationMethod, signer crypto.Signer) ([]byte, error) {
+	alg, err := jwk.SignatureAlgorithm(signer.KeyType())
+	if err != nil {
+		return nil, err
+	}
+
+	protected, err := json.Marshal(jwsHeader{Typ: MediaTypeSigned, Alg: alg})
+	if err != nil {
+		return nil, err
+	}
+
+	payload := encode(plaintext)
+	signingInput := encode(protected) + "." + payload
+
+	sig, err := signer.Sign([]byte(signingInput))
+	if err != nil {
+		return nil, fmt.Errorf("sign DIDComm message: %w", err)
+	}
+
+	// the signer is not trusted to hold the key of the verification method.
+	if err := vm.Verify([]byte(signingInput), sig); err != nil {
+		return nil, fmt.Errorf("signer does not hold the key of %s: %w", vm.ID, err)
+	}
+
+	return json.Marshal(jws{Payload: payload, Signatures: []jwsSignature{{
+		Protected: encode(protected),
+		Header:    jwsHeader{Kid: vm.ID},
+		Signature: encode(sig),
+	}}})
+}
+
+// parseJWS parses a JWS of a single signature, and returns its kid and the function verifying it with a key.
+func parseJWS(data []byte) ([]byte, string, func(vm *did.VerificationMethod) error, error) {
+	var envelope jws
+
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, "", nil, fmt.Errorf("%w: %v", ErrInvalidMessage, err)
+	}
+
+	if len(envelope.Signatures) != 1 {
+		return nil, "", nil, fmt.Errorf("%w: expected 1 signature, got %d", ErrInvalidMessage,
+			len(envelope.Signatures))
+	}
+
+	signature := envelope.Signatures[0]
+
+	var protected jwsHeader
+	if err := decodeJSON(signature.Protected, &protected); err != nil {
+		return nil, "", nil, fmt.Errorf("%w: JWS header: %v", ErrInvalidMessage, err)
+	}
+
+	kid := signature.Header.Kid
+	if kid == "" {
+		kid = protected.Kid
+	}
+
+	if kid == "" {
+		return nil, "", nil, fmt.Errorf("%w: missing kid of the signature", ErrInvalidMessage)
+	}
+
+	payload, err := decode(envelope.Payload)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("%w: JWS payload: %v", ErrInvalidMessage, err)
+	}
+
+	sig, err := decode(signature.Signature)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("%w: JWS signature: %v", ErrInvalidMessage, err)
+	}
+
+	verify := func(vm *did.VerificationMethod) error {
+		keyType, err := vm.KeyType()
+		if err != nil {
+			return err
+		}
+
+		if alg, _ := jwk.SignatureAlgorithm(keyType); alg != protected.Alg { //nolint:errcheck
+			return fmt.Errorf("%w: alg %s does not match the %s key of %s", ErrInvalidMessage, protected.Alg,
+				keyType, kid)
+		}
+
+		if err := vm.Verify([]byte(signature.Protected+"."+envelope.Payload), sig); err != nil {
+			return fmt.Errorf("%w: signature of %s: %v", ErrInvalidMessage, kid, err)
+		}
+
+		return nil
+	}
+
+	return payload, kid, verify, nil
+}
+
+func encode(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decode(data string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(data)
+}
+
+func decodeJSON(data string, v interface{}) error {
+	decoded, err := decode(data)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(decoded, v)
+}
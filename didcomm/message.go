@@ -0,0 +1,126 @@
+package didcomm
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ForwardType is the type of the forward messages of the routing protocol 2.0.
+const ForwardType = "https://didcomm.org/routing/2.0/forward"
+
+// Message is a plaintext DIDComm message.
+type Message struct {
+	ID             string                 `json:"id"`
+	Type           string                 `json:"type"`
+	From           string                 `json:"from,omitempty"`
+	To             []string               `json:"to,omitempty"`
+	ThreadID       string                 `json:"thid,omitempty"`
+	ParentThreadID string                 `json:"pthid,omitempty"`
+	CreatedTime    int64                  `json:"created_time,omitempty"`
+	ExpiresTime    int64                  `json:"expires_time,omitempty"`
+	Body           map[string]interface{} `json:"body"`
+	Attachments    []Attachment           `json:"attachments,omitempty"`
+}
+
+// Attachment is an attachment of a message.
+type Attachment struct {
+	ID          string         `json:"id,omitempty"`
+	Description string         `json:"description,omitempty"`
+	MediaType   string         `json:"media_type,omitempty"`
+	Data        AttachmentData `json:"data"`
+}
+
+// AttachmentData is the content of an attachment, inline as JSON or base64url, or by reference.
+type AttachmentData struct {
+	JSON   json.RawMessage `json:"json,omitempty"`
+	Base64 string          `json:"base64,omitempty"`
+	Links  []string        `json:"links,omitempty"`
+	Hash   string          `json:"hash,omitempty"`
+}
+
+// NewMessage creates a message of the given type and body, with a random ID and the current creation time.
+func NewMessage(msgType string, body map[string]interface{}) *Message {
+	return &Message{ID: newID(), Type: msgType, CreatedTime: time.Now().Unix(), Body: body}
+}
+
+// NewForward creates a forward message asking a mediator to deliver the packed message to next, the DID of the
+// recipient or of the next mediator.
+func NewForward(next string, packed []byte) (*Message, error) {
+	if !json.Valid(packed) {
+		return nil, fmt.Errorf("%w: forwarded message is not JSON", ErrInvalidMessage)
+	}
+
+	msg := NewMessage(ForwardType, map[string]interface{}{"next": next})
+	msg.Attachments = []Attachment{{ID: newID(), MediaType: MediaTypeEncrypted, Data: AttachmentData{JSON: packed}}}
+
+	return msg, nil
+}
+
+// Forwarded returns the next recipient and the packed message of a forward message.
+func (m *Message) Forwarded() (string, []byte, error) {
+	if m.Type != ForwardType {
+		return "", nil, fmt.Errorf("%w: %s is not a forward message", ErrInvalidMessage, m.Type)
+	}
+
+	next, _ := m.Body["next"].(string) //nolint:errcheck
+	if next == "" || len(m.Attachments) != 1 || len(m.Attachments[0].Data.JSON) == 0 {
+		return "", nil, fmt.Errorf("%w: malformed forward message %s", ErrInvalidMessage, m.ID)
+	}
+
+	return next, m.Attachments[0].Data.JSON, nil
+}
+
+// plaintext serializes the message as a plaintext envelope.
+func (m *Message) plaintext() ([]byte, error) {
+	if m.ID == "" || m.Type == "" {
+		return nil, fmt.Errorf("%w: id and type are required", ErrInvalidMessage)
+	}
+
+	msg := *m
+	if msg.Body == nil {
+		msg.Body = map[string]interface{}{}
+	}
+
+	return json.Marshal(struct {
+		Typ string `json:"typ"`
+		*Message
+	}{Typ: MediaTypePlaintext, Message: &msg})
+}
+
+// parseMessage parses a plaintext envelope.
+func parseMessage(data []byte) (*Message, error) {
+	var msg struct {
+		Typ string `json:"typ"`
+		Message
+	}
+
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMessage, err)
+	}
+
+	if msg.Typ != "" && msg.Typ != MediaTypePlaintext {
+		return nil, fmt.Errorf("%w: unexpected typ %s", ErrInvalidMessage, msg.Typ)
+	}
+
+	if msg.ID == "" || msg.Type == "" {
+		return nil, fmt.Errorf("%w: id and type are required", ErrInvalidMessage)
+	}
+
+	return &msg.Message, nil
+}
+
+// newID returns a random version 4 UUID.
+func newID() string {
+	var b [16]byte
+
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+
+	b[6] = b[6]&0x0f | 0x40 //nolint:gomnd
+	b[8] = b[8]&0x3f | 0x80 //nolint:gomnd
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,94 @@
+package didcomm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zRich/zFusion/did"
+)
+
+// Messenger sends messages to the DIDComm service endpoints of their recipients, and receives messages from a
+// transport.
+type Messenger struct {
+	packer    *Packer
+	resolver  did.DocumentResolver
+	transport Transport
+}
+
+// NewMessenger creates a messenger packing messages with packer and sending them over transport.
+func NewMessenger(packer *Packer, resolver did.DocumentResolver, transport Transport) *Messenger {
+	return &Messenger{packer: packer, resolver: resolver, transport: transport}
+}
+
+// Send packs the message with opts, then sends it to each of its recipients: to the DIDCommMessaging endpoints of
+// the recipient accepting didcomm/v2, by ascending priority until one succeeds. The message is wrapped in a
+// forward message anoncrypted for each routing key of the endpoint, the outermost for the first routing key. Send
+// stops at the first recipient which cannot be reached.
+func (m *Messenger) Send(ctx context.Context, msg *Message, opts ...PackOption) error {
+	packed, err := m.packer.Pack(msg, opts...)
+	if err != nil {
+		return err
+	}
+
+	for _, to := range msg.To {
+		doc, err := m.resolver.ResolveDocument(didOf(to))
+		if err != nil {
+			return fmt.Errorf("resolve recipient %s: %w", to, err)
+		}
+
+		err = doc.FailoverEndpoints(func(endpoint did.RankedEndpoint) error {
+			routed, err := m.packer.route(packed, didOf(to), endpoint.RoutingKeys, msg.ExpiresTime)
+			if err != nil {
+				return err
+			}
+
+			return m.transport.Send(ctx, endpoint.URI, routed)
+		}, []string{ProfileV2}, ServiceType)
+		if err != nil {
+			return fmt.Errorf("send %s to %s: %w", msg.ID, to, err)
+		}
+	}
+
+	return nil
+}
+
+// Handler returns a transport handler which unpacks the received messages and passes them to handle.
+func (m *Messenger) Handler(handle func(ctx context.Context, msg *Message, md *Metadata) error) Handler {
+	return func(ctx context.Context, packed []byte) error {
+		msg, md, err := m.packer.Unpack(packed)
+		if err != nil {
+			return err
+		}
+
+		return handle(ctx, msg, md)
+	}
+}
+
+// route wraps the packed message for next in forward messages for the routing keys, from the last to the first.
+func (p *Packer) route(packed []byte, next string, routingKeys []string, expires int64) ([]byte, error) {
+	for i := len(routingKeys) - 1; i >= 0; i-- {
+		forward, err := NewForward(next, packed)
+		if err != nil {
+			return nil, err
+		}
+
+		next = didOf(routingKeys[i])
+		forward.To, forward.ExpiresTime = []string{next}, expires
+
+		plaintext, err := forward.plaintext()
+		if err != nil {
+			return nil, err
+		}
+
+		recipients, err := p.recipientKeys([]string{routingKeys[i]}, "")
+		if err != nil {
+			return nil, fmt.Errorf("routing key %s: %w", routingKeys[i], err)
+		}
+
+		if packed, err = encrypt(plaintext, recipients, nil, p.keys); err != nil {
+			return nil, err
+		}
+	}
+
+	return packed, nil
+}
@@ -0,0 +1,94 @@
+package didcomm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zRich/zFusion/common/crypto"
+	"github.com/zRich/zFusion/common/model"
+	"github.com/zRich/zFusion/did"
+)
+
+func TestMessenger(t *testing.T) {
+	resolver := mockResolver{}
+	transport := NewInProcessTransport()
+
+	alice := newAgent(t, resolver, "did:example:alice", crypto.X25519)
+	mediator1 := newAgent(t, resolver, "did:example:mediator1", crypto.X25519)
+	mediator2 := newAgent(t, resolver, "did:example:mediator2", crypto.X25519)
+	bob := newAgent(t, resolver, "did:example:bob", crypto.X25519, did.Service{
+		ID:   "did:example:bob#didcomm",
+		Type: ServiceType,
+		ServiceEndpoint: model.NewDIDCommV2Endpoint([]model.DIDCommV2Endpoint{
+			{URI: "inproc://unreachable", Accept: []string{ProfileV2}},
+			{
+				URI:         "inproc://mediator1",
+				Accept:      []string{ProfileV2},
+				RoutingKeys: []string{mediator1.id + "#ka", mediator2.id + "#ka"},
+			},
+		}),
+	})
+
+	// mediators deliver the forwarded messages of the DIDs they know.
+	routes := map[string]string{mediator2.id: "inproc://mediator2", bob.id: "inproc://bob"}
+	hops := map[string][]string{}
+
+	for _, mediator := range []*agent{mediator1, mediator2} {
+		mediator := mediator
+		messenger := NewMessenger(mediator.packer, resolver, transport)
+
+		transport.Register("inproc://"+mediator.id[len("did:example:"):], messenger.Handler(
+			func(ctx context.Context, msg *Message, md *Metadata) error {
+				next, packed, err := msg.Forwarded()
+				if err != nil {
+					return err
+				}
+
+				require.Equal(t, []string{mediator.id}, msg.To)
+				require.Equal(t, mediator.id+"#ka", md.RecipientKeyID)
+				require.False(t, md.Authenticated())
+
+				hops[mediator.id] = append(hops[mediator.id], next)
+
+				return transport.Send(ctx, routes[next], packed)
+			}))
+	}
+
+	var received []*Message
+
+	bobMessenger := NewMessenger(bob.packer, resolver, transport)
+	transport.Register("inproc://bob", bobMessenger.Handler(func(_ context.Context, msg *Message, md *Metadata) error {
+		require.Equal(t, alice.id+"#ka", md.SenderKeyID)
+		require.Equal(t, bob.id+"#ka", md.RecipientKeyID)
+
+		received = append(received, msg)
+
+		return nil
+	}))
+
+	msg := NewMessage("https://example.com/protocols/1.0/ping", map[string]interface{}{"hello": "bob"})
+	msg.From, msg.To = alice.id, []string{bob.id}
+
+	messenger := NewMessenger(alice.packer, resolver, transport)
+	require.NoError(t, messenger.Send(context.Background(), msg, WithAuthcrypt(alice.id+"#ka")))
+
+	require.Equal(t, []*Message{msg}, received)
+	require.Equal(t, map[string][]string{mediator1.id: {mediator2.id}, mediator2.id: {bob.id}}, hops)
+
+	t.Run("unreachable", func(t *testing.T) {
+		transport.Unregister("inproc://mediator1")
+
+		err := messenger.Send(context.Background(), msg, WithAuthcrypt(alice.id+"#ka"))
+		require.ErrorIs(t, err, model.ErrAllEndpointsFailed)
+		require.Contains(t, err.Error(), "inproc://unreachable")
+	})
+
+	t.Run("no endpoint", func(t *testing.T) {
+		toAlice := NewMessage("https://example.com/protocols/1.0/ping", nil)
+		toAlice.From, toAlice.To = bob.id, []string{alice.id}
+
+		err := bobMessenger.Send(context.Background(), toAlice, WithAnoncrypt())
+		require.ErrorIs(t, err, model.ErrEndpointNotFound)
+	})
+}
@@ -0,0 +1,356 @@
+package didcomm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zRich/zFusion/common/crypto"
+	"github.com/zRich/zFusion/did"
+)
+
+// maxEnvelopes is the maximum number of nested envelopes of a packed message, to unpack
+// anoncrypt(authcrypt(sign(plaintext))).
+const maxEnvelopes = 3
+
+// PackOption configures Packer.Pack.
+type PackOption func(opts *packOpts)
+
+type packOpts struct {
+	signer    string
+	sender    string
+	anoncrypt bool
+}
+
+// WithSigner signs the message with the authentication method signer of the from DID, for non-repudiation.
+func WithSigner(signer string) PackOption {
+	return func(opts *packOpts) {
+		opts.signer = signer
+	}
+}
+
+// WithAuthcrypt encrypts the message for its recipients, authenticated with the key agreement method sender of
+// the from DID.
+func WithAuthcrypt(sender string) PackOption {
+	return func(opts *packOpts) {
+		opts.sender = sender
+	}
+}
+
+// WithAnoncrypt encrypts the message for its recipients without revealing its sender.
+func WithAnoncrypt() PackOption {
+	return func(opts *packOpts) {
+		opts.anoncrypt = true
+	}
+}
+
+// Metadata describes the envelopes of an unpacked message.
+type Metadata struct {
+	// RecipientKeyID is the ID of the key agreement method which decrypted the message, empty when the message
+	// was not encrypted.
+	RecipientKeyID string
+	// SenderKeyID is the ID of the key agreement method of the sender of an authcrypt message.
+	SenderKeyID string
+	// SignerKeyID is the ID of the authentication method which signed the message.
+	SignerKeyID string
+}
+
+// Encrypted tells whether the message was encrypted.
+func (m *Metadata) Encrypted() bool {
+	return m.RecipientKeyID != ""
+}
+
+// Authenticated tells whether the sender of the message was authenticated, with authcrypt or a signature.
+func (m *Metadata) Authenticated() bool {
+	return m.SenderKeyID != "" || m.SignerKeyID != ""
+}
+
+// Packer packs and unpacks messages with the keys of a KeyManager, resolving the DID documents of senders and
+// recipients.
+type Packer struct {
+	keys     KeyManager
+	resolver did.DocumentResolver
+}
+
+// NewPacker creates a packer.
+func NewPacker(keys KeyManager, resolver did.DocumentResolver) *Packer {
+	return &Packer{keys: keys, resolver: resolver}
+}
+
+// Pack packs the message: signed when WithSigner is given, then encrypted when WithAuthcrypt or WithAnoncrypt is
+// given, for the key agreement methods of the to DIDs of the same type as the sender key, or as the first
+// supported key of the first recipient for anoncrypt. Entries of to which are DID URLs select a single key.
+func (p *Packer) Pack(msg *Message, opts ...PackOption) ([]byte, error) {
+	o := &packOpts{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	packed, err := msg.plaintext()
+	if err != nil {
+		return nil, err
+	}
+
+	if o.signer != "" {
+		if packed, err = p.sign(packed, msg.From, o.signer); err != nil {
+			return nil, err
+		}
+	}
+
+	if o.sender == "" && !o.anoncrypt {
+		return packed, nil
+	}
+
+	var (
+		sender  *agreementKey
+		keyType crypto.KeyType
+	)
+
+	if o.sender != "" {
+		if sender, err = p.senderKey(o.sender); err != nil {
+			return nil, err
+		}
+
+		if !sameDID(sender.ID, msg.From) {
+			return nil, fmt.Errorf("%w: sender key %s is not a key of %s", ErrInvalidMessage, o.sender, msg.From)
+		}
+
+		keyType = sender.KeyType
+	}
+
+	recipients, err := p.recipientKeys(msg.To, keyType)
+	if err != nil {
+		return nil, err
+	}
+
+	return encrypt(packed, recipients, sender, p.keys)
+}
+
+// Unpack decrypts and verifies the envelopes of a packed message, and returns the message and how it was packed.
+// The DID of the sender key of authcrypt envelopes and of the signer of signed envelopes must be the from DID of
+// the message, and expired messages are rejected.
+func (p *Packer) Unpack(packed []byte) (*Message, *Metadata, error) {
+	md := &Metadata{}
+
+	for i := 0; i < maxEnvelopes; i++ {
+		var envelope map[string]json.RawMessage
+
+		if err := json.Unmarshal(packed, &envelope); err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrInvalidMessage, err)
+		}
+
+		switch {
+		case envelope["ciphertext"] != nil:
+			if md.SignerKeyID != "" {
+				return nil, nil, fmt.Errorf("%w: encrypted envelope inside a signed one", ErrInvalidMessage)
+			}
+
+			result, err := decrypt(packed, p.keys, p.senderKey)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			packed, md.RecipientKeyID = result.plaintext, result.recipient
+			if result.sender != "" {
+				md.SenderKeyID = result.sender
+			}
+		case envelope["signatures"] != nil:
+			if md.SignerKeyID != "" {
+				return nil, nil, fmt.Errorf("%w: nested signed envelopes", ErrInvalidMessage)
+			}
+
+			payload, kid, verify, err := parseJWS(packed)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if err := p.verify(kid, verify); err != nil {
+				return nil, nil, err
+			}
+
+			packed, md.SignerKeyID = payload, kid
+		default:
+			msg, err := parseMessage(packed)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if err := checkSender(msg, md); err != nil {
+				return nil, nil, err
+			}
+
+			if msg.ExpiresTime != 0 && time.Now().Unix() > msg.ExpiresTime {
+				return nil, nil, fmt.Errorf("%w: %s expired at %s", ErrMessageExpired, msg.ID,
+					time.Unix(msg.ExpiresTime, 0).UTC().Format(time.RFC3339))
+			}
+
+			return msg, md, nil
+		}
+	}
+
+	return nil, nil, fmt.Errorf("%w: more than %d envelopes", ErrInvalidMessage, maxEnvelopes)
+}
+
+// checkSender checks the authenticated keys of the envelopes of msg are keys of its sender.
+func checkSender(msg *Message, md *Metadata) error {
+	for _, kid := range []string{md.SenderKeyID, md.SignerKeyID} {
+		if kid != "" && !sameDID(kid, msg.From) {
+			return fmt.Errorf("%w: %s is not a key of the sender %q", ErrInvalidMessage, kid, msg.From)
+		}
+	}
+
+	return nil
+}
+
+// sign signs the plaintext with the authentication method kid of from.
+func (p *Packer) sign(plaintext []byte, from, kid string) ([]byte, error) {
+	if !sameDID(kid, from) {
+		return nil, fmt.Errorf("%w: signer %s is not a key of %s", ErrInvalidMessage, kid, from)
+	}
+
+	doc, err := p.resolve(kid)
+	if err != nil {
+		return nil, err
+	}
+
+	vm, err := doc.AuthorizedVerificationMethod(kid, did.Authentication)
+	if err != nil {
+		return nil, fmt.Errorf("signer %s: %w", kid, err)
+	}
+
+	vm.ID = did.AbsoluteID(doc.ID, vm.ID)
+
+	signer, err := p.keys.Signer(kid)
+	if err != nil {
+		return nil, err
+	}
+
+	return sign(plaintext, vm, signer)
+}
+
+// verify verifies a signature with the authentication method kid.
+func (p *Packer) verify(kid string, verify func(vm *did.VerificationMethod) error) error {
+	doc, err := p.resolve(kid)
+	if err != nil {
+		return err
+	}
+
+	vm, err := doc.AuthorizedVerificationMethod(kid, did.Authentication)
+	if err != nil {
+		return fmt.Errorf("%w: signer %s: %v", ErrInvalidMessage, kid, err)
+	}
+
+	return verify(vm)
+}
+
+// senderKey resolves the key agreement method kid of a sender.
+func (p *Packer) senderKey(kid string) (*agreementKey, error) {
+	doc, err := p.resolve(kid)
+	if err != nil {
+		return nil, err
+	}
+
+	vm, err := doc.AuthorizedVerificationMethod(kid, did.KeyAgreement)
+	if err != nil {
+		return nil, fmt.Errorf("%w: sender %s: %v", ErrNoKey, kid, err)
+	}
+
+	return newAgreementKey(doc.ID, vm)
+}
+
+// recipientKeys resolves the key agreement methods of recipients of the given key type. The type of the first
+// supported key is used when keyType is empty.
+func (p *Packer) recipientKeys(recipients []string, keyType crypto.KeyType) ([]agreementKey, error) {
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("%w: no recipient", ErrNoKey)
+	}
+
+	var keys []agreementKey
+
+	for _, recipient := range recipients {
+		doc, err := p.resolve(recipient)
+		if err != nil {
+			return nil, err
+		}
+
+		var candidates []did.VerificationMethod
+
+		if strings.Contains(recipient, "#") {
+			vm, err := doc.AuthorizedVerificationMethod(recipient, did.KeyAgreement)
+			if err != nil {
+				return nil, fmt.Errorf("%w: recipient %s: %v", ErrNoKey, recipient, err)
+			}
+
+			candidates = append(candidates, *vm)
+		} else {
+			for _, v := range doc.VerificationMethods(did.KeyAgreement)[did.KeyAgreement] {
+				candidates = append(candidates, v.VerificationMethod)
+			}
+		}
+
+		found := false
+
+		for i := range candidates {
+			key, err := newAgreementKey(doc.ID, &candidates[i])
+			if err != nil || (keyType != "" && key.KeyType != keyType) {
+				continue
+			}
+
+			keyType, found = key.KeyType, true
+			keys = append(keys, *key)
+		}
+
+		if !found {
+			return nil, fmt.Errorf("%w: no supported key agreement method of %s", ErrNoKey, recipient)
+		}
+	}
+
+	return keys, nil
+}
+
+// resolve resolves the DID document of a DID or DID URL.
+func (p *Packer) resolve(didURL string) (*did.Document, error) {
+	parsed, err := did.ParseDIDURL(didURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMessage, err)
+	}
+
+	doc, err := p.resolver.ResolveDocument(parsed.DID.String())
+	if err != nil {
+		return nil, fmt.Errorf("resolve %s: %w", parsed.DID.String(), err)
+	}
+
+	return doc, nil
+}
+
+// newAgreementKey returns the key of an X25519 or P-256 key agreement method of the document docID.
+func newAgreementKey(docID string, vm *did.VerificationMethod) (*agreementKey, error) {
+	keyType, err := vm.KeyType()
+	if err != nil {
+		return nil, err
+	}
+
+	if keyType != crypto.X25519 && keyType != crypto.ECDSAP256 {
+		return nil, fmt.Errorf("%w: unsupported %s key agreement method %s", ErrNoKey, keyType, vm.ID)
+	}
+
+	return &agreementKey{ID: did.AbsoluteID(docID, vm.ID), KeyType: keyType, PublicKey: vm.Value}, nil
+}
+
+// sameDID tells whether the DID URL didURL is, or is a DID URL of, the DID id.
+func sameDID(didURL, id string) bool {
+	parsed, err := did.ParseDIDURL(didURL)
+
+	return err == nil && id != "" && parsed.DID.String() == id
+}
+
+// didOf returns the DID of a DID URL, or the DID URL itself when it cannot be parsed.
+func didOf(didURL string) string {
+	parsed, err := did.ParseDIDURL(didURL)
+	if err != nil {
+		return didURL
+	}
+
+	return parsed.DID.String()
+}
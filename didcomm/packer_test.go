@@ -0,0 +1,254 @@
+package didcomm
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zRich/zFusion/common/crypto"
+	"github.com/zRich/zFusion/did"
+	"github.com/zRich/zFusion/kms"
+	"github.com/zRich/zFusion/storage/leveldb"
+)
+
+type mockResolver map[string]*did.Document
+
+func (r mockResolver) ResolveDocument(id string) (*did.Document, error) {
+	doc, ok := r[id]
+	if !ok {
+		return nil, fmt.Errorf("DID %s not found", id)
+	}
+
+	return doc, nil
+}
+
+// agent is a DID with an Ed25519 authentication key #auth and a key agreement key #ka held by its own KMS.
+type agent struct {
+	id     string
+	doc    *did.Document
+	packer *Packer
+}
+
+func newAgent(t *testing.T, resolver mockResolver, id string, keyAgreement crypto.KeyType,
+	services ...did.Service) *agent {
+	t.Helper()
+
+	p := leveldb.NewProvider(filepath.Join(t.TempDir(), "db"))
+	t.Cleanup(func() { require.NoError(t, p.Close()) })
+
+	store, err := p.OpenStore(kms.StoreName)
+	require.NoError(t, err)
+
+	keys, err := kms.New(store, []byte("passphrase"), kms.WithIterations(10))
+	require.NoError(t, err)
+
+	var vms []did.VerificationMethod
+
+	for _, key := range []struct {
+		fragment string
+		keyType  crypto.KeyType
+	}{{"#auth", crypto.Ed25519}, {"#ka", keyAgreement}} {
+		keyID, _, err := keys.Create(key.keyType)
+		require.NoError(t, err)
+
+		vm, err := keys.VerificationMethod(keyID, id+key.fragment, id)
+		require.NoError(t, err)
+
+		vms = append(vms, *vm)
+	}
+
+	doc := did.BuildDoc(
+		did.WithVerificationMethod(vms),
+		did.WithAuthentication([]did.Verification{{VerificationMethod: vms[0], Relationship: did.Authentication}}),
+		did.WithKeyAgreement([]did.Verification{{VerificationMethod: vms[1], Relationship: did.KeyAgreement}}),
+		did.WithService(services),
+	)
+	doc.ID = id
+	resolver[id] = doc
+
+	return &agent{id: id, doc: doc, packer: NewPacker(keys, resolver)}
+}
+
+func TestPack(t *testing.T) {
+	for _, keyType := range []crypto.KeyType{crypto.X25519, crypto.ECDSAP256} {
+		t.Run(string(keyType), func(t *testing.T) {
+			resolver := mockResolver{}
+			alice := newAgent(t, resolver, "did:example:alice", keyType)
+			bob := newAgent(t, resolver, "did:example:bob", keyType)
+			carol := newAgent(t, resolver, "did:example:carol", keyType)
+
+			msg := NewMessage("https://example.com/protocols/1.0/ping", map[string]interface{}{"hello": "bob"})
+			msg.From, msg.To = alice.id, []string{bob.id, carol.id}
+
+			tests := []struct {
+				name     string
+				opts     []PackOption
+				expected Metadata
+			}{
+				{name: "plaintext"},
+				{
+					name:     "signed",
+					opts:     []PackOption{WithSigner(alice.id + "#auth")},
+					expected: Metadata{SignerKeyID: alice.id + "#auth"},
+				},
+				{
+					name:     "anoncrypt",
+					opts:     []PackOption{WithAnoncrypt()},
+					expected: Metadata{RecipientKeyID: bob.id + "#ka"},
+				},
+				{
+					name:     "authcrypt",
+					opts:     []PackOption{WithAuthcrypt(alice.id + "#ka")},
+					expected: Metadata{RecipientKeyID: bob.id + "#ka", SenderKeyID: alice.id + "#ka"},
+				},
+				{
+					name:     "anoncrypt signed",
+					opts:     []PackOption{WithSigner(alice.id + "#auth"), WithAnoncrypt()},
+					expected: Metadata{RecipientKeyID: bob.id + "#ka", SignerKeyID: alice.id + "#auth"},
+				},
+			}
+
+			for _, tc := range tests {
+				packed, err := alice.packer.Pack(msg, tc.opts...)
+				require.NoError(t, err, tc.name)
+
+				unpacked, md, err := bob.packer.Unpack(packed)
+				require.NoError(t, err, tc.name)
+				require.Equal(t, msg, unpacked, tc.name)
+				require.Equal(t, tc.expected, *md, tc.name)
+
+				if md.Encrypted() {
+					_, md, err = carol.packer.Unpack(packed)
+					require.NoError(t, err, tc.name)
+					require.Equal(t, carol.id+"#ka", md.RecipientKeyID, tc.name)
+				}
+			}
+
+			packed, err := alice.packer.Pack(msg, WithAuthcrypt(alice.id+"#ka"))
+			require.NoError(t, err)
+
+			var envelope map[string]interface{}
+			require.NoError(t, json.Unmarshal(packed, &envelope))
+			require.Len(t, envelope["recipients"], 2)
+
+			var header jweHeader
+			require.NoError(t, decodeJSON(envelope["protected"].(string), &header))
+			require.Equal(t, MediaTypeEncrypted, header.Typ)
+			require.Equal(t, algECDH1PU, header.Alg)
+			require.Equal(t, encA256CBCHS512, header.Enc)
+			require.Equal(t, alice.id+"#ka", header.Skid)
+
+			_, _, err = alice.packer.Unpack(packed)
+			require.ErrorIs(t, err, ErrNoKey)
+		})
+	}
+}
+
+func TestPackErrors(t *testing.T) {
+	resolver := mockResolver{}
+	alice := newAgent(t, resolver, "did:example:alice", crypto.X25519)
+	bob := newAgent(t, resolver, "did:example:bob", crypto.X25519)
+	p256 := newAgent(t, resolver, "did:example:p256", crypto.ECDSAP256)
+	mallory := newAgent(t, resolver, "did:example:mallory", crypto.X25519)
+
+	msg := NewMessage("https://example.com/protocols/1.0/ping", nil)
+	msg.From, msg.To = alice.id, []string{bob.id}
+
+	t.Run("tampered", func(t *testing.T) {
+		packed, err := alice.packer.Pack(msg, WithAuthcrypt(alice.id+"#ka"))
+		require.NoError(t, err)
+
+		var envelope jwe
+		require.NoError(t, json.Unmarshal(packed, &envelope))
+
+		ciphertext, err := decode(envelope.Ciphertext)
+		require.NoError(t, err)
+
+		ciphertext[0] ^= 1
+		envelope.Ciphertext = encode(ciphertext)
+
+		tampered, err := json.Marshal(envelope)
+		require.NoError(t, err)
+
+		_, _, err = bob.packer.Unpack(tampered)
+		require.ErrorIs(t, err, ErrInvalidMessage)
+	})
+
+	t.Run("truncated IV", func(t *testing.T) {
+		for _, opt := range []PackOption{WithAnoncrypt(), WithAuthcrypt(alice.id + "#ka")} {
+			packed, err := alice.packer.Pack(msg, opt)
+			require.NoError(t, err)
+
+			var envelope jwe
+			require.NoError(t, json.Unmarshal(packed, &envelope))
+
+			iv, err := decode(envelope.IV)
+			require.NoError(t, err)
+
+			envelope.IV = encode(iv[:8])
+
+			truncated, err := json.Marshal(envelope)
+			require.NoError(t, err)
+
+			_, _, err = bob.packer.Unpack(truncated)
+			require.ErrorIs(t, err, ErrInvalidMessage)
+		}
+	})
+
+	t.Run("impersonated sender", func(t *testing.T) {
+		impersonated := *msg
+		impersonated.From = mallory.id
+
+		_, err := mallory.packer.Pack(&impersonated, WithAuthcrypt(alice.id+"#ka"))
+		require.ErrorIs(t, err, ErrInvalidMessage)
+
+		// mallory encrypts with its own key a message claiming to come from alice.
+		plaintext, err := msg.plaintext()
+		require.NoError(t, err)
+
+		recipients, err := mallory.packer.recipientKeys(msg.To, crypto.X25519)
+		require.NoError(t, err)
+
+		sender, err := mallory.packer.senderKey(mallory.id + "#ka")
+		require.NoError(t, err)
+
+		packed, err := encrypt(plaintext, recipients, sender, mallory.packer.keys)
+		require.NoError(t, err)
+
+		_, _, err = bob.packer.Unpack(packed)
+		require.ErrorIs(t, err, ErrInvalidMessage)
+	})
+
+	t.Run("key type mismatch", func(t *testing.T) {
+		mismatch := *msg
+		mismatch.To = []string{p256.id}
+
+		_, err := alice.packer.Pack(&mismatch, WithAuthcrypt(alice.id+"#ka"))
+		require.ErrorIs(t, err, ErrNoKey)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		expired := *msg
+		expired.ExpiresTime = time.Now().Add(-time.Minute).Unix()
+
+		packed, err := alice.packer.Pack(&expired, WithAnoncrypt())
+		require.NoError(t, err)
+
+		_, _, err = bob.packer.Unpack(packed)
+		require.ErrorIs(t, err, ErrMessageExpired)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, _, err := bob.packer.Unpack([]byte(`{"id": "1"}`))
+		require.ErrorIs(t, err, ErrInvalidMessage)
+
+		_, _, err = bob.packer.Unpack([]byte(`not JSON`))
+		require.ErrorIs(t, err, ErrInvalidMessage)
+
+		_, err = alice.packer.Pack(&Message{ID: "1"})
+		require.ErrorIs(t, err, ErrInvalidMessage)
+	})
+}
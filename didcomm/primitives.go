@@ -0,0 +1,210 @@
+package didcomm
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// errAuthentication is returned when a ciphertext or a wrapped key fails its integrity check.
+var errAuthentication = errors.New("message authentication failed")
+
+// concatKDF derives a key of keySize bytes from the shared secret z with the Concat KDF of NIST SP 800-56A and
+// SHA-256, with the OtherInfo of RFC 7518 section 4.6.2. tag is the cctag of ECDH-1PU key wrapping modes,
+// appended to SuppPubInfo as specified by draft-madden-jose-ecdh-1pu-04 section 2.3, and nil for ECDH-ES.
+func concatKDF(z []byte, alg string, apu, apv []byte, keySize int, tag []byte) []byte {
+	var otherInfo bytes.Buffer
+
+	writeLengthPrefixed(&otherInfo, []byte(alg))
+	writeLengthPrefixed(&otherInfo, apu)
+	writeLengthPrefixed(&otherInfo, apv)
+	_ = binary.Write(&otherInfo, binary.BigEndian, uint32(keySize*8)) //nolint:errcheck,gomnd
+
+	if tag != nil {
+		writeLengthPrefixed(&otherInfo, tag)
+	}
+
+	key := make([]byte, 0, keySize+sha256.Size)
+
+	for counter := uint32(1); len(key) < keySize; counter++ {
+		h := sha256.New()
+		_ = binary.Write(h, binary.BigEndian, counter) //nolint:errcheck
+		h.Write(z)
+		h.Write(otherInfo.Bytes())
+		key = h.Sum(key)
+	}
+
+	return key[:keySize]
+}
+
+func writeLengthPrefixed(buf *bytes.Buffer, data []byte) {
+	_ = binary.Write(buf, binary.BigEndian, uint32(len(data))) //nolint:errcheck
+	buf.Write(data)
+}
+
+// keyWrapIV is the default initial value of RFC 3394.
+var keyWrapIV = []byte{0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6} //nolint:gochecknoglobals
+
+// wrapKey wraps the key cek with the key encryption key kek, following the AES Key Wrap algorithm of RFC 3394.
+func wrapKey(kek, cek []byte) ([]byte, error) {
+	if len(cek)%8 != 0 || len(cek) < 16 {
+		return nil, fmt.Errorf("invalid key size %d to wrap", len(cek))
+	}
+
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	n := len(cek) / 8
+	out := make([]byte, 8+len(cek))
+	copy(out, keyWrapIV)
+	copy(out[8:], cek)
+
+	buf := make([]byte, aes.BlockSize)
+
+	for j := 0; j < 6; j++ {
+		for i := 1; i <= n; i++ {
+			copy(buf, out[:8])
+			copy(buf[8:], out[8*i:8*i+8])
+			block.Encrypt(buf, buf)
+
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(out[:8], binary.BigEndian.Uint64(buf[:8])^t)
+			copy(out[8*i:], buf[8:])
+		}
+	}
+
+	return out, nil
+}
+
+// unwrapKey unwraps a key wrapped by wrapKey.
+func unwrapKey(kek, wrapped []byte) ([]byte, error) {
+	if len(wrapped)%8 != 0 || len(wrapped) < 24 {
+		return nil, fmt.Errorf("invalid wrapped key size %d", len(wrapped))
+	}
+
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	n := len(wrapped)/8 - 1
+	out := append([]byte(nil), wrapped...)
+	buf := make([]byte, aes.BlockSize)
+
+	for j := 5; j >= 0; j-- {
+		for i := n; i >= 1; i-- {
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(buf[:8], binary.BigEndian.Uint64(out[:8])^t)
+			copy(buf[8:], out[8*i:8*i+8])
+			block.Decrypt(buf, buf)
+
+			copy(out[:8], buf[:8])
+			copy(out[8*i:], buf[8:])
+		}
+	}
+
+	if subtle.ConstantTimeCompare(out[:8], keyWrapIV) != 1 {
+		return nil, fmt.Errorf("%w: unwrap key", errAuthentication)
+	}
+
+	return out[8:], nil
+}
+
+// cbcHMAC is the AES_CBC_HMAC_SHA2 authenticated encryption of RFC 7518 section 5.2 with AES-256 and
+// HMAC-SHA-512, A256CBC-HS512. Its key is the 32 bytes MAC key followed by the 32 bytes encryption key.
+type cbcHMAC struct {
+	block  cipher.Block
+	macKey []byte
+}
+
+const (
+	cbcHMACKeySize = 64
+	cbcHMACTagSize = 32
+)
+
+func newCBCHMAC(key []byte) (cipher.AEAD, error) {
+	if len(key) != cbcHMACKeySize {
+		return nil, fmt.Errorf("invalid A256CBC-HS512 key size %d", len(key))
+	}
+
+	block, err := aes.NewCipher(key[cbcHMACKeySize/2:])
+	if err != nil {
+		return nil, err
+	}
+
+	return &cbcHMAC{block: block, macKey: append([]byte(nil), key[:cbcHMACKeySize/2]...)}, nil
+}
+
+func (c *cbcHMAC) NonceSize() int {
+	return aes.BlockSize
+}
+
+func (c *cbcHMAC) Overhead() int {
+	return aes.BlockSize + cbcHMACTagSize
+}
+
+// Seal appends the PKCS #7 padded ciphertext of plaintext and the authentication tag to dst.
+func (c *cbcHMAC) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	padding := aes.BlockSize - len(plaintext)%aes.BlockSize
+	ciphertext := make([]byte, len(plaintext)+padding)
+	copy(ciphertext, plaintext)
+
+	for i := len(plaintext); i < len(ciphertext); i++ {
+		ciphertext[i] = byte(padding)
+	}
+
+	cipher.NewCBCEncrypter(c.block, nonce).CryptBlocks(ciphertext, ciphertext)
+
+	return append(append(dst, ciphertext...), c.tag(nonce, ciphertext, additionalData)...)
+}
+
+// Open authenticates and decrypts a ciphertext sealed by Seal and appends the plaintext to dst.
+func (c *cbcHMAC) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != aes.BlockSize || len(ciphertext) < c.Overhead() ||
+		(len(ciphertext)-cbcHMACTagSize)%aes.BlockSize != 0 {
+		return nil, errAuthentication
+	}
+
+	tag := ciphertext[len(ciphertext)-cbcHMACTagSize:]
+	ciphertext = ciphertext[:len(ciphertext)-cbcHMACTagSize]
+
+	if !hmac.Equal(tag, c.tag(nonce, ciphertext, additionalData)) {
+		return nil, errAuthentication
+	}
+
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(c.block, nonce).CryptBlocks(plaintext, ciphertext)
+
+	padding := int(plaintext[len(plaintext)-1])
+	if padding == 0 || padding > aes.BlockSize {
+		return nil, errAuthentication
+	}
+
+	for _, b := range plaintext[len(plaintext)-padding:] {
+		if int(b) != padding {
+			return nil, errAuthentication
+		}
+	}
+
+	return append(dst, plaintext[:len(plaintext)-padding]...), nil
+}
+
+// tag computes the first half of HMAC-SHA-512(A || IV || E || AL).
+func (c *cbcHMAC) tag(iv, ciphertext, additionalData []byte) []byte {
+	mac := hmac.New(sha512.New, c.macKey)
+	mac.Write(additionalData)
+	mac.Write(iv)
+	mac.Write(ciphertext)
+	_ = binary.Write(mac, binary.BigEndian, uint64(len(additionalData))*8) //nolint:errcheck,gomnd
+
+	return mac.Sum(nil)[:cbcHMACTagSize]
+}
@@ -0,0 +1,84 @@
+package didcomm
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func fromHex(t *testing.T, s string) []byte {
+	t.Helper()
+
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+
+	return b
+}
+
+func TestConcatKDF(t *testing.T) {
+	// https://www.rfc-editor.org/rfc/rfc7518#appendix-C
+	z := []byte{
+		158, 86, 217, 29, 129, 113, 53, 211, 114, 131, 66, 131, 191, 132, 38, 156,
+		251, 49, 110, 163, 218, 128, 106, 72, 246, 218, 167, 121, 140, 254, 144, 196,
+	}
+
+	key := concatKDF(z, "A128GCM", []byte("Alice"), []byte("Bob"), 16, nil)
+	require.Equal(t, "VqqN6vgjbSBcIijNcacQGg", base64.RawURLEncoding.EncodeToString(key))
+
+	require.NotEqual(t, concatKDF(z, "A128GCM", nil, nil, 32, nil), concatKDF(z, "A128GCM", nil, nil, 32, []byte{1}))
+}
+
+func TestKeyWrap(t *testing.T) {
+	// https://www.rfc-editor.org/rfc/rfc3394#section-4.6
+	kek := fromHex(t, "000102030405060708090A0B0C0D0E0F101112131415161718191A1B1C1D1E1F")
+	key := fromHex(t, "00112233445566778899AABBCCDDEEFF000102030405060708090A0B0C0D0E0F")
+
+	wrapped, err := wrapKey(kek, key)
+	require.NoError(t, err)
+	require.Equal(t, fromHex(t, "28C9F404C4B810F4CBCCB35CFB87F8263F5786E2D80ED326CBC7F0E71A99F43BFB988B9B7A02DD21"),
+		wrapped)
+
+	unwrapped, err := unwrapKey(kek, wrapped)
+	require.NoError(t, err)
+	require.Equal(t, key, unwrapped)
+
+	wrapped[0] ^= 1
+	_, err = unwrapKey(kek, wrapped)
+	require.ErrorIs(t, err, errAuthentication)
+
+	_, err = wrapKey(kek, key[:12])
+	require.Error(t, err)
+}
+
+func TestCBCHMAC(t *testing.T) {
+	// https://www.rfc-editor.org/rfc/rfc7518#appendix-B.3
+	key := make([]byte, cbcHMACKeySize)
+	for i := range key {
+		key[i] = byte(i)
+	}
+
+	plaintext := []byte("A cipher system must not be required to be secret, and it must be able to fall into the " +
+		"hands of the enemy without inconvenience")
+	aad := []byte("The second principle of Auguste Kerckhoffs")
+	iv := fromHex(t, "1af38c2dc2b96ffdd86694092341bc04")
+
+	aead, err := newCBCHMAC(key)
+	require.NoError(t, err)
+
+	sealed := aead.Seal(nil, iv, plaintext, aad)
+	require.Equal(t, fromHex(t, "4dd3b4c088a7f45c216839645b2012bf2e6269a8c56a816dbc1b267761955bc5"),
+		sealed[len(sealed)-cbcHMACTagSize:])
+
+	opened, err := aead.Open(nil, iv, sealed, aad)
+	require.NoError(t, err)
+	require.Equal(t, plaintext, opened)
+
+	_, err = aead.Open(nil, iv, sealed, []byte("other"))
+	require.ErrorIs(t, err, errAuthentication)
+
+	sealed[0] ^= 1
+	_, err = aead.Open(nil, iv, sealed, aad)
+	require.ErrorIs(t, err, errAuthentication)
+}
@@ -0,0 +1,58 @@
+package didcomm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Handler handles the packed messages received by a transport.
+type Handler func(ctx context.Context, packed []byte) error
+
+// Transport sends packed messages to the URIs of service endpoints.
+type Transport interface {
+	// Send sends the packed message to the endpoint uri. It fails with ErrUnsupportedEndpoint when the transport
+	// cannot deliver to uri, so that the next endpoint of the recipient is tried.
+	Send(ctx context.Context, uri string, packed []byte) error
+}
+
+// InProcessTransport delivers messages to the handlers registered for their endpoint URI in the same process.
+// It is meant for tests.
+type InProcessTransport struct {
+	lock     sync.RWMutex
+	handlers map[string]Handler
+}
+
+// NewInProcessTransport creates an in-process transport without endpoints.
+func NewInProcessTransport() *InProcessTransport {
+	return &InProcessTransport{handlers: make(map[string]Handler)}
+}
+
+// Register registers the handler of the messages sent to the endpoint uri.
+func (t *InProcessTransport) Register(uri string, handler Handler) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.handlers[uri] = handler
+}
+
+// Unregister removes the handler of the endpoint uri.
+func (t *InProcessTransport) Unregister(uri string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	delete(t.handlers, uri)
+}
+
+// Send passes a copy of the packed message to the handler of uri, and returns its error.
+func (t *InProcessTransport) Send(ctx context.Context, uri string, packed []byte) error {
+	t.lock.RLock()
+	handler, ok := t.handlers[uri]
+	t.lock.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("%w: no in-process handler for %s", ErrUnsupportedEndpoint, uri)
+	}
+
+	return handler(ctx, append([]byte(nil), packed...))
+}
@@ -0,0 +1,162 @@
+package kms
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/zRich/zFusion/common/crypto"
+	"github.com/zRich/zFusion/storage/spi"
+)
+
+const (
+	// DefaultIterations is the default PBKDF2 iteration count used to derive the keystore encryption key.
+	DefaultIterations = 310000
+
+	saltSize       = 16
+	encryptionSize = 32
+
+	// metadataKey is the store key of the keystore metadata. Key IDs cannot collide with it as it is not a
+	// valid DID URL nor multibase fingerprint.
+	metadataKey = "kms:metadata"
+	checkValue  = "zFusion KMS"
+)
+
+// keystoreMetadata holds the parameters of the keystore encryption key derivation, and a value encrypted with
+// the key so that a wrong passphrase is detected when the keystore is opened.
+type keystoreMetadata struct {
+	Salt       []byte `json:"salt"`
+	Iterations int    `json:"iterations"`
+	Check      []byte `json:"check"`
+}
+
+// keyRecord is a stored key. Private keys are sealed with AES-256-GCM, with the key ID as additional data so
+// that records cannot be swapped. Records with an Alias bind a verification method ID to another key ID.
+type keyRecord struct {
+	Alias      string         `json:"alias,omitempty"`
+	KeyType    crypto.KeyType `json:"keyType,omitempty"`
+	PublicKey  []byte         `json:"publicKey,omitempty"`
+	PrivateKey []byte         `json:"privateKey,omitempty"`
+}
+
+// openKeystore derives the encryption key of the keystore from passphrase, initializing the keystore metadata
+// of a new store.
+func openKeystore(store spi.Store, passphrase []byte, iterations int) (cipher.AEAD, error) {
+	metadataBytes, err := store.Get(metadataKey)
+	if errors.Is(err, spi.ErrDataNotFound) {
+		return initKeystore(store, passphrase, iterations)
+	} else if err != nil {
+		return nil, err
+	}
+
+	var metadata keystoreMetadata
+	if err = json.Unmarshal(metadataBytes, &metadata); err != nil {
+		return nil, fmt.Errorf("invalid keystore metadata: %w", err)
+	}
+
+	aead, err := newAEAD(pbkdf2SHA256(passphrase, metadata.Salt, metadata.Iterations, encryptionSize))
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = open(aead, metadata.Check, metadataKey); err != nil {
+		return nil, ErrWrongPassphrase
+	}
+
+	return aead, nil
+}
+
+func initKeystore(store spi.Store, passphrase []byte, iterations int) (cipher.AEAD, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	aead, err := newAEAD(pbkdf2SHA256(passphrase, salt, iterations, encryptionSize))
+	if err != nil {
+		return nil, err
+	}
+
+	check, err := seal(aead, []byte(checkValue), metadataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	metadataBytes, err := json.Marshal(&keystoreMetadata{Salt: salt, Iterations: iterations, Check: check})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = store.Put(metadataKey, metadataBytes); err != nil {
+		return nil, err
+	}
+
+	return aead, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts plaintext, bound to id, and prefixes the ciphertext with a random nonce.
+func seal(aead cipher.AEAD, plaintext []byte, id string) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, []byte(id)), nil
+}
+
+func open(aead cipher.AEAD, sealed []byte, id string) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("sealed data too short")
+	}
+
+	return aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(id))
+}
+
+// pbkdf2SHA256 is PBKDF2 of RFC 8018 with HMAC-SHA-256.
+func pbkdf2SHA256(password, salt []byte, iterations, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	key := make([]byte, 0, keyLen+prf.Size())
+
+	var counter [4]byte
+
+	for block := uint32(1); len(key) < keyLen; block++ {
+		binary.BigEndian.PutUint32(counter[:], block)
+
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write(counter[:])
+		u := prf.Sum(nil)
+
+		t := make([]byte, len(u))
+		copy(t, u)
+
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+
+		key = append(key, t...)
+	}
+
+	return key[:keyLen]
+}
@@ -0,0 +1,473 @@
+// Package kms implements a local key management service. Private keys are kept in a storage/spi.Store, encrypted
+// with a key derived from a passphrase, and are never returned: callers sign, verify and derive shared secrets
+// by key ID. Key IDs default to the multibase fingerprint of the public key, and are bound to the IDs of the
+// verification methods which publish the keys, so that the controller of a DID can act with the key of any of
+// its verification methods.
+package kms
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/zRich/zFusion/common/crypto"
+	"github.com/zRich/zFusion/common/crypto/sm2"
+	"github.com/zRich/zFusion/common/crypto/x25519"
+	"github.com/zRich/zFusion/common/multicodec"
+	"github.com/zRich/zFusion/did"
+	"github.com/zRich/zFusion/storage/spi"
+)
+
+// StoreName is the recommended name of the store holding keys.
+const StoreName = "kms"
+
+var (
+	// ErrKeyNotFound is returned for unknown key IDs.
+	ErrKeyNotFound = errors.New("key not found")
+	// ErrKeyExists is returned when creating or binding a key ID which is already used.
+	ErrKeyExists = errors.New("key already exists")
+	// ErrUnsupportedKeyType is returned for key types or operations which are not supported.
+	ErrUnsupportedKeyType = errors.New("unsupported key type")
+	// ErrWrongPassphrase is returned when the passphrase does not decrypt the keystore.
+	ErrWrongPassphrase = errors.New("wrong keystore passphrase")
+	// ErrKeyMismatch is returned when a verification method does not publish the key it is bound to.
+	ErrKeyMismatch = errors.New("verification method does not match the key")
+)
+
+// Option configures a LocalKMS.
+type Option func(opts *kmsOpts)
+
+type kmsOpts struct {
+	iterations int
+}
+
+// WithIterations sets the PBKDF2 iteration count of a new keystore, DefaultIterations by default. The count of
+// an existing keystore is kept.
+func WithIterations(iterations int) Option {
+	return func(opts *kmsOpts) {
+		opts.iterations = iterations
+	}
+}
+
+// KeyOption configures the creation of a key.
+type KeyOption func(opts *keyOpts)
+
+type keyOpts struct {
+	keyID string
+}
+
+// WithKeyID sets the ID of a new key, such as the ID of the verification method which will publish it.
+func WithKeyID(keyID string) KeyOption {
+	return func(opts *keyOpts) {
+		opts.keyID = keyID
+	}
+}
+
+// LocalKMS manages keys persisted in a storage/spi.Store.
+type LocalKMS struct {
+	store spi.Store
+	aead  cipher.AEAD
+	lock  sync.Mutex
+}
+
+// New opens the keystore of store with passphrase. A new keystore is initialized in an empty store, otherwise
+// ErrWrongPassphrase is returned unless passphrase is the passphrase of the keystore.
+func New(store spi.Store, passphrase []byte, opts ...Option) (*LocalKMS, error) {
+	options := &kmsOpts{iterations: DefaultIterations}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	if options.iterations < 1 {
+		return nil, fmt.Errorf("invalid PBKDF2 iteration count: %d", options.iterations)
+	}
+
+	if len(passphrase) == 0 {
+		return nil, errors.New("empty keystore passphrase")
+	}
+
+	aead, err := openKeystore(store, passphrase, options.iterations)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LocalKMS{store: store, aead: aead}, nil
+}
+
+// Create creates a key of the given type, one of Ed25519, X25519, P-256, secp256k1 and SM2, and returns its key ID
+// and public key. Public keys are encoded as for crypto.Verify, ECDSA and SM2 keys are compressed.
+func (k *LocalKMS) Create(keyType crypto.KeyType, opts ...KeyOption) (string, []byte, error) {
+	options := &keyOpts{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	privateKey, publicKey, err := generateKey(keyType)
+	if err != nil {
+		return "", nil, err
+	}
+
+	keyID := options.keyID
+	if keyID == "" {
+		keyID, err = Fingerprint(keyType, publicKey)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	if keyID == metadataKey {
+		return "", nil, fmt.Errorf("%w: %s", ErrKeyExists, keyID)
+	}
+
+	sealed, err := seal(k.aead, privateKey, keyID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	if err = k.putRecord(keyID, &keyRecord{KeyType: keyType, PublicKey: publicKey, PrivateKey: sealed}); err != nil {
+		return "", nil, err
+	}
+
+	return keyID, publicKey, nil
+}
+
+// Bind binds the ID of a verification method to the key keyID, so that the key can be used by verification
+// method ID. Binding an ID again to the same key is a no-op, binding it to another key fails with ErrKeyExists.
+func (k *LocalKMS) Bind(keyID, verificationMethodID string) error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	record, err := k.getRecord(keyID)
+	if err != nil {
+		return err
+	}
+
+	if record.Alias != "" {
+		keyID = record.Alias
+	}
+
+	if keyID == verificationMethodID {
+		return nil
+	}
+
+	if bound, err := k.getRecord(verificationMethodID); err == nil && bound.Alias == keyID {
+		return nil
+	}
+
+	return k.putRecord(verificationMethodID, &keyRecord{Alias: keyID})
+}
+
+// VerificationMethod returns a Multikey verification method with the given ID and controller publishing the key
+// keyID, and binds the verification method ID to the key.
+func (k *LocalKMS) VerificationMethod(keyID, id, controller string) (*did.VerificationMethod, error) {
+	keyType, publicKey, err := k.PublicKey(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	codec, err := multicodec.ForKeyType(keyType)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = k.Bind(keyID, id); err != nil {
+		return nil, err
+	}
+
+	return did.NewMultikey(id, controller, codec.Code, publicKey)
+}
+
+// PublicKey returns the type and public key of the key keyID.
+func (k *LocalKMS) PublicKey(keyID string) (crypto.KeyType, []byte, error) {
+	_, record, err := k.resolve(keyID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return record.KeyType, record.PublicKey, nil
+}
+
+// Delete deletes the key keyID, or the binding of a verification method ID.
+func (k *LocalKMS) Delete(keyID string) error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	if _, err := k.getRecord(keyID); err != nil {
+		return err
+	}
+
+	return k.store.Delete(keyID)
+}
+
+// Signer returns a signer with the key keyID.
+func (k *LocalKMS) Signer(keyID string) (crypto.Signer, error) {
+	id, record, err := k.resolve(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	privateKey, err := k.privateKey(id, record)
+	if err != nil {
+		return nil, err
+	}
+
+	switch record.KeyType {
+	case crypto.Ed25519:
+		return crypto.NewSigner(ed25519.NewKeyFromSeed(privateKey))
+	case crypto.ECDSAP256, crypto.ECDSASecp256k1, crypto.SM2:
+		ecKey, err := ecdsaPrivateKey(record.KeyType, privateKey)
+		if err != nil {
+			return nil, err
+		}
+
+		return crypto.NewSigner(ecKey)
+	default:
+		return nil, fmt.Errorf("%w: %s keys cannot sign", ErrUnsupportedKeyType, record.KeyType)
+	}
+}
+
+// SignerFor returns a signer with the key bound to the verification method, after checking that the
+// verification method publishes the public key of the signer.
+func (k *LocalKMS) SignerFor(vm *did.VerificationMethod) (crypto.Signer, error) {
+	keyType, err := vm.KeyType()
+	if err != nil {
+		return nil, err
+	}
+
+	_, record, err := k.resolve(vm.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if keyType != record.KeyType || !samePublicKey(keyType, vm.Value, record.PublicKey) {
+		return nil, fmt.Errorf("%w: %s", ErrKeyMismatch, vm.ID)
+	}
+
+	return k.Signer(vm.ID)
+}
+
+// Sign signs msg with the key keyID.
+func (k *LocalKMS) Sign(keyID string, msg []byte) ([]byte, error) {
+	signer, err := k.Signer(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return signer.Sign(msg)
+}
+
+// Verify verifies the signature sig over msg with the public key of the key keyID.
+func (k *LocalKMS) Verify(keyID string, msg, sig []byte) error {
+	keyType, publicKey, err := k.PublicKey(keyID)
+	if err != nil {
+		return err
+	}
+
+	return crypto.Verify(keyType, publicKey, msg, sig)
+}
+
+// DeriveSharedSecret returns the Diffie-Hellman shared secret of the key keyID and the public key of a peer of the
+// same type: the X25519 output for X25519 keys, the x-coordinate of the ECDH shared point for ECDSA keys. The
+// secret must be passed through a key derivation function before use. SM2 keys agree on keys with the SM2 key
+// exchange protocol, which requires ephemeral keys, see package sm2.
+func (k *LocalKMS) DeriveSharedSecret(keyID string, peerPublicKey []byte) ([]byte, error) {
+	id, record, err := k.resolve(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if record.KeyType != crypto.X25519 && record.KeyType != crypto.ECDSAP256 &&
+		record.KeyType != crypto.ECDSASecp256k1 {
+		return nil, fmt.Errorf("%w: %s keys cannot derive shared secrets", ErrUnsupportedKeyType, record.KeyType)
+	}
+
+	privateKey, err := k.privateKey(id, record)
+	if err != nil {
+		return nil, err
+	}
+
+	if record.KeyType == crypto.X25519 {
+		return x25519.X25519(privateKey, peerPublicKey)
+	}
+
+	peer, err := crypto.ParseECDSAPublicKey(record.KeyType, peerPublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if !peer.Curve.IsOnCurve(peer.X, peer.Y) {
+		return nil, fmt.Errorf("invalid %s peer public key", record.KeyType)
+	}
+
+	x, _ := peer.Curve.ScalarMult(peer.X, peer.Y, privateKey) //nolint:staticcheck
+
+	return x.FillBytes(make([]byte, len(privateKey))), nil
+}
+
+// Fingerprint returns the multibase encoded, multicodec prefixed public key, the default key ID. It is the
+// method specific ID of the did:key of the key.
+func Fingerprint(keyType crypto.KeyType, publicKey []byte) (string, error) {
+	codec, err := multicodec.ForKeyType(keyType)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedKeyType, keyType)
+	}
+
+	prefixed, err := multicodec.Encode(codec.Code, publicKey)
+	if err != nil {
+		return "", err
+	}
+
+	return "z" + base58.Encode(prefixed), nil
+}
+
+// resolve returns the key ID and record of the key keyID, following verification method bindings.
+func (k *LocalKMS) resolve(keyID string) (string, *keyRecord, error) {
+	record, err := k.getRecord(keyID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if record.Alias == "" {
+		return keyID, record, nil
+	}
+
+	target, err := k.getRecord(record.Alias)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s is bound to %s: %w", keyID, record.Alias, err)
+	}
+
+	return record.Alias, target, nil
+}
+
+func (k *LocalKMS) privateKey(keyID string, record *keyRecord) ([]byte, error) {
+	privateKey, err := open(k.aead, record.PrivateKey, keyID)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt key %s: %w", keyID, err)
+	}
+
+	return privateKey, nil
+}
+
+func (k *LocalKMS) getRecord(keyID string) (*keyRecord, error) {
+	if keyID == metadataKey {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, keyID)
+	}
+
+	recordBytes, err := k.store.Get(keyID)
+	if errors.Is(err, spi.ErrDataNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrKeyNotFound, keyID)
+	} else if err != nil {
+		return nil, err
+	}
+
+	var record keyRecord
+	if err = json.Unmarshal(recordBytes, &record); err != nil {
+		return nil, fmt.Errorf("invalid key record %s: %w", keyID, err)
+	}
+
+	return &record, nil
+}
+
+// putRecord stores a new record. The caller must hold the lock.
+func (k *LocalKMS) putRecord(keyID string, record *keyRecord) error {
+	if _, err := k.store.Get(keyID); err == nil {
+		return fmt.Errorf("%w: %s", ErrKeyExists, keyID)
+	} else if !errors.Is(err, spi.ErrDataNotFound) {
+		return err
+	}
+
+	recordBytes, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	return k.store.Put(keyID, recordBytes)
+}
+
+// generateKey returns a private key, encoded as the Ed25519 seed, the X25519 scalar or the fixed size ECDSA
+// scalar, and its public key.
+func generateKey(keyType crypto.KeyType) ([]byte, []byte, error) {
+	switch keyType {
+	case crypto.Ed25519:
+		publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return privateKey.Seed(), publicKey, nil
+	case crypto.X25519:
+		return x25519.GenerateKey()
+	case crypto.ECDSAP256, crypto.ECDSASecp256k1, crypto.SM2:
+		curve, err := crypto.Curve(keyType)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var key *ecdsa.PrivateKey
+
+		if keyType == crypto.SM2 {
+			key, err = sm2.GenerateKey(rand.Reader)
+		} else {
+			key, err = ecdsa.GenerateKey(curve, rand.Reader)
+		}
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		signer, err := crypto.NewSigner(key)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return key.D.FillBytes(make([]byte, (curve.Params().BitSize+7)/8)), signer.PublicKeyBytes(), nil
+	default:
+		return nil, nil, fmt.Errorf("%w: %s", ErrUnsupportedKeyType, keyType)
+	}
+}
+
+func ecdsaPrivateKey(keyType crypto.KeyType, d []byte) (*ecdsa.PrivateKey, error) {
+	curve, err := crypto.Curve(keyType)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &ecdsa.PrivateKey{PublicKey: ecdsa.PublicKey{Curve: curve}, D: new(big.Int).SetBytes(d)}
+	key.X, key.Y = curve.ScalarBaseMult(d) //nolint:staticcheck
+
+	return key, nil
+}
+
+// samePublicKey compares public keys, accepting uncompressed encodings of ECDSA keys.
+func samePublicKey(keyType crypto.KeyType, a, b []byte) bool {
+	if bytes.Equal(a, b) {
+		return true
+	}
+
+	if keyType != crypto.ECDSAP256 && keyType != crypto.ECDSASecp256k1 && keyType != crypto.SM2 {
+		return false
+	}
+
+	keyA, err := crypto.ParseECDSAPublicKey(keyType, a)
+	if err != nil {
+		return false
+	}
+
+	keyB, err := crypto.ParseECDSAPublicKey(keyType, b)
+	if err != nil {
+		return false
+	}
+
+	return keyA.X.Cmp(keyB.X) == 0 && keyA.Y.Cmp(keyB.Y) == 0
+}
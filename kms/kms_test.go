@@ -0,0 +1,172 @@
+package kms
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zRich/zFusion/common/crypto"
+	"github.com/zRich/zFusion/common/jsonld"
+	"github.com/zRich/zFusion/did"
+	"github.com/zRich/zFusion/storage/leveldb"
+	"github.com/zRich/zFusion/storage/spi"
+)
+
+const testIterations = 10
+
+func newStore(t *testing.T) spi.Store {
+	t.Helper()
+
+	p := leveldb.NewProvider(filepath.Join(t.TempDir(), "db"))
+	t.Cleanup(func() { require.NoError(t, p.Close()) })
+
+	store, err := p.OpenStore(StoreName)
+	require.NoError(t, err)
+
+	return store
+}
+
+func TestLocalKMS(t *testing.T) {
+	store := newStore(t)
+
+	k, err := New(store, []byte("correct horse"), WithIterations(testIterations))
+	require.NoError(t, err)
+
+	for _, keyType := range []crypto.KeyType{crypto.Ed25519, crypto.ECDSAP256, crypto.ECDSASecp256k1, crypto.SM2} {
+		keyID, publicKey, err := k.Create(keyType)
+		require.NoError(t, err, keyType)
+
+		fingerprint, err := Fingerprint(keyType, publicKey)
+		require.NoError(t, err)
+		require.Equal(t, fingerprint, keyID)
+
+		sig, err := k.Sign(keyID, []byte("message"))
+		require.NoError(t, err, keyType)
+		require.NoError(t, k.Verify(keyID, []byte("message"), sig), keyType)
+		require.NoError(t, crypto.Verify(keyType, publicKey, []byte("message"), sig), keyType)
+		require.ErrorIs(t, k.Verify(keyID, []byte("other message"), sig), crypto.ErrInvalidSignature, keyType)
+	}
+
+	_, _, err = k.Create(crypto.RSA)
+	require.ErrorIs(t, err, ErrUnsupportedKeyType)
+
+	_, err = k.Sign("z6MkUnknown", []byte("message"))
+	require.ErrorIs(t, err, ErrKeyNotFound)
+
+	t.Run("reopen", func(t *testing.T) {
+		keyID, _, err := k.Create(crypto.Ed25519, WithKeyID("did:example:123#key-1"))
+		require.NoError(t, err)
+		require.Equal(t, "did:example:123#key-1", keyID)
+
+		_, _, err = k.Create(crypto.Ed25519, WithKeyID("did:example:123#key-1"))
+		require.ErrorIs(t, err, ErrKeyExists)
+
+		_, err = New(store, []byte("wrong passphrase"))
+		require.ErrorIs(t, err, ErrWrongPassphrase)
+
+		reopened, err := New(store, []byte("correct horse"))
+		require.NoError(t, err)
+
+		sig, err := reopened.Sign(keyID, []byte("message"))
+		require.NoError(t, err)
+		require.NoError(t, k.Verify(keyID, []byte("message"), sig))
+
+		require.NoError(t, reopened.Delete(keyID))
+		require.ErrorIs(t, reopened.Delete(keyID), ErrKeyNotFound)
+
+		_, _, err = reopened.PublicKey(keyID)
+		require.ErrorIs(t, err, ErrKeyNotFound)
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		_, err := New(newStore(t), nil)
+		require.Error(t, err)
+
+		_, err = New(newStore(t), []byte("passphrase"), WithIterations(0))
+		require.Error(t, err)
+	})
+}
+
+func TestLocalKMS_DeriveSharedSecret(t *testing.T) {
+	k, err := New(newStore(t), []byte("passphrase"), WithIterations(testIterations))
+	require.NoError(t, err)
+
+	for _, keyType := range []crypto.KeyType{crypto.X25519, crypto.ECDSAP256, crypto.ECDSASecp256k1} {
+		alice, alicePub, err := k.Create(keyType)
+		require.NoError(t, err, keyType)
+
+		bob, bobPub, err := k.Create(keyType)
+		require.NoError(t, err, keyType)
+
+		aliceSecret, err := k.DeriveSharedSecret(alice, bobPub)
+		require.NoError(t, err, keyType)
+
+		bobSecret, err := k.DeriveSharedSecret(bob, alicePub)
+		require.NoError(t, err, keyType)
+		require.Equal(t, aliceSecret, bobSecret, keyType)
+		require.Len(t, aliceSecret, 32)
+
+		_, err = k.DeriveSharedSecret(alice, []byte{1, 2, 3})
+		require.Error(t, err, keyType)
+	}
+
+	x25519Key, _, err := k.Create(crypto.X25519)
+	require.NoError(t, err)
+
+	_, err = k.Sign(x25519Key, []byte("message"))
+	require.ErrorIs(t, err, ErrUnsupportedKeyType)
+
+	ed25519Key, _, err := k.Create(crypto.Ed25519)
+	require.NoError(t, err)
+
+	_, err = k.DeriveSharedSecret(ed25519Key, make([]byte, 32))
+	require.ErrorIs(t, err, ErrUnsupportedKeyType)
+}
+
+func TestLocalKMS_VerificationMethods(t *testing.T) {
+	k, err := New(newStore(t), []byte("passphrase"), WithIterations(testIterations))
+	require.NoError(t, err)
+
+	keyID, _, err := k.Create(crypto.Ed25519)
+	require.NoError(t, err)
+
+	vm, err := k.VerificationMethod(keyID, "did:example:123#key-1", "did:example:123")
+	require.NoError(t, err)
+	require.Equal(t, did.Multikey, vm.Type)
+
+	// binding is idempotent
+	_, err = k.VerificationMethod(keyID, "did:example:123#key-1", "did:example:123")
+	require.NoError(t, err)
+
+	doc := did.BuildDoc(
+		did.WithVerificationMethod([]did.VerificationMethod{*vm}),
+		did.WithAssertion([]did.Verification{*did.NewReferencedVerification(vm, did.AssertionMethod)}),
+	)
+	doc.ID = "did:example:123"
+	doc.Context = []string{did.ContextV1, jsonld.ContextMultikey}
+
+	signer, err := k.SignerFor(vm)
+	require.NoError(t, err)
+
+	require.NoError(t, doc.AddProof(signer, vm.ID))
+	require.NoError(t, doc.VerifyProof())
+
+	sig, err := k.Sign(vm.ID, []byte("message"))
+	require.NoError(t, err)
+	require.NoError(t, vm.Verify([]byte("message"), sig))
+
+	otherID, otherKey, err := k.Create(crypto.Ed25519)
+	require.NoError(t, err)
+	require.ErrorIs(t, k.Bind(otherID, vm.ID), ErrKeyExists)
+
+	forged := did.NewVerificationMethodFromBytes(vm.ID, "Ed25519VerificationKey2018", doc.ID, otherKey)
+
+	_, err = k.SignerFor(forged)
+	require.ErrorIs(t, err, ErrKeyMismatch)
+
+	// deleting the key breaks its bindings
+	require.NoError(t, k.Delete(keyID))
+
+	_, err = k.Signer(vm.ID)
+	require.ErrorIs(t, err, ErrKeyNotFound)
+}
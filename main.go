@@ -2,68 +2,86 @@ package main
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
 	"flag"
 	"fmt"
 	"log"
 	"net"
 	"time"
 
+	"github.com/zRich/zFusion/common/crypto"
+	"github.com/zRich/zFusion/did/vdr"
+	"github.com/zRich/zFusion/did/vdr/key"
 	pb "github.com/zRich/zFusion/peer"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/proto"
 )
 
-type server struct {
-	pb.UnimplementedEndorserServer
-}
-
 var (
 	port = flag.Int("port", 3600, "The server port")
 	addr = flag.String("addr", "localhost:3600", "the address to connect to")
 )
 
 func main() {
-
-	// addr := "127.0.0.1:3600"
-	// cfg := &network.ServerConfig{}
-	// srv, err := network.NewGRPCServer(addr, *cfg)
-	// pb.RegisterEndorserServer(srv.Server(), &server{})
-	// if err != nil {
-	// 	go srv.Start()
-	// }
-
 	flag.Parse()
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", *port))
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
-	s := grpc.NewServer()
-	pb.RegisterEndorserServer(s, &server{})
-	log.Printf("server listening at %v", lis.Addr())
-	if err := s.Serve(lis); err != nil {
-		log.Fatalf("failed to serve: %v", err)
+
+	// request creators are did:key DIDs, resolved without a ledger.
+	srv, err := pb.NewGRPCServerFromListener(lis, pb.ServerConfig{DocumentResolver: vdr.New(vdr.WithVDR(key.New()))})
+	if err != nil {
+		log.Fatalf("failed to create server: %v", err)
 	}
+	defer srv.Stop()
 
-	go RunClient()
+	log.Printf("server listening at %v", lis.Addr())
+	go func() {
+		if err := srv.Start(); err != nil {
+			log.Fatalf("failed to serve: %v", err)
+		}
+	}()
+
+	RunClient()
 }
 
 func RunClient() {
+	_, privKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		log.Fatalf("failed to generate key: %v", err)
+	}
+	signer, err := crypto.NewSigner(privKey)
+	if err != nil {
+		log.Fatalf("failed to create signer: %v", err)
+	}
+	_, creator := key.CreateDIDKey(signer.PublicKeyBytes())
+
 	conn, err := grpc.Dial(*addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	if err != nil {
 		log.Fatalf("did not connect: %v", err)
 	}
 	defer conn.Close()
-	c := pb.NewEndorserClient(conn)
+	c := pb.NewProcessClient(conn)
+
+	signed, err := pb.SignRequest(&pb.Request{Header: []byte(creator), Payload: []byte("hello world")}, signer)
+	if err != nil {
+		log.Fatalf("could not sign: %v", err)
+	}
 
 	// Contact the server and print out its response.
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
-	r, err := c.ProcessProposal(ctx,
-		&pb.SignedProposal{
-			ProposalBytes: []byte("hello world"),
-		})
+	r, err := c.ProcessRequest(ctx, signed)
 	if err != nil {
 		log.Fatalf("could not greet: %v", err)
 	}
-	log.Printf("Greeting: %s", r.Endorsement.GetSignature())
+
+	response := &pb.Response{}
+	if err := proto.Unmarshal(r.GetRequestBytes(), response); err != nil {
+		log.Fatalf("invalid response: %v", err)
+	}
+	log.Printf("Greeting: %s", response.GetPayload())
 }
@@ -7,11 +7,14 @@ import (
 	"github.com/hyperledger/fabric/common/flogging"
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
+	"github.com/zRich/zFusion/did"
 )
 
 var peerLogger = flogging.MustGetLogger("peer")
 
 type ServerConfig struct {
+	// DocumentResolver resolves the DID documents of the creators of requests.
+	DocumentResolver did.DocumentResolver
 }
 
 type ClientConfig struct {
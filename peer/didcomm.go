@@ -0,0 +1,117 @@
+package peer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+
+	"github.com/zRich/zFusion/didcomm"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DIDCommScheme is the URI scheme of the DIDComm endpoints of peers, grpc://host:port.
+const DIDCommScheme = "grpc"
+
+// didcommServer delivers the DIDComm messages received over gRPC to a handler.
+type didcommServer struct {
+	UnimplementedDIDCommServer
+	handler didcomm.Handler
+}
+
+// NewDIDCommServer creates a DIDComm server delivering the received messages to handler, see
+// didcomm.Messenger.Handler.
+func NewDIDCommServer(handler didcomm.Handler) DIDCommServer {
+	return &didcommServer{handler: handler}
+}
+
+// Deliver passes the packed message to the handler. Messages the handler rejects as invalid fail with
+// codes.InvalidArgument.
+func (s *didcommServer) Deliver(ctx context.Context, envelope *DIDCommEnvelope) (*DIDCommReceipt, error) {
+	if err := s.handler(ctx, envelope.GetMessage()); err != nil {
+		code := codes.Internal
+		if errors.Is(err, didcomm.ErrInvalidMessage) || errors.Is(err, didcomm.ErrMessageExpired) ||
+			errors.Is(err, didcomm.ErrNoKey) {
+			code = codes.InvalidArgument
+		}
+
+		return nil, status.Error(code, err.Error())
+	}
+
+	return &DIDCommReceipt{}, nil
+}
+
+// RegisterDIDComm registers a DIDComm server delivering messages to handler.
+func (s *GRPCServer) RegisterDIDComm(handler didcomm.Handler) {
+	RegisterDIDCommServer(s.server, NewDIDCommServer(handler))
+}
+
+// DIDCommTransport is a didcomm.Transport sending messages to the DIDComm servers of peers, at endpoints
+// grpc://host:port. Connections are kept open until Close.
+type DIDCommTransport struct {
+	dialOpts []grpc.DialOption
+	lock     sync.Mutex
+	conns    map[string]*grpc.ClientConn
+}
+
+// NewDIDCommTransport creates a transport dialing peers with opts, which must set the transport credentials.
+func NewDIDCommTransport(opts ...grpc.DialOption) *DIDCommTransport {
+	return &DIDCommTransport{dialOpts: opts, conns: make(map[string]*grpc.ClientConn)}
+}
+
+// Send delivers the packed message to the peer of the endpoint uri.
+func (t *DIDCommTransport) Send(ctx context.Context, uri string, packed []byte) error {
+	endpoint, err := url.Parse(uri)
+	if err != nil || endpoint.Scheme != DIDCommScheme || endpoint.Host == "" {
+		return fmt.Errorf("%w: %s is not a %s://host:port endpoint", didcomm.ErrUnsupportedEndpoint, uri,
+			DIDCommScheme)
+	}
+
+	conn, err := t.conn(endpoint.Host)
+	if err != nil {
+		return err
+	}
+
+	_, err = NewDIDCommClient(conn).Deliver(ctx, &DIDCommEnvelope{Message: packed})
+
+	return err
+}
+
+// Close closes the connections of the transport.
+func (t *DIDCommTransport) Close() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	var firstErr error
+
+	for target, conn := range t.conns {
+		if err := conn.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+
+		delete(t.conns, target)
+	}
+
+	return firstErr
+}
+
+func (t *DIDCommTransport) conn(target string) (*grpc.ClientConn, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if conn, ok := t.conns[target]; ok {
+		return conn, nil
+	}
+
+	conn, err := grpc.Dial(target, t.dialOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("dial %s: %w", target, err)
+	}
+
+	t.conns[target] = conn
+
+	return conn, nil
+}
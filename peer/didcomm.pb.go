@@ -0,0 +1,205 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.28.0
+// 	protoc        v3.20.1
+// source: protos/peer/didcomm.proto
+
+package peer
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// DIDCommEnvelope carries a packed DIDComm message to the peer of its service endpoint.
+type DIDCommEnvelope struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The packed message, a plaintext, signed or encrypted envelope
+	Message []byte `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+}
+
+func (x *DIDCommEnvelope) Reset() {
+	*x = DIDCommEnvelope{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_peer_didcomm_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DIDCommEnvelope) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DIDCommEnvelope) ProtoMessage() {}
+
+func (x *DIDCommEnvelope) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_peer_didcomm_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DIDCommEnvelope.ProtoReflect.Descriptor instead.
+func (*DIDCommEnvelope) Descriptor() ([]byte, []int) {
+	return file_protos_peer_didcomm_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *DIDCommEnvelope) GetMessage() []byte {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+// DIDCommReceipt acknowledges the delivery of a DIDComm message.
+type DIDCommReceipt struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *DIDCommReceipt) Reset() {
+	*x = DIDCommReceipt{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_protos_peer_didcomm_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DIDCommReceipt) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DIDCommReceipt) ProtoMessage() {}
+
+func (x *DIDCommReceipt) ProtoReflect() protoreflect.Message {
+	mi := &file_protos_peer_didcomm_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DIDCommReceipt.ProtoReflect.Descriptor instead.
+func (*DIDCommReceipt) Descriptor() ([]byte, []int) {
+	return file_protos_peer_didcomm_proto_rawDescGZIP(), []int{1}
+}
+
+var File_protos_peer_didcomm_proto protoreflect.FileDescriptor
+
+var file_protos_peer_didcomm_proto_rawDesc = []byte{
+	0x0a, 0x19, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x73, 0x2f, 0x70, 0x65, 0x65, 0x72, 0x2f, 0x64, 0x69,
+	0x64, 0x63, 0x6f, 0x6d, 0x6d, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x04, 0x70, 0x65, 0x65,
+	0x72, 0x22, 0x2b, 0x0a, 0x0f, 0x44, 0x49, 0x44, 0x43, 0x6f, 0x6d, 0x6d, 0x45, 0x6e, 0x76, 0x65,
+	0x6c, 0x6f, 0x70, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x10,
+	0x0a, 0x0e, 0x44, 0x49, 0x44, 0x43, 0x6f, 0x6d, 0x6d, 0x52, 0x65, 0x63, 0x65, 0x69, 0x70, 0x74,
+	0x32, 0x41, 0x0a, 0x07, 0x44, 0x49, 0x44, 0x43, 0x6f, 0x6d, 0x6d, 0x12, 0x36, 0x0a, 0x07, 0x44,
+	0x65, 0x6c, 0x69, 0x76, 0x65, 0x72, 0x12, 0x15, 0x2e, 0x70, 0x65, 0x65, 0x72, 0x2e, 0x44, 0x49,
+	0x44, 0x43, 0x6f, 0x6d, 0x6d, 0x45, 0x6e, 0x76, 0x65, 0x6c, 0x6f, 0x70, 0x65, 0x1a, 0x14, 0x2e,
+	0x70, 0x65, 0x65, 0x72, 0x2e, 0x44, 0x49, 0x44, 0x43, 0x6f, 0x6d, 0x6d, 0x52, 0x65, 0x63, 0x65,
+	0x69, 0x70, 0x74, 0x42, 0x1f, 0x5a, 0x1d, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f,
+	0x6d, 0x2f, 0x7a, 0x52, 0x69, 0x63, 0x68, 0x2f, 0x7a, 0x46, 0x75, 0x73, 0x69, 0x6f, 0x6e, 0x2f,
+	0x70, 0x65, 0x65, 0x72, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_protos_peer_didcomm_proto_rawDescOnce sync.Once
+	file_protos_peer_didcomm_proto_rawDescData = file_protos_peer_didcomm_proto_rawDesc
+)
+
+func file_protos_peer_didcomm_proto_rawDescGZIP() []byte {
+	file_protos_peer_didcomm_proto_rawDescOnce.Do(func() {
+		file_protos_peer_didcomm_proto_rawDescData = protoimpl.X.CompressGZIP(file_protos_peer_didcomm_proto_rawDescData)
+	})
+	return file_protos_peer_didcomm_proto_rawDescData
+}
+
+var file_protos_peer_didcomm_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_protos_peer_didcomm_proto_goTypes = []interface{}{
+	(*DIDCommEnvelope)(nil), // 0: peer.DIDCommEnvelope
+	(*DIDCommReceipt)(nil),  // 1: peer.DIDCommReceipt
+}
+var file_protos_peer_didcomm_proto_depIdxs = []int32{
+	0, // 0: peer.DIDComm.Deliver:input_type -> peer.DIDCommEnvelope
+	1, // 1: peer.DIDComm.Deliver:output_type -> peer.DIDCommReceipt
+	1, // [1:2] is the sub-list for method output_type
+	0, // [0:1] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_protos_peer_didcomm_proto_init() }
+func file_protos_peer_didcomm_proto_init() {
+	if File_protos_peer_didcomm_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_protos_peer_didcomm_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DIDCommEnvelope); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_protos_peer_didcomm_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DIDCommReceipt); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_protos_peer_didcomm_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_protos_peer_didcomm_proto_goTypes,
+		DependencyIndexes: file_protos_peer_didcomm_proto_depIdxs,
+		MessageInfos:      file_protos_peer_didcomm_proto_msgTypes,
+	}.Build()
+	File_protos_peer_didcomm_proto = out.File
+	file_protos_peer_didcomm_proto_rawDesc = nil
+	file_protos_peer_didcomm_proto_goTypes = nil
+	file_protos_peer_didcomm_proto_depIdxs = nil
+}
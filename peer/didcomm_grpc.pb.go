@@ -0,0 +1,107 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.20.1
+// source: protos/peer/didcomm.proto
+
+package peer
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// DIDCommClient is the client API for DIDComm service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type DIDCommClient interface {
+	// Deliver delivers a packed message to the peer.
+	Deliver(ctx context.Context, in *DIDCommEnvelope, opts ...grpc.CallOption) (*DIDCommReceipt, error)
+}
+
+type dIDCommClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDIDCommClient(cc grpc.ClientConnInterface) DIDCommClient {
+	return &dIDCommClient{cc}
+}
+
+func (c *dIDCommClient) Deliver(ctx context.Context, in *DIDCommEnvelope, opts ...grpc.CallOption) (*DIDCommReceipt, error) {
+	out := new(DIDCommReceipt)
+	err := c.cc.Invoke(ctx, "/peer.DIDComm/Deliver", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DIDCommServer is the server API for DIDComm service.
+// All implementations must embed UnimplementedDIDCommServer
+// for forward compatibility
+type DIDCommServer interface {
+	// Deliver delivers a packed message to the peer.
+	Deliver(context.Context, *DIDCommEnvelope) (*DIDCommReceipt, error)
+	mustEmbedUnimplementedDIDCommServer()
+}
+
+// UnimplementedDIDCommServer must be embedded to have forward compatible implementations.
+type UnimplementedDIDCommServer struct {
+}
+
+func (UnimplementedDIDCommServer) Deliver(context.Context, *DIDCommEnvelope) (*DIDCommReceipt, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Deliver not implemented")
+}
+func (UnimplementedDIDCommServer) mustEmbedUnimplementedDIDCommServer() {}
+
+// UnsafeDIDCommServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DIDCommServer will
+// result in compilation errors.
+type UnsafeDIDCommServer interface {
+	mustEmbedUnimplementedDIDCommServer()
+}
+
+func RegisterDIDCommServer(s grpc.ServiceRegistrar, srv DIDCommServer) {
+	s.RegisterService(&DIDComm_ServiceDesc, srv)
+}
+
+func _DIDComm_Deliver_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DIDCommEnvelope)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DIDCommServer).Deliver(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/peer.DIDComm/Deliver",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DIDCommServer).Deliver(ctx, req.(*DIDCommEnvelope))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// DIDComm_ServiceDesc is the grpc.ServiceDesc for DIDComm service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var DIDComm_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "peer.DIDComm",
+	HandlerType: (*DIDCommServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Deliver",
+			Handler:    _DIDComm_Deliver_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "protos/peer/didcomm.proto",
+}
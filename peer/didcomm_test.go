@@ -0,0 +1,132 @@
+package peer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zRich/zFusion/common/crypto"
+	"github.com/zRich/zFusion/common/model"
+	"github.com/zRich/zFusion/did"
+	"github.com/zRich/zFusion/didcomm"
+	"github.com/zRich/zFusion/kms"
+	"github.com/zRich/zFusion/storage/leveldb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+type mockResolver map[string]*did.Document
+
+func (r mockResolver) ResolveDocument(id string) (*did.Document, error) {
+	doc, ok := r[id]
+	if !ok {
+		return nil, fmt.Errorf("DID %s not found", id)
+	}
+
+	return doc, nil
+}
+
+// newPacker creates a DID with an Ed25519 authentication key #auth and an X25519 key agreement key #ka, and
+// returns a packer holding its keys.
+func newPacker(t *testing.T, resolver mockResolver, id string, services ...did.Service) *didcomm.Packer {
+	t.Helper()
+
+	p := leveldb.NewProvider(filepath.Join(t.TempDir(), "db"))
+	t.Cleanup(func() { require.NoError(t, p.Close()) })
+
+	store, err := p.OpenStore(kms.StoreName)
+	require.NoError(t, err)
+
+	keys, err := kms.New(store, []byte("passphrase"), kms.WithIterations(10))
+	require.NoError(t, err)
+
+	var vms []did.VerificationMethod
+
+	for _, key := range []struct {
+		fragment string
+		keyType  crypto.KeyType
+	}{{"#auth", crypto.Ed25519}, {"#ka", crypto.X25519}} {
+		keyID, _, err := keys.Create(key.keyType)
+		require.NoError(t, err)
+
+		vm, err := keys.VerificationMethod(keyID, id+key.fragment, id)
+		require.NoError(t, err)
+
+		vms = append(vms, *vm)
+	}
+
+	doc := did.BuildDoc(
+		did.WithVerificationMethod(vms),
+		did.WithAuthentication([]did.Verification{{VerificationMethod: vms[0], Relationship: did.Authentication}}),
+		did.WithKeyAgreement([]did.Verification{{VerificationMethod: vms[1], Relationship: did.KeyAgreement}}),
+		did.WithService(services),
+	)
+	doc.ID = id
+	resolver[id] = doc
+
+	return didcomm.NewPacker(keys, resolver)
+}
+
+func TestDIDCommTransport(t *testing.T) {
+	const (
+		aliceDID = "did:example:alice"
+		bobDID   = "did:example:bob"
+	)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server, err := NewGRPCServerFromListener(lis, ServerConfig{})
+	require.NoError(t, err)
+
+	resolver := mockResolver{}
+	alice := newPacker(t, resolver, aliceDID)
+	bob := newPacker(t, resolver, bobDID, did.Service{
+		ID:   bobDID + "#didcomm",
+		Type: didcomm.ServiceType,
+		ServiceEndpoint: model.NewDIDCommV2Endpoint([]model.DIDCommV2Endpoint{
+			{URI: DIDCommScheme + "://" + lis.Addr().String(), Accept: []string{didcomm.ProfileV2}},
+		}),
+	})
+
+	received := make(chan *didcomm.Message, 1)
+
+	server.RegisterDIDComm(didcomm.NewMessenger(bob, resolver, nil).Handler(
+		func(_ context.Context, msg *didcomm.Message, md *didcomm.Metadata) error {
+			if md.SenderKeyID != aliceDID+"#ka" || md.RecipientKeyID != bobDID+"#ka" {
+				return fmt.Errorf("unexpected keys %s and %s", md.SenderKeyID, md.RecipientKeyID)
+			}
+
+			received <- msg
+
+			return nil
+		}))
+
+	go server.Start() //nolint:errcheck
+	t.Cleanup(server.Stop)
+
+	transport := NewDIDCommTransport(grpc.WithTransportCredentials(insecure.NewCredentials()))
+	t.Cleanup(func() { require.NoError(t, transport.Close()) })
+
+	msg := didcomm.NewMessage("https://example.com/protocols/1.0/ping", map[string]interface{}{"hello": "bob"})
+	msg.From, msg.To = aliceDID, []string{bobDID}
+
+	messenger := didcomm.NewMessenger(alice, resolver, transport)
+	require.NoError(t, messenger.Send(context.Background(), msg, didcomm.WithAuthcrypt(aliceDID+"#ka")))
+	require.Equal(t, msg, <-received)
+
+	t.Run("invalid message", func(t *testing.T) {
+		err := transport.Send(context.Background(), DIDCommScheme+"://"+lis.Addr().String(), []byte("invalid"))
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("unsupported endpoint", func(t *testing.T) {
+		err := transport.Send(context.Background(), "https://example.com/didcomm", []byte("invalid"))
+		require.ErrorIs(t, err, didcomm.ErrUnsupportedEndpoint)
+	})
+}
@@ -9,10 +9,13 @@ import (
 	"sync/atomic"
 	"time"
 
-	"github.com/zRich/zFusion/common/flogging"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/zRich/zFusion/did"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/health"
-	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
 type GRPCServer struct {
@@ -24,27 +27,36 @@ type GRPCServer struct {
 	healthServer      *health.Server
 }
 
-var logger = flogging.GetLogger("PeerServer")
+var logger = flogging.MustGetLogger("PeerServer")
 
 type PeerServer struct {
-	UnimplementedEndorserServer
+	UnimplementedProcessServer
+	resolver did.DocumentResolver
 }
 
-func (s *PeerServer) ProcessProposal(ctx context.Context, proposal *SignedProposal) (*ProposalResponse, error) {
-	logger.Infof("ProcessProposal")
-	return &ProposalResponse{
-		Version:   0,
-		Timestamp: &timestamppb.Timestamp{},
-		Response: &Response{
-			Status:  500,
-			Message: fmt.Sprintf("Say hello by %s", proposal.GetProposalBytes()),
-		},
-		Payload: []byte(fmt.Sprintf("Your payload: %s", proposal.GetProposalBytes())),
-		Endorsement: &Endorsement{
-			Endorser:  []byte(fmt.Sprintf("Your payload: %s", proposal.GetProposalBytes())),
-			Signature: []byte("Signed by Rich"),
-		},
-	}, nil
+// ProcessRequest processes requests signed by their creator, see VerifyRequestCreator. Requests which do not verify
+// fail with codes.Unauthenticated.
+func (s *PeerServer) ProcessRequest(ctx context.Context, signed *SignedRequest) (*SignedResponse, error) {
+	logger.Infof("ProcessRequest")
+
+	if s.resolver == nil {
+		return nil, status.Error(codes.Unauthenticated, "no document resolver to resolve request creators")
+	}
+
+	request, err := VerifyRequestCreator(signed, s.resolver)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	responseBytes, err := proto.Marshal(&Response{
+		Header:  request.GetHeader(),
+		Payload: []byte(fmt.Sprintf("Your payload: %s", request.GetPayload())),
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &SignedResponse{RequestBytes: responseBytes}, nil
 }
 
 func NewGRPCServer(address string, serverConfig ServerConfig) (*GRPCServer, error) {
@@ -75,7 +87,7 @@ func NewGRPCServerFromListener(listener net.Listener, serverConfig ServerConfig)
 	serverOpts = append(serverOpts, grpc.ConnectionTimeout(10*time.Second))
 	grpcServer.server = grpc.NewServer(serverOpts...)
 
-	RegisterEndorserServer(grpcServer.server, &PeerServer{})
+	RegisterProcessServer(grpcServer.server, &PeerServer{resolver: serverConfig.DocumentResolver})
 
 	return grpcServer, nil
 }
@@ -0,0 +1,77 @@
+package peer
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zRich/zFusion/common/crypto"
+	"github.com/zRich/zFusion/did"
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrInvalidRequestSignature is returned when the signature of a SignedRequest does not verify.
+var ErrInvalidRequestSignature = errors.New("invalid request signature")
+
+// SignRequest marshals request and signs the bytes with signer, for instance an SM2 signer of the GM crypto
+// provider.
+func SignRequest(request *Request, signer crypto.Signer) (*SignedRequest, error) {
+	requestBytes, err := proto.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	sig, err := signer.Sign(requestBytes)
+	if err != nil {
+		return nil, fmt.Errorf("sign request: %w", err)
+	}
+
+	return &SignedRequest{RequestBytes: requestBytes, Signature: sig}, nil
+}
+
+// VerifyRequest verifies the signature of signed with the public key of its creator, and returns the request.
+func VerifyRequest(signed *SignedRequest, keyType crypto.KeyType, pubKey []byte) (*Request, error) {
+	if err := crypto.Verify(keyType, pubKey, signed.GetRequestBytes(), signed.GetSignature()); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRequestSignature, err)
+	}
+
+	request := &Request{}
+	if err := proto.Unmarshal(signed.GetRequestBytes(), request); err != nil {
+		return nil, fmt.Errorf("unmarshal request: %w", err)
+	}
+
+	return request, nil
+}
+
+// VerifyRequestCreator verifies the signature of signed with the key of its creator, and returns the request. The
+// header of the request is the DID URL of the creator key, which must be an authentication key of the DID document
+// resolved with resolver.
+func VerifyRequestCreator(signed *SignedRequest, resolver did.DocumentResolver) (*Request, error) {
+	request := &Request{}
+	if err := proto.Unmarshal(signed.GetRequestBytes(), request); err != nil {
+		return nil, fmt.Errorf("unmarshal request: %w", err)
+	}
+
+	creator := string(request.GetHeader())
+
+	didURL, err := did.ParseDIDURL(creator)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid creator %q: %v", ErrInvalidRequestSignature, creator, err)
+	}
+
+	doc, err := resolver.ResolveDocument(didURL.DID.String())
+	if err != nil {
+		return nil, fmt.Errorf("resolve creator %s: %w", creator, err)
+	}
+
+	vm, err := doc.AuthorizedVerificationMethod(creator, did.Authentication)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRequestSignature, err)
+	}
+
+	keyType, err := vm.KeyType()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRequestSignature, err)
+	}
+
+	return VerifyRequest(signed, keyType, vm.Value)
+}
@@ -0,0 +1,133 @@
+package peer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zRich/zFusion/common/crypto"
+	"github.com/zRich/zFusion/did"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestSignRequest(t *testing.T) {
+	for _, name := range []string{crypto.ProviderGM, crypto.ProviderStandard} {
+		t.Run(name, func(t *testing.T) {
+			provider, err := crypto.NewProvider(name)
+			require.NoError(t, err)
+
+			signer, err := provider.GenerateSigner()
+			require.NoError(t, err)
+
+			request := &Request{Header: []byte("header"), Payload: []byte("payload")}
+
+			signed, err := SignRequest(request, signer)
+			require.NoError(t, err)
+
+			verified, err := VerifyRequest(signed, provider.KeyType(), signer.PublicKeyBytes())
+			require.NoError(t, err)
+			require.True(t, proto.Equal(request, verified))
+
+			tampered, err := proto.Marshal(&Request{Header: []byte("header"), Payload: []byte("tampered")})
+			require.NoError(t, err)
+
+			_, err = VerifyRequest(&SignedRequest{RequestBytes: tampered, Signature: signed.GetSignature()},
+				provider.KeyType(), signer.PublicKeyBytes())
+			require.ErrorIs(t, err, ErrInvalidRequestSignature)
+
+			other, err := provider.GenerateSigner()
+			require.NoError(t, err)
+
+			_, err = VerifyRequest(signed, provider.KeyType(), other.PublicKeyBytes())
+			require.ErrorIs(t, err, ErrInvalidRequestSignature)
+		})
+	}
+}
+
+// newCreator creates a DID whose authentication key #key-1 is the key of signer, and returns its key ID.
+func newCreator(t *testing.T, resolver mockResolver, id string, signer crypto.Signer) string {
+	t.Helper()
+
+	vmType := did.Sm2VerificationKey
+	if signer.KeyType() == crypto.ECDSAP256 {
+		vmType = "EcdsaSecp256r1VerificationKey2019"
+	}
+
+	vm := did.NewVerificationMethodFromBytes(id+"#key-1", vmType, id, signer.PublicKeyBytes())
+
+	doc := did.BuildDoc(
+		did.WithVerificationMethod([]did.VerificationMethod{*vm}),
+		did.WithAuthentication([]did.Verification{*did.NewReferencedVerification(vm, did.Authentication)}),
+	)
+	doc.ID = id
+	resolver[id] = doc
+
+	return vm.ID
+}
+
+func TestProcessRequest(t *testing.T) {
+	for _, name := range []string{crypto.ProviderGM, crypto.ProviderStandard} {
+		t.Run(name, func(t *testing.T) {
+			provider, err := crypto.NewProvider(name)
+			require.NoError(t, err)
+
+			signer, err := provider.GenerateSigner()
+			require.NoError(t, err)
+
+			resolver := mockResolver{}
+			creator := newCreator(t, resolver, "did:example:creator", signer)
+			server := &PeerServer{resolver: resolver}
+
+			signed, err := SignRequest(&Request{Header: []byte(creator), Payload: []byte("payload")}, signer)
+			require.NoError(t, err)
+
+			signedResponse, err := server.ProcessRequest(context.Background(), signed)
+			require.NoError(t, err)
+
+			response := &Response{}
+			require.NoError(t, proto.Unmarshal(signedResponse.GetRequestBytes(), response))
+			require.Equal(t, "Your payload: payload", string(response.GetPayload()))
+
+			// a request signed by another key on behalf of the creator.
+			other, err := provider.GenerateSigner()
+			require.NoError(t, err)
+
+			forged, err := SignRequest(&Request{Header: []byte(creator), Payload: []byte("payload")}, other)
+			require.NoError(t, err)
+
+			unsigned := &SignedRequest{RequestBytes: signed.GetRequestBytes()}
+
+			for _, request := range []*SignedRequest{forged, unsigned} {
+				_, err = server.ProcessRequest(context.Background(), request)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			}
+
+			_, err = VerifyRequestCreator(forged, resolver)
+			require.ErrorIs(t, err, ErrInvalidRequestSignature)
+
+			_, err = (&PeerServer{}).ProcessRequest(context.Background(), signed)
+			require.Equal(t, codes.Unauthenticated, status.Code(err))
+		})
+	}
+
+	t.Run("unknown creator", func(t *testing.T) {
+		provider, err := crypto.NewProvider(crypto.ProviderStandard)
+		require.NoError(t, err)
+
+		signer, err := provider.GenerateSigner()
+		require.NoError(t, err)
+
+		resolver := mockResolver{}
+		creator := newCreator(t, resolver, "did:example:creator", signer)
+
+		for _, header := range []string{"did:example:unknown#key-1", creator + "0", "not a DID"} {
+			signed, err := SignRequest(&Request{Header: []byte(header)}, signer)
+			require.NoError(t, err)
+
+			_, err = VerifyRequestCreator(signed, resolver)
+			require.Error(t, err, header)
+		}
+	})
+}
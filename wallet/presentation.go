@@ -0,0 +1,60 @@
+package wallet
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/zRich/zFusion/did/vc"
+)
+
+// PresentationRequest is a request of a verifier for the credentials of a holder.
+type PresentationRequest struct {
+	// Queries select the requested credentials. Each query must select at least one credential.
+	Queries []Query
+	// Holder is the DID presenting the credentials.
+	Holder string
+	// Time is the time at which the presented credentials must be valid, now by default. Queries setting ValidAt
+	// keep it.
+	Time time.Time
+}
+
+// Present assembles an unsigned presentation by the request holder of the credentials selected by the queries of
+// req, without duplicates. Credentials which are not valid at the request time are not presented. It fails with
+// ErrNoMatch if a query selects no credential. The presentation is signed by the holder, see vc.SignPresentation
+// and vc.SignPresentationJWT.
+func (w *Wallet) Present(req *PresentationRequest) (*vc.Presentation, error) {
+	at := req.Time
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	var (
+		credentials [][]byte
+		presented   = map[string]bool{}
+	)
+
+	for i := range req.Queries {
+		q := req.Queries[i]
+		if q.ValidAt.IsZero() {
+			q.ValidAt = at
+		}
+
+		records, err := w.Credentials(&q)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(records) == 0 {
+			return nil, fmt.Errorf("%w: query %d", ErrNoMatch, i)
+		}
+
+		for _, record := range records {
+			if !presented[record.ID] {
+				presented[record.ID] = true
+				credentials = append(credentials, record.Data)
+			}
+		}
+	}
+
+	return vc.NewPresentation(req.Holder, credentials...)
+}
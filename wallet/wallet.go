@@ -0,0 +1,445 @@
+// Package wallet implements a holder wallet persisted in a storage/spi.Store: the credentials, presentations and
+// DID documents of a holder, such as the ownership and license credentials issued by peers to the individuals an
+// edge node acts for. Credentials are indexed by store tags on their types, issuer, subjects and validity period,
+// and are selected by queries to assemble presentations in answer to presentation requests.
+package wallet
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zRich/zFusion/did"
+	"github.com/zRich/zFusion/did/vc"
+	"github.com/zRich/zFusion/storage/spi"
+)
+
+// StoreName is the recommended name of the store holding the wallet.
+const StoreName = "wallet"
+
+// Content types of the wallet.
+const (
+	ContentCredential   = "credential"
+	ContentPresentation = "presentation"
+	ContentDID          = "did"
+)
+
+// Tags of the wallet items. Values which may hold ':', forbidden by some stores, are base64url encoded.
+const (
+	tagContent = "content"
+	tagType    = "type"
+	tagIssuer  = "issuer"
+	tagSubject = "subject"
+	tagHolder  = "holder"
+	tagIssued  = "issued"
+	tagExpires = "expires"
+)
+
+var (
+	// ErrNotFound is returned for items which are not in the wallet.
+	ErrNotFound = errors.New("not found in wallet")
+	// ErrNoMatch is returned when no credential matches a query of a presentation request.
+	ErrNoMatch = errors.New("no credential matches the query")
+)
+
+// Record is an item of the wallet, with its data as added to the wallet.
+type Record struct {
+	ID   string
+	Data []byte
+}
+
+// Query selects the credentials meeting all of its criteria. The zero query selects all the credentials.
+type Query struct {
+	// Types are types each selected credential has.
+	Types []string
+	// Issuers are DIDs, one of which issued each selected credential.
+	Issuers []string
+	// Subject is the ID of a subject of each selected credential.
+	Subject string
+	// ExpiresBefore selects the credentials expiring before it, if set.
+	ExpiresBefore time.Time
+	// ValidAt selects the credentials which are issued and not expired at it, if set.
+	ValidAt time.Time
+}
+
+// Wallet holds credentials, presentations and DID documents in a storage/spi.Store.
+type Wallet struct {
+	store spi.Store
+	lock  sync.Mutex
+}
+
+// New returns a wallet persisted in store.
+func New(store spi.Store) *Wallet {
+	return &Wallet{store: store}
+}
+
+// AddCredential adds a credential, given as JSON or as a JWT, and returns its ID: the credential ID, or the
+// SHA-256 digest of data for credentials without ID. A credential with the same ID is replaced. Credentials are
+// checked to conform to the data model, their proofs are not verified.
+func (w *Wallet) AddCredential(data []byte) (string, error) {
+	data = bytes.TrimSpace(data)
+
+	var (
+		cred *vc.Credential
+		err  error
+	)
+
+	if len(data) > 0 && data[0] != '{' {
+		cred, err = vc.ParseCredentialJWT(string(data))
+	} else {
+		cred, err = vc.ParseCredential(data)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	id := cred.ID
+	if id == "" {
+		id = digest(data)
+	}
+
+	tags := []spi.Tag{
+		{Name: tagContent, Value: ContentCredential},
+		{Name: tagIssuer, Value: encodeTag(cred.Issuer.ID)},
+	}
+
+	for _, t := range cred.Types {
+		tags = append(tags, spi.Tag{Name: tagType, Value: encodeTag(t)})
+	}
+
+	for _, s := range cred.Subjects {
+		if s.ID != "" {
+			tags = append(tags, spi.Tag{Name: tagSubject, Value: encodeTag(s.ID)})
+		}
+	}
+
+	if cred.Issued != nil {
+		tags = append(tags, spi.Tag{Name: tagIssued, Value: strconv.FormatInt(cred.Issued.Unix(), 10)})
+	}
+
+	if cred.Expired != nil {
+		tags = append(tags, spi.Tag{Name: tagExpires, Value: strconv.FormatInt(cred.Expired.Unix(), 10)})
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if err := w.store.Put(key(ContentCredential, id), data, tags...); err != nil {
+		return "", fmt.Errorf("put credential %s: %w", id, err)
+	}
+
+	return id, nil
+}
+
+// Credential returns the data of the credential id.
+func (w *Wallet) Credential(id string) ([]byte, error) {
+	return w.get(ContentCredential, id)
+}
+
+// RemoveCredential removes the credential id.
+func (w *Wallet) RemoveCredential(id string) error {
+	return w.remove(ContentCredential, id)
+}
+
+// Credentials returns the credentials selected by q, by ID.
+func (w *Wallet) Credentials(q *Query) ([]*Record, error) {
+	// the store selects the credentials by the most selective tag of the query, the others are matched here.
+	expression := tagContent + ":" + ContentCredential
+
+	switch {
+	case q.Subject != "":
+		expression = tagSubject + ":" + encodeTag(q.Subject)
+	case len(q.Issuers) == 1:
+		expression = tagIssuer + ":" + encodeTag(q.Issuers[0])
+	case len(q.Types) > 0:
+		expression = tagType + ":" + encodeTag(q.Types[len(q.Types)-1])
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	return w.query(expression, q.matches)
+}
+
+// AddPresentation adds a presentation, given as JSON, and returns its ID: the presentation ID, or the SHA-256
+// digest of data for presentations without ID. A presentation with the same ID is replaced.
+func (w *Wallet) AddPresentation(data []byte) (string, error) {
+	data = bytes.TrimSpace(data)
+
+	p, err := vc.ParsePresentation(data)
+	if err != nil {
+		return "", err
+	}
+
+	id := p.ID
+	if id == "" {
+		id = digest(data)
+	}
+
+	tags := []spi.Tag{{Name: tagContent, Value: ContentPresentation}}
+	if p.Holder != "" {
+		tags = append(tags, spi.Tag{Name: tagHolder, Value: encodeTag(p.Holder)})
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if err := w.store.Put(key(ContentPresentation, id), data, tags...); err != nil {
+		return "", fmt.Errorf("put presentation %s: %w", id, err)
+	}
+
+	return id, nil
+}
+
+// Presentation returns the data of the presentation id.
+func (w *Wallet) Presentation(id string) ([]byte, error) {
+	return w.get(ContentPresentation, id)
+}
+
+// RemovePresentation removes the presentation id.
+func (w *Wallet) RemovePresentation(id string) error {
+	return w.remove(ContentPresentation, id)
+}
+
+// Presentations returns the presentations of holder, or all the presentations if holder is empty, by ID.
+func (w *Wallet) Presentations(holder string) ([]*Record, error) {
+	expression := tagContent + ":" + ContentPresentation
+	if holder != "" {
+		expression = tagHolder + ":" + encodeTag(holder)
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	return w.query(expression, nil)
+}
+
+// AddDID adds a DID document, replacing the document of the same DID.
+func (w *Wallet) AddDID(doc *did.Document) error {
+	data, err := doc.JSONBytes()
+	if err != nil {
+		return fmt.Errorf("marshal DID document %s: %w", doc.ID, err)
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if err := w.store.Put(key(ContentDID, doc.ID), data, spi.Tag{Name: tagContent, Value: ContentDID}); err != nil {
+		return fmt.Errorf("put DID document %s: %w", doc.ID, err)
+	}
+
+	return nil
+}
+
+// DID returns the document of the DID id.
+func (w *Wallet) DID(id string) (*did.Document, error) {
+	data, err := w.get(ContentDID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return did.ParseDocument(data)
+}
+
+// ResolveDocument returns the document of the DID id, so that the wallet resolves the DIDs it holds.
+func (w *Wallet) ResolveDocument(id string) (*did.Document, error) {
+	return w.DID(id)
+}
+
+// RemoveDID removes the document of the DID id.
+func (w *Wallet) RemoveDID(id string) error {
+	return w.remove(ContentDID, id)
+}
+
+// DIDs returns the DID documents of the wallet, by DID.
+func (w *Wallet) DIDs() ([]*did.Document, error) {
+	w.lock.Lock()
+	records, err := w.query(tagContent+":"+ContentDID, nil)
+	w.lock.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make([]*did.Document, len(records))
+
+	for i, record := range records {
+		if docs[i], err = did.ParseDocument(record.Data); err != nil {
+			return nil, fmt.Errorf("parse DID document %s: %w", record.ID, err)
+		}
+	}
+
+	return docs, nil
+}
+
+func (w *Wallet) get(content, id string) ([]byte, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	data, err := w.store.Get(key(content, id))
+	if errors.Is(err, spi.ErrDataNotFound) {
+		return nil, fmt.Errorf("%w: %s %s", ErrNotFound, content, id)
+	} else if err != nil {
+		return nil, fmt.Errorf("get %s %s: %w", content, id, err)
+	}
+
+	return data, nil
+}
+
+func (w *Wallet) remove(content, id string) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if _, err := w.store.Get(key(content, id)); errors.Is(err, spi.ErrDataNotFound) {
+		return fmt.Errorf("%w: %s %s", ErrNotFound, content, id)
+	} else if err != nil {
+		return fmt.Errorf("get %s %s: %w", content, id, err)
+	}
+
+	return w.store.Delete(key(content, id))
+}
+
+// query returns the items matching the store query expression and the tags filter, if any, by ID.
+func (w *Wallet) query(expression string, filter func(tags []spi.Tag) bool) ([]*Record, error) {
+	it, err := w.store.Query(expression)
+	if err != nil {
+		return nil, fmt.Errorf("query wallet: %w", err)
+	}
+	defer spi.Close(it)
+
+	var records []*Record
+
+	for {
+		ok, err := it.Next()
+		if err != nil {
+			return nil, fmt.Errorf("query wallet: %w", err)
+		}
+
+		if !ok {
+			break
+		}
+
+		tags, err := it.Tags()
+		if err != nil {
+			return nil, fmt.Errorf("query wallet: %w", err)
+		}
+
+		if filter != nil && !filter(tags) {
+			continue
+		}
+
+		k, err := it.Key()
+		if err != nil {
+			return nil, fmt.Errorf("query wallet: %w", err)
+		}
+
+		data, err := it.Value()
+		if err != nil {
+			return nil, fmt.Errorf("query wallet: %w", err)
+		}
+
+		records = append(records, &Record{ID: k[strings.Index(k, "/")+1:], Data: data})
+	}
+
+	sort.Slice(records, func(i, j int) bool { return records[i].ID < records[j].ID })
+
+	return records, nil
+}
+
+// matches reports whether the tags of a credential meet the query criteria.
+func (q *Query) matches(tags []spi.Tag) bool {
+	values := make(map[string][]string, len(tags))
+	for _, tag := range tags {
+		values[tag.Name] = append(values[tag.Name], tag.Value)
+	}
+
+	if !contains(values[tagContent], ContentCredential) {
+		return false
+	}
+
+	for _, t := range q.Types {
+		if !contains(values[tagType], encodeTag(t)) {
+			return false
+		}
+	}
+
+	if len(q.Issuers) > 0 {
+		issued := false
+		for _, issuer := range q.Issuers {
+			issued = issued || contains(values[tagIssuer], encodeTag(issuer))
+		}
+
+		if !issued {
+			return false
+		}
+	}
+
+	if q.Subject != "" && !contains(values[tagSubject], encodeTag(q.Subject)) {
+		return false
+	}
+
+	issued, hasIssued := unixTag(values[tagIssued])
+	expires, hasExpires := unixTag(values[tagExpires])
+
+	if !q.ExpiresBefore.IsZero() && (!hasExpires || !expires.Before(q.ExpiresBefore)) {
+		return false
+	}
+
+	if !q.ValidAt.IsZero() {
+		if hasIssued && q.ValidAt.Before(issued) {
+			return false
+		}
+
+		if hasExpires && !q.ValidAt.Before(expires) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func unixTag(values []string) (time.Time, bool) {
+	if len(values) == 0 {
+		return time.Time{}, false
+	}
+
+	seconds, err := strconv.ParseInt(values[0], 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return time.Unix(seconds, 0), true
+}
+
+func key(content, id string) string {
+	return content + "/" + id
+}
+
+func digest(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func encodeTag(value string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(value))
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
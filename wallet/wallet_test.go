@@ -0,0 +1,304 @@
+package wallet
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zRich/zFusion/common/crypto"
+	"github.com/zRich/zFusion/did"
+	"github.com/zRich/zFusion/did/vc"
+	"github.com/zRich/zFusion/kms"
+	"github.com/zRich/zFusion/storage/leveldb"
+	"github.com/zRich/zFusion/storage/spi"
+)
+
+const (
+	peerDID  = "did:zfusion:peer1"
+	otherDID = "did:zfusion:peer2"
+	aliceDID = "did:example:alice"
+	bobDID   = "did:example:bob"
+)
+
+// party is a DID with an Ed25519 key #key-1 for assertions and authentication.
+type party struct {
+	doc    *did.Document
+	signer crypto.Signer
+}
+
+func newParty(t *testing.T, keys *kms.LocalKMS, id string) *party {
+	t.Helper()
+
+	keyID, _, err := keys.Create(crypto.Ed25519)
+	require.NoError(t, err)
+
+	vm, err := keys.VerificationMethod(keyID, id+"#key-1", id)
+	require.NoError(t, err)
+
+	signer, err := keys.SignerFor(vm)
+	require.NoError(t, err)
+
+	doc := did.BuildDoc(
+		did.WithVerificationMethod([]did.VerificationMethod{*vm}),
+		did.WithAssertion([]did.Verification{*did.NewReferencedVerification(vm, did.AssertionMethod)}),
+		did.WithAuthentication([]did.Verification{*did.NewReferencedVerification(vm, did.Authentication)}),
+	)
+	doc.ID = id
+
+	return &party{doc: doc, signer: signer}
+}
+
+func newStores(t *testing.T) (spi.Store, *kms.LocalKMS) {
+	t.Helper()
+
+	p := leveldb.NewProvider(filepath.Join(t.TempDir(), "db"))
+	t.Cleanup(func() { require.NoError(t, p.Close()) })
+
+	store, err := p.OpenStore(StoreName)
+	require.NoError(t, err)
+
+	keyStore, err := p.OpenStore(kms.StoreName)
+	require.NoError(t, err)
+
+	keys, err := kms.New(keyStore, []byte("passphrase"), kms.WithIterations(10))
+	require.NoError(t, err)
+
+	return store, keys
+}
+
+func newCredential(id, credType, subject string, issued time.Time, expires *time.Time) *vc.Credential {
+	return &vc.Credential{
+		Context:       []string{vc.ContextV1},
+		CustomContext: []interface{}{map[string]interface{}{"@vocab": "https://example.com/vocab#"}},
+		ID:            id,
+		Types:         []string{vc.TypeVerifiableCredential, credType},
+		Issued:        &issued,
+		Expired:       expires,
+		Subjects:      []vc.Subject{{ID: subject, Claims: map[string]interface{}{"asset": "urn:asset:42"}}},
+	}
+}
+
+func issue(t *testing.T, issuer *party, cred *vc.Credential) []byte {
+	t.Helper()
+
+	require.NoError(t, vc.Issue(cred, issuer.doc, "#key-1", issuer.signer))
+
+	data, err := json.Marshal(cred)
+	require.NoError(t, err)
+
+	return data
+}
+
+func TestWallet(t *testing.T) {
+	store, keys := newStores(t)
+	w := New(store)
+
+	issuer := newParty(t, keys, peerDID)
+	other := newParty(t, keys, otherDID)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	nextWeek, lastWeek := now.Add(7*24*time.Hour), now.Add(-7*24*time.Hour)
+	nextYear := now.AddDate(1, 0, 0)
+
+	ownership := issue(t, issuer, newCredential("urn:cred:1", "OwnershipCredential", aliceDID, lastWeek, nil))
+	license := issue(t, issuer, newCredential("urn:cred:2", "LicenseCredential", aliceDID, lastWeek, &nextWeek))
+	expired := issue(t, other, newCredential("urn:cred:3", "LicenseCredential", aliceDID, lastWeek.AddDate(-1, 0, 0),
+		&lastWeek))
+	bobs := issue(t, other, newCredential("urn:cred:4", "OwnershipCredential", bobDID, lastWeek, &nextYear))
+
+	token, err := vc.IssueJWT(newCredential("", "LicenseCredential", bobDID, now, nil), issuer.doc, "#key-1",
+		issuer.signer)
+	require.NoError(t, err)
+
+	for _, data := range [][]byte{ownership, license, expired, bobs} {
+		_, err := w.AddCredential(data)
+		require.NoError(t, err)
+	}
+
+	jwtID, err := w.AddCredential([]byte(token))
+	require.NoError(t, err)
+	require.Contains(t, jwtID, "sha256:")
+
+	data, err := w.Credential(jwtID)
+	require.NoError(t, err)
+	require.Equal(t, token, string(data))
+
+	tests := []struct {
+		name     string
+		query    Query
+		expected []string
+	}{
+		{"all", Query{}, []string{"sha256", "urn:cred:1", "urn:cred:2", "urn:cred:3", "urn:cred:4"}},
+		{"type", Query{Types: []string{"LicenseCredential"}}, []string{"sha256", "urn:cred:2", "urn:cred:3"}},
+		{"issuer", Query{Issuers: []string{otherDID}}, []string{"urn:cred:3", "urn:cred:4"}},
+		{
+			"issuers and type",
+			Query{Issuers: []string{peerDID, otherDID}, Types: []string{"OwnershipCredential"}},
+			[]string{"urn:cred:1", "urn:cred:4"},
+		},
+		{"subject", Query{Subject: bobDID}, []string{"sha256", "urn:cred:4"}},
+		{
+			"subject and issuer",
+			Query{Subject: aliceDID, Issuers: []string{peerDID}},
+			[]string{"urn:cred:1", "urn:cred:2"},
+		},
+		{"expires before", Query{ExpiresBefore: now.AddDate(0, 1, 0)}, []string{"urn:cred:2", "urn:cred:3"}},
+		{"valid at", Query{Subject: aliceDID, ValidAt: now}, []string{"urn:cred:1", "urn:cred:2"}},
+		{"valid later", Query{ValidAt: nextWeek}, []string{"sha256", "urn:cred:1", "urn:cred:4"}},
+		{"not yet valid", Query{Subject: bobDID, ValidAt: lastWeek.Add(-time.Hour)}, nil},
+		{"no match", Query{Types: []string{"UnknownCredential"}}, nil},
+	}
+
+	for _, tc := range tests {
+		records, err := w.Credentials(&tc.query)
+		require.NoError(t, err, tc.name)
+
+		var ids []string
+
+		for _, record := range records {
+			id := record.ID
+			if strings.HasPrefix(id, "sha256:") {
+				// the JWT credential without ID is identified by its digest.
+				id = "sha256"
+			}
+
+			ids = append(ids, id)
+		}
+
+		require.Equal(t, tc.expected, ids, tc.name)
+	}
+
+	require.NoError(t, w.RemoveCredential("urn:cred:3"))
+	require.ErrorIs(t, w.RemoveCredential("urn:cred:3"), ErrNotFound)
+
+	_, err = w.Credential("urn:cred:3")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	records, err := w.Credentials(&Query{Issuers: []string{otherDID}})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+
+	_, err = w.AddCredential([]byte(`{"id": "urn:cred:5"}`))
+	require.ErrorIs(t, err, vc.ErrInvalidCredential)
+}
+
+func TestWalletDIDs(t *testing.T) {
+	store, keys := newStores(t)
+	w := New(store)
+
+	for _, id := range []string{bobDID, aliceDID} {
+		require.NoError(t, w.AddDID(newParty(t, keys, id).doc))
+	}
+
+	doc, err := w.ResolveDocument(aliceDID)
+	require.NoError(t, err)
+	require.Equal(t, aliceDID, doc.ID)
+	require.Len(t, doc.Authentication, 1)
+
+	docs, err := w.DIDs()
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	require.Equal(t, aliceDID, docs[0].ID)
+	require.Equal(t, bobDID, docs[1].ID)
+
+	require.NoError(t, w.RemoveDID(bobDID))
+
+	_, err = w.DID(bobDID)
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestPresent(t *testing.T) {
+	store, keys := newStores(t)
+	w := New(store)
+
+	issuer := newParty(t, keys, peerDID)
+	alice := newParty(t, keys, aliceDID)
+	require.NoError(t, w.AddDID(alice.doc))
+
+	now := time.Now().UTC().Truncate(time.Second)
+	lastWeek, yesterday := now.Add(-7*24*time.Hour), now.Add(-24*time.Hour)
+
+	for i, cred := range []*vc.Credential{
+		newCredential("urn:cred:1", "OwnershipCredential", aliceDID, lastWeek, nil),
+		newCredential("urn:cred:2", "LicenseCredential", aliceDID, lastWeek, &yesterday),
+		newCredential("urn:cred:3", "LicenseCredential", aliceDID, lastWeek, nil),
+		newCredential("urn:cred:4", "OwnershipCredential", bobDID, lastWeek, nil),
+	} {
+		id, err := w.AddCredential(issue(t, issuer, cred))
+		require.NoError(t, err)
+		require.Equal(t, fmt.Sprintf("urn:cred:%d", i+1), id)
+	}
+
+	req := &PresentationRequest{
+		Queries: []Query{
+			{Types: []string{"OwnershipCredential"}, Subject: aliceDID, Issuers: []string{peerDID}},
+			{Types: []string{"LicenseCredential"}, Subject: aliceDID},
+			{Subject: aliceDID, Issuers: []string{peerDID}},
+		},
+		Holder: aliceDID,
+	}
+
+	p, err := w.Present(req)
+	require.NoError(t, err)
+	require.Equal(t, aliceDID, p.Holder)
+	require.Len(t, p.Credentials, 2)
+
+	for i, id := range []string{"urn:cred:1", "urn:cred:3"} {
+		cred, err := vc.ParseCredential(p.Credentials[i])
+		require.NoError(t, err)
+		require.Equal(t, id, cred.ID)
+	}
+
+	// the holder signs the presentation with the challenge and domain of the verifier.
+	opts := []vc.Option{vc.WithChallenge("challenge"), vc.WithDomain("example.com")}
+	require.NoError(t, vc.SignPresentation(p, alice.doc, "#key-1", alice.signer, opts...))
+
+	data, err := json.Marshal(p)
+	require.NoError(t, err)
+
+	resolver := mockResolver{peerDID: issuer.doc, aliceDID: alice.doc}
+	result, err := vc.VerifyPresentation(data, append(opts, vc.WithDocumentResolver(resolver))...)
+	require.NoError(t, err)
+	require.True(t, result.Verified())
+
+	id, err := w.AddPresentation(data)
+	require.NoError(t, err)
+
+	presentations, err := w.Presentations(aliceDID)
+	require.NoError(t, err)
+	require.Equal(t, []*Record{{ID: id, Data: data}}, presentations)
+
+	presentations, err = w.Presentations(bobDID)
+	require.NoError(t, err)
+	require.Empty(t, presentations)
+
+	require.NoError(t, w.RemovePresentation(id))
+
+	_, err = w.Presentation(id)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	// the license expired yesterday was valid last week.
+	req.Time = lastWeek.Add(time.Hour)
+	p, err = w.Present(req)
+	require.NoError(t, err)
+	require.Len(t, p.Credentials, 3)
+
+	req.Queries = append(req.Queries, Query{Types: []string{"UsageCredential"}})
+	_, err = w.Present(req)
+	require.ErrorIs(t, err, ErrNoMatch)
+}
+
+type mockResolver map[string]*did.Document
+
+func (r mockResolver) ResolveDocument(id string) (*did.Document, error) {
+	doc, ok := r[id]
+	if !ok {
+		return nil, fmt.Errorf("DID %s not found", id)
+	}
+
+	return doc, nil
+}